@@ -0,0 +1,144 @@
+/*
+Apply schema migrations of services to postgres.
+*/
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/KonnorFrik/BinaryTentacles/internal/migrations"
+	"github.com/KonnorFrik/BinaryTentacles/pkg/config"
+	loggingWrap "github.com/KonnorFrik/BinaryTentacles/pkg/logging"
+	"github.com/KonnorFrik/BinaryTentacles/pkg/postgres"
+	"github.com/KonnorFrik/BinaryTentacles/pkg/retry"
+)
+
+// Config - settings of migrator.
+type Config struct {
+	Postgres postgres.Config `yaml:"postgres"`
+	// Timeout - time limit of connect and migrate.
+	Timeout time.Duration `yaml:"timeout" env:"MIGRATE_TIMEOUT" env-default:"5m" env-description:"Time limit of connect and migrate."`
+	// DryRun - only list pending migrations.
+	DryRun bool `yaml:"dry_run" env:"MIGRATE_DRY_RUN" env-default:"false" env-description:"Only list pending migrations, don't apply."`
+}
+
+// Validate - implement config.Validator interface.
+func (c *Config) Validate() error {
+	if !c.Postgres.Enabled() {
+		return errors.New("postgres dsn is required")
+	}
+
+	if c.Timeout <= 0 {
+		return errors.New("timeout must be positive")
+	}
+
+	return nil
+}
+
+// dialPolicy - repeat of connection to postgres at start.
+var dialPolicy = retry.Policy{
+	Attempts: 5,
+	Delay:    time.Second,
+	MaxDelay: 10 * time.Second,
+	Timeout:  5 * time.Second,
+}
+
+func main() {
+	var cfg Config
+	loaded, err := config.Load(os.Args[0], os.Args[1:], &cfg)
+
+	if errors.Is(err, config.ErrHelp) {
+		return
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	if loaded.PrintConfig {
+		if err = config.Print(os.Stdout, &cfg); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		return
+	}
+
+	logger := loggingWrap.Default()
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeout)
+	defer cancel()
+	pool, err := postgres.Connect(ctx, cfg.Postgres, dialPolicy)
+
+	if err != nil {
+		logger.LogAttrs(
+			ctx,
+			slog.LevelError,
+			"[Migrator/Connect]",
+			slog.String("error", err.Error()),
+		)
+		os.Exit(1)
+	}
+
+	defer pool.Close()
+
+	if cfg.DryRun {
+		pending, err := migrations.Pending(ctx, pool)
+
+		if err != nil {
+			logger.LogAttrs(
+				ctx,
+				slog.LevelError,
+				"[Migrator/Pending]",
+				slog.String("error", err.Error()),
+			)
+			pool.Close()
+			os.Exit(1)
+		}
+
+		for _, migration := range pending {
+			logger.LogAttrs(
+				ctx,
+				slog.LevelInfo,
+				"[Migrator/Pending]",
+				slog.String("version", migration.Version),
+			)
+		}
+
+		return
+	}
+
+	applied, err := migrations.Up(ctx, pool)
+
+	for _, migration := range applied {
+		logger.LogAttrs(
+			ctx,
+			slog.LevelInfo,
+			"[Migrator/Up]",
+			slog.String("applied", migration.Version),
+		)
+	}
+
+	if err != nil {
+		logger.LogAttrs(
+			ctx,
+			slog.LevelError,
+			"[Migrator/Up]",
+			slog.String("error", err.Error()),
+		)
+		pool.Close()
+		os.Exit(1)
+	}
+
+	logger.LogAttrs(
+		ctx,
+		slog.LevelInfo,
+		"[Migrator/Up]",
+		slog.Int("applied count", len(applied)),
+	)
+}
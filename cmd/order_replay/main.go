@@ -0,0 +1,170 @@
+/*
+Rebuild projection and snapshots of every order of order_service from history of orders in postgres.
+Rebuilt orders are dropped from cache of orders in redis, so order_service reads them from postgres.
+*/
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/KonnorFrik/BinaryTentacles/cmd/order_service/v1/usecase/order"
+	"github.com/KonnorFrik/BinaryTentacles/cmd/order_service/v1/usecase/repository"
+	redCache "github.com/KonnorFrik/BinaryTentacles/pkg/cache/redis"
+	"github.com/KonnorFrik/BinaryTentacles/pkg/config"
+	loggingWrap "github.com/KonnorFrik/BinaryTentacles/pkg/logging"
+	"github.com/KonnorFrik/BinaryTentacles/pkg/postgres"
+	"github.com/KonnorFrik/BinaryTentacles/pkg/retry"
+)
+
+// Config - settings of order replay.
+type Config struct {
+	Postgres postgres.Config `yaml:"postgres"`
+	// Redis - redis of order_service with cached orders.
+	Redis redCache.Config `yaml:"redis"`
+	// RedisKeyPrefix - prefix of keys of orders in redis, same as of order_service.
+	RedisKeyPrefix string `yaml:"redis_key_prefix" env:"ORDER_REDIS_KEY_PREFIX" env-description:"Prefix of keys of orders and events in redis."`
+	// Timeout - time limit of connect and replay.
+	Timeout time.Duration `yaml:"timeout" env:"REPLAY_TIMEOUT" env-default:"1h" env-description:"Time limit of connect and replay."`
+	// Verbose - log every rebuilt order.
+	Verbose bool `yaml:"verbose" env:"REPLAY_VERBOSE" env-default:"false" env-description:"Log every rebuilt order."`
+}
+
+// Validate - implement config.Validator interface.
+func (c *Config) Validate() error {
+	var errs []error
+
+	if !c.Postgres.Enabled() {
+		errs = append(errs, errors.New("postgres dsn is required"))
+	}
+
+	if err := c.Redis.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if c.Timeout <= 0 {
+		errs = append(errs, errors.New("timeout must be positive"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// dialPolicy - repeat of connection to postgres at start.
+var dialPolicy = retry.Policy{
+	Attempts: 5,
+	Delay:    time.Second,
+	MaxDelay: 10 * time.Second,
+	Timeout:  5 * time.Second,
+}
+
+func main() {
+	var cfg Config
+	loaded, err := config.Load(os.Args[0], os.Args[1:], &cfg)
+
+	if errors.Is(err, config.ErrHelp) {
+		return
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	if loaded.PrintConfig {
+		if err = config.Print(os.Stdout, &cfg); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		return
+	}
+
+	logger := loggingWrap.Default()
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeout)
+	defer cancel()
+	pool, err := postgres.Connect(ctx, cfg.Postgres, dialPolicy)
+
+	if err != nil {
+		logger.LogAttrs(
+			ctx,
+			slog.LevelError,
+			"[OrderReplay/Connect]",
+			slog.String("error", err.Error()),
+		)
+		os.Exit(1)
+	}
+
+	defer pool.Close()
+	cache, err := redCache.Connect(ctx, cfg.Redis, dialPolicy, redCache.WithKeyPrefix(cfg.RedisKeyPrefix))
+
+	if err != nil {
+		logger.LogAttrs(
+			ctx,
+			slog.LevelError,
+			"[OrderReplay/Connect]",
+			slog.String("error", err.Error()),
+		)
+		pool.Close()
+		os.Exit(1)
+	}
+
+	defer cache.Close(context.Background())
+	var (
+		store      = repository.NewPostgres(pool)
+		cached     = repository.NewCached(store, cache, 0)
+		notDropped int
+	)
+
+	count, err := store.Replay(ctx, func(ord *order.Order) {
+		if err := cached.Drop(ctx, ord.Id); err != nil {
+			notDropped++
+			logger.LogAttrs(
+				ctx,
+				slog.LevelError,
+				"[OrderReplay/Drop]",
+				slog.String("order", ord.Id),
+				slog.String("error", err.Error()),
+			)
+		}
+
+		if cfg.Verbose {
+			logger.LogAttrs(
+				ctx,
+				slog.LevelInfo,
+				"[OrderReplay/Replay]",
+				slog.String("order", ord.Id),
+				slog.Uint64("revision", ord.Revision),
+				slog.String("status", ord.Status.String()),
+			)
+		}
+	})
+
+	// Not dropped orders are served from cache until ttl of it.
+	if err == nil && notDropped > 0 {
+		err = fmt.Errorf("%d rebuilt orders are not dropped from cache", notDropped)
+	}
+
+	if err != nil {
+		logger.LogAttrs(
+			ctx,
+			slog.LevelError,
+			"[OrderReplay/Replay]",
+			slog.Int("rebuilt count", count),
+			slog.String("error", err.Error()),
+		)
+		cache.Close(context.Background())
+		pool.Close()
+		os.Exit(1)
+	}
+
+	logger.LogAttrs(
+		ctx,
+		slog.LevelInfo,
+		"[OrderReplay/Replay]",
+		slog.Int("rebuilt count", count),
+	)
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/KonnorFrik/BinaryTentacles/cmd/order_service/v1/usecase/availability"
+	redCache "github.com/KonnorFrik/BinaryTentacles/pkg/cache/redis"
+	"github.com/KonnorFrik/BinaryTentacles/pkg/postgres"
+)
+
+// Config - settings of OrderService.
+// Loaded by config.Load, see it for sources of settings.
+type Config struct {
+	// ListenAddr - address of gRPC server.
+	ListenAddr string `yaml:"listen_addr" env:"LISTEN_ADDR" env-default:":8888" env-description:"Address of gRPC server."`
+	// MetricsAddr - address of http server with prometheus metrics.
+	MetricsAddr string `yaml:"metrics_addr" env:"METRICS_ADDR" env-default:":2112" env-description:"Address of http server with prometheus metrics."`
+	// SpotInstrumentAddr - address of SpotInstrumentService.
+	SpotInstrumentAddr string `yaml:"spot_instrument_addr" env:"SPOT_INSTRUMENT_ADDR" env-default:"spot_instrument:9999" env-description:"Address of SpotInstrumentService."`
+	// SpotInstrumentToken - token of service in SpotInstrumentService, with role 'internal_service'.
+	SpotInstrumentToken string `yaml:"spot_instrument_token" env:"SPOT_INSTRUMENT_TOKEN" env-description:"Token of service in SpotInstrumentService." secret:"true"`
+	// StartupTimeout - time limit of connect to dependencies at start.
+	StartupTimeout time.Duration `yaml:"startup_timeout" env:"STARTUP_TIMEOUT" env-default:"2m" env-description:"Time limit of connect to dependencies at start."`
+	// ShutdownTimeout - time limit of graceful shutdown.
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT" env-default:"30s" env-description:"Time limit of graceful shutdown."`
+	Tracing         TracingConfig `yaml:"tracing"`
+	// Redis - standalone or sentinel redis reading from master. Cluster mode and sentinel
+	// reading from nearest or random node are not supported: both use cluster client, and
+	// order is saved with events of user, history and outbox in one transaction on many
+	// keys, which can't be in different slots.
+	Redis redCache.Config `yaml:"redis"`
+	// RedisKeyPrefix - prefix of keys of orders and events in redis, so redis db may be shared.
+	RedisKeyPrefix string `yaml:"redis_key_prefix" env:"ORDER_REDIS_KEY_PREFIX" env-description:"Prefix of keys of orders and events in redis."`
+	// Postgres - durable storage of orders and events of users.
+	// Without it orders and events are stored only in redis with ttl.
+	Postgres postgres.Config `yaml:"postgres"`
+	// OrderCacheTTL - how long order read from postgres is kept in redis.
+	OrderCacheTTL time.Duration       `yaml:"order_cache_ttl" env:"ORDER_CACHE_TTL" env-default:"1m" env-description:"How long order read from postgres is kept in redis."`
+	MarketCache   availability.Config `yaml:"market_cache"`
+}
+
+// TracingConfig - settings of export of traces.
+type TracingConfig struct {
+	// JaegerURL - endpoint of jaeger collector.
+	JaegerURL string `yaml:"jaeger_url" env:"JAEGER_URL" env-default:"http://localhost:14268/api/traces" env-description:"Endpoint of jaeger collector."`
+	// ServiceName - name of service in traces.
+	ServiceName string `yaml:"service_name" env:"SERVICE_NAME" env-default:"server" env-description:"Name of service in traces."`
+}
+
+// Validate - implement config.Validator interface.
+func (c *Config) Validate() error {
+	var errs []error
+
+	if c.ListenAddr == "" || c.MetricsAddr == "" || c.SpotInstrumentAddr == "" {
+		errs = append(errs, errors.New("listen, metrics and SpotInstrumentService addresses can't be empty"))
+	}
+
+	if c.StartupTimeout <= 0 || c.ShutdownTimeout <= 0 {
+		errs = append(errs, errors.New("startup and shutdown timeouts must be positive"))
+	}
+
+	if u, err := url.Parse(c.Tracing.JaegerURL); err != nil || u.Scheme == "" || u.Host == "" {
+		errs = append(errs, fmt.Errorf("jaeger url %q is not absolute url", c.Tracing.JaegerURL))
+	}
+
+	if c.Tracing.ServiceName == "" {
+		errs = append(errs, errors.New("service name can't be empty"))
+	}
+
+	if err := c.Redis.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if c.Redis.Clustered() {
+		errs = append(errs, errors.New("redis cluster mode and sentinel reads from nearest or random are not supported, transactions of orders span many slots"))
+	}
+
+	if c.OrderCacheTTL <= 0 {
+		errs = append(errs, errors.New("order cache ttl must be positive"))
+	}
+
+	if c.MarketCache.TTL < 0 || c.MarketCache.NegativeTTL < 0 || c.MarketCache.LookupTimeout < 0 {
+		errs = append(errs, errors.New("market cache ttl and lookup timeout can't be negative"))
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/KonnorFrik/BinaryTentacles/pkg/auth"
+	redCache "github.com/KonnorFrik/BinaryTentacles/pkg/cache/redis"
+	"github.com/KonnorFrik/BinaryTentacles/pkg/postgres"
+	"github.com/KonnorFrik/BinaryTentacles/pkg/retry"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// dialPolicy - repeat of connection to dependency at start.
+var dialPolicy = retry.Policy{
+	Attempts: 5,
+	Delay:    time.Second,
+	MaxDelay: 10 * time.Second,
+	Timeout:  5 * time.Second,
+}
+
+// dependencies - connections used by usecase logic.
+type dependencies struct {
+	orderCache *redCache.Cache
+	spotConn   *grpc.ClientConn
+	// orderStore - postgres with orders, nil if not configured.
+	orderStore *pgxpool.Pool
+}
+
+// connectDependencies - connect to all dependencies from 'cfg'.
+// Fails if any dependency is unreachable after dialPolicy.
+func connectDependencies(
+	ctx context.Context,
+	cfg *Config,
+	logger *slog.Logger,
+) (
+	*dependencies,
+	error,
+) {
+	var (
+		deps dependencies
+		err  error
+	)
+
+	deps.orderCache, err = redCache.Connect(
+		ctx,
+		cfg.Redis,
+		dialPolicy,
+		redCache.WithSlog(logger),
+		redCache.WithKeyPrefix(cfg.RedisKeyPrefix),
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("connect to redis %s: %w", cfg.Redis.Addr, err)
+	}
+
+	deps.spotConn, err = dialSpotInstrument(ctx, cfg.SpotInstrumentAddr, cfg.SpotInstrumentToken, logger)
+
+	if err != nil {
+		deps.orderCache.Close(ctx)
+		return nil, fmt.Errorf("connect to SpotInstrumentService %s: %w", cfg.SpotInstrumentAddr, err)
+	}
+
+	if cfg.Postgres.Enabled() {
+		deps.orderStore, err = postgres.Connect(ctx, cfg.Postgres, dialPolicy)
+
+		if err != nil {
+			deps.Close(ctx)
+			return nil, fmt.Errorf("connect to postgres: %w", err)
+		}
+	}
+
+	return &deps, nil
+}
+
+// Close - close all connections of 'd'.
+func (d *dependencies) Close(ctx context.Context) error {
+	if d.orderStore != nil {
+		d.orderStore.Close()
+	}
+
+	return errors.Join(
+		d.spotConn.Close(),
+		d.orderCache.Close(ctx),
+	)
+}
+
+// dialSpotInstrument - connect to SpotInstrumentService at 'addr',
+// calls are authenticated by 'token'. Returns after connection is ready.
+func dialSpotInstrument(
+	ctx context.Context,
+	addr string,
+	token string,
+	logger *slog.Logger,
+) (
+	*grpc.ClientConn,
+	error,
+) {
+	conn, err := grpc.NewClient(
+		addr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithPerRPCCredentials(auth.Token(token)),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = retry.Do(ctx, dialPolicy, func(ctx context.Context) error {
+		return waitReady(ctx, conn)
+	}, func(attempt int, err error) {
+		logger.LogAttrs(
+			ctx,
+			slog.LevelWarn,
+			"[Server/dialSpotInstrument]",
+			slog.Int("attempt", attempt),
+			slog.String("error", err.Error()),
+		)
+	})
+
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	logger.LogAttrs(
+		ctx,
+		slog.LevelInfo,
+		"[Server/dialSpotInstrument]",
+		slog.String("Connection to", addr),
+		slog.String("Status", "Successfull"),
+	)
+	return conn, nil
+}
+
+// waitReady - start connect of 'conn' and wait until it ready or 'ctx' done.
+func waitReady(
+	ctx context.Context,
+	conn *grpc.ClientConn,
+) error {
+	conn.Connect()
+
+	for {
+		state := conn.GetState()
+
+		if state == connectivity.Ready {
+			return nil
+		}
+
+		if !conn.WaitForStateChange(ctx, state) {
+			return fmt.Errorf("connection is %s: %w", state, ctx.Err())
+		}
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"log/slog"
 
 	"github.com/KonnorFrik/BinaryTentacles/cmd/order_service/v1/usecase"
+	"github.com/KonnorFrik/BinaryTentacles/cmd/order_service/v1/usecase/book"
+	"github.com/KonnorFrik/BinaryTentacles/cmd/order_service/v1/usecase/feed"
 	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/logging"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -70,6 +72,27 @@ func wrapError(err error) error {
 	case errors.Is(err, usecase.ErrDoesNotExist):
 		code = codes.NotFound
 		msg = "object cannot be found"
+	case errors.Is(err, usecase.ErrMarketNotTrading):
+		code = codes.FailedPrecondition
+		msg = err.Error()
+	case errors.Is(err, usecase.ErrOrderFinalized):
+		code = codes.FailedPrecondition
+		msg = err.Error()
+	case errors.Is(err, usecase.ErrInvalidOrder), errors.Is(err, usecase.ErrInvalidRequest):
+		code = codes.InvalidArgument
+		msg = err.Error()
+	case errors.Is(err, usecase.ErrConcurrentChange):
+		code = codes.Aborted
+		msg = err.Error()
+	case errors.Is(err, book.ErrLagged):
+		code = codes.ResourceExhausted
+		msg = err.Error() + ", resync from new snapshot"
+	case errors.Is(err, feed.ErrLagged):
+		code = codes.ResourceExhausted
+		msg = err.Error() + ", resume from last received sequence"
+	case errors.Is(err, book.ErrClosed), errors.Is(err, feed.ErrClosed), errors.Is(err, usecase.ErrBooksOwned):
+		code = codes.Unavailable
+		msg = err.Error()
 	case errors.Is(err, usecase.ErrMarketUnavailable):
 		code = codes.FailedPrecondition
 		msg = "market is unavailable"
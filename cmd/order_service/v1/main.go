@@ -5,17 +5,22 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"sync"
 	"syscall"
-	"time"
 
 	"github.com/KonnorFrik/BinaryTentacles/cmd/order_service/v1/usecase"
+	"github.com/KonnorFrik/BinaryTentacles/cmd/order_service/v1/usecase/repository"
 	pb "github.com/KonnorFrik/BinaryTentacles/internal/generated/order_service/v1"
+	client "github.com/KonnorFrik/BinaryTentacles/internal/generated/spot_instrument/v1"
 	callChain "github.com/KonnorFrik/BinaryTentacles/pkg/call_chain"
+	"github.com/KonnorFrik/BinaryTentacles/pkg/config"
 	interceptor "github.com/KonnorFrik/BinaryTentacles/pkg/interceptor"
 	loggingWrap "github.com/KonnorFrik/BinaryTentacles/pkg/logging"
 
@@ -25,16 +30,33 @@ import (
 	"google.golang.org/grpc"
 )
 
-const (
-	laddr = ":8888"
-)
-
 func main() {
+	var cfg Config
+	loaded, err := config.Load(os.Args[0], os.Args[1:], &cfg)
+
+	if errors.Is(err, config.ErrHelp) {
+		return
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	if loaded.PrintConfig {
+		if err = config.Print(os.Stdout, &cfg); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		return
+	}
+
 	osSignalChan := make(chan os.Signal, 6)
 	signal.Notify(osSignalChan, os.Interrupt, syscall.SIGKILL, syscall.SIGTERM)
 
 	logger := loggingWrap.Default()
-	listener, err := net.Listen("tcp", laddr)
+	listener, err := net.Listen("tcp", cfg.ListenAddr)
 
 	if err != nil {
 		logger.LogAttrs(
@@ -46,7 +68,7 @@ func main() {
 		os.Exit(1)
 	}
 
-	tracer, err := NewTracer("http://localhost:14268/api/traces", "server")
+	tracer, err := NewTracer(cfg.Tracing.JaegerURL, cfg.Tracing.ServiceName)
 
 	if err != nil {
 		logger.LogAttrs(
@@ -58,10 +80,49 @@ func main() {
 		os.Exit(1)
 	}
 
+	startCtx, cancelStart := context.WithTimeout(context.Background(), cfg.StartupTimeout)
+	deps, err := connectDependencies(startCtx, &cfg, logger.Logger)
+
+	if err != nil {
+		cancelStart()
+		logger.LogAttrs(
+			nil,
+			slog.LevelError,
+			"[Server/connectDependencies]",
+			slog.String("error", err.Error()),
+		)
+		os.Exit(1)
+	}
+
+	orderUsecase, err := newUsecase(startCtx, &cfg, deps)
+	cancelStart()
+
+	if err != nil {
+		logger.LogAttrs(
+			nil,
+			slog.LevelError,
+			"[Server/newUsecase]",
+			slog.String("error", err.Error()),
+		)
+		os.Exit(1)
+	}
+
 	orderServer, err := NewServer(
+		orderUsecase,
 		WithSlog(logger.Logger),
 		WithOtelTracerProvider(tracer),
 	)
+
+	if err != nil {
+		logger.LogAttrs(
+			nil,
+			slog.LevelError,
+			"[Server/NewServer]",
+			slog.String("error", err.Error()),
+		)
+		os.Exit(1)
+	}
+
 	grpcServer := grpc.NewServer(
 		grpc.ChainUnaryInterceptor(
 			grpc_prometheus.UnaryServerInterceptor,
@@ -86,14 +147,32 @@ func main() {
 		),
 	)
 	pb.RegisterOrderServiceServer(grpcServer, orderServer)
+	grpc_prometheus.Register(grpcServer)
+	metricsServer := newMetricsServer(cfg.MetricsAddr)
+
+	go func() {
+		err := metricsServer.ListenAndServe()
+
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.LogAttrs(
+				nil,
+				slog.LevelError,
+				"[Server/Metrics]",
+				slog.String("error", err.Error()),
+			)
+		}
+	}()
+
 	logger.LogAttrs(
 		nil,
 		slog.LevelInfo,
 		"[Server/Listen]",
-		slog.String("Local address", laddr),
+		slog.String("Local address", cfg.ListenAddr),
 	)
 
 	gracefullShutdownChain := callChain.New(
+		orderUsecase.ShutdownOrderBooks,
+		orderUsecase.ShutdownOrderUpdates,
 		func(ctx context.Context) error {
 			select {
 			case <-ctx.Done():
@@ -104,7 +183,10 @@ func main() {
 			grpcServer.GracefulStop()
 			return nil
 		},
-		usecase.ShutdownOrderCache,
+		orderUsecase.ReleaseOrderBooks,
+		metricsServer.Shutdown,
+		orderUsecase.StopMarketWatch,
+		deps.Close,
 	)
 
 	var chainGroup sync.WaitGroup
@@ -119,7 +201,7 @@ func main() {
 			"[GracefullShutdownChain]",
 			slog.String("status", "start"),
 		)
-		ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
 		defer cancel()
 		ind, err := gracefullShutdownChain.Call(ctx)
 
@@ -156,3 +238,31 @@ func main() {
 
 	chainGroup.Wait()
 }
+
+// newUsecase - create a logic of service with settings 'cfg' and dependencies 'deps'.
+func newUsecase(
+	ctx context.Context,
+	cfg *Config,
+	deps *dependencies,
+) (
+	*usecase.Usecase,
+	error,
+) {
+	var opts = []usecase.Option{
+		usecase.WithOrderCache(deps.orderCache),
+		usecase.WithMarketCache(cfg.MarketCache),
+	}
+
+	if deps.orderStore != nil {
+		store := repository.NewPostgres(deps.orderStore)
+		opts = append(
+			opts,
+			usecase.WithOrderRepository(repository.NewCached(store, deps.orderCache, cfg.OrderCacheTTL)),
+			usecase.WithEventRepository(repository.NewPostgresEvents(deps.orderStore)),
+			usecase.WithOutbox(store),
+			usecase.WithHistoryRepository(store),
+		)
+	}
+
+	return usecase.New(ctx, client.NewSpotInstrumentServiceClient(deps.spotConn), opts...)
+}
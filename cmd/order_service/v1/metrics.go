@@ -0,0 +1,17 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// newMetricsServer - create http server with prometheus metrics on "/metrics".
+func newMetricsServer(addr string) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	return &http.Server{
+		Addr:    addr,
+		Handler: mux,
+	}
+}
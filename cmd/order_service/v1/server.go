@@ -6,6 +6,7 @@ import (
 	"log/slog"
 
 	"github.com/KonnorFrik/BinaryTentacles/cmd/order_service/v1/usecase"
+	"github.com/KonnorFrik/BinaryTentacles/cmd/order_service/v1/usecase/book"
 	pb "github.com/KonnorFrik/BinaryTentacles/internal/generated/order_service/v1"
 	"github.com/KonnorFrik/BinaryTentacles/pkg/interceptor"
 	"go.opentelemetry.io/otel/attribute"
@@ -14,6 +15,7 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 // Option - option for customize server at creation.
@@ -21,13 +23,18 @@ type Option func(*server) error
 
 type server struct {
 	pb.UnimplementedOrderServiceServer
-	logger *slog.Logger
-	tracer *tracesdk.TracerProvider
+	usecase *usecase.Usecase
+	logger  *slog.Logger
+	tracer  *tracesdk.TracerProvider
 }
 
-// NewServer - create a new server with options.
-func NewServer(opts ...Option) (*server, error) {
-	var srv server
+// NewServer - create a new server with logic 'uc' and options.
+func NewServer(uc *usecase.Usecase, opts ...Option) (*server, error) {
+	if uc == nil {
+		return nil, errors.New("'NewServer: usecase can't be nil")
+	}
+
+	var srv = server{usecase: uc}
 
 	for _, opt := range opts {
 		if err := opt(&srv); err != nil {
@@ -48,7 +55,7 @@ func (s *server) Create(
 ) {
 	const method = "Create"
 	defer s.startTraceMetdod(ctx, method)()
-	order, err := usecase.Create(ctx, req)
+	order, err := s.usecase.Create(ctx, req)
 
 	if err != nil {
 		return nil, s.wrapError(err, method)
@@ -69,7 +76,7 @@ func (s *server) OrderStatus(
 ) {
 	const method = "OrderStatus"
 	defer s.startTraceMetdod(ctx, method)()
-	order, err := usecase.OrderStatus(ctx, req)
+	order, err := s.usecase.OrderStatus(ctx, req)
 
 	if err != nil {
 		return nil, s.wrapError(err, method)
@@ -80,6 +87,28 @@ func (s *server) OrderStatus(
 	return &response, status.Error(codes.OK, "ok")
 }
 
+// Cancel - cancel a order.
+func (s *server) Cancel(
+	ctx context.Context,
+	req *pb.CancelRequest,
+) (
+	*pb.CancelResponse,
+	error,
+) {
+	const method = "Cancel"
+	defer s.startTraceMetdod(ctx, method)()
+	order, err := s.usecase.Cancel(ctx, req)
+
+	if err != nil {
+		return nil, s.wrapError(err, method)
+	}
+
+	var response pb.CancelResponse
+	response.OrderId = order.Id
+	response.Status = order.GetStatus()
+	return &response, status.Error(codes.OK, "ok")
+}
+
 // OrderUpdates - get order's status update in realtime.
 func (s *server) OrderUpdates(
 	req *pb.OrderUpdatesRequest,
@@ -87,35 +116,147 @@ func (s *server) OrderUpdates(
 ) error {
 	const method = "OrderUpdates"
 	defer s.startTraceMetdod(stream.Context(), method)()
-	order, err := usecase.OrderById(stream.Context(), req.GetOrderId())
+	watch, err := s.usecase.OrderUpdates(stream.Context(), req)
+
+	if err != nil {
+		return s.wrapError(err, method)
+	}
+
+	for update := range watch.Updates() {
+		var resp pb.OrderUpdatesResponse
+		resp.Status = update.Status
+		resp.Sequence = update.Sequence
+		resp.At = timestamppb.New(update.At)
+
+		if e := stream.Send(&resp); e != nil {
+			// TODO: catch a closed by a client connection
+			return e
+		}
+	}
+
+	return s.wrapError(watch.Err(), method)
+}
+
+// GetOrderBook - get aggregated price levels of market.
+func (s *server) GetOrderBook(
+	ctx context.Context,
+	req *pb.GetOrderBookRequest,
+) (
+	*pb.GetOrderBookResponse,
+	error,
+) {
+	const method = "GetOrderBook"
+	defer s.startTraceMetdod(ctx, method)()
+	snapshot, err := s.usecase.GetOrderBook(ctx, req)
+
+	if err != nil {
+		return nil, s.wrapError(err, method)
+	}
+
+	var response pb.GetOrderBookResponse
+	response.MarketId = req.GetMarketId()
+	book.SnapshotToProtobuf(&snapshot, &response)
+	return &response, status.Error(codes.OK, "ok")
+}
+
+// StreamOrderBook - get snapshot of market order book and it changes in realtime.
+func (s *server) StreamOrderBook(
+	req *pb.StreamOrderBookRequest,
+	stream grpc.ServerStreamingServer[pb.StreamOrderBookResponse],
+) error {
+	const method = "StreamOrderBook"
+	defer s.startTraceMetdod(stream.Context(), method)()
+	sub, snapshot, err := s.usecase.StreamOrderBook(stream.Context(), req)
 
 	if err != nil {
 		return s.wrapError(err, method)
 	}
 
-	statuses := order.UpdateStatus(stream.Context())
+	defer sub.Close()
+	var resp = pb.StreamOrderBookResponse{MarketId: req.GetMarketId()}
+	book.StreamSnapshotToProtobuf(&snapshot, &resp)
+
+	if e := stream.Send(&resp); e != nil {
+		return e
+	}
 
 	for {
 		var (
-			resp     = new(pb.OrderUpdatesResponse)
-			isClosed bool
+			update book.Update
+			isOpen bool
 		)
 
 		select {
 		case <-stream.Context().Done():
 			return nil
-		case resp.Status, isClosed = <-statuses:
+		case update, isOpen = <-sub.Updates():
 		}
 
-		if isClosed {
-			return nil
+		if !isOpen {
+			return s.wrapError(sub.Err(), method)
 		}
 
-		if e := stream.Send(resp); e != nil {
-			// TODO: catch a closed by a client connection
+		resp = pb.StreamOrderBookResponse{MarketId: req.GetMarketId()}
+		book.UpdateToProtobuf(&update, &resp)
+
+		if e := stream.Send(&resp); e != nil {
+			return e
+		}
+	}
+}
+
+// StreamUserOrders - get all order events of user in realtime.
+func (s *server) StreamUserOrders(
+	req *pb.StreamUserOrdersRequest,
+	stream grpc.ServerStreamingServer[pb.StreamUserOrdersResponse],
+) error {
+	const method = "StreamUserOrders"
+	defer s.startTraceMetdod(stream.Context(), method)()
+	events, err := s.usecase.StreamUserOrders(stream.Context(), req)
+
+	if err != nil {
+		return s.wrapError(err, method)
+	}
+
+	for event := range events.Events() {
+		var resp = pb.StreamUserOrdersResponse{Event: new(pb.OrderEvent)}
+		event.ToGrpcOrderEvent(resp.Event)
+
+		if e := stream.Send(&resp); e != nil {
 			return e
 		}
 	}
+
+	return s.wrapError(events.Err(), method)
+}
+
+// GetOrderHistory - get all events of order.
+func (s *server) GetOrderHistory(
+	ctx context.Context,
+	req *pb.GetOrderHistoryRequest,
+) (
+	*pb.GetOrderHistoryResponse,
+	error,
+) {
+	const method = "GetOrderHistory"
+	defer s.startTraceMetdod(ctx, method)()
+	events, err := s.usecase.GetOrderHistory(ctx, req)
+
+	if err != nil {
+		return nil, s.wrapError(err, method)
+	}
+
+	var response = pb.GetOrderHistoryResponse{
+		OrderId: req.GetOrderId(),
+		Events:  make([]*pb.OrderEvent, len(events)),
+	}
+
+	for i, event := range events {
+		response.Events[i] = new(pb.OrderEvent)
+		event.ToGrpcOrderEvent(response.Events[i])
+	}
+
+	return &response, status.Error(codes.OK, "ok")
 }
 
 // startTraceMetdod - start tracing.
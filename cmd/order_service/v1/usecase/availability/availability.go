@@ -0,0 +1,195 @@
+/*
+Local cache of market availability from SpotInstrumentService.
+Concurrent lookups of same market are deduplicated.
+Unavailable markets are cached with shorter TTL.
+*/
+package availability
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	client "github.com/KonnorFrik/BinaryTentacles/internal/generated/spot_instrument/v1"
+	"github.com/ilyakaznacheev/cleanenv"
+	"golang.org/x/sync/singleflight"
+)
+
+// Config - settings of availability cache.
+type Config struct {
+	// TTL - how long keep market which accept orders.
+	TTL time.Duration `yaml:"ttl" env:"MARKET_CACHE_TTL" env-default:"5s" env-description:"How long keep market which accept orders."`
+	// NegativeTTL - how long keep market which not accept orders or not exist.
+	NegativeTTL time.Duration `yaml:"negative_ttl" env:"MARKET_CACHE_NEGATIVE_TTL" env-default:"1s" env-description:"How long keep market which not accept orders or not exist."`
+	// LookupTimeout - time limit of one lookup shared by concurrent callers. Zero is no limit.
+	LookupTimeout time.Duration `yaml:"lookup_timeout" env:"MARKET_CACHE_LOOKUP_TIMEOUT" env-default:"5s" env-description:"Time limit of one lookup shared by concurrent callers."`
+}
+
+// NewConfig - create a new config for cache.
+// Read values from env, missing values set to defaults.
+func NewConfig() (Config, error) {
+	var config Config
+	err := cleanenv.ReadEnv(&config)
+	return config, err
+}
+
+// Result - availability of one market.
+type Result struct {
+	State  client.MarketState
+	Reason string
+	// Err - error of lookup, cached only if Negative.
+	Err error
+}
+
+// Lookup - ask availability of market with 'marketId'.
+// Returns true if result is negative and must be cached with NegativeTTL.
+type Lookup func(ctx context.Context, marketId string) (Result, bool, error)
+
+type entry struct {
+	result    Result
+	expiresAt time.Time
+}
+
+// Cache - availability of markets with TTL.
+// Cache is disabled until Enable, disabled cache always call Lookup.
+type Cache struct {
+	mut     sync.Mutex
+	config  Config
+	lookup  Lookup
+	group   singleflight.Group
+	enabled bool
+	// generation - changed on every invalidation,
+	// lookup started before invalidation is not stored.
+	generation uint64
+	entries    map[string]entry
+	now        func() time.Time
+}
+
+// New - create a new disabled Cache.
+func New(config Config, lookup Lookup) *Cache {
+	return &Cache{
+		config:  config,
+		lookup:  lookup,
+		entries: make(map[string]entry),
+		now:     time.Now,
+	}
+}
+
+// Get - return availability of market with 'marketId'.
+// Lookup is called on miss, once for all concurrent callers.
+// Lookup is not canceled with 'ctx' of caller who started it, it limited by LookupTimeout,
+// every caller stops wait when own 'ctx' is done.
+// Error of Lookup is returned as is and not cached.
+func (c *Cache) Get(
+	ctx context.Context,
+	marketId string,
+) (
+	Result,
+	error,
+) {
+	c.mut.Lock()
+	ent, found := c.entries[marketId]
+	generation := c.generation
+	enabled := c.enabled
+
+	if found && c.now().After(ent.expiresAt) {
+		delete(c.entries, marketId)
+		found = false
+	}
+
+	c.mut.Unlock()
+
+	if found {
+		requests.WithLabelValues(resultHit).Inc()
+		return ent.result, ent.result.Err
+	}
+
+	requests.WithLabelValues(resultMiss).Inc()
+	flight := c.group.DoChan(marketId, func() (any, error) {
+		lookupCtx := context.WithoutCancel(ctx)
+
+		if c.config.LookupTimeout > 0 {
+			var cancel context.CancelFunc
+			lookupCtx, cancel = context.WithTimeout(lookupCtx, c.config.LookupTimeout)
+			defer cancel()
+		}
+
+		result, negative, err := c.lookup(lookupCtx, marketId)
+
+		if err != nil {
+			return result, err
+		}
+
+		if enabled {
+			c.store(marketId, result, negative, generation)
+		}
+
+		return result, result.Err
+	})
+
+	select {
+	case <-ctx.Done():
+		return Result{}, ctx.Err()
+	case res := <-flight:
+		if res.Shared {
+			sharedLookups.Inc()
+		}
+
+		return res.Val.(Result), res.Err
+	}
+}
+
+// Invalidate - remove market with 'marketId' from cache.
+func (c *Cache) Invalidate(marketId string) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	c.generation++
+	delete(c.entries, marketId)
+	invalidations.Inc()
+}
+
+// Enable - clear cache and start store results.
+func (c *Cache) Enable() {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	c.generation++
+	c.enabled = true
+	clear(c.entries)
+}
+
+// Disable - clear cache and stop store results.
+// Used when changes of markets can't be tracked.
+func (c *Cache) Disable() {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	c.generation++
+	c.enabled = false
+	clear(c.entries)
+}
+
+// store - save 'result' of market 'marketId' if cache not invalidated since 'generation'.
+func (c *Cache) store(
+	marketId string,
+	result Result,
+	negative bool,
+	generation uint64,
+) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	if !c.enabled || c.generation != generation {
+		return
+	}
+
+	ttl := c.config.TTL
+
+	if negative {
+		ttl = c.config.NegativeTTL
+	}
+
+	if ttl <= 0 {
+		return
+	}
+
+	c.entries[marketId] = entry{result: result, expiresAt: c.now().Add(ttl)}
+}
@@ -0,0 +1,246 @@
+package availability
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	client "github.com/KonnorFrik/BinaryTentacles/internal/generated/spot_instrument/v1"
+)
+
+const marketId = "5d6f8857-fafe-432c-8380-2b340ec03bb7"
+
+var config = Config{TTL: time.Minute, NegativeTTL: time.Second}
+
+// counting - return Lookup which count calls and always return 'result'.
+func counting(calls *atomic.Int64, result Result, negative bool) Lookup {
+	return func(ctx context.Context, marketId string) (Result, bool, error) {
+		calls.Add(1)
+		return result, negative, nil
+	}
+}
+
+func TestCacheGet(t *testing.T) {
+	var (
+		calls  atomic.Int64
+		active = Result{State: client.MarketState_MARKET_STATE_ACTIVE}
+		cache  = New(config, counting(&calls, active, false))
+	)
+
+	cache.Get(context.Background(), marketId)
+
+	if calls.Load() != 1 {
+		t.Fatalf("Disabled: Got = %d calls, Want = 1\n", calls.Load())
+	}
+
+	cache.Enable()
+
+	for range 3 {
+		result, err := cache.Get(context.Background(), marketId)
+
+		if err != nil {
+			t.Fatalf("Got = %q\n", err)
+		}
+
+		if result.State != active.State {
+			t.Fatalf("Got = %d, Want = %d\n", result.State, active.State)
+		}
+	}
+
+	if calls.Load() != 2 {
+		t.Fatalf("Enabled: Got = %d calls, Want = 2\n", calls.Load())
+	}
+
+	cache.Invalidate(marketId)
+	cache.Get(context.Background(), marketId)
+
+	if calls.Load() != 3 {
+		t.Fatalf("Invalidated: Got = %d calls, Want = 3\n", calls.Load())
+	}
+}
+
+func TestCacheNegative(t *testing.T) {
+	var (
+		calls    atomic.Int64
+		notFound = errors.New("not found")
+		now      = time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+		cache    = New(config, counting(&calls, Result{Err: notFound}, true))
+	)
+
+	cache.now = func() time.Time { return now }
+	cache.Enable()
+
+	for range 2 {
+		if _, err := cache.Get(context.Background(), marketId); !errors.Is(err, notFound) {
+			t.Fatalf("Got = %q, Want = %q\n", err, notFound)
+		}
+	}
+
+	if calls.Load() != 1 {
+		t.Fatalf("Got = %d calls, Want = 1\n", calls.Load())
+	}
+
+	now = now.Add(config.NegativeTTL + time.Millisecond)
+	cache.Get(context.Background(), marketId)
+
+	if calls.Load() != 2 {
+		t.Fatalf("Expired: Got = %d calls, Want = 2\n", calls.Load())
+	}
+}
+
+func TestCacheLookupError(t *testing.T) {
+	var (
+		calls       atomic.Int64
+		unavailable = errors.New("unavailable")
+	)
+	cache := New(config, func(ctx context.Context, marketId string) (Result, bool, error) {
+		calls.Add(1)
+		return Result{}, false, unavailable
+	})
+	cache.Enable()
+
+	for range 2 {
+		if _, err := cache.Get(context.Background(), marketId); !errors.Is(err, unavailable) {
+			t.Fatalf("Got = %q, Want = %q\n", err, unavailable)
+		}
+	}
+
+	if calls.Load() != 2 {
+		t.Fatalf("Got = %d calls, Want = 2\n", calls.Load())
+	}
+}
+
+func TestCacheSingleflight(t *testing.T) {
+	var (
+		calls   atomic.Int64
+		release = make(chan struct{})
+		group   sync.WaitGroup
+	)
+	cache := New(config, func(ctx context.Context, marketId string) (Result, bool, error) {
+		calls.Add(1)
+		<-release
+		return Result{State: client.MarketState_MARKET_STATE_ACTIVE}, false, nil
+	})
+
+	for range 10 {
+		group.Add(1)
+
+		go func() {
+			defer group.Done()
+			cache.Get(context.Background(), marketId)
+		}()
+	}
+
+	// Let all callers reach lookup before release it.
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	group.Wait()
+
+	if calls.Load() != 1 {
+		t.Fatalf("Got = %d calls, Want = 1\n", calls.Load())
+	}
+}
+
+func TestCacheInvalidateDuringLookup(t *testing.T) {
+	var (
+		calls   atomic.Int64
+		started = make(chan struct{})
+		release = make(chan struct{})
+		cache   *Cache
+	)
+	cache = New(config, func(ctx context.Context, marketId string) (Result, bool, error) {
+		if calls.Add(1) == 1 {
+			close(started)
+			<-release
+		}
+
+		return Result{State: client.MarketState_MARKET_STATE_ACTIVE}, false, nil
+	})
+	cache.Enable()
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		cache.Get(context.Background(), marketId)
+	}()
+
+	<-started
+	cache.Invalidate(marketId)
+	close(release)
+	<-done
+
+	// Result of lookup started before invalidation must not be stored.
+	cache.Get(context.Background(), marketId)
+
+	if calls.Load() != 2 {
+		t.Fatalf("Got = %d calls, Want = 2\n", calls.Load())
+	}
+}
+
+func TestCacheFirstCallerCanceled(t *testing.T) {
+	var (
+		calls   atomic.Int64
+		started = make(chan struct{})
+		release = make(chan struct{})
+		active  = Result{State: client.MarketState_MARKET_STATE_ACTIVE}
+	)
+	cache := New(config, func(ctx context.Context, marketId string) (Result, bool, error) {
+		calls.Add(1)
+		close(started)
+
+		select {
+		case <-release:
+			return active, false, nil
+		case <-ctx.Done():
+			return Result{}, false, ctx.Err()
+		}
+	})
+	cache.Enable()
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+
+	go func() {
+		_, err := cache.Get(ctx, marketId)
+		first <- err
+	}()
+
+	<-started
+	second := make(chan Result, 1)
+
+	go func() {
+		result, _ := cache.Get(context.Background(), marketId)
+		second <- result
+	}()
+
+	// Let second caller join lookup.
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	if err := <-first; !errors.Is(err, context.Canceled) {
+		t.Fatalf("Got = %v, Want = %v\n", err, context.Canceled)
+	}
+
+	close(release)
+
+	if result := <-second; result.State != active.State {
+		t.Fatalf("Got = %d, Want = %d\n", result.State, active.State)
+	}
+
+	if calls.Load() != 1 {
+		t.Fatalf("Got = %d calls, Want = 1\n", calls.Load())
+	}
+}
+
+func TestCacheLookupTimeout(t *testing.T) {
+	cache := New(Config{LookupTimeout: 10 * time.Millisecond}, func(ctx context.Context, marketId string) (Result, bool, error) {
+		<-ctx.Done()
+		return Result{}, false, ctx.Err()
+	})
+
+	if _, err := cache.Get(context.Background(), marketId); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Got = %v, Want = %v\n", err, context.DeadlineExceeded)
+	}
+}
@@ -0,0 +1,43 @@
+package availability
+
+import "github.com/prometheus/client_golang/prometheus"
+
+const (
+	resultHit  = "hit"
+	resultMiss = "miss"
+)
+
+var (
+	// requests - lookups in cache by result: "hit" or "miss".
+	requests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "order_service",
+			Subsystem: "market_cache",
+			Name:      "requests_total",
+			Help:      "Market availability cache lookups by result: hit or miss.",
+		},
+		[]string{"result"},
+	)
+	// sharedLookups - misses served by lookup of other concurrent caller.
+	sharedLookups = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "order_service",
+			Subsystem: "market_cache",
+			Name:      "shared_lookups_total",
+			Help:      "Market availability cache misses served by concurrent lookup.",
+		},
+	)
+	// invalidations - markets removed from cache by change notification.
+	invalidations = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "order_service",
+			Subsystem: "market_cache",
+			Name:      "invalidations_total",
+			Help:      "Markets removed from availability cache by change notification.",
+		},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(requests, sharedLookups, invalidations)
+}
@@ -0,0 +1,457 @@
+/*
+In-memory limit order book with price-time priority matching.
+Every change of price levels get a sequence number, one more than previous,
+so subscribers can detect lost updates and resync from snapshot.
+*/
+package book
+
+import (
+	"cmp"
+	"errors"
+	"slices"
+	"sync"
+
+	pb "github.com/KonnorFrik/BinaryTentacles/internal/generated/order_service/v1"
+)
+
+var (
+	// ErrLagged - subscriber read updates too slow and was dropped.
+	// Subscriber must resync from a new snapshot.
+	ErrLagged = errors.New("order book subscriber is too slow")
+	// ErrClosed - book is closed, no more updates will be published.
+	ErrClosed = errors.New("order book is closed")
+)
+
+// subscriberBuffer - how many updates can wait for read in one subscription.
+const subscriberBuffer = 256
+
+// Level - aggregated orders at one price.
+type Level struct {
+	Price int64
+	// Quantity - sum of remaining quantity. Zero in update means level removed.
+	Quantity uint64
+	Orders   int
+}
+
+// Fill - one match of taker order with resting maker order.
+type Fill struct {
+	MakerOrderId string
+	TakerOrderId string
+	// Price - price of maker order.
+	Price    int64
+	Quantity uint64
+	// MakerDone - maker order is fully filled and removed from book.
+	MakerDone bool
+}
+
+// Snapshot - state of book after update with Sequence.
+type Snapshot struct {
+	Sequence uint64
+	// Bids - best (highest) price first.
+	Bids []Level
+	// Asks - best (lowest) price first.
+	Asks []Level
+}
+
+// Update - new state of changed levels.
+type Update struct {
+	Sequence uint64
+	Bids     []Level
+	Asks     []Level
+}
+
+// Book - order book of one market.
+type Book struct {
+	mut sync.Mutex
+	// bids - best (highest) price first.
+	bids []*level
+	// asks - best (lowest) price first.
+	asks        []*level
+	orders      map[string]*resting
+	sequence    uint64
+	subscribers map[*Subscription]struct{}
+	closed      bool
+}
+
+type level struct {
+	price    int64
+	quantity uint64
+	// queue - orders in time priority.
+	queue []*resting
+}
+
+type resting struct {
+	id        string
+	side      pb.OrderSide
+	price     int64
+	remaining uint64
+}
+
+// New - create an empty Book.
+func New() *Book {
+	return &Book{
+		orders:      make(map[string]*resting),
+		subscribers: make(map[*Subscription]struct{}),
+	}
+}
+
+// Place - match order 'id' against opposite side and rest remaining quantity in book.
+// Order matched while its price is same or better than best opposite price.
+// Returns fills in order of execution and quantity left in book.
+func (b *Book) Place(
+	id string,
+	side pb.OrderSide,
+	price int64,
+	quantity uint64,
+) (
+	fills []Fill,
+	rested uint64,
+) {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+
+	var changes changeSet
+	makers := b.side(opposite(side))
+
+	for quantity > 0 && len(*makers) > 0 {
+		best := (*makers)[0]
+
+		if !crosses(side, price, best.price) {
+			break
+		}
+
+		for quantity > 0 && len(best.queue) > 0 {
+			maker := best.queue[0]
+			matched := min(quantity, maker.remaining)
+			quantity -= matched
+			maker.remaining -= matched
+			best.quantity -= matched
+			fill := Fill{
+				MakerOrderId: maker.id,
+				TakerOrderId: id,
+				Price:        best.price,
+				Quantity:     matched,
+			}
+
+			if maker.remaining == 0 {
+				best.queue = best.queue[1:]
+				delete(b.orders, maker.id)
+				fill.MakerDone = true
+			}
+
+			fills = append(fills, fill)
+		}
+
+		changes.add(opposite(side), best)
+
+		if len(best.queue) == 0 {
+			*makers = (*makers)[1:]
+		}
+	}
+
+	if quantity > 0 {
+		b.rest(id, side, price, quantity, &changes)
+	}
+
+	b.publish(&changes)
+	return fills, quantity
+}
+
+// Rest - put order 'id' with remaining 'quantity' in book without match,
+// for rebuild of book from saved orders. Order already in book is kept.
+func (b *Book) Rest(
+	id string,
+	side pb.OrderSide,
+	price int64,
+	quantity uint64,
+) {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+
+	if _, exist := b.orders[id]; exist || quantity == 0 {
+		return
+	}
+
+	var changes changeSet
+	b.rest(id, side, price, quantity, &changes)
+	b.publish(&changes)
+}
+
+// rest - append order in queue of it price level.
+// Must be called with locked book.
+func (b *Book) rest(
+	id string,
+	side pb.OrderSide,
+	price int64,
+	quantity uint64,
+	changes *changeSet,
+) {
+	order := &resting{
+		id:        id,
+		side:      side,
+		price:     price,
+		remaining: quantity,
+	}
+	lvl := b.level(side, price)
+	lvl.queue = append(lvl.queue, order)
+	lvl.quantity += quantity
+	b.orders[id] = order
+	changes.add(side, lvl)
+}
+
+// Cancel - remove order 'id' from book.
+// Returns remaining quantity and false if order is not in book.
+func (b *Book) Cancel(id string) (uint64, bool) {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+
+	order, exist := b.orders[id]
+
+	if !exist {
+		return 0, false
+	}
+
+	delete(b.orders, id)
+	levels := b.side(order.side)
+	ind, found := findLevel(*levels, order.side, order.price)
+
+	if !found {
+		return order.remaining, true
+	}
+
+	lvl := (*levels)[ind]
+	lvl.queue = slices.DeleteFunc(lvl.queue, func(r *resting) bool { return r == order })
+	lvl.quantity -= order.remaining
+
+	if len(lvl.queue) == 0 {
+		*levels = slices.Delete(*levels, ind, ind+1)
+	}
+
+	var changes changeSet
+	changes.add(order.side, lvl)
+	b.publish(&changes)
+	return order.remaining, true
+}
+
+// Top - return best bid and best ask prices. Zero if side is empty.
+func (b *Book) Top() (bid, ask int64) {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+
+	if len(b.bids) > 0 {
+		bid = b.bids[0].price
+	}
+
+	if len(b.asks) > 0 {
+		ask = b.asks[0].price
+	}
+
+	return bid, ask
+}
+
+// Snapshot - return up to 'depth' best levels of each side.
+// Zero or negative 'depth' means all levels.
+func (b *Book) Snapshot(depth int) Snapshot {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+	return b.snapshot(depth)
+}
+
+// Subscribe - create a new subscription for updates after returned snapshot.
+// First update in subscription has sequence one more than snapshot.
+func (b *Book) Subscribe() (*Subscription, Snapshot) {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+
+	sub := &Subscription{
+		book:    b,
+		updates: make(chan Update, subscriberBuffer),
+	}
+	b.subscribers[sub] = struct{}{}
+
+	if b.closed {
+		b.drop(sub, ErrClosed)
+	}
+
+	return sub, b.snapshot(0)
+}
+
+// Close - drop all subscriptions with ErrClosed.
+// New subscriptions are dropped right away.
+func (b *Book) Close() {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+	b.closed = true
+
+	for sub := range b.subscribers {
+		b.drop(sub, ErrClosed)
+	}
+}
+
+// snapshot - must be called with locked book.
+func (b *Book) snapshot(depth int) Snapshot {
+	return Snapshot{
+		Sequence: b.sequence,
+		Bids:     levelsOf(b.bids, depth),
+		Asks:     levelsOf(b.asks, depth),
+	}
+}
+
+// side - return levels of side 's'.
+func (b *Book) side(s pb.OrderSide) *[]*level {
+	if s == pb.OrderSide_ORDER_SIDE_BUY {
+		return &b.bids
+	}
+
+	return &b.asks
+}
+
+// level - return level of side 's' at 'price', create it if not exist.
+func (b *Book) level(s pb.OrderSide, price int64) *level {
+	levels := b.side(s)
+	ind, found := findLevel(*levels, s, price)
+
+	if !found {
+		*levels = slices.Insert(*levels, ind, &level{price: price})
+	}
+
+	return (*levels)[ind]
+}
+
+// publish - assign next sequence to changes and send them to all subscriptions.
+// Subscription which can't accept update is dropped with ErrLagged.
+// Must be called with locked book.
+func (b *Book) publish(changes *changeSet) {
+	if changes.empty() {
+		return
+	}
+
+	b.sequence++
+	update := Update{
+		Sequence: b.sequence,
+		Bids:     changes.bids,
+		Asks:     changes.asks,
+	}
+
+	for sub := range b.subscribers {
+		select {
+		case sub.updates <- update:
+		default:
+			b.drop(sub, ErrLagged)
+		}
+	}
+}
+
+// drop - remove subscription 'sub' with error 'err'.
+// Must be called with locked book.
+func (b *Book) drop(sub *Subscription, err error) {
+	if _, exist := b.subscribers[sub]; !exist {
+		return
+	}
+
+	delete(b.subscribers, sub)
+	sub.err = err
+	close(sub.updates)
+}
+
+// Subscription - stream of updates from Book.
+type Subscription struct {
+	book    *Book
+	updates chan Update
+	err     error
+}
+
+// Updates - return channel of updates.
+// Channel closed after Close or when subscription dropped, see Err.
+func (s *Subscription) Updates() <-chan Update {
+	return s.updates
+}
+
+// Err - return reason of drop subscription.
+// Nil if subscription active or closed by Close.
+func (s *Subscription) Err() error {
+	s.book.mut.Lock()
+	defer s.book.mut.Unlock()
+	return s.err
+}
+
+// Close - stop receive updates.
+func (s *Subscription) Close() {
+	s.book.mut.Lock()
+	defer s.book.mut.Unlock()
+	s.book.drop(s, nil)
+}
+
+// changeSet - changed levels of one book operation.
+type changeSet struct {
+	bids []Level
+	asks []Level
+}
+
+// add - record state of level 'lvl' of side 's'.
+func (c *changeSet) add(s pb.OrderSide, lvl *level) {
+	state := Level{
+		Price:    lvl.price,
+		Quantity: lvl.quantity,
+		Orders:   len(lvl.queue),
+	}
+
+	if s == pb.OrderSide_ORDER_SIDE_BUY {
+		c.bids = append(c.bids, state)
+
+	} else {
+		c.asks = append(c.asks, state)
+	}
+}
+
+func (c *changeSet) empty() bool {
+	return len(c.bids) == 0 && len(c.asks) == 0
+}
+
+// findLevel - binary search of level with 'price' in levels of side 's'.
+func findLevel(levels []*level, s pb.OrderSide, price int64) (int, bool) {
+	return slices.BinarySearchFunc(levels, price, func(lvl *level, price int64) int {
+		if s == pb.OrderSide_ORDER_SIDE_BUY {
+			return cmp.Compare(price, lvl.price)
+		}
+
+		return cmp.Compare(lvl.price, price)
+	})
+}
+
+// levelsOf - copy up to 'depth' levels. Zero or negative 'depth' means all.
+func levelsOf(levels []*level, depth int) []Level {
+	if depth <= 0 || depth > len(levels) {
+		depth = len(levels)
+	}
+
+	var result = make([]Level, depth)
+
+	for i, lvl := range levels[:depth] {
+		result[i] = Level{
+			Price:    lvl.price,
+			Quantity: lvl.quantity,
+			Orders:   len(lvl.queue),
+		}
+	}
+
+	return result
+}
+
+// opposite - return side which orders of side 's' matched with.
+func opposite(s pb.OrderSide) pb.OrderSide {
+	if s == pb.OrderSide_ORDER_SIDE_BUY {
+		return pb.OrderSide_ORDER_SIDE_SELL
+	}
+
+	return pb.OrderSide_ORDER_SIDE_BUY
+}
+
+// crosses - report whether taker of side 's' with 'price' match resting 'bestPrice'.
+func crosses(s pb.OrderSide, price, bestPrice int64) bool {
+	if s == pb.OrderSide_ORDER_SIDE_BUY {
+		return price >= bestPrice
+	}
+
+	return price <= bestPrice
+}
@@ -0,0 +1,191 @@
+package book
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	pb "github.com/KonnorFrik/BinaryTentacles/internal/generated/order_service/v1"
+)
+
+const (
+	buy  = pb.OrderSide_ORDER_SIDE_BUY
+	sell = pb.OrderSide_ORDER_SIDE_SELL
+)
+
+func TestPlace(t *testing.T) {
+	var b = New()
+	b.Place("s1", sell, 101, 2)
+	b.Place("s2", sell, 101, 3)
+	b.Place("s3", sell, 102, 5)
+	b.Place("b1", buy, 99, 4)
+
+	fills, rested := b.Place("t1", buy, 101, 4)
+	wantFills := []Fill{
+		{MakerOrderId: "s1", TakerOrderId: "t1", Price: 101, Quantity: 2, MakerDone: true},
+		{MakerOrderId: "s2", TakerOrderId: "t1", Price: 101, Quantity: 2},
+	}
+
+	if !slices.Equal(fills, wantFills) || rested != 0 {
+		t.Fatalf("Got = %+v, %d\nWant = %+v, 0\n", fills, rested, wantFills)
+	}
+
+	fills, rested = b.Place("t2", buy, 103, 10)
+	wantFills = []Fill{
+		{MakerOrderId: "s2", TakerOrderId: "t2", Price: 101, Quantity: 1, MakerDone: true},
+		{MakerOrderId: "s3", TakerOrderId: "t2", Price: 102, Quantity: 5, MakerDone: true},
+	}
+
+	if !slices.Equal(fills, wantFills) || rested != 4 {
+		t.Fatalf("Got = %+v, %d\nWant = %+v, 4\n", fills, rested, wantFills)
+	}
+
+	got := b.Snapshot(0)
+	wantBids := []Level{{Price: 103, Quantity: 4, Orders: 1}, {Price: 99, Quantity: 4, Orders: 1}}
+
+	if !slices.Equal(got.Bids, wantBids) || len(got.Asks) != 0 {
+		t.Fatalf("Got = %+v\nWant bids = %+v\n", got, wantBids)
+	}
+
+	if bid, ask := b.Top(); bid != 103 || ask != 0 {
+		t.Fatalf("Got = %d/%d, Want = 103/0\n", bid, ask)
+	}
+}
+
+func TestSnapshotDepth(t *testing.T) {
+	var b = New()
+
+	for i, price := range []int64{10, 12, 11, 9} {
+		b.Place(string(rune('a'+i)), sell, price, 1)
+	}
+
+	got := b.Snapshot(2)
+	want := []Level{{Price: 9, Quantity: 1, Orders: 1}, {Price: 10, Quantity: 1, Orders: 1}}
+
+	if !slices.Equal(got.Asks, want) {
+		t.Fatalf("Got = %+v\nWant = %+v\n", got.Asks, want)
+	}
+
+	if got.Sequence != 4 {
+		t.Fatalf("Got = %d, Want = 4\n", got.Sequence)
+	}
+}
+
+func TestCancel(t *testing.T) {
+	var b = New()
+	b.Place("b1", buy, 100, 2)
+	b.Place("b2", buy, 100, 3)
+
+	remaining, ok := b.Cancel("b1")
+
+	if !ok || remaining != 2 {
+		t.Fatalf("Got = %d, %t, Want = 2, true\n", remaining, ok)
+	}
+
+	if _, ok := b.Cancel("b1"); ok {
+		t.Fatalf("Cancelled twice\n")
+	}
+
+	want := []Level{{Price: 100, Quantity: 3, Orders: 1}}
+
+	if got := b.Snapshot(0); !slices.Equal(got.Bids, want) {
+		t.Fatalf("Got = %+v\nWant = %+v\n", got.Bids, want)
+	}
+
+	b.Cancel("b2")
+
+	if got := b.Snapshot(0); len(got.Bids) != 0 {
+		t.Fatalf("Got = %+v, Want empty book\n", got.Bids)
+	}
+}
+
+func TestRest(t *testing.T) {
+	var b = New()
+	b.Rest("s1", sell, 101, 2)
+	b.Rest("s1", sell, 101, 2)
+	// Crossing order is not matched.
+	b.Rest("b1", buy, 102, 1)
+
+	want := Snapshot{
+		Sequence: 2,
+		Bids:     []Level{{Price: 102, Quantity: 1, Orders: 1}},
+		Asks:     []Level{{Price: 101, Quantity: 2, Orders: 1}},
+	}
+
+	if got := b.Snapshot(0); got.Sequence != want.Sequence || !slices.Equal(got.Bids, want.Bids) || !slices.Equal(got.Asks, want.Asks) {
+		t.Fatalf("Got = %+v\nWant = %+v\n", got, want)
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	var b = New()
+	b.Place("s1", sell, 101, 2)
+	sub, snapshot := b.Subscribe()
+	defer sub.Close()
+
+	if snapshot.Sequence != 1 || len(snapshot.Asks) != 1 {
+		t.Fatalf("Got = %+v\n", snapshot)
+	}
+
+	b.Place("b1", buy, 101, 3)
+	b.Cancel("b1")
+
+	want := []Update{
+		{
+			Sequence: 2,
+			Bids:     []Level{{Price: 101, Quantity: 1, Orders: 1}},
+			Asks:     []Level{{Price: 101}},
+		},
+		{
+			Sequence: 3,
+			Bids:     []Level{{Price: 101}},
+		},
+	}
+
+	for _, w := range want {
+		got := <-sub.Updates()
+
+		if got.Sequence != w.Sequence || !slices.Equal(got.Bids, w.Bids) || !slices.Equal(got.Asks, w.Asks) {
+			t.Fatalf("Got = %+v\nWant = %+v\n", got, w)
+		}
+	}
+}
+
+func TestSubscribeLagged(t *testing.T) {
+	var b = New()
+	sub, _ := b.Subscribe()
+
+	for i := range subscriberBuffer + 1 {
+		b.Place("b", buy, int64(i+1), 1)
+	}
+
+	var count int
+
+	for range sub.Updates() {
+		count++
+	}
+
+	if count != subscriberBuffer {
+		t.Fatalf("Got = %d, Want = %d\n", count, subscriberBuffer)
+	}
+
+	if !errors.Is(sub.Err(), ErrLagged) {
+		t.Fatalf("Got = %v, Want = %v\n", sub.Err(), ErrLagged)
+	}
+}
+
+func TestClose(t *testing.T) {
+	var books = NewBooks()
+	sub, _ := books.Get("m1").Subscribe()
+	books.Close()
+
+	if _, ok := <-sub.Updates(); ok || !errors.Is(sub.Err(), ErrClosed) {
+		t.Fatalf("Got = %v, Want = %v\n", sub.Err(), ErrClosed)
+	}
+
+	sub, _ = books.Get("m2").Subscribe()
+
+	if !errors.Is(sub.Err(), ErrClosed) {
+		t.Fatalf("Got = %v, Want = %v\n", sub.Err(), ErrClosed)
+	}
+}
@@ -0,0 +1,57 @@
+package book
+
+import "sync"
+
+// Books - order books by market id.
+type Books struct {
+	mut    sync.Mutex
+	books  map[string]*Book
+	closed bool
+}
+
+// NewBooks - create an empty Books.
+func NewBooks() *Books {
+	return &Books{
+		books: make(map[string]*Book),
+	}
+}
+
+// Get - return book of market 'marketId', create it if not exist.
+func (b *Books) Get(marketId string) *Book {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+
+	book, exist := b.books[marketId]
+
+	if !exist {
+		book = New()
+
+		if b.closed {
+			book.Close()
+		}
+
+		b.books[marketId] = book
+	}
+
+	return book
+}
+
+// Lookup - return book of market 'marketId' and false if it not exist.
+func (b *Books) Lookup(marketId string) (*Book, bool) {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+
+	book, exist := b.books[marketId]
+	return book, exist
+}
+
+// Close - close all books, see Book.Close.
+func (b *Books) Close() {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+	b.closed = true
+
+	for _, book := range b.books {
+		book.Close()
+	}
+}
@@ -0,0 +1,61 @@
+package book
+
+import (
+	pb "github.com/KonnorFrik/BinaryTentacles/internal/generated/order_service/v1"
+)
+
+// LevelsToProtobuf - convert many Level objects in pb.OrderBookLevel objects.
+func LevelsToProtobuf(in []Level) []*pb.OrderBookLevel {
+	var out = make([]*pb.OrderBookLevel, len(in))
+
+	for i, lvl := range in {
+		out[i] = &pb.OrderBookLevel{
+			Price:      lvl.Price,
+			Quantity:   lvl.Quantity,
+			OrderCount: uint32(lvl.Orders),
+		}
+	}
+
+	return out
+}
+
+// SnapshotToProtobuf - convert Snapshot object in pb.GetOrderBookResponse object.
+func SnapshotToProtobuf(in *Snapshot, out *pb.GetOrderBookResponse) {
+	if in == nil || out == nil {
+		return
+	}
+
+	out.Sequence = in.Sequence
+	out.Bids = LevelsToProtobuf(in.Bids)
+	out.Asks = LevelsToProtobuf(in.Asks)
+}
+
+// StreamSnapshotToProtobuf - convert Snapshot object in first message of order book stream.
+func StreamSnapshotToProtobuf(in *Snapshot, out *pb.StreamOrderBookResponse) {
+	if in == nil || out == nil {
+		return
+	}
+
+	out.Sequence = in.Sequence
+	out.Payload = &pb.StreamOrderBookResponse_Snapshot{
+		Snapshot: &pb.OrderBookSnapshot{
+			Bids: LevelsToProtobuf(in.Bids),
+			Asks: LevelsToProtobuf(in.Asks),
+		},
+	}
+}
+
+// UpdateToProtobuf - convert Update object in message of order book stream.
+func UpdateToProtobuf(in *Update, out *pb.StreamOrderBookResponse) {
+	if in == nil || out == nil {
+		return
+	}
+
+	out.Sequence = in.Sequence
+	out.Payload = &pb.StreamOrderBookResponse_Update{
+		Update: &pb.OrderBookUpdate{
+			Bids: LevelsToProtobuf(in.Bids),
+			Asks: LevelsToProtobuf(in.Asks),
+		},
+	}
+}
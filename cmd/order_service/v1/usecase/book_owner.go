@@ -0,0 +1,133 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	redCache "github.com/KonnorFrik/BinaryTentacles/pkg/cache/redis"
+	"github.com/google/uuid"
+)
+
+// Order books are matching state in memory of one instance, rebuilt from saved
+// resting orders on start. Two instances would match orders of one market in
+// different books, so order_service runs as single replica. Instance owns books
+// by key in redis, other instance with same redis does not start while key is held.
+
+const (
+	// bookOwnerKey - key with id of instance owning order books.
+	bookOwnerKey = "order_books_owner"
+	// bookOwnerTTL - how long ownership lasts without renew,
+	// so books of crashed instance are owned by next one after it.
+	bookOwnerTTL = 15 * time.Second
+)
+
+var (
+	// ErrBooksOwned - order books are owned by other instance, only one instance can match orders
+	ErrBooksOwned = errors.New("order books are owned by other instance")
+)
+
+// claimOrderBooks - take ownership of order books for this instance,
+// renew it in background until lifecycle of orders stopped.
+// Without redis books are always owned, there is no other instance.
+func (u *Usecase) claimOrderBooks(ctx context.Context) error {
+	if u.orderCache == nil {
+		return nil
+	}
+
+	u.instanceId = uuid.NewString()
+	claimed, err := u.orderCache.SetNew(ctx, bookOwnerKey, u.instanceId, bookOwnerTTL)
+
+	if err != nil {
+		return fmt.Errorf("claim order books: %w", err)
+	}
+
+	if !claimed {
+		owner, _ := u.orderCache.Get(ctx, bookOwnerKey)
+		return fmt.Errorf("%w %q, order_service runs as single replica", ErrBooksOwned, owner)
+	}
+
+	u.bookOwnedUntil.Store(time.Now().Add(bookOwnerTTL).UnixNano())
+	go u.renewOrderBooks(u.lifecycleCtx)
+	return nil
+}
+
+// renewOrderBooks - extend ownership of order books until 'ctx' done.
+// Lost ownership is not taken back, orders are not placed until restart.
+func (u *Usecase) renewOrderBooks(ctx context.Context) {
+	ticker := time.NewTicker(bookOwnerTTL / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		renewedAt := time.Now()
+		err := u.orderCache.SetIf(ctx, bookOwnerKey, u.instanceId, bookOwnerTTL, func(owner string) error {
+			if owner != u.instanceId {
+				return fmt.Errorf("%w %q", ErrBooksOwned, owner)
+			}
+
+			return nil
+		})
+
+		switch {
+		case err == nil:
+			u.bookOwnedUntil.Store(renewedAt.Add(bookOwnerTTL).UnixNano())
+			continue
+		case errors.Is(err, redCache.ErrNil), errors.Is(err, ErrBooksOwned):
+			// Key expired while redis was unreachable, other instance may match orders already.
+			u.bookOwnedUntil.Store(0)
+		case ctx.Err() != nil:
+			return
+		}
+
+		logger.LogAttrs(
+			ctx,
+			slog.LevelError,
+			"[OrderService/renewOrderBooks]",
+			slog.String("instance", u.instanceId),
+			slog.String("error", err.Error()),
+		)
+
+		if u.bookOwnedUntil.Load() == 0 {
+			return
+		}
+	}
+}
+
+// ownsOrderBooks - report is ownership of order books by this instance not expired.
+func (u *Usecase) ownsOrderBooks() bool {
+	return u.orderCache == nil || time.Now().UnixNano() < u.bookOwnedUntil.Load()
+}
+
+// ReleaseOrderBooks - give up ownership of order books, so next instance starts without wait.
+// Must be called after graceful stop of server, when no orders are placed.
+func (u *Usecase) ReleaseOrderBooks(ctx context.Context) error {
+	if u.orderCache == nil || u.bookOwnedUntil.Load() == 0 {
+		return nil
+	}
+
+	u.bookOwnedUntil.Store(0)
+	err := u.orderCache.Transaction(ctx, func(tx *redCache.Tx) error {
+		owner, err := tx.Get(bookOwnerKey)
+
+		if err != nil || owner != u.instanceId {
+			return err
+		}
+
+		tx.Delete(bookOwnerKey)
+		return nil
+	}, bookOwnerKey)
+
+	if err != nil && !errors.Is(err, redCache.ErrNil) {
+		return fmt.Errorf("release order books: %w", err)
+	}
+
+	return nil
+}
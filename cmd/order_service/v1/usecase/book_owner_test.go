@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	pb "github.com/KonnorFrik/BinaryTentacles/internal/generated/order_service/v1"
+	client "github.com/KonnorFrik/BinaryTentacles/internal/generated/spot_instrument/v1"
+	redCache "github.com/KonnorFrik/BinaryTentacles/pkg/cache/redis"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// newRedisUsecase - create Usecase with orders in redis 'server'.
+func newRedisUsecase(t *testing.T, server *miniredis.Miniredis) (*Usecase, error) {
+	t.Helper()
+	cache, err := redCache.New(context.Background(), redCache.Config{Addr: server.Addr()})
+
+	if err != nil {
+		t.Fatalf("Got = %v, Want = %v\n", err, nil)
+	}
+
+	t.Cleanup(func() { cache.Close(context.Background()) })
+	u, err := New(
+		context.Background(),
+		struct {
+			client.SpotInstrumentServiceClient
+		}{},
+		WithOrderCache(cache),
+		WithMetricsRegisterer(prometheus.NewRegistry()),
+	)
+
+	if err == nil {
+		t.Cleanup(func() { u.ShutdownOrderUpdates(context.Background()) })
+	}
+
+	return u, err
+}
+
+func TestOrderBooksOwner(t *testing.T) {
+	server := miniredis.RunT(t)
+	first, err := newRedisUsecase(t, server)
+
+	if err != nil || !first.ownsOrderBooks() {
+		t.Fatalf("Got = %v, Want owned books\n", err)
+	}
+
+	// Second replica on same redis does not start.
+	if _, err := newRedisUsecase(t, server); !errors.Is(err, ErrBooksOwned) {
+		t.Fatalf("Got = %v, Want = %v\n", err, ErrBooksOwned)
+	}
+
+	if err := first.ReleaseOrderBooks(context.Background()); err != nil {
+		t.Fatalf("Got = %v, Want = %v\n", err, nil)
+	}
+
+	if first.ownsOrderBooks() {
+		t.Fatalf("Got = owned books, Want released\n")
+	}
+
+	if _, err := first.Create(context.Background(), &pb.CreateRequest{
+		UserId:   "user",
+		MarketId: testMarketId,
+		Side:     pb.OrderSide_ORDER_SIDE_BUY,
+		Price:    100,
+		Quantity: 10,
+	}); !errors.Is(err, ErrBooksOwned) {
+		t.Fatalf("Got = %v, Want = %v\n", err, ErrBooksOwned)
+	}
+
+	// Next instance starts after release without wait.
+	if _, err := newRedisUsecase(t, server); err != nil {
+		t.Fatalf("Got = %v, Want = %v\n", err, nil)
+	}
+}
@@ -0,0 +1,134 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/KonnorFrik/BinaryTentacles/cmd/order_service/v1/usecase/order"
+	"github.com/KonnorFrik/BinaryTentacles/cmd/order_service/v1/usecase/repository"
+	pb "github.com/KonnorFrik/BinaryTentacles/internal/generated/order_service/v1"
+	client "github.com/KonnorFrik/BinaryTentacles/internal/generated/spot_instrument/v1"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const testMarketId = "0197a1c2-0000-7000-8000-0000000000aa"
+
+// failingUpdates - Memory with failures of next updates.
+type failingUpdates struct {
+	*repository.Memory
+	// failures - errors returned by next updates, one per update.
+	failures []error
+}
+
+func (f *failingUpdates) Update(
+	ctx context.Context,
+	ord *order.Order,
+	events ...order.Event,
+) error {
+	if len(f.failures) > 0 {
+		err := f.failures[0]
+		f.failures = f.failures[1:]
+		return err
+	}
+
+	return f.Memory.Update(ctx, ord, events...)
+}
+
+// newTestUsecase - create Usecase with orders in 'repo' and resting order of user "user".
+func newTestUsecase(t *testing.T, repo *failingUpdates) (*Usecase, *order.Order) {
+	t.Helper()
+	u, err := New(
+		context.Background(),
+		struct {
+			client.SpotInstrumentServiceClient
+		}{},
+		WithOrderRepository(repo),
+		WithEventRepository(repo.Events()),
+		WithMetricsRegisterer(prometheus.NewRegistry()),
+	)
+
+	if err != nil {
+		t.Fatalf("Got = %v, Want = %v\n", err, nil)
+	}
+
+	t.Cleanup(func() { u.ShutdownOrderUpdates(context.Background()) })
+	ord := &order.Order{
+		Id:       "0197a1c2-0000-7000-8000-000000000001",
+		UserId:   "user",
+		MarketId: testMarketId,
+		Side:     pb.OrderSide_ORDER_SIDE_BUY,
+		Price:    100,
+		Quantity: 10,
+	}
+	ord.Transition(pb.OrderStatus_ORDER_STATUS_CONFIRM, time.Now())
+
+	if err := repo.Create(context.Background(), ord, time.Hour); err != nil {
+		t.Fatalf("Got = %v, Want = %v\n", err, nil)
+	}
+
+	u.orderBooks.Get(testMarketId).Rest(ord.Id, ord.Side, ord.Price, ord.Quantity)
+	return u, ord
+}
+
+// bookQuantity - return quantity of bids in book of test market.
+func bookQuantity(u *Usecase) uint64 {
+	var quantity uint64
+
+	for _, lvl := range u.orderBooks.Get(testMarketId).Snapshot(10).Bids {
+		quantity += lvl.Quantity
+	}
+
+	return quantity
+}
+
+func TestCancelRetry(t *testing.T) {
+	repo := &failingUpdates{Memory: repository.NewMemory(), failures: []error{repository.ErrVersionConflict}}
+	u, ord := newTestUsecase(t, repo)
+	cancelled, err := u.Cancel(context.Background(), &pb.CancelRequest{OrderId: ord.Id, UserId: "user"})
+
+	if err != nil || cancelled.GetStatus() != pb.OrderStatus_ORDER_STATUS_CANCELLED {
+		t.Fatalf("Got = %v, %v, Want cancelled order\n", cancelled.GetStatus(), err)
+	}
+
+	if quantity := bookQuantity(u); quantity != 0 {
+		t.Fatalf("Got = %d in book, Want = 0\n", quantity)
+	}
+}
+
+func TestCancelFailedSave(t *testing.T) {
+	failure := errors.New("storage is down")
+	repo := &failingUpdates{Memory: repository.NewMemory(), failures: []error{failure}}
+	u, ord := newTestUsecase(t, repo)
+
+	if _, err := u.Cancel(context.Background(), &pb.CancelRequest{OrderId: ord.Id, UserId: "user"}); !errors.Is(err, ErrInternal) {
+		t.Fatalf("Got = %v, Want = %v\n", err, ErrInternal)
+	}
+
+	// Order not cancelled is back in book.
+	if quantity := bookQuantity(u); quantity != ord.Quantity {
+		t.Fatalf("Got = %d in book, Want = %d\n", quantity, ord.Quantity)
+	}
+
+	// Cancel of other user does not touch book.
+	if _, err := u.Cancel(context.Background(), &pb.CancelRequest{OrderId: ord.Id, UserId: "other"}); !errors.Is(err, ErrDoesNotExist) {
+		t.Fatalf("Got = %v, Want = %v\n", err, ErrDoesNotExist)
+	}
+
+	if _, err := u.Cancel(context.Background(), &pb.CancelRequest{OrderId: ord.Id, UserId: "user"}); err != nil {
+		t.Fatalf("Got = %v, Want = %v\n", err, nil)
+	}
+}
+
+func TestCancelMatched(t *testing.T) {
+	repo := &failingUpdates{Memory: repository.NewMemory()}
+	u, ord := newTestUsecase(t, repo)
+
+	// Matched in book, fill is not saved yet.
+	u.orderBooks.Get(testMarketId).Cancel(ord.Id)
+
+	if _, err := u.Cancel(context.Background(), &pb.CancelRequest{OrderId: ord.Id, UserId: "user"}); !errors.Is(err, ErrOrderFinalized) {
+		t.Fatalf("Got = %v, Want = %v\n", err, ErrOrderFinalized)
+	}
+}
@@ -0,0 +1,186 @@
+package feed
+
+import (
+	"context"
+
+	"github.com/KonnorFrik/BinaryTentacles/cmd/order_service/v1/usecase/order"
+)
+
+// maxPendingEvents - how many live events can wait for read in one event stream.
+// Stream is ended with ErrLagged if reader is slower. Stored events are not counted.
+const maxPendingEvents = 1024
+
+// Load - return stored events of user with sequence after 'sequence', oldest first.
+type Load func(ctx context.Context, sequence uint64) ([]order.Event, error)
+
+// EventStream - stream of all order events of one user.
+// Stored events after requested sequence come first, then live events.
+type EventStream struct {
+	events chan order.Event
+	err    error
+	hub    *Hub[order.Event]
+	userId string
+	load   Load
+	match  func(*order.Event) bool
+}
+
+// StartEvents - start stream of events of user 'userId' from events after 'sequence'.
+// Only events accepted by 'match' are sent, nil 'match' accept all events.
+// Every event is sent once in sequence order. Stream ends when 'ctx' is done,
+// hub closed or reader too slow.
+func StartEvents(
+	ctx context.Context,
+	hub *Hub[order.Event],
+	userId string,
+	sequence uint64,
+	match func(*order.Event) bool,
+	load Load,
+) (
+	*EventStream,
+	error,
+) {
+	// Subscribe before load, so no event lost between them.
+	sub := hub.Subscribe(userId)
+	missed, err := load(ctx, sequence)
+
+	if err != nil {
+		sub.Close()
+		return nil, err
+	}
+
+	if match == nil {
+		match = func(*order.Event) bool { return true }
+	}
+
+	s := &EventStream{
+		events: make(chan order.Event),
+		hub:    hub,
+		userId: userId,
+		load:   load,
+		match:  match,
+	}
+
+	go s.run(ctx, sub, missed, sequence)
+	return s, nil
+}
+
+// Events - return channel of events.
+// Channel closed when stream ends, see Err.
+func (s *EventStream) Events() <-chan order.Event {
+	return s.events
+}
+
+// Err - return reason of end stream. Nil if stream ended by 'ctx'.
+// Valid after Events channel is closed.
+func (s *EventStream) Err() error {
+	return s.err
+}
+
+// run - send 'queue' and live events from 'sub'.
+// 'sent' is sequence of last event known by reader.
+func (s *EventStream) run(
+	ctx context.Context,
+	sub *Subscription[order.Event],
+	queue []order.Event,
+	sent uint64,
+) {
+	defer close(s.events)
+	defer func() { sub.Close() }()
+
+	// stored - count of stored events at head of queue.
+	var stored = len(queue)
+
+	// last - sequence of last event sent or waiting in queue.
+	last := func() uint64 {
+		if len(queue) > 0 {
+			return queue[len(queue)-1].Sequence
+		}
+
+		return sent
+	}
+
+	// reload - append stored events after last to queue.
+	reload := func() error {
+		missed, err := s.load(ctx, last())
+
+		if err != nil {
+			return err
+		}
+
+		after := last()
+
+		for _, e := range missed {
+			if e.Sequence > after {
+				queue = append(queue, e)
+			}
+		}
+
+		stored = len(queue)
+		return nil
+	}
+
+	for {
+		var (
+			next order.Event
+			out  chan<- order.Event
+		)
+
+		if len(queue) > 0 {
+			next = queue[0]
+
+			if !s.match(&next) {
+				queue = queue[1:]
+				stored = max(stored-1, 0)
+				sent = next.Sequence
+				continue
+			}
+
+			out = s.events
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+
+		case out <- next:
+			queue = queue[1:]
+			stored = max(stored-1, 0)
+			sent = next.Sequence
+
+		case e, isOpen := <-sub.Updates():
+			if isOpen {
+				switch {
+				case e.Sequence <= last():
+				case e.Sequence == last()+1:
+					queue = append(queue, e)
+				default:
+					// Events of user are published by many writers, earlier event may come later.
+					if err := reload(); err != nil {
+						s.err = err
+						return
+					}
+				}
+
+				if len(queue)-stored > maxPendingEvents {
+					s.err = ErrLagged
+					return
+				}
+
+				continue
+			}
+
+			// Subscription is read without wait for reader, so lag of it is not lag of reader.
+			if err := sub.Err(); err != ErrLagged && err != ErrResync {
+				s.err = err
+				return
+			}
+
+			sub = s.hub.Subscribe(s.userId)
+
+			if err := reload(); err != nil {
+				s.err = err
+				return
+			}
+		}
+	}
+}
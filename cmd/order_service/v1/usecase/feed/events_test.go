@@ -0,0 +1,153 @@
+package feed
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/KonnorFrik/BinaryTentacles/cmd/order_service/v1/usecase/order"
+)
+
+const userId = "user"
+
+// eventStore - stored events of one user.
+type eventStore struct {
+	mut    sync.Mutex
+	events []order.Event
+}
+
+// add - store event for order 'orderId' and return it.
+func (s *eventStore) add(orderId string) order.Event {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	e := order.Event{Sequence: uint64(len(s.events) + 1), UserId: userId, OrderId: orderId}
+	s.events = append(s.events, e)
+	return e
+}
+
+// load - Load of store 's'.
+func (s *eventStore) load(ctx context.Context, sequence uint64) ([]order.Event, error) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	return append([]order.Event(nil), s.events[min(int(sequence), len(s.events)):]...), nil
+}
+
+// receiveEvents - read 'count' events of stream 's' with timeout.
+func receiveEvents(t *testing.T, s *EventStream, count int) []order.Event {
+	var (
+		events  []order.Event
+		timeout = time.After(5 * time.Second)
+	)
+
+	for len(events) < count {
+		select {
+		case <-timeout:
+			t.Fatalf("Got = %v, Want %d events\n", events, count)
+		case e, isOpen := <-s.Events():
+			if !isOpen {
+				t.Fatalf("Stream ended with %v, got = %v\n", s.Err(), events)
+			}
+
+			events = append(events, e)
+		}
+	}
+
+	return events
+}
+
+func TestEventsResume(t *testing.T) {
+	var (
+		hub   = NewHub[order.Event]()
+		store eventStore
+	)
+
+	store.add("a")
+	store.add("b")
+	s, err := StartEvents(context.Background(), hub, userId, 1, nil, store.load)
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	hub.Publish(userId, store.add("c"))
+	// Published twice and out of order, every event must be sent once.
+	fourth, fifth := store.add("d"), store.add("e")
+	hub.Publish(userId, fifth)
+	hub.Publish(userId, fourth)
+
+	for i, e := range receiveEvents(t, s, 4) {
+		if e.Sequence != uint64(i+2) {
+			t.Fatalf("Got sequence = %d, Want = %d\n", e.Sequence, i+2)
+		}
+	}
+
+	select {
+	case e := <-s.Events():
+		t.Fatalf("Got duplicate = %+v\n", e)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestEventsMatch(t *testing.T) {
+	var (
+		hub   = NewHub[order.Event]()
+		store eventStore
+		match = func(e *order.Event) bool { return e.OrderId == "b" }
+	)
+
+	store.add("a")
+	store.add("b")
+	s, err := StartEvents(context.Background(), hub, userId, 0, match, store.load)
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	hub.Publish(userId, store.add("a"))
+	hub.Publish(userId, store.add("b"))
+	got := receiveEvents(t, s, 2)
+
+	if got[0].Sequence != 2 || got[1].Sequence != 4 {
+		t.Fatalf("Got = %+v, Want sequence 2 and 4\n", got)
+	}
+}
+
+func TestEventsLagged(t *testing.T) {
+	var (
+		hub   = NewHub[order.Event]()
+		store eventStore
+	)
+
+	s, err := StartEvents(context.Background(), hub, userId, 0, nil, store.load)
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	// Nobody reads stream, publisher must not be blocked.
+	for range maxPendingEvents + subscriberBuffer + 1 {
+		hub.Publish(userId, store.add("a"))
+		time.Sleep(10 * time.Microsecond)
+	}
+
+	timeout := time.After(5 * time.Second)
+
+	for {
+		select {
+		case <-timeout:
+			t.Fatalf("Stream is not ended\n")
+		case _, isOpen := <-s.Events():
+			if isOpen {
+				continue
+			}
+
+			if !errors.Is(s.Err(), ErrLagged) {
+				t.Fatalf("Got = %v, Want = %v\n", s.Err(), ErrLagged)
+			}
+
+			return
+		}
+	}
+}
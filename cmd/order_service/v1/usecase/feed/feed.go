@@ -0,0 +1,150 @@
+/*
+Fan-out of order changes to watchers in one instance.
+Feed deliver only live changes, past changes are read from store.
+*/
+package feed
+
+import (
+	"errors"
+	"sync"
+)
+
+var (
+	// ErrLagged - watcher read updates too slow and was dropped.
+	// Watcher must read missed updates from store.
+	ErrLagged = errors.New("watcher read updates too slow")
+	// ErrResync - published updates may be lost, watcher was dropped.
+	// Watcher must read missed updates from store.
+	ErrResync = errors.New("updates may be lost")
+	// ErrClosed - feed is closed, no more updates will be published.
+	ErrClosed = errors.New("order updates are closed")
+)
+
+// subscriberBuffer - how many updates can wait for read in one subscription.
+const subscriberBuffer = 16
+
+// Hub - deliver published updates of key to subscriptions of this key.
+type Hub[T any] struct {
+	mut         sync.Mutex
+	subscribers map[string]map[*Subscription[T]]struct{}
+	closed      bool
+}
+
+// NewHub - create a new Hub.
+func NewHub[T any]() *Hub[T] {
+	return &Hub[T]{
+		subscribers: make(map[string]map[*Subscription[T]]struct{}),
+	}
+}
+
+// Publish - send update 'u' of 'key' to all subscriptions of key.
+// Subscription which can't accept update is dropped with ErrLagged.
+func (h *Hub[T]) Publish(key string, u T) {
+	h.mut.Lock()
+	defer h.mut.Unlock()
+
+	for sub := range h.subscribers[key] {
+		select {
+		case sub.updates <- u:
+		default:
+			h.drop(sub, ErrLagged)
+		}
+	}
+}
+
+// Subscribe - create a new subscription for updates of 'key'.
+func (h *Hub[T]) Subscribe(key string) *Subscription[T] {
+	h.mut.Lock()
+	defer h.mut.Unlock()
+
+	sub := &Subscription[T]{
+		hub:     h,
+		key:     key,
+		updates: make(chan T, subscriberBuffer),
+	}
+
+	if h.subscribers[key] == nil {
+		h.subscribers[key] = make(map[*Subscription[T]]struct{})
+	}
+
+	h.subscribers[key][sub] = struct{}{}
+
+	if h.closed {
+		h.drop(sub, ErrClosed)
+	}
+
+	return sub
+}
+
+// Close - drop all subscriptions with ErrClosed.
+// New subscriptions are dropped right away.
+func (h *Hub[T]) Close() {
+	h.mut.Lock()
+	defer h.mut.Unlock()
+	h.closed = true
+
+	for _, subscribers := range h.subscribers {
+		for sub := range subscribers {
+			h.drop(sub, ErrClosed)
+		}
+	}
+}
+
+// Resync - drop all subscriptions with ErrResync.
+// Used when published updates may be lost, so watchers read them from store.
+func (h *Hub[T]) Resync() {
+	h.mut.Lock()
+	defer h.mut.Unlock()
+
+	for _, subscribers := range h.subscribers {
+		for sub := range subscribers {
+			h.drop(sub, ErrResync)
+		}
+	}
+}
+
+// drop - remove subscription 'sub' with error 'err'.
+// Must be called with locked hub.
+func (h *Hub[T]) drop(sub *Subscription[T], err error) {
+	if _, exist := h.subscribers[sub.key][sub]; !exist {
+		return
+	}
+
+	delete(h.subscribers[sub.key], sub)
+
+	if len(h.subscribers[sub.key]) == 0 {
+		delete(h.subscribers, sub.key)
+	}
+
+	sub.err = err
+	close(sub.updates)
+}
+
+// Subscription - stream of updates of one key from Hub.
+type Subscription[T any] struct {
+	hub     *Hub[T]
+	key     string
+	updates chan T
+	err     error
+}
+
+// Updates - return channel of updates.
+// Channel closed after Close or when subscription dropped, see Err.
+func (s *Subscription[T]) Updates() <-chan T {
+	return s.updates
+}
+
+// Err - return reason of drop subscription.
+// Nil if subscription active or closed by Close.
+func (s *Subscription[T]) Err() error {
+	s.hub.mut.Lock()
+	defer s.hub.mut.Unlock()
+	return s.err
+}
+
+// Close - stop receive updates.
+func (s *Subscription[T]) Close() {
+	s.hub.mut.Lock()
+	defer s.hub.mut.Unlock()
+	s.hub.drop(s, nil)
+}
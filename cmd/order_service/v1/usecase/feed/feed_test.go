@@ -0,0 +1,193 @@
+package feed
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/KonnorFrik/BinaryTentacles/cmd/order_service/v1/usecase/order"
+	pb "github.com/KonnorFrik/BinaryTentacles/internal/generated/order_service/v1"
+)
+
+const orderId = "0197a1c2-0000-7000-8000-000000000001"
+
+// storedOrder - reload from order 'ord'.
+func storedOrder(ord *order.Order) Reload {
+	return func(ctx context.Context, sequence uint64) ([]order.Update, bool, error) {
+		return ord.UpdatesAfter(sequence), ord.IsFinal(), nil
+	}
+}
+
+// receive - read all updates of watch 'w' with timeout.
+func receive(t *testing.T, w *Watch) []pb.OrderStatus {
+	var (
+		statuses []pb.OrderStatus
+		sequence uint64
+		timeout  = time.After(5 * time.Second)
+	)
+
+	for {
+		select {
+		case <-timeout:
+			t.Fatalf("Watch is not ended, got = %v\n", statuses)
+		case u, isOpen := <-w.Updates():
+			if !isOpen {
+				return statuses
+			}
+
+			if u.Sequence <= sequence {
+				t.Fatalf("Got sequence = %d after %d\n", u.Sequence, sequence)
+			}
+
+			sequence = u.Sequence
+			statuses = append(statuses, u.Status)
+		}
+	}
+}
+
+func TestWatchResume(t *testing.T) {
+	var (
+		hub = NewHub[order.Update]()
+		ord order.Order
+		now = time.Now()
+	)
+
+	ord.Transition(pb.OrderStatus_ORDER_STATUS_CREATED, now)
+	ord.Transition(pb.OrderStatus_ORDER_STATUS_PROCESSING, now)
+	ord.Transition(pb.OrderStatus_ORDER_STATUS_PROCESSED, now)
+
+	w, err := Start(context.Background(), hub, orderId, 1, 0, storedOrder(&ord))
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	u, _ := ord.Cancel(now)
+	hub.Publish(orderId, u)
+	got := receive(t, w)
+	want := []pb.OrderStatus{
+		pb.OrderStatus_ORDER_STATUS_PROCESSING,
+		pb.OrderStatus_ORDER_STATUS_PROCESSED,
+		pb.OrderStatus_ORDER_STATUS_CANCELLED,
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("Got = %v, Want = %v\n", got, want)
+	}
+
+	for i := range got {
+		if got[i] != want[i] {
+			t.Fatalf("Got = %v, Want = %v\n", got, want)
+		}
+	}
+}
+
+func TestWatchCoalesce(t *testing.T) {
+	var (
+		hub   = NewHub[order.Update]()
+		ord   order.Order
+		now   = time.Now()
+		delay = 200 * time.Millisecond
+	)
+
+	w, err := Start(context.Background(), hub, orderId, 0, delay, storedOrder(&ord))
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	hub.Publish(orderId, ord.Transition(pb.OrderStatus_ORDER_STATUS_CREATED, now))
+	first := <-w.Updates()
+	start := time.Now()
+	hub.Publish(orderId, ord.Transition(pb.OrderStatus_ORDER_STATUS_PROCESSING, now))
+	hub.Publish(orderId, ord.Transition(pb.OrderStatus_ORDER_STATUS_PROCESSED, now))
+	second := <-w.Updates()
+
+	if elapsed := time.Since(start); elapsed < delay/2 {
+		t.Fatalf("Got update after %s, Want about %s\n", elapsed, delay)
+	}
+
+	if first.Sequence != 1 || second.Sequence != 3 || second.Status != pb.OrderStatus_ORDER_STATUS_PROCESSED {
+		t.Fatalf("Got = %+v, %+v, Want sequence 1 and 3\n", first, second)
+	}
+
+	// Confirmed order waits in book, watch ends with final status only.
+	u := ord.Transition(pb.OrderStatus_ORDER_STATUS_REJECT, now)
+	hub.Publish(orderId, u)
+
+	if got := receive(t, w); len(got) != 1 || got[0] != u.Status {
+		t.Fatalf("Got = %v, Want = [%v]\n", got, u.Status)
+	}
+}
+
+func TestWatchFinal(t *testing.T) {
+	var (
+		hub = NewHub[order.Update]()
+		ord order.Order
+	)
+
+	ord.Transition(pb.OrderStatus_ORDER_STATUS_CREATED, time.Now())
+	ord.Cancel(time.Now())
+	w, err := Start(context.Background(), hub, orderId, 2, 0, storedOrder(&ord))
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	if got := receive(t, w); len(got) != 0 {
+		t.Fatalf("Got = %v, Want no updates\n", got)
+	}
+}
+
+func TestWatchClosed(t *testing.T) {
+	var (
+		hub = NewHub[order.Update]()
+		ord order.Order
+	)
+
+	w, err := Start(context.Background(), hub, orderId, 0, 0, storedOrder(&ord))
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	hub.Close()
+	receive(t, w)
+
+	if !errors.Is(w.Err(), ErrClosed) {
+		t.Fatalf("Got = %v, Want = %v\n", w.Err(), ErrClosed)
+	}
+}
+
+func TestWatchResync(t *testing.T) {
+	var (
+		hub = NewHub[order.Update]()
+		ord order.Order
+		now = time.Now()
+	)
+
+	w, err := Start(context.Background(), hub, orderId, 0, 0, storedOrder(&ord))
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	// Updates saved but never published, as if lost by broker.
+	ord.Transition(pb.OrderStatus_ORDER_STATUS_CREATED, now)
+	ord.Cancel(now)
+	hub.Resync()
+	got := receive(t, w)
+	want := []pb.OrderStatus{
+		pb.OrderStatus_ORDER_STATUS_CREATED,
+		pb.OrderStatus_ORDER_STATUS_CANCELLED,
+	}
+
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("Got = %v, Want = %v\n", got, want)
+	}
+
+	if w.Err() != nil {
+		t.Fatalf("Got = %q\n", w.Err())
+	}
+}
@@ -0,0 +1,188 @@
+package feed
+
+import (
+	"context"
+	"time"
+
+	"github.com/KonnorFrik/BinaryTentacles/cmd/order_service/v1/usecase/order"
+)
+
+// Reload - return stored updates of order with sequence after 'sequence'
+// and report whether order is in final status.
+type Reload func(ctx context.Context, sequence uint64) ([]order.Update, bool, error)
+
+// Watch - stream of one order updates.
+// Stored updates after requested sequence come first, then live updates.
+type Watch struct {
+	updates chan order.Update
+	err     error
+	hub     *Hub[order.Update]
+	orderId string
+	reload  Reload
+	delay   time.Duration
+}
+
+// Start - start watch of order 'orderId' from updates after 'sequence'.
+// Updates are sent at least 'delay' apart. Live updates received while waiting
+// are coalesced to latest, stored updates are sent all.
+// Watch ends after final status or when 'ctx' is done.
+func Start(
+	ctx context.Context,
+	hub *Hub[order.Update],
+	orderId string,
+	sequence uint64,
+	delay time.Duration,
+	reload Reload,
+) (
+	*Watch,
+	error,
+) {
+	// Subscribe before reload, so no update lost between them.
+	sub := hub.Subscribe(orderId)
+	missed, final, err := reload(ctx, sequence)
+
+	if err != nil {
+		sub.Close()
+		return nil, err
+	}
+
+	w := &Watch{
+		updates: make(chan order.Update),
+		hub:     hub,
+		orderId: orderId,
+		reload:  reload,
+		delay:   delay,
+	}
+
+	if len(missed) == 0 && final {
+		sub.Close()
+		close(w.updates)
+		return w, nil
+	}
+
+	go w.run(ctx, sub, missed, sequence)
+	return w, nil
+}
+
+// Updates - return channel of updates.
+// Channel closed when watch ends, see Err.
+func (w *Watch) Updates() <-chan order.Update {
+	return w.updates
+}
+
+// Err - return reason of end watch. Nil if watch ended by final status or 'ctx'.
+// Valid after Updates channel is closed.
+func (w *Watch) Err() error {
+	return w.err
+}
+
+// run - send 'queue' and live updates from 'sub' until final status.
+// 'sent' is sequence of last update known by watcher.
+func (w *Watch) run(
+	ctx context.Context,
+	sub *Subscription[order.Update],
+	queue []order.Update,
+	sent uint64,
+) {
+	defer close(w.updates)
+	defer func() { sub.Close() }()
+
+	var (
+		// stored - count of stored updates at head of queue, they are not coalesced.
+		stored   = len(queue)
+		lastPush time.Time
+	)
+
+	enqueue := func(u order.Update) {
+		if u.Sequence <= sent || len(queue) > 0 && u.Sequence <= queue[len(queue)-1].Sequence {
+			return
+		}
+
+		if len(queue) > stored {
+			queue[len(queue)-1] = u
+			return
+		}
+
+		queue = append(queue, u)
+	}
+
+	for {
+		var (
+			timer *time.Timer
+			ready <-chan time.Time
+		)
+
+		if len(queue) > 0 {
+			timer = time.NewTimer(max(w.delay-time.Since(lastPush), 0))
+			ready = timer.C
+		}
+
+		select {
+		case <-ctx.Done():
+			stopTimer(timer)
+			return
+
+		case u, isOpen := <-sub.Updates():
+			stopTimer(timer)
+
+			if isOpen {
+				enqueue(u)
+				continue
+			}
+
+			if err := sub.Err(); err != ErrLagged && err != ErrResync {
+				w.err = err
+				return
+			}
+
+			// Updates are missed: read them from store and continue.
+			sub = w.hub.Subscribe(w.orderId)
+			after := sent
+
+			if len(queue) > 0 {
+				after = queue[len(queue)-1].Sequence
+			}
+
+			missed, _, err := w.reload(ctx, after)
+
+			if err != nil {
+				w.err = err
+				return
+			}
+
+			for _, u := range missed {
+				if u.Sequence > after {
+					queue = append(queue, u)
+				}
+			}
+
+			// Read from store, so not coalesced.
+			stored = len(queue)
+
+		case <-ready:
+			u := queue[0]
+
+			select {
+			case <-ctx.Done():
+				return
+			case w.updates <- u:
+			}
+
+			queue = queue[1:]
+			stored = max(stored-1, 0)
+			sent = u.Sequence
+			lastPush = time.Now()
+
+			if u.IsFinal() {
+				return
+			}
+		}
+	}
+}
+
+// stopTimer - stop timer 't' if it created.
+func stopTimer(t *time.Timer) {
+	if t != nil {
+		t.Stop()
+	}
+}
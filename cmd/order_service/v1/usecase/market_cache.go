@@ -0,0 +1,176 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/KonnorFrik/BinaryTentacles/cmd/order_service/v1/usecase/availability"
+	client "github.com/KonnorFrik/BinaryTentacles/internal/generated/spot_instrument/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// watchRetryMin - first delay before reconnect to market changes.
+	watchRetryMin = time.Second
+	// watchRetryMax - max delay before reconnect to market changes.
+	watchRetryMax = 30 * time.Second
+)
+
+// startMarketWatch - start invalidation of market cache by market changes.
+// Stop with StopMarketWatch.
+func (u *Usecase) startMarketWatch() {
+	if u.marketAvailability == nil {
+		return
+	}
+
+	var watchCtx context.Context
+	watchCtx, u.stopMarketWatch = context.WithCancel(context.Background())
+	go u.watchMarketChanges(watchCtx)
+}
+
+// marketAvailable - return availability of market with 'marketId' from local cache.
+func (u *Usecase) marketAvailable(
+	ctx context.Context,
+	marketId string,
+) (
+	availability.Result,
+	error,
+) {
+	if u.marketAvailability == nil {
+		result, _, err := u.lookupAvailability(ctx, marketId)
+
+		if err == nil {
+			err = result.Err
+		}
+
+		return result, err
+	}
+
+	return u.marketAvailability.Get(ctx, marketId)
+}
+
+// lookupAvailability - ask SpotInstrumentService about availability of market with 'marketId'.
+// Unknown and invalid markets are returned as negative result, not as error.
+func (u *Usecase) lookupAvailability(
+	ctx context.Context,
+	marketId string,
+) (
+	availability.Result,
+	bool,
+	error,
+) {
+	clientReq := client.IsAvailableRequest{
+		UserRole: client.UserRole_USER_ROLE_CUSTOMER,
+		MarketId: marketId,
+	}
+	resp, err := u.spotInstrument.IsAvailable(ctx, &clientReq)
+
+	if err != nil {
+		logger.LogAttrs(
+			ctx,
+			slog.LevelError,
+			"[OrderService/lookupAvailability]",
+			slog.String("Call", "SpotInstrumentService.IsAvailable"),
+			slog.String("Error", err.Error()),
+		)
+		code := status.Code(err)
+		err = fmt.Errorf("%w: SpotInstrumentService reason: %w", ErrMarketUnavailable, err)
+
+		switch code {
+		case codes.NotFound, codes.InvalidArgument:
+			return availability.Result{State: client.MarketState_MARKET_STATE_CLOSED, Err: err}, true, nil
+		}
+
+		return availability.Result{}, false, err
+	}
+
+	result := availability.Result{
+		State:  resp.GetState(),
+		Reason: resp.GetReason(),
+	}
+	return result, !acceptsNewOrders(result.State), nil
+}
+
+// watchMarketChanges - invalidate market cache on every market change in SpotInstrumentService.
+// Cache is disabled while changes can't be received. Stop with StopMarketWatch.
+func (u *Usecase) watchMarketChanges(ctx context.Context) {
+	if u.marketAvailability == nil {
+		return
+	}
+
+	var delay = watchRetryMin
+
+	for {
+		err := u.receiveMarketChanges(ctx, &delay)
+		u.marketAvailability.Disable()
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		logger.LogAttrs(
+			ctx,
+			slog.LevelWarn,
+			"[OrderService/watchMarketChanges]",
+			slog.String("Market cache", "disabled"),
+			slog.Duration("Reconnect after", delay),
+			slog.String("Error", err.Error()),
+		)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+
+		delay = min(delay*2, watchRetryMax)
+	}
+}
+
+// receiveMarketChanges - apply market changes to cache until stream broken.
+// Every connection start from snapshot, cache enabled after it.
+// 'delay' is reset after snapshot received.
+func (u *Usecase) receiveMarketChanges(
+	ctx context.Context,
+	delay *time.Duration,
+) error {
+	stream, err := u.spotInstrument.WatchMarkets(ctx, &client.WatchMarketsRequest{
+		UserRole: client.UserRole_USER_ROLE_INTERNAL_SERVICE,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	for {
+		resp, err := stream.Recv()
+
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case resp.GetSnapshot() != nil:
+			u.marketAvailability.Enable()
+			*delay = watchRetryMin
+			logger.LogAttrs(
+				ctx,
+				slog.LevelInfo,
+				"[OrderService/watchMarketChanges]",
+				slog.String("Market cache", "enabled"),
+				slog.Uint64("Revision", resp.GetRevision()),
+			)
+		case resp.GetEvent() != nil:
+			u.marketAvailability.Invalidate(resp.GetEvent().GetMarket().GetId())
+		}
+	}
+}
+
+// StopMarketWatch - stop receive market changes.
+func (u *Usecase) StopMarketWatch(ctx context.Context) error {
+	u.stopMarketWatch()
+	return nil
+}
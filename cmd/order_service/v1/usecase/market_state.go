@@ -0,0 +1,27 @@
+package usecase
+
+import (
+	"strings"
+
+	client "github.com/KonnorFrik/BinaryTentacles/internal/generated/spot_instrument/v1"
+)
+
+// acceptsNewOrders - report whether market in 'state' allow to place new orders.
+// Cancels are allowed in any state.
+// There is no auction matching: AUCTION_ONLY is alias of ACTIVE, orders of any type
+// are placed and matched right away.
+func acceptsNewOrders(state client.MarketState) bool {
+	switch state {
+	case client.MarketState_MARKET_STATE_ACTIVE,
+		client.MarketState_MARKET_STATE_AUCTION_ONLY:
+		return true
+	}
+
+	return false
+}
+
+// marketStateName - human readable name of market 'state'.
+func marketStateName(state client.MarketState) string {
+	name := strings.TrimPrefix(state.String(), "MARKET_STATE_")
+	return strings.ReplaceAll(strings.ToLower(name), "_", " ")
+}
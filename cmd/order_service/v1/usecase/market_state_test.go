@@ -0,0 +1,25 @@
+package usecase
+
+import (
+	"testing"
+
+	client "github.com/KonnorFrik/BinaryTentacles/internal/generated/spot_instrument/v1"
+)
+
+func TestAcceptsNewOrders(t *testing.T) {
+	cases := map[client.MarketState]bool{
+		client.MarketState_MARKET_STATE_UNSPECIFIED: false,
+		client.MarketState_MARKET_STATE_ACTIVE:      true,
+		client.MarketState_MARKET_STATE_HALTED:      false,
+		// Alias of ACTIVE, there is no auction matching.
+		client.MarketState_MARKET_STATE_AUCTION_ONLY: true,
+		client.MarketState_MARKET_STATE_CANCEL_ONLY:  false,
+		client.MarketState_MARKET_STATE_CLOSED:       false,
+	}
+
+	for state, want := range cases {
+		if got := acceptsNewOrders(state); got != want {
+			t.Errorf("%s: Got = %t, Want = %t\n", state, got, want)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package usecase
+
+import (
+	"fmt"
+
+	"github.com/KonnorFrik/BinaryTentacles/cmd/order_service/v1/usecase/availability"
+	"github.com/KonnorFrik/BinaryTentacles/cmd/order_service/v1/usecase/repository"
+	redCache "github.com/KonnorFrik/BinaryTentacles/pkg/cache/redis"
+	"github.com/KonnorFrik/BinaryTentacles/pkg/eventbus"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Option - option for customize Usecase at creation.
+type Option func(*Usecase) error
+
+// WithOrderCache - use redis 'c' for store orders and events of users
+// and for deliver updates between instances.
+func WithOrderCache(c *redCache.Cache) Option {
+	return func(u *Usecase) error {
+		if c == nil {
+			return fmt.Errorf("%w: order cache cannot be nil", ErrInvalidOption)
+		}
+
+		u.orderCache = c
+		return nil
+	}
+}
+
+// WithOrderRepository - store orders in 'r' instead of default storage.
+func WithOrderRepository(r repository.OrderRepository) Option {
+	return func(u *Usecase) error {
+		if r == nil {
+			return fmt.Errorf("%w: order repository cannot be nil", ErrInvalidOption)
+		}
+
+		u.orders = r
+		return nil
+	}
+}
+
+// WithEventRepository - read order events of users from 'r' instead of default storage.
+// 'r' must read events saved by order repository.
+func WithEventRepository(r repository.EventRepository) Option {
+	return func(u *Usecase) error {
+		if r == nil {
+			return fmt.Errorf("%w: event repository cannot be nil", ErrInvalidOption)
+		}
+
+		u.events = r
+		return nil
+	}
+}
+
+// WithOutbox - publish order events saved by order repository from 'o'.
+// Needed if order repository does not implement Outbox.
+func WithOutbox(o repository.Outbox) Option {
+	return func(u *Usecase) error {
+		if o == nil {
+			return fmt.Errorf("%w: outbox cannot be nil", ErrInvalidOption)
+		}
+
+		u.outbox = o
+		return nil
+	}
+}
+
+// WithHistoryRepository - read events of orders from 'r'.
+// Needed if order repository does not implement HistoryRepository.
+func WithHistoryRepository(r repository.HistoryRepository) Option {
+	return func(u *Usecase) error {
+		if r == nil {
+			return fmt.Errorf("%w: history repository cannot be nil", ErrInvalidOption)
+		}
+
+		u.history = r
+		return nil
+	}
+}
+
+// WithEventBus - publish events of orders and trades to 'b'.
+// Without it redis streams of order cache are used, without redis events are kept in this instance.
+func WithEventBus(b eventbus.Bus) Option {
+	return func(u *Usecase) error {
+		if b == nil {
+			return fmt.Errorf("%w: event bus cannot be nil", ErrInvalidOption)
+		}
+
+		u.bus = b
+		return nil
+	}
+}
+
+// WithMarketCache - keep availability of markets in local cache with 'config'.
+// Without it every check of market asks SpotInstrumentService.
+func WithMarketCache(config availability.Config) Option {
+	return func(u *Usecase) error {
+		u.marketAvailability = availability.New(config, u.lookupAvailability)
+		return nil
+	}
+}
+
+// WithMetricsRegisterer - register metrics of Usecase in 'r'.
+// Without it metrics are registered in default prometheus registry.
+func WithMetricsRegisterer(r prometheus.Registerer) Option {
+	return func(u *Usecase) error {
+		if r == nil {
+			return fmt.Errorf("%w: metrics registerer cannot be nil", ErrInvalidOption)
+		}
+
+		u.registerer = r
+		return nil
+	}
+}
@@ -0,0 +1,79 @@
+package order
+
+import (
+	"time"
+
+	pb "github.com/KonnorFrik/BinaryTentacles/internal/generated/order_service/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Event - one change of order reported to user of order.
+// Sequence counts events of user, first event is 1.
+// Revision counts events of order, first event is 1.
+type Event struct {
+	Sequence uint64            `json:"sequence"`
+	Revision uint64            `json:"revision"`
+	Type     pb.OrderEventType `json:"type"`
+	UserId   string            `json:"user_id"`
+	OrderId  string            `json:"order_id"`
+	MarketId string            `json:"market_id"`
+	Side     pb.OrderSide      `json:"side"`
+	// OrderType - type of order, only for CREATED event.
+	OrderType pb.OrderType   `json:"order_type,omitempty"`
+	Price     int64          `json:"price"`
+	Quantity  uint64         `json:"quantity"`
+	Filled    uint64         `json:"filled"`
+	Status    pb.OrderStatus `json:"status"`
+	// FillPrice, FillQuantity - match of FILLED event.
+	FillPrice    int64     `json:"fill_price,omitempty"`
+	FillQuantity uint64    `json:"fill_quantity,omitempty"`
+	At           time.Time `json:"at"`
+}
+
+// Event - add event 'typ' at time 'at' in history of order 'o'
+// and return it with current state of order and next revision.
+// Sequence is assigned when event is stored.
+func (o *Order) Event(typ pb.OrderEventType, at time.Time) Event {
+	o.mut.Lock()
+	defer o.mut.Unlock()
+	o.Revision++
+	e := Event{
+		Revision: o.Revision,
+		Type:     typ,
+		UserId:   o.UserId,
+		OrderId:  o.Id,
+		MarketId: o.MarketId,
+		Side:     o.Side,
+		Price:    o.Price,
+		Quantity: o.Quantity,
+		Filled:   o.Filled,
+		Status:   o.Status,
+		At:       at,
+	}
+
+	if typ == pb.OrderEventType_ORDER_EVENT_TYPE_CREATED {
+		e.OrderType = o.Type
+	}
+
+	return e
+}
+
+// ToGrpcOrderEvent - just copy data from event 'e' in 'event'.
+func (e *Event) ToGrpcOrderEvent(
+	event *pb.OrderEvent,
+) *Event {
+	event.Sequence = e.Sequence
+	event.Revision = e.Revision
+	event.Type = e.Type
+	event.OrderId = e.OrderId
+	event.MarketId = e.MarketId
+	event.Side = e.Side
+	event.Price = e.Price
+	event.Quantity = e.Quantity
+	event.Filled = e.Filled
+	event.Status = e.Status
+	event.FillPrice = e.FillPrice
+	event.FillQuantity = e.FillQuantity
+	event.At = timestamppb.New(e.At)
+	return e
+}
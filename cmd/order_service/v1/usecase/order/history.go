@@ -0,0 +1,95 @@
+package order
+
+import (
+	"errors"
+	"fmt"
+
+	pb "github.com/KonnorFrik/BinaryTentacles/internal/generated/order_service/v1"
+)
+
+// ErrHistory - events can't be applied to order, history is broken.
+var ErrHistory = errors.New("invalid order history")
+
+// Fold - return order built by apply 'events' to copy of 'snapshot' in order.
+// Nil 'snapshot' means empty order, then first event must be CREATED.
+// Events with revision of snapshot or older are skipped.
+func Fold(
+	snapshot *Order,
+	events []Event,
+) (
+	*Order,
+	error,
+) {
+	var ord = new(Order)
+
+	if snapshot != nil {
+		ord = snapshot.Clone()
+	}
+
+	for _, e := range events {
+		if e.Revision <= ord.Revision {
+			continue
+		}
+
+		if err := ord.Apply(e); err != nil {
+			return nil, err
+		}
+	}
+
+	if ord.Revision == 0 {
+		return nil, fmt.Errorf("%w: no events", ErrHistory)
+	}
+
+	return ord, nil
+}
+
+// Apply - change order 'o' by event 'e', next event in history of order.
+func (o *Order) Apply(e Event) error {
+	o.mut.Lock()
+	defer o.mut.Unlock()
+
+	if e.Revision != o.Revision+1 {
+		return fmt.Errorf("%w: event revision %d after %d", ErrHistory, e.Revision, o.Revision)
+	}
+
+	if o.Revision == 0 && e.Type != pb.OrderEventType_ORDER_EVENT_TYPE_CREATED {
+		return fmt.Errorf("%w: first event is %s", ErrHistory, e.Type)
+	}
+
+	switch e.Type {
+	case pb.OrderEventType_ORDER_EVENT_TYPE_CREATED:
+		if o.Revision != 0 {
+			return fmt.Errorf("%w: order %s created twice", ErrHistory, e.OrderId)
+		}
+
+		o.Id = e.OrderId
+		o.UserId = e.UserId
+		o.MarketId = e.MarketId
+		o.Type = e.OrderType
+		o.Side = e.Side
+		o.Price = e.Price
+		o.Quantity = e.Quantity
+		o.transition(e.Status, e.At)
+	case pb.OrderEventType_ORDER_EVENT_TYPE_STATUS_CHANGED,
+		pb.OrderEventType_ORDER_EVENT_TYPE_ACCEPTED,
+		pb.OrderEventType_ORDER_EVENT_TYPE_REJECTED,
+		pb.OrderEventType_ORDER_EVENT_TYPE_CANCELLED:
+		o.transition(e.Status, e.At)
+	case pb.OrderEventType_ORDER_EVENT_TYPE_FILLED:
+		o.Filled = min(o.Filled+e.FillQuantity, o.Quantity)
+
+		// Fills saved before fill statuses keep status of order.
+		if e.Status != o.Status {
+			o.transition(e.Status, e.At)
+		}
+	case pb.OrderEventType_ORDER_EVENT_TYPE_AMENDED:
+		o.Price = e.Price
+		o.Quantity = e.Quantity
+		o.Filled = min(o.Filled, o.Quantity)
+	default:
+		return fmt.Errorf("%w: unknown event type %s", ErrHistory, e.Type)
+	}
+
+	o.Revision = e.Revision
+	return nil
+}
@@ -0,0 +1,74 @@
+package order
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	pb "github.com/KonnorFrik/BinaryTentacles/internal/generated/order_service/v1"
+)
+
+func TestFold(t *testing.T) {
+	var (
+		now = time.Now()
+		ord = &Order{Id: "order", UserId: "user", Type: pb.OrderType_ORDER_TYPE_T1, Price: 10, Quantity: 5}
+	)
+
+	ord.Transition(pb.OrderStatus_ORDER_STATUS_CREATED, now)
+	events := []Event{ord.Event(pb.OrderEventType_ORDER_EVENT_TYPE_CREATED, now)}
+	ord.Fill(2, now)
+	filled := ord.Event(pb.OrderEventType_ORDER_EVENT_TYPE_FILLED, now)
+	filled.FillQuantity = 2
+	events = append(events, filled)
+	ord.Transition(pb.OrderStatus_ORDER_STATUS_PROCESSING, now)
+	events = append(events, ord.Event(pb.OrderEventType_ORDER_EVENT_TYPE_STATUS_CHANGED, now))
+	ord.Cancel(now)
+	events = append(events, ord.Event(pb.OrderEventType_ORDER_EVENT_TYPE_CANCELLED, now))
+
+	folded, err := Fold(nil, events)
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	if folded.Type != ord.Type || folded.Filled != 2 || folded.Status != ord.Status ||
+		folded.Sequence != ord.Sequence || folded.Revision != 4 || len(folded.Updates) != 4 {
+		t.Fatalf("Got = %+v, Want = %+v\n", folded, ord)
+	}
+
+	// Snapshot with older events gives same order.
+	snapshot, _ := Fold(nil, events[:2])
+	fromSnapshot, err := Fold(snapshot, events)
+
+	if err != nil || fromSnapshot.Status != folded.Status || fromSnapshot.Revision != folded.Revision {
+		t.Fatalf("Got = %v, %+v\n", err, fromSnapshot)
+	}
+}
+
+func TestFoldBrokenHistory(t *testing.T) {
+	var (
+		now = time.Now()
+		ord = &Order{Id: "order", Quantity: 5}
+	)
+
+	created := ord.Event(pb.OrderEventType_ORDER_EVENT_TYPE_CREATED, now)
+	ord.Event(pb.OrderEventType_ORDER_EVENT_TYPE_FILLED, now)
+	cancelled := ord.Event(pb.OrderEventType_ORDER_EVENT_TYPE_CANCELLED, now)
+
+	tests := []struct {
+		name   string
+		events []Event
+	}{
+		{"empty", nil},
+		{"no created", []Event{{Revision: 1, Type: pb.OrderEventType_ORDER_EVENT_TYPE_FILLED}}},
+		{"gap", []Event{created, cancelled}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Fold(nil, tt.events); !errors.Is(err, ErrHistory) {
+				t.Fatalf("Got = %v, Want = %v\n", err, ErrHistory)
+			}
+		})
+	}
+}
@@ -1,9 +1,9 @@
 package order
 
 import (
-	"context"
-	"math/rand"
+	"slices"
 	"sync"
+	"time"
 
 	pb "github.com/KonnorFrik/BinaryTentacles/internal/generated/order_service/v1"
 )
@@ -13,12 +13,58 @@ type Order struct {
 	mut sync.Mutex
 
 	Id       string       `json:"id"`
+	UserId   string       `json:"user_id"`
 	MarketId string       `json:"market_id"`
 	Type     pb.OrderType `json:"type"`
+	Side     pb.OrderSide `json:"side"`
 	Price    int64        `json:"price"`
 	Quantity uint64       `json:"quantity"`
+	// Filled - quantity matched in order book.
+	Filled uint64 `json:"filled"`
 
 	Status pb.OrderStatus `json:"status"`
+	// Sequence - sequence of last status change.
+	Sequence uint64 `json:"sequence"`
+	// Updates - all status changes, oldest first.
+	Updates []Update `json:"updates"`
+	// Revision - count of events in history of order, see Order.Event.
+	Revision uint64 `json:"revision"`
+	// Version - count of saves of order, used for detect concurrent changes.
+	Version uint64 `json:"version"`
+}
+
+// Update - one change of order status.
+// Sequence of first change is 1, every next change is one more.
+type Update struct {
+	Sequence uint64         `json:"sequence"`
+	Status   pb.OrderStatus `json:"status"`
+	At       time.Time      `json:"at"`
+}
+
+// IsFinal - report whether update 'u' is last update of order.
+func (u *Update) IsFinal() bool {
+	return isFinalStatus(u.Status)
+}
+
+// Clone - return copy of order 'o'.
+func (o *Order) Clone() *Order {
+	o.mut.Lock()
+	defer o.mut.Unlock()
+	return &Order{
+		Id:       o.Id,
+		UserId:   o.UserId,
+		MarketId: o.MarketId,
+		Type:     o.Type,
+		Side:     o.Side,
+		Price:    o.Price,
+		Quantity: o.Quantity,
+		Filled:   o.Filled,
+		Status:   o.Status,
+		Sequence: o.Sequence,
+		Updates:  slices.Clone(o.Updates),
+		Revision: o.Revision,
+		Version:  o.Version,
+	}
 }
 
 // GetStatus - return status of order 'o'.
@@ -28,12 +74,159 @@ func (o *Order) GetStatus() pb.OrderStatus {
 	return o.Status
 }
 
+// IsFinal - report whether order 'o' reached a final status.
+func (o *Order) IsFinal() bool {
+	o.mut.Lock()
+	defer o.mut.Unlock()
+	return isFinalStatus(o.Status)
+}
+
+// Cancel - move order 'o' in cancelled status at time 'at'.
+// Returns false if order already in final status.
+func (o *Order) Cancel(at time.Time) (Update, bool) {
+	o.mut.Lock()
+	defer o.mut.Unlock()
+
+	if isFinalStatus(o.Status) {
+		return Update{}, false
+	}
+
+	return o.transition(pb.OrderStatus_ORDER_STATUS_CANCELLED, at), true
+}
+
+// Transition - move order 'o' in 'status' at time 'at'.
+func (o *Order) Transition(status pb.OrderStatus, at time.Time) Update {
+	o.mut.Lock()
+	defer o.mut.Unlock()
+	return o.transition(status, at)
+}
+
+// Advance - move order 'o' in next status of fake processing at time 'at'.
+// Processed order is rejected if 'reject', else confirmed, then it waits in book.
+// Returns false if order is not in processing, e.g. matched in book.
+func (o *Order) Advance(at time.Time, reject bool) (Update, bool) {
+	o.mut.Lock()
+	defer o.mut.Unlock()
+
+	var next pb.OrderStatus
+
+	switch o.Status {
+	case pb.OrderStatus_ORDER_STATUS_CREATED:
+		next = pb.OrderStatus_ORDER_STATUS_PROCESSING
+	case pb.OrderStatus_ORDER_STATUS_PROCESSING:
+		next = pb.OrderStatus_ORDER_STATUS_PROCESSED
+	case pb.OrderStatus_ORDER_STATUS_PROCESSED:
+		if reject {
+			next = pb.OrderStatus_ORDER_STATUS_REJECT
+
+		} else {
+			next = pb.OrderStatus_ORDER_STATUS_CONFIRM
+		}
+	default:
+		return Update{}, false
+	}
+
+	return o.transition(next, at), true
+}
+
+// InProcessing - report whether order 'o' can be moved by Advance.
+func (o *Order) InProcessing() bool {
+	o.mut.Lock()
+	defer o.mut.Unlock()
+
+	switch o.Status {
+	case pb.OrderStatus_ORDER_STATUS_CREATED,
+		pb.OrderStatus_ORDER_STATUS_PROCESSING,
+		pb.OrderStatus_ORDER_STATUS_PROCESSED:
+		return true
+	}
+
+	return false
+}
+
+// UpdatesAfter - return status changes of order 'o' with sequence after 'sequence'.
+func (o *Order) UpdatesAfter(sequence uint64) []Update {
+	o.mut.Lock()
+	defer o.mut.Unlock()
+
+	var result []Update
+
+	for _, u := range o.Updates {
+		if u.Sequence > sequence {
+			result = append(result, u)
+		}
+	}
+
+	return result
+}
+
+// transition - must be called with locked order.
+func (o *Order) transition(status pb.OrderStatus, at time.Time) Update {
+	o.Sequence++
+	o.Status = status
+	u := Update{
+		Sequence: o.Sequence,
+		Status:   status,
+		At:       at,
+	}
+	o.Updates = append(o.Updates, u)
+	return u
+}
+
+// Fill - add 'quantity' matched in order book to order 'o' at time 'at'
+// and move it in partially filled or filled status.
+// Final order keeps status, it matched before it removed from book.
+// Returns false if status is not changed.
+func (o *Order) Fill(quantity uint64, at time.Time) (Update, bool) {
+	o.mut.Lock()
+	defer o.mut.Unlock()
+	o.Filled = min(o.Filled+quantity, o.Quantity)
+	status := fillStatus(o.Filled, o.Quantity)
+
+	if status == o.Status || isFinalStatus(o.Status) {
+		return Update{}, false
+	}
+
+	return o.transition(status, at), true
+}
+
+// Resting - report whether order 'o' waits for match in order book.
+func (o *Order) Resting() bool {
+	o.mut.Lock()
+	defer o.mut.Unlock()
+	return o.Filled < o.Quantity && !isFinalStatus(o.Status)
+}
+
+// fillStatus - return status of order with 'quantity' of which 'filled' is matched.
+func fillStatus(filled, quantity uint64) pb.OrderStatus {
+	if filled >= quantity {
+		return pb.OrderStatus_ORDER_STATUS_FILLED
+	}
+
+	return pb.OrderStatus_ORDER_STATUS_PARTIALLY_FILLED
+}
+
+// isFinalStatus - report whether status 'st' can't be changed anymore.
+// Confirmed order is not final, it waits in book until filled or cancelled.
+func isFinalStatus(st pb.OrderStatus) bool {
+	switch st {
+	case pb.OrderStatus_ORDER_STATUS_REJECT,
+		pb.OrderStatus_ORDER_STATUS_CANCELLED,
+		pb.OrderStatus_ORDER_STATUS_FILLED:
+		return true
+	}
+
+	return false
+}
+
 // FromGrpcCreateRequest - just copy data from request 'req' in order 'o'.
 func (o *Order) FromGrpcCreateRequest(
 	req *pb.CreateRequest,
 ) *Order {
+	o.UserId = req.GetUserId()
 	o.MarketId = req.GetMarketId()
 	o.Type = req.GetOrderType()
+	o.Side = req.GetSide()
 	o.Price = req.GetPrice()
 	o.Quantity = req.GetQuantity()
 	return o
@@ -47,53 +240,3 @@ func (o *Order) ToGrpcCreateResponse(
 	resp.OrderStatus = o.Status
 	return o
 }
-
-// UpdateStatus - Start goroutine for fake update order status.
-func (o *Order) UpdateStatus(
-	ctx context.Context,
-) <-chan pb.OrderStatus {
-	var result = make(chan pb.OrderStatus)
-
-	go func() {
-		defer close(result)
-		var loop bool = true
-
-		for loop {
-			o.mut.Lock()
-			var orderStatus pb.OrderStatus = o.Status
-
-			if o.Status == pb.OrderStatus_ORDER_STATUS_CONFIRM || o.Status == pb.OrderStatus_ORDER_STATUS_REJECT {
-				o.mut.Unlock()
-				break
-			}
-
-			switch o.Status {
-			case pb.OrderStatus_ORDER_STATUS_CREATED:
-				o.Status = pb.OrderStatus_ORDER_STATUS_PROCESSING
-
-			case pb.OrderStatus_ORDER_STATUS_PROCESSING:
-				o.Status = pb.OrderStatus_ORDER_STATUS_PROCESSED
-
-			case pb.OrderStatus_ORDER_STATUS_PROCESSED:
-				if rand.Intn(2) == 0 {
-					o.Status = pb.OrderStatus_ORDER_STATUS_CONFIRM
-
-				} else {
-					o.Status = pb.OrderStatus_ORDER_STATUS_REJECT
-				}
-
-				loop = false
-			}
-
-			o.mut.Unlock()
-
-			select {
-			case <-ctx.Done():
-				return
-			case result <- orderStatus:
-			}
-		}
-	}()
-
-	return result
-}
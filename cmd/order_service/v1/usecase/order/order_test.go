@@ -0,0 +1,57 @@
+package order
+
+import (
+	"testing"
+	"time"
+
+	pb "github.com/KonnorFrik/BinaryTentacles/internal/generated/order_service/v1"
+)
+
+func TestFill(t *testing.T) {
+	var (
+		now = time.Now()
+		ord = &Order{Id: "order", Quantity: 5}
+	)
+
+	ord.Transition(pb.OrderStatus_ORDER_STATUS_CREATED, now)
+	update, changed := ord.Fill(2, now)
+
+	if !changed || update.Status != pb.OrderStatus_ORDER_STATUS_PARTIALLY_FILLED || !ord.Resting() {
+		t.Fatalf("Got = %+v, %t, Want = %s\n", update, changed, pb.OrderStatus_ORDER_STATUS_PARTIALLY_FILLED)
+	}
+
+	// Next partial fill keeps status.
+	if _, changed = ord.Fill(1, now); changed || ord.Filled != 3 {
+		t.Fatalf("Got = %t, filled %d, Want = false, filled 3\n", changed, ord.Filled)
+	}
+
+	update, changed = ord.Fill(2, now)
+
+	if !changed || !update.IsFinal() || ord.Resting() {
+		t.Fatalf("Got = %+v, %t, Want = %s\n", update, changed, pb.OrderStatus_ORDER_STATUS_FILLED)
+	}
+
+	if _, ok := ord.Cancel(now); ok {
+		t.Fatalf("Got = cancelled, Want = filled order is final\n")
+	}
+}
+
+func TestFillCancelled(t *testing.T) {
+	var (
+		now = time.Now()
+		ord = &Order{Id: "order", Quantity: 5}
+	)
+
+	ord.Transition(pb.OrderStatus_ORDER_STATUS_CONFIRM, now)
+
+	if ord.IsFinal() || !ord.Resting() {
+		t.Fatalf("Got = final %t, resting %t, Want confirmed order in book\n", ord.IsFinal(), ord.Resting())
+	}
+
+	ord.Cancel(now)
+
+	// Matched before cancel, saved after.
+	if _, changed := ord.Fill(2, now); changed || ord.Status != pb.OrderStatus_ORDER_STATUS_CANCELLED || ord.Filled != 2 {
+		t.Fatalf("Got = %t, %s, filled %d, Want cancelled with 2 filled\n", changed, ord.Status, ord.Filled)
+	}
+}
@@ -0,0 +1,286 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/KonnorFrik/BinaryTentacles/cmd/order_service/v1/usecase/book"
+	"github.com/KonnorFrik/BinaryTentacles/cmd/order_service/v1/usecase/order"
+	pb "github.com/KonnorFrik/BinaryTentacles/internal/generated/order_service/v1"
+	"github.com/google/uuid"
+)
+
+const (
+	// defaultBookDepth - levels per side of GetOrderBook if not given.
+	defaultBookDepth = 50
+	// maxBookDepth - max levels per side of GetOrderBook.
+	maxBookDepth = 1000
+	// tradesTopic - topic of event bus with trades of all markets.
+	tradesTopic = "trades"
+)
+
+// GetOrderBook - return aggregated price levels of market logic.
+func (u *Usecase) GetOrderBook(
+	ctx context.Context,
+	req *pb.GetOrderBookRequest,
+) (
+	book.Snapshot,
+	error,
+) {
+	if err := u.checkBookMarket(ctx, req.GetMarketId()); err != nil {
+		return book.Snapshot{}, err
+	}
+
+	depth := int(req.GetDepth())
+
+	switch {
+	case depth == 0:
+		depth = defaultBookDepth
+	case depth > maxBookDepth:
+		depth = maxBookDepth
+	}
+
+	return u.orderBooks.Get(req.GetMarketId()).Snapshot(depth), nil
+}
+
+// StreamOrderBook - start stream of order book changes logic.
+// Returns full book and subscription for updates after it.
+// Caller must Close returned subscription.
+func (u *Usecase) StreamOrderBook(
+	ctx context.Context,
+	req *pb.StreamOrderBookRequest,
+) (
+	*book.Subscription,
+	book.Snapshot,
+	error,
+) {
+	if err := u.checkBookMarket(ctx, req.GetMarketId()); err != nil {
+		return nil, book.Snapshot{}, err
+	}
+
+	sub, snapshot := u.orderBooks.Get(req.GetMarketId()).Subscribe()
+	return sub, snapshot, nil
+}
+
+// ShutdownOrderBooks - stop all streams of order books.
+// Must be called before graceful stop of server, book streams never end by itself.
+func (u *Usecase) ShutdownOrderBooks(ctx context.Context) error {
+	u.orderBooks.Close()
+	return nil
+}
+
+// checkBookMarket - check is book of market with 'marketId' can be viewed.
+// Book of known market is viewable in any market state.
+func (u *Usecase) checkBookMarket(
+	ctx context.Context,
+	marketId string,
+) error {
+	if e := uuid.Validate(marketId); e != nil {
+		return fmt.Errorf("%w: requested market id is invalid", ErrMarketUnavailable)
+	}
+
+	result, err := u.marketAvailable(ctx, marketId)
+
+	if err != nil {
+		return err
+	}
+
+	return result.Err
+}
+
+// matchResult - fills of placed order with best prices of book after match.
+type matchResult struct {
+	// order - placed order saved after match.
+	order    *order.Order
+	marketId string
+	fills    []book.Fill
+	bestBid  int64
+	bestAsk  int64
+	at       time.Time
+}
+
+// matchOrder - place saved order 'ord' in book of its market.
+// Matched quantity is saved in resting orders and in placed order,
+// which is read again while it changed concurrently, with event to users of both orders.
+// Must be called with locked order, so fill of it as maker waits until it saved as taker.
+func (u *Usecase) matchOrder(
+	ctx context.Context,
+	ord *order.Order,
+) (
+	matchResult,
+	error,
+) {
+	ordBook := u.orderBooks.Get(ord.MarketId)
+	fills, _ := ordBook.Place(ord.Id, ord.Side, ord.Price, ord.Quantity)
+	result := matchResult{
+		order:    ord,
+		marketId: ord.MarketId,
+		fills:    fills,
+		at:       time.Now(),
+	}
+
+	if len(fills) == 0 {
+		return result, nil
+	}
+
+	result.bestBid, result.bestAsk = ordBook.Top()
+
+	for _, fill := range fills {
+		u.fillRestingOrder(ctx, fill, result.at)
+	}
+
+	var updates []order.Update
+	saved, err := u.updateOrder(ctx, ord.Id, func(o *order.Order) ([]order.Event, bool) {
+		var events = make([]order.Event, 0, len(fills))
+		updates = updates[:0]
+
+		for _, fill := range fills {
+			if update, changed := o.Fill(fill.Quantity, result.at); changed {
+				updates = append(updates, update)
+			}
+
+			events = append(events, fillEvent(o, fill, result.at))
+		}
+
+		return events, true
+	})
+	u.wakeOutboxRelay()
+
+	if err != nil {
+		return result, err
+	}
+
+	result.order = saved
+
+	for _, update := range updates {
+		u.publishOrderUpdate(ctx, ord.Id, update)
+	}
+
+	return result, nil
+}
+
+// reportMatch - report every fill of 'result' as trade with best prices after match.
+// Called without lock of order, reports go to other services.
+func (u *Usecase) reportMatch(
+	ctx context.Context,
+	result matchResult,
+) {
+	for _, fill := range result.fills {
+		u.reportTrade(ctx, result.marketId, fill, result.bestBid, result.bestAsk)
+		u.publishTrade(ctx, result.marketId, fill, result.at)
+	}
+}
+
+// fillRestingOrder - save matched quantity of maker order from 'fill' at time 'at'.
+// Errors only logged, match already done in book.
+// Maker is locked while taker is locked: maker was in book before taker,
+// so it never waits for lock of taker.
+func (u *Usecase) fillRestingOrder(
+	ctx context.Context,
+	fill book.Fill,
+	at time.Time,
+) {
+	unlock := u.orderLocks.Lock(fill.MakerOrderId)
+	defer unlock()
+
+	var (
+		update  order.Update
+		changed bool
+	)
+	_, err := u.updateOrder(ctx, fill.MakerOrderId, func(o *order.Order) ([]order.Event, bool) {
+		update, changed = o.Fill(fill.Quantity, at)
+		return []order.Event{fillEvent(o, fill, at)}, true
+	})
+
+	if err != nil {
+		logger.LogAttrs(
+			ctx,
+			slog.LevelError,
+			"[OrderService/fillRestingOrder]",
+			slog.String("order", fill.MakerOrderId),
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+
+	if changed {
+		u.publishOrderUpdate(ctx, fill.MakerOrderId, update)
+	}
+}
+
+// restoreOrderBooks - put saved resting orders in books of their markets,
+// so matching continues after restart. Orders in processing continue it.
+func (u *Usecase) restoreOrderBooks(ctx context.Context) error {
+	orders, err := u.orders.ListResting(ctx)
+
+	if err != nil {
+		return fmt.Errorf("%w: restore order books: %w", ErrInternal, err)
+	}
+
+	for _, ord := range orders {
+		u.orderBooks.Get(ord.MarketId).Rest(ord.Id, ord.Side, ord.Price, ord.Quantity-ord.Filled)
+
+		if ord.InProcessing() {
+			go u.runOrderLifecycle(ord.Id)
+		}
+	}
+
+	return nil
+}
+
+// fillEvent - return FILLED event of order 'ord' matched by 'fill' at time 'at'.
+func fillEvent(
+	ord *order.Order,
+	fill book.Fill,
+	at time.Time,
+) order.Event {
+	e := ord.Event(pb.OrderEventType_ORDER_EVENT_TYPE_FILLED, at)
+	e.FillPrice = fill.Price
+	e.FillQuantity = fill.Quantity
+	return e
+}
+
+// tradeEvent - trade published to event bus.
+type tradeEvent struct {
+	MarketId     string    `json:"market_id"`
+	MakerOrderId string    `json:"maker_order_id"`
+	TakerOrderId string    `json:"taker_order_id"`
+	Price        int64     `json:"price"`
+	Quantity     uint64    `json:"quantity"`
+	At           time.Time `json:"at"`
+}
+
+// publishTrade - publish 'fill' in market 'marketId' at time 'at' as trade to event bus.
+// Errors only logged, orders already saved.
+func (u *Usecase) publishTrade(
+	ctx context.Context,
+	marketId string,
+	fill book.Fill,
+	at time.Time,
+) {
+	tradeBytes, err := json.Marshal(tradeEvent{
+		MarketId:     marketId,
+		MakerOrderId: fill.MakerOrderId,
+		TakerOrderId: fill.TakerOrderId,
+		Price:        fill.Price,
+		Quantity:     fill.Quantity,
+		At:           at,
+	})
+
+	if err == nil {
+		_, err = u.bus.Publish(ctx, tradesTopic, tradeBytes)
+	}
+
+	if err != nil {
+		logger.LogAttrs(
+			ctx,
+			slog.LevelError,
+			"[OrderService/publishTrade]",
+			slog.String("market", marketId),
+			slog.String("error", err.Error()),
+		)
+	}
+}
@@ -0,0 +1,41 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/KonnorFrik/BinaryTentacles/cmd/order_service/v1/usecase/order"
+	"github.com/KonnorFrik/BinaryTentacles/cmd/order_service/v1/usecase/repository"
+	pb "github.com/KonnorFrik/BinaryTentacles/internal/generated/order_service/v1"
+)
+
+// GetOrderHistory - return all events of order, oldest first, logic.
+// If user id is given, only history of order of this user is returned.
+func (u *Usecase) GetOrderHistory(
+	ctx context.Context,
+	req *pb.GetOrderHistoryRequest,
+) (
+	[]order.Event,
+	error,
+) {
+	if req.GetOrderId() == "" {
+		return nil, fmt.Errorf("%w: order id is required", ErrInvalidRequest)
+	}
+
+	events, err := u.history.History(ctx, req.GetOrderId())
+
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrDoesNotExist
+		}
+
+		return nil, fmt.Errorf("%w: %w", ErrInternal, err)
+	}
+
+	if userId := req.GetUserId(); userId != "" && events[0].UserId != userId {
+		return nil, ErrDoesNotExist
+	}
+
+	return events, nil
+}
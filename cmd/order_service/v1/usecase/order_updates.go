@@ -0,0 +1,357 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/KonnorFrik/BinaryTentacles/cmd/order_service/v1/usecase/feed"
+	"github.com/KonnorFrik/BinaryTentacles/cmd/order_service/v1/usecase/order"
+	"github.com/KonnorFrik/BinaryTentacles/cmd/order_service/v1/usecase/repository"
+	pb "github.com/KonnorFrik/BinaryTentacles/internal/generated/order_service/v1"
+	redCache "github.com/KonnorFrik/BinaryTentacles/pkg/cache/redis"
+)
+
+const (
+	// orderLifecycleStep - time between fake status changes of order.
+	orderLifecycleStep = time.Second
+	// maxUpdateAttempts - how many times change of order is tried if order changed concurrently.
+	maxUpdateAttempts = 5
+	// orderUpdatesChannel - prefix of redis channel with updates of one order,
+	// full channel is prefix + order id.
+	orderUpdatesChannel = "order_updates:"
+)
+
+// startOrderBroker - subscribe to updates of orders from all instances.
+// Without redis updates are delivered only in this instance.
+func (u *Usecase) startOrderBroker(ctx context.Context) error {
+	if u.orderCache == nil {
+		return nil
+	}
+
+	broker, err := u.orderCache.PSubscribe(ctx, orderUpdatesChannel+"*")
+
+	if err != nil {
+		return fmt.Errorf("subscribe to order updates: %w", err)
+	}
+
+	u.orderBroker = broker
+	go u.relayOrderUpdates(broker)
+	return nil
+}
+
+// OrderUpdates - start watch of order status changes logic.
+// Changes after 'from_sequence' are sent first, then live changes.
+func (u *Usecase) OrderUpdates(
+	ctx context.Context,
+	req *pb.OrderUpdatesRequest,
+) (
+	*feed.Watch,
+	error,
+) {
+	if req.GetDelayMs() < 0 {
+		return nil, fmt.Errorf("%w: delay can't be negative", ErrInvalidRequest)
+	}
+
+	reload := func(ctx context.Context, sequence uint64) ([]order.Update, bool, error) {
+		ord, err := u.OrderById(ctx, req.GetOrderId())
+
+		if err != nil {
+			return nil, false, err
+		}
+
+		return ord.UpdatesAfter(sequence), ord.IsFinal(), nil
+	}
+
+	return feed.Start(
+		ctx,
+		u.orderUpdates,
+		req.GetOrderId(),
+		req.GetFromSequence(),
+		time.Duration(req.GetDelayMs())*time.Millisecond,
+		reload,
+	)
+}
+
+// ShutdownOrderUpdates - stop fake processing of orders, all watches of orders and streams of users.
+// Must be called before graceful stop of server, watch streams end only with final status
+// and streams of users never end by itself.
+func (u *Usecase) ShutdownOrderUpdates(ctx context.Context) error {
+	u.stopOrderLifecycle()
+
+	// Relay is stopped before broker, events not published are left in outbox.
+	select {
+	case <-u.outboxDone:
+	case <-ctx.Done():
+	}
+
+	u.orderUpdates.Close()
+	u.userEvents.Close()
+	err := u.busEvents.Close()
+
+	if u.orderBroker != nil {
+		err = errors.Join(err, u.orderBroker.Close())
+	}
+
+	return err
+}
+
+// publishOrderUpdate - send 'update' of order 'orderId' to watchers in all instances.
+// Without broker or if publish failed update is sent only to watchers in this instance.
+func (u *Usecase) publishOrderUpdate(
+	ctx context.Context,
+	orderId string,
+	update order.Update,
+) {
+	if u.orderBroker == nil {
+		u.orderUpdates.Publish(orderId, update)
+		return
+	}
+
+	payload, err := json.Marshal(update)
+
+	if err == nil {
+		err = u.orderCache.Publish(ctx, orderUpdatesChannel+orderId, string(payload))
+	}
+
+	if err != nil {
+		logger.LogAttrs(
+			ctx,
+			slog.LevelError,
+			"[OrderService/publishOrderUpdate]",
+			slog.String("order", orderId),
+			slog.String("error", err.Error()),
+		)
+		u.orderUpdates.Publish(orderId, update)
+	}
+}
+
+// relayOrderUpdates - send updates received by 'broker' to watchers
+// in this instance until broker closed.
+func (u *Usecase) relayOrderUpdates(broker *redCache.Subscription) {
+	for msg := range broker.Messages() {
+		if msg.Subscribed {
+			// Updates published while connection was broken are lost.
+			u.orderUpdates.Resync()
+			continue
+		}
+
+		var (
+			update order.Update
+			err    error
+		)
+
+		if orderId, ok := strings.CutPrefix(msg.Channel, orderUpdatesChannel); ok {
+			if err = json.Unmarshal([]byte(msg.Payload), &update); err == nil {
+				u.orderUpdates.Publish(orderId, update)
+			}
+		}
+
+		if err != nil {
+			logger.LogAttrs(
+				u.lifecycleCtx,
+				slog.LevelError,
+				"[OrderService/relayOrderUpdates]",
+				slog.String("channel", msg.Channel),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+}
+
+// runOrderLifecycle - move order with 'orderId' through fake processing
+// every orderLifecycleStep until it confirmed, rejected or matched in book.
+// Rejected order is removed from book.
+func (u *Usecase) runOrderLifecycle(orderId string) {
+	for {
+		timer := time.NewTimer(orderLifecycleStep)
+
+		select {
+		case <-u.lifecycleCtx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		// Processed order is randomly confirmed or rejected.
+		reject := rand.Intn(2) == 0
+		next, err := u.changeOrderOutOfBook(
+			u.lifecycleCtx,
+			orderId,
+			func(ord *order.Order) bool {
+				return reject && ord.Status == pb.OrderStatus_ORDER_STATUS_PROCESSED
+			},
+			func(ord *order.Order) (order.Update, bool) {
+				return ord.Advance(time.Now(), reject)
+			},
+		)
+
+		// Order matched while rejected is filled, not rejected.
+		if errors.Is(err, errMatched) {
+			return
+		}
+
+		if err != nil {
+			logger.LogAttrs(
+				u.lifecycleCtx,
+				slog.LevelError,
+				"[OrderService/runOrderLifecycle]",
+				slog.String("order", orderId),
+				slog.String("error", err.Error()),
+			)
+			return
+		}
+
+		if next == nil || next.IsFinal() || next.Status == pb.OrderStatus_ORDER_STATUS_CONFIRM {
+			return
+		}
+	}
+}
+
+// changeOrder - apply status change 'change' to saved order with 'orderId',
+// save it with event of change and publish it. Returns nil update if 'change' reject it.
+func (u *Usecase) changeOrder(
+	ctx context.Context,
+	orderId string,
+	change func(*order.Order) (order.Update, bool),
+) (
+	*order.Update,
+	error,
+) {
+	unlock := u.orderLocks.Lock(orderId)
+	defer unlock()
+	return u.changeLockedOrder(ctx, orderId, change)
+}
+
+// changeOrderOutOfBook - apply status change 'change' like changeOrder,
+// but resting order for which 'takeOut' reports true is taken out of book of its market first,
+// so it can't be matched while change is saved. 'change' must make such order final.
+// Order is put back in book, after orders of same price, if change is not saved or not final.
+// Returns errMatched if order is resting by saved state, but not in book.
+func (u *Usecase) changeOrderOutOfBook(
+	ctx context.Context,
+	orderId string,
+	takeOut func(*order.Order) bool,
+	change func(*order.Order) (order.Update, bool),
+) (
+	*order.Update,
+	error,
+) {
+	unlock := u.orderLocks.Lock(orderId)
+	defer unlock()
+	ord, err := u.OrderById(ctx, orderId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !ord.Resting() || !takeOut(ord) {
+		return u.changeLockedOrder(ctx, orderId, change)
+	}
+
+	remaining, removed := u.removeFromBook(ord)
+
+	if !removed {
+		return nil, errMatched
+	}
+
+	update, err := u.changeLockedOrder(ctx, orderId, change)
+
+	if err != nil || update == nil || !update.IsFinal() {
+		u.orderBooks.Get(ord.MarketId).Rest(ord.Id, ord.Side, ord.Price, remaining)
+	}
+
+	return update, err
+}
+
+// changeLockedOrder - apply status change 'change' like changeOrder.
+// Must be called with locked order. 'change' may be called again if order changed concurrently,
+// so it must only change order.
+func (u *Usecase) changeLockedOrder(
+	ctx context.Context,
+	orderId string,
+	change func(*order.Order) (order.Update, bool),
+) (
+	*order.Update,
+	error,
+) {
+	var update order.Update
+	ord, err := u.updateOrder(ctx, orderId, func(o *order.Order) ([]order.Event, bool) {
+		var ok bool
+		update, ok = change(o)
+
+		if !ok {
+			return nil, false
+		}
+
+		return []order.Event{o.Event(statusEventType(update.Status), update.At)}, true
+	})
+
+	if err != nil || ord == nil {
+		return nil, err
+	}
+
+	// Published with locked order, so updates of one order are published in sequence order.
+	u.publishOrderUpdate(ctx, orderId, update)
+	u.wakeOutboxRelay()
+	return &update, nil
+}
+
+// statusEventType - return type of order event of change in 'status'.
+func statusEventType(status pb.OrderStatus) pb.OrderEventType {
+	switch status {
+	case pb.OrderStatus_ORDER_STATUS_CONFIRM:
+		return pb.OrderEventType_ORDER_EVENT_TYPE_ACCEPTED
+	case pb.OrderStatus_ORDER_STATUS_REJECT:
+		return pb.OrderEventType_ORDER_EVENT_TYPE_REJECTED
+	case pb.OrderStatus_ORDER_STATUS_CANCELLED:
+		return pb.OrderEventType_ORDER_EVENT_TYPE_CANCELLED
+	}
+
+	return pb.OrderEventType_ORDER_EVENT_TYPE_STATUS_CHANGED
+}
+
+// updateOrder - apply 'change' to saved order with 'orderId' and save it
+// with events returned by 'change'.
+// If order is changed by other instance, 'change' is repeated on new order.
+// Returns nil order if 'change' reject it.
+func (u *Usecase) updateOrder(
+	ctx context.Context,
+	orderId string,
+	change func(*order.Order) ([]order.Event, bool),
+) (
+	*order.Order,
+	error,
+) {
+	for attempt := 1; ; attempt++ {
+		ord, err := u.OrderById(ctx, orderId)
+
+		if err != nil {
+			return nil, err
+		}
+
+		events, ok := change(ord)
+
+		if !ok {
+			return nil, nil
+		}
+
+		err = u.orders.Update(ctx, ord, events...)
+
+		switch {
+		case err == nil:
+			return ord, nil
+		case errors.Is(err, repository.ErrNotFound):
+			return nil, ErrDoesNotExist
+		case !errors.Is(err, repository.ErrVersionConflict):
+			return nil, fmt.Errorf("%w: Order save: %w", ErrInternal, err)
+		case attempt == maxUpdateAttempts:
+			return nil, fmt.Errorf("%w: %d attempts failed", ErrConcurrentChange, attempt)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/KonnorFrik/BinaryTentacles/cmd/order_service/v1/usecase/repository"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// outboxPollInterval - how often outbox is checked without wake up,
+	// picks events left by failed publish or by other instance.
+	outboxPollInterval = time.Second
+	// outboxBatchSize - count of events read from outbox at once.
+	outboxBatchSize = 100
+)
+
+// outboxMetrics - metrics of relay of outbox.
+type outboxMetrics struct {
+	// lag - age of oldest not published event at last relay.
+	lag prometheus.Gauge
+	// published - events published from outbox.
+	published prometheus.Counter
+	// failures - failed publishes and reads of outbox.
+	failures prometheus.Counter
+}
+
+// newOutboxMetrics - create metrics of outbox registered in 'registerer'.
+// Metrics already registered by other Usecase with same registerer are shared.
+func newOutboxMetrics(registerer prometheus.Registerer) (outboxMetrics, error) {
+	var (
+		metrics outboxMetrics
+		err     error
+	)
+
+	metrics.lag, err = registerMetric(registerer, prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "order_service",
+			Subsystem: "outbox",
+			Name:      "lag_seconds",
+			Help:      "Age of oldest order event waiting in outbox at last relay.",
+		},
+	))
+
+	if err != nil {
+		return metrics, err
+	}
+
+	metrics.published, err = registerMetric(registerer, prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "order_service",
+			Subsystem: "outbox",
+			Name:      "published_total",
+			Help:      "Order events published from outbox.",
+		},
+	))
+
+	if err != nil {
+		return metrics, err
+	}
+
+	metrics.failures, err = registerMetric(registerer, prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "order_service",
+			Subsystem: "outbox",
+			Name:      "failures_total",
+			Help:      "Failed reads of outbox and publishes of order events.",
+		},
+	))
+	return metrics, err
+}
+
+// registerMetric - register 'metric' in 'registerer' and return it,
+// or return same metric registered before.
+func registerMetric[T prometheus.Collector](
+	registerer prometheus.Registerer,
+	metric T,
+) (
+	T,
+	error,
+) {
+	err := registerer.Register(metric)
+
+	if already, ok := err.(prometheus.AlreadyRegisteredError); ok {
+		if existing, ok := already.ExistingCollector.(T); ok {
+			return existing, nil
+		}
+	}
+
+	if err != nil {
+		return metric, fmt.Errorf("%w: register metric: %w", ErrInvalidOption, err)
+	}
+
+	return metric, nil
+}
+
+// wakeOutboxRelay - start relay of outbox now, without wait of poll.
+// Call after save of events.
+func (u *Usecase) wakeOutboxRelay() {
+	select {
+	case u.outboxWake <- struct{}{}:
+	default:
+	}
+}
+
+// relayOutbox - publish events from outbox on wake up and every outboxPollInterval until 'ctx' done.
+func (u *Usecase) relayOutbox(ctx context.Context) {
+	defer close(u.outboxDone)
+	ticker := time.NewTicker(outboxPollInterval)
+	defer ticker.Stop()
+
+	for {
+		u.publishOutbox(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-u.outboxWake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// publishOutbox - publish events from outbox oldest first and mark them sent
+// until outbox is empty or publish failed. Not sent events stay for next relay.
+// Event is published again if mark failed or other instance relay it at same time,
+// streams of users skip events by sequence.
+func (u *Usecase) publishOutbox(ctx context.Context) {
+	for ctx.Err() == nil {
+		entries, err := u.outbox.Pending(ctx, outboxBatchSize)
+
+		if err != nil {
+			u.outboxMetrics.failures.Inc()
+			logger.LogAttrs(
+				ctx,
+				slog.LevelError,
+				"[OrderService/publishOutbox/Pending]",
+				slog.String("error", err.Error()),
+			)
+			return
+		}
+
+		if len(entries) == 0 {
+			u.outboxMetrics.lag.Set(0)
+			return
+		}
+
+		u.outboxMetrics.lag.Set(time.Since(entries[0].StoredAt).Seconds())
+		var sent = make([]repository.OutboxEntry, 0, len(entries))
+
+		for _, entry := range entries {
+			if err = u.publishUserEvent(ctx, entry.Event); err != nil {
+				u.outboxMetrics.failures.Inc()
+				logger.LogAttrs(
+					ctx,
+					slog.LevelError,
+					"[OrderService/publishOutbox/publishUserEvent]",
+					slog.String("order", entry.Event.OrderId),
+					slog.String("user", entry.Event.UserId),
+					slog.String("error", err.Error()),
+				)
+				break
+			}
+
+			sent = append(sent, entry)
+		}
+
+		if len(sent) > 0 {
+			if err := u.outbox.MarkSent(ctx, sent); err != nil {
+				u.outboxMetrics.failures.Inc()
+				logger.LogAttrs(
+					ctx,
+					slog.LevelError,
+					"[OrderService/publishOutbox/MarkSent]",
+					slog.String("error", err.Error()),
+				)
+				return
+			}
+
+			u.outboxMetrics.published.Add(float64(len(sent)))
+		}
+
+		if err != nil || len(entries) < outboxBatchSize {
+			return
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/KonnorFrik/BinaryTentacles/cmd/order_service/v1/usecase/order"
+	redCache "github.com/KonnorFrik/BinaryTentacles/pkg/cache/redis"
+)
+
+const (
+	// cachedOrderKey - prefix of key with cached order, full key is prefix + order id.
+	cachedOrderKey = "order:"
+	// maxCacheAttempts - how many times write or drop of cached order is tried.
+	maxCacheAttempts = 3
+)
+
+// errOlderVersion - cached order is newer than order being cached.
+var errOlderVersion = errors.New("cached order is newer")
+
+// Cache - storage of values with expiry, like redis.
+// Get and SetIf return redis.ErrNil if 'key' not exist,
+// SetIf returns redis.ErrChanged if value changed while checked.
+type Cache interface {
+	Get(ctx context.Context, key string) (string, error)
+	SetNew(ctx context.Context, key string, value string, ttl time.Duration) (bool, error)
+	SetIf(ctx context.Context, key string, value string, ttl time.Duration, check func(current string) error) error
+	Delete(ctx context.Context, keys ...string) (int64, error)
+}
+
+// Cached - OrderRepository 'store' with read-through cache of orders.
+// Changes go to 'store' first, then changed order is cached.
+// Cached order is never replaced by older version, so read of store
+// which finished after change can't bring back order before change,
+// and cache never keeps order newer than in store.
+type Cached struct {
+	store OrderRepository
+	cache Cache
+	ttl   time.Duration
+}
+
+// NewCached - create a new Cached with orders stored in 'store'
+// and kept in 'cache' for 'ttl' after read.
+func NewCached(
+	store OrderRepository,
+	cache Cache,
+	ttl time.Duration,
+) *Cached {
+	return &Cached{store: store, cache: cache, ttl: ttl}
+}
+
+// Create - implement OrderRepository interface.
+func (c *Cached) Create(
+	ctx context.Context,
+	ord *order.Order,
+	ttl time.Duration,
+	events ...order.Event,
+) error {
+	if err := c.store.Create(ctx, ord, ttl, events...); err != nil {
+		return err
+	}
+
+	c.put(ctx, ord)
+	return nil
+}
+
+// Get - implement OrderRepository interface.
+func (c *Cached) Get(
+	ctx context.Context,
+	id string,
+) (
+	*order.Order,
+	error,
+) {
+	orderJSON, err := c.cache.Get(ctx, cachedOrderKey+id)
+
+	if err == nil {
+		if ord, err := decodeOrder(orderJSON); err == nil {
+			return ord, nil
+		}
+	}
+
+	ord, err := c.store.Get(ctx, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	c.put(ctx, ord)
+	return ord, nil
+}
+
+// Update - implement OrderRepository interface.
+// On version conflict cached order is replaced by order from store, so repeated read gets it.
+func (c *Cached) Update(
+	ctx context.Context,
+	ord *order.Order,
+	events ...order.Event,
+) error {
+	err := c.store.Update(ctx, ord, events...)
+
+	switch {
+	case err == nil:
+		c.put(ctx, ord)
+	case errors.Is(err, ErrVersionConflict):
+		c.refresh(ctx, ord.Id)
+	case errors.Is(err, ErrNotFound):
+		c.drop(ctx, ord.Id)
+	}
+
+	return err
+}
+
+// ListByUser - implement OrderRepository interface.
+// Read from store, lists are not cached.
+func (c *Cached) ListByUser(
+	ctx context.Context,
+	userId string,
+) (
+	[]*order.Order,
+	error,
+) {
+	return c.store.ListByUser(ctx, userId)
+}
+
+// ListResting - implement OrderRepository interface.
+// Read from store, lists are not cached.
+func (c *Cached) ListResting(ctx context.Context) ([]*order.Order, error) {
+	return c.store.ListResting(ctx)
+}
+
+// Delete - implement OrderRepository interface.
+func (c *Cached) Delete(
+	ctx context.Context,
+	id string,
+) error {
+	err := c.store.Delete(ctx, id)
+	c.drop(ctx, id)
+	return err
+}
+
+// put - keep 'ord' in cache unless newer version of it is cached.
+// If write failed, cached order is dropped, so it is read from store next time.
+func (c *Cached) put(
+	ctx context.Context,
+	ord *order.Order,
+) {
+	var key = cachedOrderKey + ord.Id
+	orderJSON, err := json.Marshal(ord)
+
+	if err != nil {
+		c.drop(ctx, ord.Id)
+		return
+	}
+
+	for range maxCacheAttempts {
+		created, err := c.cache.SetNew(ctx, key, string(orderJSON), c.ttl)
+
+		if err == nil && created {
+			return
+		}
+
+		if err == nil {
+			err = c.cache.SetIf(ctx, key, string(orderJSON), c.ttl, func(current string) error {
+				if cached, err := decodeOrder(current); err == nil && cached.Version > ord.Version {
+					return errOlderVersion
+				}
+
+				return nil
+			})
+		}
+
+		switch {
+		case err == nil, errors.Is(err, errOlderVersion):
+			return
+		case errors.Is(err, redCache.ErrNil), errors.Is(err, redCache.ErrChanged):
+			continue
+		}
+
+		break
+	}
+
+	c.drop(ctx, ord.Id)
+}
+
+// refresh - replace cached order with 'id' by order from store.
+// If read failed, cached order is dropped.
+func (c *Cached) refresh(
+	ctx context.Context,
+	id string,
+) {
+	ord, err := c.store.Get(ctx, id)
+
+	if err != nil {
+		c.drop(ctx, id)
+		return
+	}
+
+	c.put(ctx, ord)
+}
+
+// Drop - remove order with 'id' from cache, tried maxCacheAttempts times.
+// For orders changed in store without Cached, like rebuilt by Postgres.Replay.
+func (c *Cached) Drop(
+	ctx context.Context,
+	id string,
+) error {
+	var err error
+
+	for range maxCacheAttempts {
+		if _, err = c.cache.Delete(ctx, cachedOrderKey+id); err == nil || ctx.Err() != nil {
+			break
+		}
+	}
+
+	return err
+}
+
+// drop - remove order with 'id' from cache.
+// If remove failed, cached order stays until ttl.
+func (c *Cached) drop(
+	ctx context.Context,
+	id string,
+) {
+	c.Drop(ctx, id)
+}
+
+// compile time check, redis cache is usable as Cache.
+var _ Cache = (*redCache.Cache)(nil)
@@ -0,0 +1,231 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/KonnorFrik/BinaryTentacles/cmd/order_service/v1/usecase/order"
+	pb "github.com/KonnorFrik/BinaryTentacles/internal/generated/order_service/v1"
+	redCache "github.com/KonnorFrik/BinaryTentacles/pkg/cache/redis"
+)
+
+// mapCache - Cache in map without expiry.
+type mapCache struct {
+	mut    sync.Mutex
+	values map[string]string
+}
+
+func newMapCache() *mapCache {
+	return &mapCache{values: make(map[string]string)}
+}
+
+func (m *mapCache) Get(ctx context.Context, key string) (string, error) {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	value, exist := m.values[key]
+
+	if !exist {
+		return "", redCache.ErrNil
+	}
+
+	return value, nil
+}
+
+func (m *mapCache) Set(ctx context.Context, key string, value string, ttl time.Duration) error {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	m.values[key] = value
+	return nil
+}
+
+func (m *mapCache) SetNew(ctx context.Context, key string, value string, ttl time.Duration) (bool, error) {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+
+	if _, exist := m.values[key]; exist {
+		return false, nil
+	}
+
+	m.values[key] = value
+	return true, nil
+}
+
+func (m *mapCache) SetIf(
+	ctx context.Context,
+	key string,
+	value string,
+	ttl time.Duration,
+	check func(current string) error,
+) error {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	current, exist := m.values[key]
+
+	if !exist {
+		return redCache.ErrNil
+	}
+
+	if err := check(current); err != nil {
+		return err
+	}
+
+	m.values[key] = value
+	return nil
+}
+
+func (m *mapCache) Delete(ctx context.Context, keys ...string) (int64, error) {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	var removed int64
+
+	for _, key := range keys {
+		if _, exist := m.values[key]; exist {
+			delete(m.values, key)
+			removed++
+		}
+	}
+
+	return removed, nil
+}
+
+func TestCachedReadThrough(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		store = NewMemory()
+		cache = newMapCache()
+		repo  = NewCached(store, cache, time.Minute)
+		ord   = &order.Order{Id: "0197a1c2-0000-7000-8000-000000000001", UserId: "user"}
+	)
+
+	if err := store.Create(ctx, ord, 0); err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	if _, err := repo.Get(ctx, ord.Id); err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	if _, err := cache.Get(ctx, cachedOrderKey+ord.Id); err != nil {
+		t.Fatalf("Order is not cached after read: %v\n", err)
+	}
+
+	// Order read from store is served from cache, even if store lost it.
+	store.Delete(ctx, ord.Id)
+
+	if _, err := repo.Get(ctx, ord.Id); err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	if _, err := repo.Get(ctx, "0197a1c2-0000-7000-8000-000000000002"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Got = %v, Want = %v\n", err, ErrNotFound)
+	}
+}
+
+func TestCachedStaleUpdate(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		store = NewMemory()
+		repo  = NewCached(store, newMapCache(), time.Minute)
+		ord   = &order.Order{Id: "0197a1c2-0000-7000-8000-000000000001", UserId: "user"}
+	)
+
+	if err := repo.Create(ctx, ord, 0); err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	// Other instance changed order in store, cache keeps old version.
+	changed, _ := store.Get(ctx, ord.Id)
+	changed.Transition(pb.OrderStatus_ORDER_STATUS_CREATED, time.Now())
+
+	if err := store.Update(ctx, changed); err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	stale, _ := repo.Get(ctx, ord.Id)
+	stale.Cancel(time.Now())
+
+	if err := repo.Update(ctx, stale); !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("Got = %v, Want = %v\n", err, ErrVersionConflict)
+	}
+
+	// Conflict replaces cached order, so repeated read gets new version.
+	fresh, err := repo.Get(ctx, ord.Id)
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	if fresh.Version != 2 || fresh.Status != pb.OrderStatus_ORDER_STATUS_CREATED {
+		t.Fatalf("Got version = %d, status = %s\n", fresh.Version, fresh.Status)
+	}
+
+	fresh.Cancel(time.Now())
+
+	if err := repo.Update(ctx, fresh); err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+}
+
+func TestCachedStaleRead(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		store = NewMemory()
+		cache = newMapCache()
+		repo  = NewCached(store, cache, time.Minute)
+		ord   = &order.Order{Id: "0197a1c2-0000-7000-8000-000000000001", UserId: "user"}
+	)
+
+	if err := store.Create(ctx, ord, 0); err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	// Read of store started before update, finished after it.
+	stale, _ := store.Get(ctx, ord.Id)
+	changed, _ := repo.Get(ctx, ord.Id)
+	changed.Transition(pb.OrderStatus_ORDER_STATUS_CREATED, time.Now())
+
+	if err := repo.Update(ctx, changed); err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	repo.put(ctx, stale)
+	cached, err := repo.Get(ctx, ord.Id)
+
+	if err != nil || cached.Version != 2 || cached.Status != pb.OrderStatus_ORDER_STATUS_CREATED {
+		t.Fatalf("Got = %+v, %v, Want version 2 in CREATED\n", cached, err)
+	}
+}
+
+func TestCachedDrop(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		store = NewMemory()
+		repo  = NewCached(store, newMapCache(), time.Minute)
+		ord   = &order.Order{Id: "0197a1c2-0000-7000-8000-000000000001", UserId: "user"}
+	)
+
+	if err := repo.Create(ctx, ord, 0); err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	// Order rebuilt in store without Cached.
+	rebuilt, _ := store.Get(ctx, ord.Id)
+	rebuilt.Transition(pb.OrderStatus_ORDER_STATUS_CREATED, time.Now())
+
+	if err := store.Update(ctx, rebuilt); err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	if err := repo.Drop(ctx, ord.Id); err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	got, err := repo.Get(ctx, ord.Id)
+
+	if err != nil || got.Status != pb.OrderStatus_ORDER_STATUS_CREATED {
+		t.Fatalf("Got = %+v, %v, Want order in CREATED\n", got, err)
+	}
+}
@@ -0,0 +1,312 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/KonnorFrik/BinaryTentacles/cmd/order_service/v1/usecase/order"
+	"github.com/KonnorFrik/BinaryTentacles/pkg/fake_db"
+)
+
+// Memory - OrderRepository, HistoryRepository and Outbox in memory of process, for run and tests without redis.
+// Saved events of users are read by Memory.Events.
+type Memory struct {
+	mut sync.Mutex
+	db  *fake_db.Db
+	// ids - id in db by order id.
+	ids map[string]uint64
+	// events - events of users by user id, event sequence is it position + 1.
+	events map[string][]order.Event
+	// history - events of orders by order id, event revision is it position + 1.
+	history map[string][]order.Event
+	outbox  []OutboxEntry
+	// outboxId - id of last entry added to outbox.
+	outboxId uint64
+	now      func() time.Time
+}
+
+// memoryEntry - stored order with time of expire.
+// Zero 'expiresAt' means order never expires.
+type memoryEntry struct {
+	order     *order.Order
+	expiresAt time.Time
+}
+
+// expired - report whether entry 'e' is expired at time 'now'.
+func (e *memoryEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+// NewMemory - create a new empty Memory.
+func NewMemory() *Memory {
+	return &Memory{
+		db:      fake_db.New(),
+		ids:     make(map[string]uint64),
+		events:  make(map[string][]order.Event),
+		history: make(map[string][]order.Event),
+		now:     time.Now,
+	}
+}
+
+// Create - implement OrderRepository interface.
+func (m *Memory) Create(
+	ctx context.Context,
+	ord *order.Order,
+	ttl time.Duration,
+	events ...order.Event,
+) error {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+
+	if _, exist := m.lookup(ctx, ord.Id); exist {
+		return ErrExists
+	}
+
+	var entry = memoryEntry{order: ord.Clone()}
+
+	if ttl > 0 {
+		entry.expiresAt = m.now().Add(ttl)
+	}
+
+	ord.Version = 1
+	entry.order.Version = 1
+	m.ids[ord.Id] = m.db.Create(ctx, entry)
+	m.appendEvents(events)
+	return nil
+}
+
+// Get - implement OrderRepository interface.
+func (m *Memory) Get(
+	ctx context.Context,
+	id string,
+) (
+	*order.Order,
+	error,
+) {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+
+	entry, exist := m.lookup(ctx, id)
+
+	if !exist {
+		return nil, ErrNotFound
+	}
+
+	return entry.order.Clone(), nil
+}
+
+// Update - implement OrderRepository interface.
+func (m *Memory) Update(
+	ctx context.Context,
+	ord *order.Order,
+	events ...order.Event,
+) error {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+
+	entry, exist := m.lookup(ctx, ord.Id)
+
+	if !exist {
+		return ErrNotFound
+	}
+
+	if entry.order.Version != ord.Version {
+		return ErrVersionConflict
+	}
+
+	ord.Version++
+	entry.order = ord.Clone()
+	m.db.Update(ctx, m.ids[ord.Id], entry)
+	m.appendEvents(events)
+	return nil
+}
+
+// ListByUser - implement OrderRepository interface.
+func (m *Memory) ListByUser(
+	ctx context.Context,
+	userId string,
+) (
+	[]*order.Order,
+	error,
+) {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+
+	var (
+		result []*order.Order
+		now    = m.now()
+	)
+
+	for _, entry := range fake_db.All[memoryEntry](ctx, m.db) {
+		if entry.order.UserId == userId && !entry.expired(now) {
+			result = append(result, entry.order.Clone())
+		}
+	}
+
+	// Order ids are UUIDv7, so sorted by time of create.
+	slices.SortFunc(result, func(a, b *order.Order) int {
+		return strings.Compare(a.Id, b.Id)
+	})
+	return result, nil
+}
+
+// ListResting - implement OrderRepository interface.
+func (m *Memory) ListResting(ctx context.Context) ([]*order.Order, error) {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+
+	var (
+		result []*order.Order
+		now    = m.now()
+	)
+
+	for _, entry := range fake_db.All[memoryEntry](ctx, m.db) {
+		if entry.order.Resting() && !entry.expired(now) {
+			result = append(result, entry.order.Clone())
+		}
+	}
+
+	slices.SortFunc(result, func(a, b *order.Order) int {
+		return strings.Compare(a.Id, b.Id)
+	})
+	return result, nil
+}
+
+// Delete - implement OrderRepository interface.
+func (m *Memory) Delete(
+	ctx context.Context,
+	id string,
+) error {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+
+	if _, exist := m.lookup(ctx, id); !exist {
+		return ErrNotFound
+	}
+
+	m.db.Delete(ctx, m.ids[id])
+	delete(m.ids, id)
+	return nil
+}
+
+// lookup - return not expired entry of order with 'id'.
+// Expired entry is removed. Must be called with locked 'm'.
+func (m *Memory) lookup(
+	ctx context.Context,
+	id string,
+) (
+	memoryEntry,
+	bool,
+) {
+	dbId, exist := m.ids[id]
+
+	if !exist {
+		return memoryEntry{}, false
+	}
+
+	entry, _ := fake_db.As[memoryEntry](ctx, m.db, dbId)
+
+	if entry.expired(m.now()) {
+		m.db.Delete(ctx, dbId)
+		delete(m.ids, id)
+		return memoryEntry{}, false
+	}
+
+	return entry, true
+}
+
+// appendEvents - save 'events' in events of users with next sequences, in history of orders and in outbox.
+// Must be called with locked 'm'.
+func (m *Memory) appendEvents(events []order.Event) {
+	now := m.now()
+
+	for _, e := range events {
+		e.Sequence = uint64(len(m.events[e.UserId])) + 1
+		m.events[e.UserId] = append(m.events[e.UserId], e)
+		m.history[e.OrderId] = append(m.history[e.OrderId], e)
+		m.outboxId++
+		m.outbox = append(m.outbox, OutboxEntry{
+			Id:       strconv.FormatUint(m.outboxId, 10),
+			Event:    e,
+			StoredAt: now,
+		})
+	}
+}
+
+// History - implement HistoryRepository interface.
+// History is kept after order is deleted or expired.
+func (m *Memory) History(
+	ctx context.Context,
+	orderId string,
+) (
+	[]order.Event,
+	error,
+) {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	events, exist := m.history[orderId]
+
+	if !exist {
+		return nil, ErrNotFound
+	}
+
+	return slices.Clone(events), nil
+}
+
+// Pending - implement Outbox interface.
+func (m *Memory) Pending(
+	ctx context.Context,
+	limit int,
+) (
+	[]OutboxEntry,
+	error,
+) {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	return slices.Clone(m.outbox[:min(limit, len(m.outbox))]), nil
+}
+
+// MarkSent - implement Outbox interface.
+func (m *Memory) MarkSent(
+	ctx context.Context,
+	entries []OutboxEntry,
+) error {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	m.outbox = slices.DeleteFunc(m.outbox, func(pending OutboxEntry) bool {
+		return slices.ContainsFunc(entries, func(sent OutboxEntry) bool {
+			return sent.Id == pending.Id
+		})
+	})
+	return nil
+}
+
+// Events - return EventRepository with events of users saved by 'm'.
+func (m *Memory) Events() *MemoryEvents {
+	return &MemoryEvents{memory: m}
+}
+
+// MemoryEvents - EventRepository with events saved by Memory.
+// Events are never expire.
+type MemoryEvents struct {
+	memory *Memory
+}
+
+// ListByUser - implement EventRepository interface.
+func (e *MemoryEvents) ListByUser(
+	ctx context.Context,
+	userId string,
+	sequence uint64,
+) (
+	[]order.Event,
+	error,
+) {
+	e.memory.mut.Lock()
+	defer e.memory.mut.Unlock()
+	events := e.memory.events[userId]
+	return slices.Clone(events[min(sequence, uint64(len(events))):]), nil
+}
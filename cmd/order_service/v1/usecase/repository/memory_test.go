@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/KonnorFrik/BinaryTentacles/cmd/order_service/v1/usecase/order"
+	pb "github.com/KonnorFrik/BinaryTentacles/internal/generated/order_service/v1"
+)
+
+func TestMemoryUpdate(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		repo = NewMemory()
+		ord  = &order.Order{Id: "0197a1c2-0000-7000-8000-000000000001", UserId: "user"}
+	)
+
+	if err := repo.Create(ctx, ord, time.Hour); err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	if err := repo.Create(ctx, ord, time.Hour); !errors.Is(err, ErrExists) {
+		t.Fatalf("Got = %v, Want = %v\n", err, ErrExists)
+	}
+
+	first, _ := repo.Get(ctx, ord.Id)
+	second, _ := repo.Get(ctx, ord.Id)
+	first.Transition(pb.OrderStatus_ORDER_STATUS_CREATED, time.Now())
+
+	if err := repo.Update(ctx, first); err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	// 'second' is read before update of 'first'.
+	second.Cancel(time.Now())
+
+	if err := repo.Update(ctx, second); !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("Got = %v, Want = %v\n", err, ErrVersionConflict)
+	}
+
+	stored, err := repo.Get(ctx, ord.Id)
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	if stored.Version != 2 || stored.Status != pb.OrderStatus_ORDER_STATUS_CREATED {
+		t.Fatalf("Got version = %d, status = %s\n", stored.Version, stored.Status)
+	}
+}
+
+func TestMemoryListByUser(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		repo = NewMemory()
+		now  = time.Now()
+	)
+
+	repo.now = func() time.Time { return now }
+	orders := []*order.Order{
+		{Id: "0197a1c2-0000-7000-8000-000000000003", UserId: "user"},
+		{Id: "0197a1c2-0000-7000-8000-000000000001", UserId: "user"},
+		{Id: "0197a1c2-0000-7000-8000-000000000002", UserId: "other"},
+		{Id: "0197a1c2-0000-7000-8000-000000000004", UserId: "user"},
+	}
+
+	for i, ord := range orders {
+		ttl := time.Hour
+
+		// Last order expires first.
+		if i == len(orders)-1 {
+			ttl = time.Minute
+		}
+
+		if err := repo.Create(ctx, ord, ttl); err != nil {
+			t.Fatalf("Got = %q\n", err)
+		}
+	}
+
+	if err := repo.Delete(ctx, orders[1].Id); err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	if err := repo.Delete(ctx, orders[1].Id); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Got = %v, Want = %v\n", err, ErrNotFound)
+	}
+
+	now = now.Add(2 * time.Minute)
+	got, err := repo.ListByUser(ctx, "user")
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	if len(got) != 1 || got[0].Id != orders[0].Id {
+		t.Fatalf("Got = %v, Want only %s\n", got, orders[0].Id)
+	}
+
+	if _, err := repo.Get(ctx, orders[3].Id); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Got = %v, Want = %v\n", err, ErrNotFound)
+	}
+}
+
+func TestMemoryListResting(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		repo = NewMemory()
+		now  = time.Now()
+	)
+
+	orders := []*order.Order{
+		{Id: "0197a1c2-0000-7000-8000-000000000003", Quantity: 5},
+		{Id: "0197a1c2-0000-7000-8000-000000000001", Quantity: 5},
+		{Id: "0197a1c2-0000-7000-8000-000000000002", Quantity: 5},
+		{Id: "0197a1c2-0000-7000-8000-000000000004", Quantity: 5},
+	}
+	orders[0].Fill(2, now)
+	orders[2].Fill(5, now)
+	orders[3].Cancel(now)
+
+	for _, ord := range orders {
+		if err := repo.Create(ctx, ord, time.Hour); err != nil {
+			t.Fatalf("Got = %q\n", err)
+		}
+	}
+
+	got, err := repo.ListResting(ctx)
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	if len(got) != 2 || got[0].Id != orders[1].Id || got[1].Id != orders[0].Id {
+		t.Fatalf("Got = %v, Want = %s, %s\n", got, orders[1].Id, orders[0].Id)
+	}
+}
+
+func TestMemoryOutbox(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		repo   = NewMemory()
+		events = repo.Events()
+		ord    = &order.Order{Id: "0197a1c2-0000-7000-8000-000000000001", UserId: "user"}
+	)
+
+	created := ord.Event(pb.OrderEventType_ORDER_EVENT_TYPE_CREATED, time.Now())
+
+	if err := repo.Create(ctx, ord, time.Hour, created); err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	stale := ord.Clone()
+	ord.Transition(pb.OrderStatus_ORDER_STATUS_CREATED, time.Now())
+	changed := ord.Event(pb.OrderEventType_ORDER_EVENT_TYPE_STATUS_CHANGED, time.Now())
+
+	if err := repo.Update(ctx, ord, changed); err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	if err := repo.Update(ctx, stale, changed); !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("Got = %v, Want = %v\n", err, ErrVersionConflict)
+	}
+
+	stored, _ := events.ListByUser(ctx, "user", 0)
+
+	if len(stored) != 2 || stored[1].Sequence != 2 || stored[1].Status != pb.OrderStatus_ORDER_STATUS_CREATED {
+		t.Fatalf("Got = %+v\n", stored)
+	}
+
+	history, err := repo.History(ctx, ord.Id)
+
+	if err != nil || len(history) != 2 || history[1].Revision != 2 {
+		t.Fatalf("Got = %v, %+v\n", err, history)
+	}
+
+	pending, _ := repo.Pending(ctx, 1)
+
+	if len(pending) != 1 || pending[0].Event.Sequence != 1 {
+		t.Fatalf("Got = %+v, Want first event\n", pending)
+	}
+
+	repo.MarkSent(ctx, pending)
+	pending, _ = repo.Pending(ctx, 10)
+
+	if len(pending) != 1 || pending[0].Event.Sequence != 2 {
+		t.Fatalf("Got = %+v, Want second event\n", pending)
+	}
+}
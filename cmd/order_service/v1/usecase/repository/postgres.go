@@ -0,0 +1,707 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/KonnorFrik/BinaryTentacles/cmd/order_service/v1/usecase/order"
+	"github.com/KonnorFrik/BinaryTentacles/pkg/postgres"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// snapshotInterval - count of events of order between saves of it snapshot.
+const snapshotInterval = 50
+
+// replayBatch - count of orders read at once by Postgres.Replay.
+const replayBatch = 500
+
+// Postgres - OrderRepository, HistoryRepository and Outbox stored in postgres.
+// Events of orders are saved in table 'order_events', read events of users with PostgresEvents.
+// Order is folded from it latest snapshot in table 'order_snapshots' and events after it,
+// table 'orders' is projection of orders for lists and version checks, see Postgres.Replay.
+// Orders never expire, 'ttl' of Create is ignored.
+type Postgres struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgres - create a new Postgres with orders stored in database of 'pool'.
+func NewPostgres(pool *pgxpool.Pool) *Postgres {
+	return &Postgres{pool: pool}
+}
+
+// Create - implement OrderRepository interface.
+func (p *Postgres) Create(
+	ctx context.Context,
+	ord *order.Order,
+	ttl time.Duration,
+	events ...order.Event,
+) error {
+	stored := ord.Clone()
+	stored.Version = 1
+	orderJSON, err := json.Marshal(stored)
+
+	if err != nil {
+		return fmt.Errorf("order marshal: %w", err)
+	}
+
+	err = pgx.BeginFunc(ctx, p.pool, func(tx pgx.Tx) error {
+		_, err := tx.Exec(
+			ctx,
+			`INSERT INTO orders (id, user_id, market_id, status, version, data)
+			VALUES ($1, $2, $3, $4, $5, $6)`,
+			stored.Id, stored.UserId, stored.MarketId, int32(stored.Status), stored.Version, orderJSON,
+		)
+
+		if postgres.IsUniqueViolation(err) {
+			return ErrExists
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if err = appendEvents(ctx, tx, events); err != nil {
+			return err
+		}
+
+		return saveSnapshot(ctx, tx, stored, orderJSON, len(events))
+	})
+
+	if err != nil {
+		return err
+	}
+
+	ord.Version = 1
+	return nil
+}
+
+// Get - implement OrderRepository interface.
+// Order is folded from it snapshot and events after it, version is read from projection.
+// Order without events is read from projection.
+func (p *Postgres) Get(
+	ctx context.Context,
+	id string,
+) (
+	*order.Order,
+	error,
+) {
+	var (
+		ord     *order.Order
+		options = pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly}
+	)
+
+	err := pgx.BeginTxFunc(ctx, p.pool, options, func(tx pgx.Tx) error {
+		var (
+			version   uint64
+			orderJSON []byte
+		)
+
+		err := tx.QueryRow(ctx, "SELECT version, data FROM orders WHERE id = $1", id).Scan(&version, &orderJSON)
+
+		if postgres.IsNotFound(err) {
+			return ErrNotFound
+		}
+
+		if err != nil {
+			return err
+		}
+
+		snapshot, err := loadSnapshot(ctx, tx, id)
+
+		if err != nil {
+			return err
+		}
+
+		var after uint64
+
+		if snapshot != nil {
+			after = snapshot.Revision
+		}
+
+		events, err := loadHistory(ctx, tx, id, after)
+
+		if err != nil {
+			return err
+		}
+
+		if snapshot == nil && len(events) == 0 {
+			ord, err = decodeOrder(string(orderJSON))
+			return err
+		}
+
+		if ord, err = order.Fold(snapshot, events); err != nil {
+			return err
+		}
+
+		ord.Version = version
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ord, nil
+}
+
+// Update - implement OrderRepository interface.
+func (p *Postgres) Update(
+	ctx context.Context,
+	ord *order.Order,
+	events ...order.Event,
+) error {
+	stored := ord.Clone()
+	stored.Version++
+	orderJSON, err := json.Marshal(stored)
+
+	if err != nil {
+		return fmt.Errorf("order marshal: %w", err)
+	}
+
+	err = pgx.BeginFunc(ctx, p.pool, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(
+			ctx,
+			`UPDATE orders SET status = $3, version = $4, data = $5, updated_at = now()
+			WHERE id = $1 AND version = $2`,
+			stored.Id, ord.Version, int32(stored.Status), stored.Version, orderJSON,
+		)
+
+		if err != nil {
+			return err
+		}
+
+		if tag.RowsAffected() == 0 {
+			var exist bool
+			err = tx.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM orders WHERE id = $1)", ord.Id).Scan(&exist)
+
+			switch {
+			case err != nil:
+				return err
+			case !exist:
+				return ErrNotFound
+			}
+
+			return ErrVersionConflict
+		}
+
+		if err = appendEvents(ctx, tx, events); err != nil {
+			return err
+		}
+
+		return saveSnapshot(ctx, tx, stored, orderJSON, len(events))
+	})
+
+	if err != nil {
+		return err
+	}
+
+	ord.Version = stored.Version
+	return nil
+}
+
+// ListByUser - implement OrderRepository interface.
+func (p *Postgres) ListByUser(
+	ctx context.Context,
+	userId string,
+) (
+	[]*order.Order,
+	error,
+) {
+	// Order ids are UUIDv7, so sorted by time of create.
+	rows, err := p.pool.Query(ctx, "SELECT data FROM orders WHERE user_id = $1 ORDER BY id", userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (*order.Order, error) {
+		var orderJSON []byte
+
+		if err := row.Scan(&orderJSON); err != nil {
+			return nil, err
+		}
+
+		return decodeOrder(string(orderJSON))
+	})
+}
+
+// ListResting - implement OrderRepository interface.
+func (p *Postgres) ListResting(ctx context.Context) ([]*order.Order, error) {
+	// Statuses REJECT, CANCELLED and FILLED never rest, same as partial index of migration 0007.
+	rows, err := p.pool.Query(ctx, "SELECT data FROM orders WHERE status NOT IN (5, 6, 8) ORDER BY id")
+
+	if err != nil {
+		return nil, err
+	}
+
+	orders, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*order.Order, error) {
+		var orderJSON []byte
+
+		if err := row.Scan(&orderJSON); err != nil {
+			return nil, err
+		}
+
+		return decodeOrder(string(orderJSON))
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return slices.DeleteFunc(orders, func(ord *order.Order) bool { return !ord.Resting() }), nil
+}
+
+// Delete - implement OrderRepository interface.
+func (p *Postgres) Delete(
+	ctx context.Context,
+	id string,
+) error {
+	tag, err := p.pool.Exec(ctx, "DELETE FROM orders WHERE id = $1", id)
+
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// History - implement HistoryRepository interface.
+func (p *Postgres) History(
+	ctx context.Context,
+	orderId string,
+) (
+	[]order.Event,
+	error,
+) {
+	events, err := loadHistory(ctx, p.pool, orderId, 0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(events) == 0 {
+		return nil, ErrNotFound
+	}
+
+	return events, nil
+}
+
+// Replay - rebuild projection 'orders' and snapshots of every order by fold of all it events.
+// Version of order in projection is kept. 'rebuilt' is called with every rebuilt order, may be nil.
+// Returns count of rebuilt orders.
+func (p *Postgres) Replay(
+	ctx context.Context,
+	rebuilt func(*order.Order),
+) (
+	int,
+	error,
+) {
+	var (
+		count int
+		last  string
+	)
+
+	for {
+		rows, err := p.pool.Query(
+			ctx,
+			"SELECT DISTINCT order_id FROM order_events WHERE order_id > $1 ORDER BY order_id LIMIT $2",
+			last, replayBatch,
+		)
+
+		if err != nil {
+			return count, err
+		}
+
+		ids, err := pgx.CollectRows(rows, pgx.RowTo[string])
+
+		if err != nil {
+			return count, err
+		}
+
+		for _, id := range ids {
+			ord, err := p.replayOrder(ctx, id)
+
+			if err != nil {
+				return count, fmt.Errorf("order %s: %w", id, err)
+			}
+
+			count++
+
+			if rebuilt != nil {
+				rebuilt(ord)
+			}
+		}
+
+		if len(ids) < replayBatch {
+			return count, nil
+		}
+
+		last = ids[len(ids)-1]
+	}
+}
+
+// replayOrder - rebuild projection and snapshot of order 'id' from all it events.
+// Order in projection is locked first, so concurrent Update waits for rebuild.
+func (p *Postgres) replayOrder(
+	ctx context.Context,
+	id string,
+) (
+	*order.Order,
+	error,
+) {
+	var ord *order.Order
+	err := pgx.BeginFunc(ctx, p.pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, "SELECT 1 FROM orders WHERE id = $1 FOR UPDATE", id); err != nil {
+			return err
+		}
+
+		events, err := loadHistory(ctx, tx, id, 0)
+
+		if err != nil {
+			return err
+		}
+
+		if ord, err = order.Fold(nil, events); err != nil {
+			return err
+		}
+
+		ord.Version = 1
+		orderJSON, err := json.Marshal(ord)
+
+		if err != nil {
+			return fmt.Errorf("order marshal: %w", err)
+		}
+
+		err = tx.QueryRow(
+			ctx,
+			`INSERT INTO orders (id, user_id, market_id, status, version, data)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (id) DO UPDATE SET
+				user_id = EXCLUDED.user_id,
+				market_id = EXCLUDED.market_id,
+				status = EXCLUDED.status,
+				data = jsonb_set(EXCLUDED.data, '{version}', to_jsonb(orders.version)),
+				updated_at = now()
+			RETURNING version`,
+			ord.Id, ord.UserId, ord.MarketId, int32(ord.Status), ord.Version, orderJSON,
+		).Scan(&ord.Version)
+
+		if err != nil {
+			return err
+		}
+
+		if ord.Revision < snapshotInterval {
+			_, err = tx.Exec(ctx, "DELETE FROM order_snapshots WHERE order_id = $1", id)
+			return err
+		}
+
+		return saveSnapshot(ctx, tx, ord, orderJSON, int(ord.Revision))
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ord, nil
+}
+
+// Pending - implement Outbox interface.
+// Entries which can't be decoded are moved to table 'order_outbox_dead'.
+func (p *Postgres) Pending(
+	ctx context.Context,
+	limit int,
+) (
+	[]OutboxEntry,
+	error,
+) {
+	rows, err := p.pool.Query(ctx, "SELECT id, data, created_at FROM order_outbox ORDER BY id LIMIT $1", limit)
+
+	if err != nil {
+		return nil, err
+	}
+
+	type row struct {
+		id        int64
+		eventJSON []byte
+		storedAt  time.Time
+	}
+
+	stored, err := pgx.CollectRows(rows, func(r pgx.CollectableRow) (row, error) {
+		var entry row
+		err := r.Scan(&entry.id, &entry.eventJSON, &entry.storedAt)
+		return entry, err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	var entries = make([]OutboxEntry, 0, len(stored))
+
+	for _, entry := range stored {
+		var e order.Event
+
+		if err := json.Unmarshal(entry.eventJSON, &e); err != nil {
+			if err := p.moveToDead(ctx, entry.id, err); err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		entries = append(entries, OutboxEntry{
+			Id:       strconv.FormatInt(entry.id, 10),
+			Event:    e,
+			StoredAt: entry.storedAt,
+		})
+	}
+
+	return entries, nil
+}
+
+// moveToDead - move outbox entry 'id' which can't be decoded with 'reason' to 'order_outbox_dead'.
+func (p *Postgres) moveToDead(
+	ctx context.Context,
+	id int64,
+	reason error,
+) error {
+	_, err := p.pool.Exec(
+		ctx,
+		`WITH dead AS (DELETE FROM order_outbox WHERE id = $1 RETURNING id, data, created_at)
+		INSERT INTO order_outbox_dead (id, data, created_at, error) SELECT id, data, created_at, $2 FROM dead`,
+		id, reason.Error(),
+	)
+
+	if err != nil {
+		return fmt.Errorf("move outbox entry %d to dead: %w", id, err)
+	}
+
+	logDeadEntry(ctx, "[OrderRepository/Postgres/Pending]", strconv.FormatInt(id, 10), reason)
+	return nil
+}
+
+// MarkSent - implement Outbox interface.
+func (p *Postgres) MarkSent(
+	ctx context.Context,
+	entries []OutboxEntry,
+) error {
+	var ids = make([]int64, len(entries))
+
+	for i, entry := range entries {
+		id, err := strconv.ParseInt(entry.Id, 10, 64)
+
+		if err != nil {
+			return fmt.Errorf("outbox entry id: %w", err)
+		}
+
+		ids[i] = id
+	}
+
+	_, err := p.pool.Exec(ctx, "DELETE FROM order_outbox WHERE id = ANY($1)", ids)
+	return err
+}
+
+// appendEvents - save 'events' in 'order_events' with next sequences of users and in 'order_outbox'.
+// Revision of event is unique in order, so events of concurrent change of order are not saved.
+func appendEvents(
+	ctx context.Context,
+	tx pgx.Tx,
+	events []order.Event,
+) error {
+	for _, e := range events {
+		// Row of user stays locked until commit, so events of one user get sequences in order.
+		err := tx.QueryRow(
+			ctx,
+			`INSERT INTO user_event_sequences (user_id, last) VALUES ($1, 1)
+			ON CONFLICT (user_id) DO UPDATE SET last = user_event_sequences.last + 1
+			RETURNING last`,
+			e.UserId,
+		).Scan(&e.Sequence)
+
+		if err != nil {
+			return err
+		}
+
+		eventJSON, err := json.Marshal(e)
+
+		if err != nil {
+			return fmt.Errorf("event marshal: %w", err)
+		}
+
+		_, err = tx.Exec(
+			ctx,
+			`INSERT INTO order_events (user_id, sequence, order_id, revision, type, data, at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+			e.UserId, e.Sequence, e.OrderId, e.Revision, int32(e.Type), eventJSON, e.At,
+		)
+
+		if postgres.IsUniqueViolation(err) {
+			return ErrVersionConflict
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if _, err = tx.Exec(ctx, "INSERT INTO order_outbox (data) VALUES ($1)", eventJSON); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// saveSnapshot - save 'ord' as 'orderJSON' in 'order_snapshots'
+// if last 'saved' events of order passed next snapshot interval.
+func saveSnapshot(
+	ctx context.Context,
+	tx pgx.Tx,
+	ord *order.Order,
+	orderJSON []byte,
+	saved int,
+) error {
+	previous := ord.Revision - min(ord.Revision, uint64(saved))
+
+	if ord.Revision/snapshotInterval == previous/snapshotInterval {
+		return nil
+	}
+
+	_, err := tx.Exec(
+		ctx,
+		`INSERT INTO order_snapshots (order_id, revision, data) VALUES ($1, $2, $3)
+		ON CONFLICT (order_id) DO UPDATE SET
+			revision = EXCLUDED.revision, data = EXCLUDED.data, created_at = now()`,
+		ord.Id, ord.Revision, orderJSON,
+	)
+	return err
+}
+
+// loadSnapshot - return latest snapshot of order 'orderId', nil if order has no snapshot.
+func loadSnapshot(
+	ctx context.Context,
+	tx pgx.Tx,
+	orderId string,
+) (
+	*order.Order,
+	error,
+) {
+	var orderJSON []byte
+	err := tx.QueryRow(ctx, "SELECT data FROM order_snapshots WHERE order_id = $1", orderId).Scan(&orderJSON)
+
+	if postgres.IsNotFound(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeOrder(string(orderJSON))
+}
+
+// queryer - source of rows, pool or transaction.
+type queryer interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// loadHistory - return events of order 'orderId' with revision after 'revision', oldest first.
+func loadHistory(
+	ctx context.Context,
+	db queryer,
+	orderId string,
+	revision uint64,
+) (
+	[]order.Event,
+	error,
+) {
+	rows, err := db.Query(
+		ctx,
+		"SELECT revision, data FROM order_events WHERE order_id = $1 AND revision > $2 ORDER BY revision",
+		orderId, revision,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (order.Event, error) {
+		var (
+			e         order.Event
+			revision  uint64
+			eventJSON []byte
+		)
+
+		if err := row.Scan(&revision, &eventJSON); err != nil {
+			return e, err
+		}
+
+		if err := json.Unmarshal(eventJSON, &e); err != nil {
+			return e, fmt.Errorf("event unmarshal: %w", err)
+		}
+
+		// Events saved before history have no revision in data.
+		e.Revision = revision
+		return e, nil
+	})
+}
+
+// PostgresEvents - EventRepository with events saved by Postgres, table 'order_events'.
+type PostgresEvents struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresEvents - create a new PostgresEvents with events stored in database of 'pool'.
+func NewPostgresEvents(pool *pgxpool.Pool) *PostgresEvents {
+	return &PostgresEvents{pool: pool}
+}
+
+// ListByUser - implement EventRepository interface.
+// Events which can't be decoded are logged and skipped.
+func (p *PostgresEvents) ListByUser(
+	ctx context.Context,
+	userId string,
+	sequence uint64,
+) (
+	[]order.Event,
+	error,
+) {
+	rows, err := p.pool.Query(
+		ctx,
+		"SELECT data FROM order_events WHERE user_id = $1 AND sequence > $2 ORDER BY sequence",
+		userId, sequence,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	eventsJSON, err := pgx.CollectRows(rows, pgx.RowTo[[]byte])
+
+	if err != nil {
+		return nil, err
+	}
+
+	var events = make([]order.Event, 0, len(eventsJSON))
+
+	for _, eventJSON := range eventsJSON {
+		var e order.Event
+
+		if err := json.Unmarshal(eventJSON, &e); err != nil {
+			logUndecodableEvent(ctx, "[OrderRepository/PostgresEvents/ListByUser]", userId, err)
+			continue
+		}
+
+		events = append(events, e)
+	}
+
+	return events, nil
+}
@@ -0,0 +1,581 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/KonnorFrik/BinaryTentacles/cmd/order_service/v1/usecase/order"
+	redCache "github.com/KonnorFrik/BinaryTentacles/pkg/cache/redis"
+)
+
+const (
+	// userOrdersKey - prefix of key with set of order ids of one user,
+	// full key is prefix + user id. Order is stored at key equal to it id.
+	userOrdersKey = "orders_of_user:"
+	// userEventsKey - prefix of key with list of order events of one user,
+	// full key is prefix + user id.
+	userEventsKey = "user_orders:"
+	// userSequenceKey - prefix of key with sequence of last order event of one user,
+	// full key is prefix + user id. Never expires, so sequences are not reused
+	// after list of events of user is expired.
+	userSequenceKey = "user_order_sequence:"
+	// orderHistoryKey - prefix of key with list of events of one order,
+	// full key is prefix + order id.
+	orderHistoryKey = "order_history:"
+	// restingOrdersKey - key of set with ids of orders which may wait in order book.
+	// Ids of orders not resting anymore are removed on read.
+	restingOrdersKey = "resting_orders"
+	// outboxKey - key of list with outbox entries as JSON, oldest first.
+	outboxKey = "order_outbox"
+	// deadOutboxKey - key of list with outbox entries which can't be decoded, moved from outbox.
+	deadOutboxKey = "order_outbox_dead"
+	// maxTxAttempts - how many times transaction is tried if sequences of users changed concurrently.
+	maxTxAttempts = 5
+)
+
+// Redis - OrderRepository, HistoryRepository and Outbox stored in redis as JSON.
+// Changes of order and it events are written in one transaction (MULTI).
+// Saved order is snapshot of it history after every change.
+// Transaction spans keys of order, users and outbox in different slots,
+// so Redis can't be used with redis cluster.
+type Redis struct {
+	cache  *redCache.Cache
+	orders *redCache.TypedStore[*order.Order]
+	// eventsTTL - how long events of user and history of order are kept after last event.
+	eventsTTL time.Duration
+}
+
+// NewRedis - create a new Redis with orders stored in 'cache'.
+// Events of user are kept for 'eventsTTL' after last event of user, read them with RedisEvents.
+// History of order is kept for 'eventsTTL' after last event of order.
+func NewRedis(
+	cache *redCache.Cache,
+	eventsTTL time.Duration,
+) *Redis {
+	return &Redis{
+		cache:     cache,
+		orders:    redCache.NewTypedStore(cache, redCache.JSONCodec[*order.Order]{}),
+		eventsTTL: eventsTTL,
+	}
+}
+
+// Create - implement OrderRepository interface.
+func (r *Redis) Create(
+	ctx context.Context,
+	ord *order.Order,
+	ttl time.Duration,
+	events ...order.Event,
+) error {
+	stored := ord.Clone()
+	stored.Version = 1
+	orderJSON, err := json.Marshal(stored)
+
+	if err != nil {
+		return fmt.Errorf("order marshal: %w", err)
+	}
+
+	err = r.transaction(ctx, ord.Id, events, func(tx *redCache.Tx) error {
+		_, err := tx.Get(ord.Id)
+
+		switch {
+		case err == nil:
+			return ErrExists
+		case !errors.Is(err, redCache.ErrNil):
+			return err
+		}
+
+		tx.Set(ord.Id, string(orderJSON), ttl)
+		tx.SetAdd(userOrdersKey+ord.UserId, ord.Id)
+
+		if stored.Resting() {
+			tx.SetAdd(restingOrdersKey, ord.Id)
+		}
+
+		return r.queueEvents(tx, events)
+	})
+
+	if err != nil {
+		return err
+	}
+
+	ord.Version = 1
+	return nil
+}
+
+// Get - implement OrderRepository interface.
+func (r *Redis) Get(
+	ctx context.Context,
+	id string,
+) (
+	*order.Order,
+	error,
+) {
+	ord, err := r.orders.Get(ctx, id)
+
+	if errors.Is(err, redCache.ErrNil) {
+		return nil, ErrNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ord, nil
+}
+
+// Update - implement OrderRepository interface.
+func (r *Redis) Update(
+	ctx context.Context,
+	ord *order.Order,
+	events ...order.Event,
+) error {
+	stored := ord.Clone()
+	stored.Version++
+	orderJSON, err := json.Marshal(stored)
+
+	if err != nil {
+		return fmt.Errorf("order marshal: %w", err)
+	}
+
+	err = r.transaction(ctx, ord.Id, events, func(tx *redCache.Tx) error {
+		current, err := tx.Get(ord.Id)
+
+		if errors.Is(err, redCache.ErrNil) {
+			return ErrNotFound
+		}
+
+		if err != nil {
+			return err
+		}
+
+		var version struct {
+			Version uint64 `json:"version"`
+		}
+
+		if err := json.Unmarshal([]byte(current), &version); err != nil {
+			return fmt.Errorf("order unmarshal: %w", err)
+		}
+
+		if version.Version != ord.Version {
+			return ErrVersionConflict
+		}
+
+		tx.Set(ord.Id, string(orderJSON), redCache.KeepTTL)
+		return r.queueEvents(tx, events)
+	})
+
+	if err != nil {
+		return err
+	}
+
+	ord.Version = stored.Version
+	return nil
+}
+
+// transaction - run 'fn' in transaction watching order 'orderId' and sequences of users of 'events'.
+// Repeated while sequences of users are changed by others, order changes are found by 'fn'.
+func (r *Redis) transaction(
+	ctx context.Context,
+	orderId string,
+	events []order.Event,
+	fn func(tx *redCache.Tx) error,
+) error {
+	var keys = []string{orderId}
+
+	for _, e := range events {
+		if key := userSequenceKey + e.UserId; !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := r.cache.Transaction(ctx, fn, keys...)
+
+		if !errors.Is(err, redCache.ErrChanged) {
+			return err
+		}
+
+		if attempt == maxTxAttempts {
+			return ErrVersionConflict
+		}
+	}
+}
+
+// queueEvents - queue write of 'events' in events of users with next sequences,
+// in history of orders and in outbox.
+func (r *Redis) queueEvents(
+	tx *redCache.Tx,
+	events []order.Event,
+) error {
+	var (
+		sequences = make(map[string]uint64)
+		now       = time.Now()
+	)
+
+	for _, e := range events {
+		key := userEventsKey + e.UserId
+		sequence, counted := sequences[e.UserId]
+
+		if !counted {
+			var err error
+
+			if sequence, err = lastSequence(tx, e.UserId); err != nil {
+				return err
+			}
+		}
+
+		sequence++
+		sequences[e.UserId] = sequence
+		e.Sequence = sequence
+		eventJSON, err := json.Marshal(e)
+
+		if err != nil {
+			return fmt.Errorf("event marshal: %w", err)
+		}
+
+		entryJSON, err := json.Marshal(OutboxEntry{Event: e, StoredAt: now})
+
+		if err != nil {
+			return fmt.Errorf("outbox entry marshal: %w", err)
+		}
+
+		tx.Push(key, r.eventsTTL, string(eventJSON))
+		tx.Push(orderHistoryKey+e.OrderId, r.eventsTTL, string(eventJSON))
+		tx.Push(outboxKey, 0, string(entryJSON))
+	}
+
+	for userId, sequence := range sequences {
+		tx.Set(userSequenceKey+userId, strconv.FormatUint(sequence, 10), 0)
+	}
+
+	return nil
+}
+
+// lastSequence - return sequence of last event of user 'userId'.
+// Users with events saved before sequence counter continue from length of their events.
+func lastSequence(
+	tx *redCache.Tx,
+	userId string,
+) (
+	uint64,
+	error,
+) {
+	value, err := tx.Get(userSequenceKey + userId)
+
+	if errors.Is(err, redCache.ErrNil) {
+		length, err := tx.Len(userEventsKey + userId)
+		return uint64(length), err
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	sequence, err := strconv.ParseUint(value, 10, 64)
+
+	if err != nil {
+		return 0, fmt.Errorf("sequence of user %s: %w", userId, err)
+	}
+
+	return sequence, nil
+}
+
+// History - implement HistoryRepository interface.
+func (r *Redis) History(
+	ctx context.Context,
+	orderId string,
+) (
+	[]order.Event,
+	error,
+) {
+	eventsJSON, err := r.cache.Range(ctx, orderHistoryKey+orderId, 0, -1)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(eventsJSON) == 0 {
+		return nil, ErrNotFound
+	}
+
+	var events = make([]order.Event, len(eventsJSON))
+
+	for i, eventJSON := range eventsJSON {
+		if err := json.Unmarshal([]byte(eventJSON), &events[i]); err != nil {
+			return nil, fmt.Errorf("event unmarshal: %w", err)
+		}
+	}
+
+	return events, nil
+}
+
+// Pending - implement Outbox interface.
+// Entry id is it JSON, unique as it has user and sequence of event.
+// Entries which can't be decoded are moved to dead outbox.
+func (r *Redis) Pending(
+	ctx context.Context,
+	limit int,
+) (
+	[]OutboxEntry,
+	error,
+) {
+	if limit <= 0 {
+		return nil, nil
+	}
+
+	entriesJSON, err := r.cache.Range(ctx, outboxKey, 0, int64(limit)-1)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var entries = make([]OutboxEntry, 0, len(entriesJSON))
+
+	for _, entryJSON := range entriesJSON {
+		var entry OutboxEntry
+
+		if err := json.Unmarshal([]byte(entryJSON), &entry); err != nil {
+			if err := r.moveToDead(ctx, entryJSON, err); err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		entry.Id = entryJSON
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// moveToDead - move outbox entry 'entryJSON' which can't be decoded with 'reason' to dead outbox.
+// Entry is pushed first, so it is never lost, but may be in dead outbox twice.
+func (r *Redis) moveToDead(
+	ctx context.Context,
+	entryJSON string,
+	reason error,
+) error {
+	if err := r.cache.Push(ctx, deadOutboxKey, entryJSON); err != nil {
+		return fmt.Errorf("move outbox entry to dead: %w", err)
+	}
+
+	if _, err := r.cache.ListRemove(ctx, outboxKey, entryJSON); err != nil {
+		return fmt.Errorf("move outbox entry to dead: %w", err)
+	}
+
+	logDeadEntry(ctx, "[OrderRepository/Redis/Pending]", entryJSON, reason)
+	return nil
+}
+
+// MarkSent - implement Outbox interface.
+func (r *Redis) MarkSent(
+	ctx context.Context,
+	entries []OutboxEntry,
+) error {
+	for _, entry := range entries {
+		if _, err := r.cache.ListRemove(ctx, outboxKey, entry.Id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ListByUser - implement OrderRepository interface.
+// Ids of expired orders are removed from set of user.
+func (r *Redis) ListByUser(
+	ctx context.Context,
+	userId string,
+) (
+	[]*order.Order,
+	error,
+) {
+	ids, err := r.cache.SetMembers(ctx, userOrdersKey+userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := r.orders.MGet(ctx, ids...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		result  []*order.Order
+		expired []string
+	)
+
+	for _, entry := range entries {
+		switch {
+		case errors.Is(entry.Err, redCache.ErrNil):
+			expired = append(expired, entry.Key)
+		case entry.Err != nil:
+			return nil, entry.Err
+		default:
+			result = append(result, entry.Value)
+		}
+	}
+
+	if len(expired) > 0 {
+		if err := r.cache.SetRemove(ctx, userOrdersKey+userId, expired...); err != nil {
+			return nil, err
+		}
+	}
+
+	// Order ids are UUIDv7, so sorted by time of create.
+	slices.SortFunc(result, func(a, b *order.Order) int {
+		return strings.Compare(a.Id, b.Id)
+	})
+	return result, nil
+}
+
+// ListResting - implement OrderRepository interface.
+// Ids of expired, deleted and not resting orders are removed from set.
+func (r *Redis) ListResting(ctx context.Context) ([]*order.Order, error) {
+	ids, err := r.cache.SetMembers(ctx, restingOrdersKey)
+
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := r.orders.MGet(ctx, ids...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		result []*order.Order
+		done   []string
+	)
+
+	for _, entry := range entries {
+		switch {
+		case errors.Is(entry.Err, redCache.ErrNil):
+			done = append(done, entry.Key)
+		case entry.Err != nil:
+			return nil, entry.Err
+		case !entry.Value.Resting():
+			done = append(done, entry.Key)
+		default:
+			result = append(result, entry.Value)
+		}
+	}
+
+	if len(done) > 0 {
+		if err := r.cache.SetRemove(ctx, restingOrdersKey, done...); err != nil {
+			return nil, err
+		}
+	}
+
+	slices.SortFunc(result, func(a, b *order.Order) int {
+		return strings.Compare(a.Id, b.Id)
+	})
+	return result, nil
+}
+
+// Delete - implement OrderRepository interface.
+func (r *Redis) Delete(
+	ctx context.Context,
+	id string,
+) error {
+	ord, err := r.Get(ctx, id)
+
+	if err != nil {
+		return err
+	}
+
+	removed, err := r.cache.Delete(ctx, id)
+
+	if err != nil {
+		return err
+	}
+
+	if removed == 0 {
+		return ErrNotFound
+	}
+
+	return r.cache.SetRemove(ctx, userOrdersKey+ord.UserId, id)
+}
+
+// decodeOrder - return order stored as 'orderJSON'.
+func decodeOrder(orderJSON string) (*order.Order, error) {
+	var ord order.Order
+
+	if err := json.Unmarshal([]byte(orderJSON), &ord); err != nil {
+		return nil, fmt.Errorf("order unmarshal: %w", err)
+	}
+
+	return &ord, nil
+}
+
+// RedisEvents - EventRepository with events saved by Redis in lists as JSON.
+// Sequences of events in list of user are consecutive, oldest first.
+type RedisEvents struct {
+	cache *redCache.Cache
+}
+
+// NewRedisEvents - create a new RedisEvents with events stored in 'cache'.
+func NewRedisEvents(cache *redCache.Cache) *RedisEvents {
+	return &RedisEvents{cache: cache}
+}
+
+// ListByUser - implement EventRepository interface.
+// Events which can't be decoded are logged and skipped.
+func (r *RedisEvents) ListByUser(
+	ctx context.Context,
+	userId string,
+	sequence uint64,
+) (
+	[]order.Event,
+	error,
+) {
+	var key = userEventsKey + userId
+	eventsJSON, err := r.cache.Range(ctx, key, 0, 0)
+
+	if err != nil || len(eventsJSON) == 0 {
+		return nil, err
+	}
+
+	var (
+		first order.Event
+		start int64
+	)
+
+	// Skip events up to 'sequence' by offset from first event, if it can be decoded.
+	if err := json.Unmarshal([]byte(eventsJSON[0]), &first); err == nil && sequence >= first.Sequence {
+		start = int64(sequence-first.Sequence) + 1
+	}
+
+	eventsJSON, err = r.cache.Range(ctx, key, start, -1)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var events = make([]order.Event, 0, len(eventsJSON))
+
+	for _, eventJSON := range eventsJSON {
+		var e order.Event
+
+		if err := json.Unmarshal([]byte(eventJSON), &e); err != nil {
+			logUndecodableEvent(ctx, "[OrderRepository/RedisEvents/ListByUser]", userId, err)
+			continue
+		}
+
+		if e.Sequence > sequence {
+			events = append(events, e)
+		}
+	}
+
+	return events, nil
+}
@@ -0,0 +1,236 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/KonnorFrik/BinaryTentacles/cmd/order_service/v1/usecase/order"
+	pb "github.com/KonnorFrik/BinaryTentacles/internal/generated/order_service/v1"
+	redCache "github.com/KonnorFrik/BinaryTentacles/pkg/cache/redis"
+	"github.com/alicebob/miniredis/v2"
+)
+
+// newTestRedis - create Redis with events kept for 'eventsTTL' in miniredis.
+func newTestRedis(t *testing.T, eventsTTL time.Duration) (*Redis, *miniredis.Miniredis) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	cache, err := redCache.New(context.Background(), redCache.Config{Addr: server.Addr()})
+
+	if err != nil {
+		t.Fatalf("Got = %v, Want = %v\n", err, nil)
+	}
+
+	t.Cleanup(func() { cache.Close(context.Background()) })
+	return NewRedis(cache, eventsTTL), server
+}
+
+// sequences - return sequences of 'events'.
+func sequences(events []order.Event) []uint64 {
+	var result = make([]uint64, len(events))
+
+	for i, e := range events {
+		result[i] = e.Sequence
+	}
+
+	return result
+}
+
+func TestRedisEventSequence(t *testing.T) {
+	var (
+		ctx          = context.Background()
+		repo, server = newTestRedis(t, time.Minute)
+		events       = NewRedisEvents(repo.cache)
+		ord          = &order.Order{Id: "0197a1c2-0000-7000-8000-000000000001", UserId: "user"}
+	)
+
+	if err := repo.Create(ctx, ord, 0, ord.Event(pb.OrderEventType_ORDER_EVENT_TYPE_CREATED, time.Now())); err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	ord.Transition(pb.OrderStatus_ORDER_STATUS_CREATED, time.Now())
+
+	if err := repo.Update(ctx, ord, ord.Event(pb.OrderEventType_ORDER_EVENT_TYPE_STATUS_CHANGED, time.Now())); err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	// Events of user expire, sequence goes on.
+	server.FastForward(2 * time.Minute)
+	ord.Cancel(time.Now())
+
+	if err := repo.Update(ctx, ord, ord.Event(pb.OrderEventType_ORDER_EVENT_TYPE_CANCELLED, time.Now())); err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	got, err := events.ListByUser(ctx, "user", 0)
+
+	if err != nil || len(got) != 1 || got[0].Sequence != 3 {
+		t.Fatalf("Got = %v, %v, Want = [3]\n", sequences(got), err)
+	}
+
+	got, err = events.ListByUser(ctx, "user", 3)
+
+	if err != nil || len(got) != 0 {
+		t.Fatalf("Got = %v, %v, Want = []\n", sequences(got), err)
+	}
+}
+
+func TestRedisEventsUndecodable(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		repo, _ = newTestRedis(t, time.Hour)
+		events  = NewRedisEvents(repo.cache)
+		ord     = &order.Order{Id: "0197a1c2-0000-7000-8000-000000000001", UserId: "user"}
+	)
+
+	if err := repo.Create(ctx, ord, 0, ord.Event(pb.OrderEventType_ORDER_EVENT_TYPE_CREATED, time.Now())); err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	if err := repo.cache.Push(ctx, userEventsKey+"user", "{broken"); err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	ord.Transition(pb.OrderStatus_ORDER_STATUS_CREATED, time.Now())
+
+	if err := repo.Update(ctx, ord, ord.Event(pb.OrderEventType_ORDER_EVENT_TYPE_STATUS_CHANGED, time.Now())); err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	got, err := events.ListByUser(ctx, "user", 0)
+
+	if err != nil || len(got) != 2 || got[0].Sequence != 1 || got[1].Sequence != 2 {
+		t.Fatalf("Got = %v, %v, Want = [1 2]\n", sequences(got), err)
+	}
+
+	got, err = events.ListByUser(ctx, "user", 1)
+
+	if err != nil || len(got) != 1 || got[0].Sequence != 2 {
+		t.Fatalf("Got = %v, %v, Want = [2]\n", sequences(got), err)
+	}
+}
+
+func TestRedisUpdateConflict(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		repo, _ = newTestRedis(t, time.Hour)
+		ord     = &order.Order{Id: "0197a1c2-0000-7000-8000-000000000001", UserId: "user"}
+	)
+
+	if err := repo.Create(ctx, ord, time.Hour); err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	if err := repo.Create(ctx, ord, time.Hour); !errors.Is(err, ErrExists) {
+		t.Fatalf("Got = %v, Want = %v\n", err, ErrExists)
+	}
+
+	first, _ := repo.Get(ctx, ord.Id)
+	second, _ := repo.Get(ctx, ord.Id)
+	first.Transition(pb.OrderStatus_ORDER_STATUS_CREATED, time.Now())
+
+	if err := repo.Update(ctx, first, first.Event(pb.OrderEventType_ORDER_EVENT_TYPE_STATUS_CHANGED, time.Now())); err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	// 'second' is read before update of 'first', nothing of it is written.
+	second.Cancel(time.Now())
+
+	if err := repo.Update(ctx, second, second.Event(pb.OrderEventType_ORDER_EVENT_TYPE_CANCELLED, time.Now())); !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("Got = %v, Want = %v\n", err, ErrVersionConflict)
+	}
+
+	stored, err := repo.Get(ctx, ord.Id)
+
+	if err != nil || stored.Version != 2 || stored.Status != pb.OrderStatus_ORDER_STATUS_CREATED {
+		t.Fatalf("Got = %+v, %v, Want version 2 in CREATED\n", stored, err)
+	}
+
+	history, err := repo.History(ctx, ord.Id)
+
+	if err != nil || len(history) != 1 {
+		t.Fatalf("Got = %d events, %v, Want = 1\n", len(history), err)
+	}
+
+	if err := repo.Update(ctx, &order.Order{Id: "0197a1c2-0000-7000-8000-000000000002"}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Got = %v, Want = %v\n", err, ErrNotFound)
+	}
+}
+
+func TestRedisConcurrentEvents(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		repo, _ = newTestRedis(t, time.Hour)
+		events  = NewRedisEvents(repo.cache)
+		wg      sync.WaitGroup
+		errs    = make(chan error, 4)
+	)
+
+	// Orders of one user change sequence of same user, transaction is repeated on change.
+	for i := range 4 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			ord := &order.Order{Id: fmt.Sprintf("0197a1c2-0000-7000-8000-00000000000%d", i), UserId: "user"}
+			errs <- repo.Create(ctx, ord, time.Hour, ord.Event(pb.OrderEventType_ORDER_EVENT_TYPE_CREATED, time.Now()))
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Got = %q\n", err)
+		}
+	}
+
+	got, err := events.ListByUser(ctx, "user", 0)
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	if got := sequences(got); !slices.Equal(got, []uint64{1, 2, 3, 4}) {
+		t.Fatalf("Got = %v, Want = [1 2 3 4]\n", got)
+	}
+}
+
+func TestRedisOutboxDead(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		repo, _ = newTestRedis(t, time.Hour)
+		ord     = &order.Order{Id: "0197a1c2-0000-7000-8000-000000000001", UserId: "user"}
+	)
+
+	if err := repo.cache.Push(ctx, outboxKey, "{broken"); err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	if err := repo.Create(ctx, ord, 0, ord.Event(pb.OrderEventType_ORDER_EVENT_TYPE_CREATED, time.Now())); err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	pending, err := repo.Pending(ctx, 10)
+
+	if err != nil || len(pending) != 1 || pending[0].Event.OrderId != ord.Id {
+		t.Fatalf("Got = %+v, %v, Want one entry of %s\n", pending, err, ord.Id)
+	}
+
+	if dead, _ := repo.cache.Range(ctx, deadOutboxKey, 0, -1); len(dead) != 1 || dead[0] != "{broken" {
+		t.Fatalf("Got = %v, Want = [{broken]\n", dead)
+	}
+
+	if err := repo.MarkSent(ctx, pending); err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	if pending, err := repo.Pending(ctx, 10); err != nil || len(pending) != 0 {
+		t.Fatalf("Got = %+v, %v, Want empty outbox\n", pending, err)
+	}
+}
@@ -0,0 +1,120 @@
+/*
+Storage of orders and order events of users.
+Repository save copies of orders, changes of returned order are not saved until Update.
+Order events are saved with changes of orders in one transaction,
+in events of user, in history of order and in outbox, from which they are published.
+History of order is append-only, state of order can be rebuilt from it with order.Fold.
+*/
+package repository
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/KonnorFrik/BinaryTentacles/cmd/order_service/v1/usecase/order"
+	"github.com/KonnorFrik/BinaryTentacles/pkg/logging"
+)
+
+var (
+	// ErrNotFound - order is not exist or expired.
+	ErrNotFound = errors.New("order not found")
+	// ErrExists - order with same id already exist.
+	ErrExists = errors.New("order already exist")
+	// ErrVersionConflict - order was changed after it was read.
+	// Caller must read order again and repeat change.
+	ErrVersionConflict = errors.New("order version conflict")
+)
+
+var (
+	logger = logging.Default()
+)
+
+// OrderRepository - storage of orders.
+type OrderRepository interface {
+	// Create - save new order 'ord' for 'ttl' and set it version to 1.
+	// 'events' of order are saved in same transaction, see OrderRepository.Update.
+	Create(ctx context.Context, ord *order.Order, ttl time.Duration, events ...order.Event) error
+	// Get - return order with 'id'.
+	Get(ctx context.Context, id string) (*order.Order, error)
+	// Update - save changed status, fills and other state of order 'ord'
+	// if stored order has same version, then increment version of 'ord'.
+	// TTL of order is kept.
+	// 'events' of order are saved in same transaction in events of user
+	// with next sequences and in outbox.
+	Update(ctx context.Context, ord *order.Order, events ...order.Event) error
+	// ListByUser - return all orders of user 'userId', oldest first.
+	ListByUser(ctx context.Context, userId string) ([]*order.Order, error)
+	// ListResting - return all orders waiting for match in order book, see order.Order.Resting.
+	// Oldest first, so book is rebuilt with same time priority.
+	ListResting(ctx context.Context) ([]*order.Order, error)
+	// Delete - remove order with 'id'.
+	Delete(ctx context.Context, id string) error
+}
+
+// EventRepository - order events of users, saved by OrderRepository.
+type EventRepository interface {
+	// ListByUser - return events of user 'userId' with sequence after 'sequence', oldest first.
+	// Events which can't be decoded are skipped, so one broken event does not block the rest.
+	ListByUser(ctx context.Context, userId string, sequence uint64) ([]order.Event, error)
+}
+
+// HistoryRepository - events of orders saved by OrderRepository, by order.
+type HistoryRepository interface {
+	// History - return all events of order 'orderId', oldest first.
+	// Returns ErrNotFound if order has no events.
+	History(ctx context.Context, orderId string) ([]order.Event, error)
+}
+
+// OutboxEntry - saved order event waiting for publish.
+type OutboxEntry struct {
+	// Id - key of entry in outbox.
+	Id    string      `json:"-"`
+	Event order.Event `json:"event"`
+	// StoredAt - time of save of event.
+	StoredAt time.Time `json:"stored_at"`
+}
+
+// Outbox - order events saved by OrderRepository and not yet published.
+// Entry stays in outbox until marked as sent, so publish is repeated after failure
+// and event may be published more than once.
+// Entry which can't be decoded is moved out of outbox and logged, so it never blocks others.
+type Outbox interface {
+	// Pending - return up to 'limit' not sent entries, oldest first.
+	Pending(ctx context.Context, limit int) ([]OutboxEntry, error)
+	// MarkSent - remove published 'entries' from outbox.
+	MarkSent(ctx context.Context, entries []OutboxEntry) error
+}
+
+// logUndecodableEvent - log event of user 'userId' skipped as it can't be decoded.
+func logUndecodableEvent(
+	ctx context.Context,
+	msg string,
+	userId string,
+	err error,
+) {
+	logger.LogAttrs(
+		ctx,
+		slog.LevelError,
+		msg,
+		slog.String("user", userId),
+		slog.String("error", err.Error()),
+	)
+}
+
+// logDeadEntry - log outbox entry 'id' moved out of outbox as it can't be decoded.
+func logDeadEntry(
+	ctx context.Context,
+	msg string,
+	id string,
+	err error,
+) {
+	logger.LogAttrs(
+		ctx,
+		slog.LevelError,
+		msg,
+		slog.String("entry", id),
+		slog.String("error", err.Error()),
+	)
+}
@@ -2,29 +2,29 @@ package usecase
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
+	"sync/atomic"
 	"time"
 
+	"github.com/KonnorFrik/BinaryTentacles/cmd/order_service/v1/usecase/availability"
+	"github.com/KonnorFrik/BinaryTentacles/cmd/order_service/v1/usecase/book"
+	"github.com/KonnorFrik/BinaryTentacles/cmd/order_service/v1/usecase/feed"
 	"github.com/KonnorFrik/BinaryTentacles/cmd/order_service/v1/usecase/order"
+	"github.com/KonnorFrik/BinaryTentacles/cmd/order_service/v1/usecase/repository"
 	pb "github.com/KonnorFrik/BinaryTentacles/internal/generated/order_service/v1"
 	client "github.com/KonnorFrik/BinaryTentacles/internal/generated/spot_instrument/v1"
 	redCache "github.com/KonnorFrik/BinaryTentacles/pkg/cache/redis"
+	"github.com/KonnorFrik/BinaryTentacles/pkg/eventbus"
+	"github.com/KonnorFrik/BinaryTentacles/pkg/keylock"
 	"github.com/KonnorFrik/BinaryTentacles/pkg/logging"
 	"github.com/google/uuid"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
-)
-
-const (
-	spotInstrumentAddr = "spot_instrument:9999"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 var (
-	spotInstrument client.SpotInstrumentServiceClient
-	logger         = logging.Default()
+	logger = logging.Default()
 )
 
 var (
@@ -36,132 +36,453 @@ var (
 	ErrUnknown = errors.New("unknown")
 	// ErrInternal - indicate errors for any reason in OrderSevice/usecase logic
 	ErrInternal = errors.New("internal")
+	// ErrMarketNotTrading - market state does not allow requested action
+	ErrMarketNotTrading = errors.New("market does not accept orders")
+	// ErrOrderFinalized - order already in final status
+	ErrOrderFinalized = errors.New("order already finalized")
+	// ErrInvalidOrder - order parameters can't be placed in book
+	ErrInvalidOrder = errors.New("invalid order")
+	// ErrInvalidRequest - request parameters are invalid
+	ErrInvalidRequest = errors.New("invalid request")
+	// ErrConcurrentChange - order is changed by others too often, change can't be saved
+	ErrConcurrentChange = errors.New("order is changed concurrently")
+	// ErrInvalidOption - invalid option of Usecase for any reason
+	ErrInvalidOption = errors.New("invalid option")
+	// errMatched - resting order is not in book, it fully matched and it fill is not saved yet
+	errMatched = errors.New("order is matched")
 )
 
-// init a grpc connection with spot instrument service
-func init() {
-	opts := []grpc.DialOption{
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
+// Usecase - logic of OrderService with it dependencies.
+type Usecase struct {
+	spotInstrument client.SpotInstrumentServiceClient
+	// orderCache - redis for events of users and for updates of orders between instances.
+	// Nil if not given, then updates are delivered only in this instance
+	// and events of users are unavailable.
+	orderCache *redCache.Cache
+	// orders - storage of orders.
+	orders repository.OrderRepository
+	// events - order events of users saved by orders.
+	events repository.EventRepository
+	// history - events of orders saved by orders.
+	history repository.HistoryRepository
+	// outbox - order events saved by orders and waiting for publish.
+	outbox        repository.Outbox
+	outboxWake    chan struct{}
+	outboxDone    chan struct{}
+	outboxMetrics outboxMetrics
+	// registerer - registry of metrics of Usecase.
+	registerer prometheus.Registerer
+	// marketAvailability - local cache of market availability, nil if disabled.
+	marketAvailability *availability.Cache
+	stopMarketWatch    context.CancelFunc
+	// orderBooks - matching state of all markets.
+	// Rebuilt from saved resting orders on start, owned by one instance, see claimOrderBooks.
+	orderBooks *book.Books
+	// instanceId - id of this instance in ownership of order books.
+	instanceId string
+	// bookOwnedUntil - unix nanoseconds of end of ownership of order books, 0 if not owned.
+	bookOwnedUntil atomic.Int64
+	// orderUpdates - watchers of orders in this instance.
+	orderUpdates *feed.Hub[order.Update]
+	// orderBroker - updates of orders from all instances.
+	orderBroker *redCache.Subscription
+	// bus - events of orders and trades for other services and instances.
+	bus eventbus.Bus
+	// busEvents - order events from all instances for streams of users.
+	busEvents eventbus.Subscription
+	// userEvents - streams of users in this instance.
+	userEvents *feed.Hub[order.Event]
+	// orderLocks - serialize read-modify-write of one saved order.
+	orderLocks         keylock.Locks
+	lifecycleCtx       context.Context
+	stopOrderLifecycle context.CancelFunc
+}
+
+// New - create a new Usecase with client of SpotInstrumentService 'spotInstrument'.
+// Orders and events of users are stored in redis from WithOrderCache if no storage given,
+// without redis they are stored in memory.
+// Outbox and history are order repository if it implements Outbox and HistoryRepository
+// and WithOutbox or WithHistoryRepository is not given.
+// With redis only one instance owns order books, New fails if other instance owns them.
+// Starts background work, stop it with ShutdownOrderUpdates and StopMarketWatch.
+func New(
+	ctx context.Context,
+	spotInstrument client.SpotInstrumentServiceClient,
+	opts ...Option,
+) (
+	*Usecase,
+	error,
+) {
+	if spotInstrument == nil {
+		return nil, fmt.Errorf("%w: client of SpotInstrumentService is required", ErrInvalidOption)
+	}
+
+	u := &Usecase{
+		spotInstrument:  spotInstrument,
+		stopMarketWatch: func() {},
+		orderBooks:      book.NewBooks(),
+		orderUpdates:    feed.NewHub[order.Update](),
+		userEvents:      feed.NewHub[order.Event](),
+		outboxWake:      make(chan struct{}, 1),
+		outboxDone:      make(chan struct{}),
+		registerer:      prometheus.DefaultRegisterer,
 	}
 
-	conn, err := grpc.NewClient(spotInstrumentAddr, opts...)
+	for _, opt := range opts {
+		if err := opt(u); err != nil {
+			return nil, err
+		}
+	}
+
+	switch {
+	case u.orders != nil:
+	case u.orderCache != nil:
+		u.orders = repository.NewRedis(u.orderCache, userEventsTTL)
+
+		if u.events == nil {
+			u.events = repository.NewRedisEvents(u.orderCache)
+		}
+
+	default:
+		memory := repository.NewMemory()
+		u.orders = memory
+
+		if u.events == nil {
+			u.events = memory.Events()
+		}
+	}
+
+	if u.bus == nil {
+		if u.orderCache != nil {
+			bus, err := eventbus.NewRedis(
+				u.orderCache.Client(),
+				eventbus.WithSlog(logger.Logger),
+				eventbus.WithTenant(u.orderCache.Tenant()),
+			)
+
+			if err != nil {
+				return nil, fmt.Errorf("%w: %w", ErrInvalidOption, err)
+			}
+
+			u.bus = bus
+
+		} else {
+			u.bus = eventbus.NewMemory()
+		}
+	}
+
+	metrics, err := newOutboxMetrics(u.registerer)
 
 	if err != nil {
-		logger.LogAttrs(
-			nil,
-			slog.LevelError,
-			"[OrderService/init]",
-			slog.String("Connection to", "SpotInstrumentService"),
-			slog.String("Error", err.Error()),
-		)
-		return
+		return nil, err
 	}
 
-	spotInstrument = client.NewSpotInstrumentServiceClient(conn)
-	logger.LogAttrs(
-		nil,
-		slog.LevelInfo,
-		"[OrderService/init]",
-		slog.String("Connection to", "SpotInstrumentService"),
-		slog.String("Status", "Successfull"),
-	)
+	u.outboxMetrics = metrics
+
+	if u.outbox == nil {
+		outbox, ok := u.orders.(repository.Outbox)
+
+		if !ok {
+			return nil, fmt.Errorf("%w: outbox is required for order repository", ErrInvalidOption)
+		}
+
+		u.outbox = outbox
+	}
+
+	if u.history == nil {
+		history, ok := u.orders.(repository.HistoryRepository)
+
+		if !ok {
+			return nil, fmt.Errorf("%w: history repository is required for order repository", ErrInvalidOption)
+		}
+
+		u.history = history
+	}
+
+	u.lifecycleCtx, u.stopOrderLifecycle = context.WithCancel(context.Background())
+
+	if err := u.claimOrderBooks(ctx); err != nil {
+		u.stopOrderLifecycle()
+		return nil, err
+	}
+
+	if err := u.restoreOrderBooks(ctx); err != nil {
+		u.stopOrderLifecycle()
+		return nil, err
+	}
+
+	if err := u.startOrderBroker(ctx); err != nil {
+		u.stopOrderLifecycle()
+		return nil, err
+	}
+
+	if err := u.startEventRelay(ctx); err != nil {
+		u.stopOrderLifecycle()
+
+		if u.orderBroker != nil {
+			u.orderBroker.Close()
+		}
+
+		return nil, err
+	}
+
+	go u.relayOutbox(u.lifecycleCtx)
+	u.startMarketWatch()
+	return u, nil
 }
 
 // Create - create a order logic.
-func Create(
+func (u *Usecase) Create(
 	ctx context.Context,
 	req *pb.CreateRequest,
 ) (
 	*order.Order,
 	error,
 ) {
-	if e := uuid.Validate(req.MarketId); e != nil {
-		return nil, fmt.Errorf("%w: requested market id is invalid", ErrMarketUnavailable)
+	if err := validateOrder(req); err != nil {
+		return nil, err
 	}
 
-	clientReq := client.IsAvailableRequest{
-		UserRole: client.UserRole_USER_ROLE_CUSTOMER,
-		MarketId: req.GetMarketId(),
+	if !u.ownsOrderBooks() {
+		return nil, fmt.Errorf("%w: orders are not placed", ErrBooksOwned)
 	}
-	// TODO: cache this
-	available, err := spotInstrument.IsAvailable(ctx, &clientReq)
+
+	marketId, err := u.resolveMarketId(ctx, req)
 
 	if err != nil {
-		logger.LogAttrs(
-			nil,
-			slog.LevelError,
-			"[OrderService/Create]",
-			slog.String("Call", "SpotInstrumentService.ViewMarkets"),
-			slog.String("Error", err.Error()),
-		)
-		return nil, fmt.Errorf("%w: SpotInstrumentService reason: %w", ErrMarketUnavailable, err)
+		return nil, err
 	}
 
-	if !available.GetIsAvailable() {
-		return nil, ErrMarketUnavailable
+	availability, err := u.marketAvailable(ctx, marketId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !acceptsNewOrders(availability.State) {
+		return nil, fmt.Errorf(
+			"%w: market is %s: %s",
+			ErrMarketNotTrading,
+			marketStateName(availability.State),
+			availability.Reason,
+		)
 	}
 
 	var order = new(order.Order)
 	order.FromGrpcCreateRequest(req)
-	order.Status = pb.OrderStatus_ORDER_STATUS_CREATED
+	order.MarketId = marketId
+	created := order.Transition(pb.OrderStatus_ORDER_STATUS_CREATED, time.Now())
 	orderId, err := uuid.NewV7()
 
 	if err != nil {
 		return nil, fmt.Errorf("%w: UUID create: %w", ErrInternal, err)
 	}
 
-	orderJsonBytes, err := json.Marshal(order)
+	order.Id = orderId.String()
+
+	// Locked before order is saved, so it can't be changed until placed in book.
+	unlock := u.orderLocks.Lock(order.Id)
+	err = u.orders.Create(ctx, order, time.Hour, order.Event(pb.OrderEventType_ORDER_EVENT_TYPE_CREATED, created.At))
+
+	if err != nil {
+		unlock()
+		return nil, fmt.Errorf("%w: Order save: %w", ErrInternal, err)
+	}
+
+	u.wakeOutboxRelay()
+	result, err := u.matchOrder(ctx, order)
+	unlock()
+	u.reportMatch(ctx, result)
 
 	if err != nil {
-		return nil, fmt.Errorf("%w: Order marshal: %w", ErrInternal, err)
+		return nil, err
 	}
 
-	order.Id = orderId.String()
-	err = orderCache.Set(ctx, order.Id, string(orderJsonBytes), time.Hour)
+	// Matched order skips processing.
+	if result.order.InProcessing() {
+		go u.runOrderLifecycle(result.order.Id)
+	}
+
+	return result.order, nil
+}
+
+// validateOrder - check is order from request 'req' can be placed in book.
+func validateOrder(req *pb.CreateRequest) error {
+	switch req.GetSide() {
+	case pb.OrderSide_ORDER_SIDE_BUY, pb.OrderSide_ORDER_SIDE_SELL:
+	default:
+		return fmt.Errorf("%w: order side must be BUY or SELL", ErrInvalidOrder)
+	}
+
+	if req.GetPrice() <= 0 {
+		return fmt.Errorf("%w: price must be positive", ErrInvalidOrder)
+	}
+
+	if req.GetQuantity() == 0 {
+		return fmt.Errorf("%w: quantity must be positive", ErrInvalidOrder)
+	}
+
+	return nil
+}
+
+// resolveMarketId - return market id from request 'req'.
+// Market symbol resolved with SpotInstrumentService if id is not given.
+func (u *Usecase) resolveMarketId(
+	ctx context.Context,
+	req *pb.CreateRequest,
+) (
+	string,
+	error,
+) {
+	if req.GetMarketId() == "" && req.GetMarketSymbol() != "" {
+		clientReq := client.GetMarketBySymbolRequest{
+			UserRole: client.UserRole_USER_ROLE_CUSTOMER,
+			Symbol:   req.GetMarketSymbol(),
+		}
+		resp, err := u.spotInstrument.GetMarketBySymbol(ctx, &clientReq)
+
+		if err != nil {
+			logger.LogAttrs(
+				nil,
+				slog.LevelError,
+				"[OrderService/Create]",
+				slog.String("Call", "SpotInstrumentService.GetMarketBySymbol"),
+				slog.String("Error", err.Error()),
+			)
+			return "", fmt.Errorf("%w: SpotInstrumentService reason: %w", ErrMarketUnavailable, err)
+		}
+
+		return resp.GetMarket().GetId(), nil
+	}
+
+	if e := uuid.Validate(req.GetMarketId()); e != nil {
+		return "", fmt.Errorf("%w: requested market id is invalid", ErrMarketUnavailable)
+	}
+
+	return req.GetMarketId(), nil
+}
+
+// Cancel - cancel a order logic.
+// Cancels allowed in any market state for orders in processing and waiting in book,
+// filled order is final and can't be cancelled.
+// Order of other user is reported as not existing.
+func (u *Usecase) Cancel(
+	ctx context.Context,
+	req *pb.CancelRequest,
+) (
+	*order.Order,
+	error,
+) {
+	var ord *order.Order
+	update, err := u.changeOrderOutOfBook(
+		ctx,
+		req.GetOrderId(),
+		func(o *order.Order) bool {
+			return o.UserId == req.GetUserId()
+		},
+		func(o *order.Order) (order.Update, bool) {
+			ord = o
+
+			if o.UserId != req.GetUserId() {
+				return order.Update{}, false
+			}
+
+			return o.Cancel(time.Now())
+		},
+	)
+
+	// Matched order is not in book, it fill is being saved by taker.
+	if errors.Is(err, errMatched) {
+		return nil, fmt.Errorf("%w: order is filled", ErrOrderFinalized)
+	}
 
 	if err != nil {
-		return nil, fmt.Errorf("%w: Order save: %w", ErrInternal, err)
+		return nil, err
+	}
+
+	if ord.UserId != req.GetUserId() {
+		return nil, ErrDoesNotExist
+	}
+
+	if update == nil {
+		return nil, fmt.Errorf("%w: status %s", ErrOrderFinalized, ord.GetStatus())
+	}
+
+	return ord, nil
+}
+
+// removeFromBook - remove order 'ord' from book of its market.
+// Returns remaining quantity in book and false if order is not in book,
+// then it fully matched and it fill is saved after lock of order is released.
+// Must be called with locked order.
+func (u *Usecase) removeFromBook(ord *order.Order) (uint64, bool) {
+	ordBook, exist := u.orderBooks.Lookup(ord.MarketId)
+
+	if !exist {
+		return 0, false
 	}
 
-	return order, nil
+	return ordBook.Cancel(ord.Id)
+}
+
+// reportTrade - feed 'fill' as trade in SpotInstrumentService ticker and circuit breaker.
+// Errors only logged, order already saved.
+func (u *Usecase) reportTrade(
+	ctx context.Context,
+	marketId string,
+	fill book.Fill,
+	bestBid int64,
+	bestAsk int64,
+) {
+	clientReq := client.ReportTradeRequest{
+		MarketId: marketId,
+		Price:    fill.Price,
+		Quantity: fill.Quantity,
+		BestBid:  bestBid,
+		BestAsk:  bestAsk,
+	}
+	_, err := u.spotInstrument.ReportTrade(ctx, &clientReq)
+
+	if err != nil {
+		logger.LogAttrs(
+			ctx,
+			slog.LevelError,
+			"[OrderService/reportTrade]",
+			slog.String("Call", "SpotInstrumentService.ReportTrade"),
+			slog.String("Error", err.Error()),
+		)
+	}
 }
 
 // OrderStatus - return a order status logic.
-func OrderStatus(
+func (u *Usecase) OrderStatus(
 	ctx context.Context,
 	req *pb.OrderStatusRequest,
 ) (
 	*order.Order,
 	error,
 ) {
-	order, err := OrderById(ctx, req.GetOrderId())
+	order, err := u.OrderById(ctx, req.GetOrderId())
 	return order, err
 }
 
 // OrderById - get order from db by it id.
-func OrderById(
+func (u *Usecase) OrderById(
 	ctx context.Context,
 	id string,
 ) (
 	*order.Order,
 	error,
 ) {
-	orderJson, err := orderCache.Get(ctx, id)
+	ord, err := u.orders.Get(ctx, id)
 
 	if err != nil {
-		if err == redCache.ErrNil {
+		if errors.Is(err, repository.ErrNotFound) {
 			return nil, ErrDoesNotExist
 		}
 
 		return nil, fmt.Errorf("%w: %w", ErrInternal, err)
 	}
 
-	var ord order.Order
-	err = json.Unmarshal([]byte(orderJson), &ord)
-
-	if err != nil {
-		return nil, fmt.Errorf("%w: %w", ErrInternal, err)
-	}
-
-	return &ord, nil
+	return ord, nil
 }
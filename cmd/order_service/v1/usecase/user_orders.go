@@ -0,0 +1,136 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/KonnorFrik/BinaryTentacles/cmd/order_service/v1/usecase/feed"
+	"github.com/KonnorFrik/BinaryTentacles/cmd/order_service/v1/usecase/order"
+	pb "github.com/KonnorFrik/BinaryTentacles/internal/generated/order_service/v1"
+	"github.com/KonnorFrik/BinaryTentacles/pkg/eventbus"
+	"github.com/google/uuid"
+)
+
+const (
+	// orderEventsTopic - topic of event bus with order events of all users.
+	orderEventsTopic = "order_events"
+	// userEventsTTL - how long events of user are stored in redis after last event.
+	userEventsTTL = 24 * time.Hour
+)
+
+// StreamUserOrders - start stream of all order events of user logic.
+// Events after 'from_sequence' are sent first, then live events.
+func (u *Usecase) StreamUserOrders(
+	ctx context.Context,
+	req *pb.StreamUserOrdersRequest,
+) (
+	*feed.EventStream,
+	error,
+) {
+	userId := req.GetUserId()
+
+	if userId == "" {
+		return nil, fmt.Errorf("%w: user id is required", ErrInvalidRequest)
+	}
+
+	var match func(*order.Event) bool
+
+	if marketId := req.GetMarketId(); marketId != "" {
+		if e := uuid.Validate(marketId); e != nil {
+			return nil, fmt.Errorf("%w: requested market id is invalid", ErrInvalidRequest)
+		}
+
+		match = func(e *order.Event) bool {
+			return e.MarketId == marketId
+		}
+	}
+
+	load := func(ctx context.Context, sequence uint64) ([]order.Event, error) {
+		return u.storedUserEvents(ctx, userId, sequence)
+	}
+
+	return feed.StartEvents(ctx, u.userEvents, userId, req.GetFromSequence(), match, load)
+}
+
+// publishUserEvent - send stored event 'e' to event bus,
+// from it event goes to streams of user in all instances.
+func (u *Usecase) publishUserEvent(
+	ctx context.Context,
+	e order.Event,
+) error {
+	eventBytes, err := json.Marshal(e)
+
+	if err != nil {
+		return fmt.Errorf("%w: Event marshal: %w", ErrInternal, err)
+	}
+
+	if _, err = u.bus.Publish(ctx, orderEventsTopic, eventBytes); err != nil {
+		return fmt.Errorf("%w: Event publish: %w", ErrInternal, err)
+	}
+
+	return nil
+}
+
+// startEventRelay - subscribe to order events of event bus in own group of this instance,
+// so every instance gets all events.
+func (u *Usecase) startEventRelay(ctx context.Context) error {
+	sub, err := u.bus.Subscribe(ctx, orderEventsTopic, "order_service:"+uuid.NewString(), eventbus.WithEphemeral())
+
+	if err != nil {
+		return fmt.Errorf("subscribe to order events: %w", err)
+	}
+
+	u.busEvents = sub
+	go u.relayUserEvents(sub)
+	return nil
+}
+
+// relayUserEvents - send events received by 'sub' to streams of users
+// in this instance until subscription closed.
+// Events not sent to streams are read from store by streams on next event of user.
+func (u *Usecase) relayUserEvents(sub eventbus.Subscription) {
+	for msg := range sub.Messages() {
+		var e order.Event
+
+		if err := json.Unmarshal(msg.Payload, &e); err != nil {
+			logger.LogAttrs(
+				u.lifecycleCtx,
+				slog.LevelError,
+				"[OrderService/relayUserEvents]",
+				slog.String("message", msg.Id),
+				slog.String("error", err.Error()),
+			)
+
+		} else {
+			u.userEvents.Publish(e.UserId, e)
+		}
+
+		sub.Ack(u.lifecycleCtx, msg)
+	}
+}
+
+// storedUserEvents - return stored events of user 'userId' with sequence after 'sequence'.
+// Events which can't be decoded are logged and skipped by repository, so stream goes on after them.
+func (u *Usecase) storedUserEvents(
+	ctx context.Context,
+	userId string,
+	sequence uint64,
+) (
+	[]order.Event,
+	error,
+) {
+	if u.events == nil {
+		return nil, fmt.Errorf("%w: no storage of events of users", ErrInternal)
+	}
+
+	events, err := u.events.ListByUser(ctx, userId, sequence)
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInternal, err)
+	}
+
+	return events, nil
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+
+	"github.com/KonnorFrik/BinaryTentacles/cmd/spot_instrument/v1/usecase/market"
+	pb "github.com/KonnorFrik/BinaryTentacles/internal/generated/spot_instrument/v1"
+)
+
+// CreateMarket - create a new market.
+func (s *server) CreateMarket(
+	ctx context.Context,
+	req *pb.CreateMarketRequest,
+) (
+	*pb.MarketResponse,
+	error,
+) {
+	const method = "CreateMarket"
+	mark, err := s.usecase.CreateMarket(ctx, req)
+	return s.marketResponse(mark, err, method)
+}
+
+// UpdateMarket - change given fields of market.
+func (s *server) UpdateMarket(
+	ctx context.Context,
+	req *pb.UpdateMarketRequest,
+) (
+	*pb.MarketResponse,
+	error,
+) {
+	const method = "UpdateMarket"
+	mark, err := s.usecase.UpdateMarket(ctx, req)
+	return s.marketResponse(mark, err, method)
+}
+
+// EnableMarket - enable market.
+func (s *server) EnableMarket(
+	ctx context.Context,
+	req *pb.MarketActionRequest,
+) (
+	*pb.MarketResponse,
+	error,
+) {
+	const method = "EnableMarket"
+	mark, err := s.usecase.EnableMarket(ctx, req)
+	return s.marketResponse(mark, err, method)
+}
+
+// DisableMarket - disable market.
+func (s *server) DisableMarket(
+	ctx context.Context,
+	req *pb.MarketActionRequest,
+) (
+	*pb.MarketResponse,
+	error,
+) {
+	const method = "DisableMarket"
+	mark, err := s.usecase.DisableMarket(ctx, req)
+	return s.marketResponse(mark, err, method)
+}
+
+// SoftDeleteMarket - mark market as deleted.
+func (s *server) SoftDeleteMarket(
+	ctx context.Context,
+	req *pb.MarketActionRequest,
+) (
+	*pb.MarketResponse,
+	error,
+) {
+	const method = "SoftDeleteMarket"
+	mark, err := s.usecase.SoftDeleteMarket(ctx, req)
+	return s.marketResponse(mark, err, method)
+}
+
+// RestoreMarket - restore soft deleted market.
+func (s *server) RestoreMarket(
+	ctx context.Context,
+	req *pb.MarketActionRequest,
+) (
+	*pb.MarketResponse,
+	error,
+) {
+	const method = "RestoreMarket"
+	mark, err := s.usecase.RestoreMarket(ctx, req)
+	return s.marketResponse(mark, err, method)
+}
+
+// GetMarketAudit - return audit trail of market.
+func (s *server) GetMarketAudit(
+	ctx context.Context,
+	req *pb.GetMarketAuditRequest,
+) (
+	*pb.GetMarketAuditResponse,
+	error,
+) {
+	const method = "GetMarketAudit"
+	records, err := s.usecase.GetMarketAudit(ctx, req)
+
+	if err != nil {
+		return nil, s.wrapError(err, method)
+	}
+
+	var resp pb.GetMarketAuditResponse
+	resp.Records = make([]*pb.MarketAuditRecord, len(records))
+
+	for i, record := range records {
+		resp.Records[i] = new(pb.MarketAuditRecord)
+		market.AuditToProtobuf(record, resp.Records[i])
+	}
+
+	return &resp, nil
+}
+
+// BackfillCandles - rebuild candles of market from stored trades.
+func (s *server) BackfillCandles(
+	ctx context.Context,
+	req *pb.BackfillCandlesRequest,
+) (
+	*pb.BackfillCandlesResponse,
+	error,
+) {
+	const method = "BackfillCandles"
+	trades, candles, err := s.usecase.BackfillCandles(ctx, req)
+
+	if err != nil {
+		return nil, s.wrapError(err, method)
+	}
+
+	var resp pb.BackfillCandlesResponse
+	resp.Trades = trades
+	resp.Candles = candles
+	return &resp, nil
+}
+
+// marketResponse - build response with changed market or wrap error.
+func (s *server) marketResponse(
+	mark *market.Market,
+	err error,
+	method string,
+) (
+	*pb.MarketResponse,
+	error,
+) {
+	if err != nil {
+		return nil, s.wrapError(err, method)
+	}
+
+	var resp pb.MarketResponse
+	resp.Market = new(pb.Market)
+	market.ToProtobuf(mark, resp.Market, s.usecase.Now())
+	return &resp, nil
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"errors"
+	"time"
+
+	"github.com/KonnorFrik/BinaryTentacles/cmd/spot_instrument/v1/usecase/breaker"
+	"github.com/KonnorFrik/BinaryTentacles/pkg/auth"
+	redCache "github.com/KonnorFrik/BinaryTentacles/pkg/cache/redis"
+	"github.com/KonnorFrik/BinaryTentacles/pkg/postgres"
+)
+
+// Config - settings of SpotInstrumentService.
+// Loaded by config.Load, see it for sources of settings.
+type Config struct {
+	// ListenAddr - address of gRPC server.
+	ListenAddr string `yaml:"listen_addr" env:"LISTEN_ADDR" env-default:":9999" env-description:"Address of gRPC server."`
+	// MarketSeedFile - YAML or JSON file with markets for upsert at startup, empty for skip.
+	MarketSeedFile string `yaml:"market_seed_file" env:"MARKET_SEED_FILE" env-description:"YAML or JSON file with markets for upsert at startup."`
+	// StartupTimeout - time limit of connect to dependencies at start.
+	StartupTimeout time.Duration `yaml:"startup_timeout" env:"STARTUP_TIMEOUT" env-default:"2m" env-description:"Time limit of connect to dependencies at start."`
+	// ShutdownTimeout - time limit of graceful shutdown.
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT" env-default:"30s" env-description:"Time limit of graceful shutdown."`
+	// Redis - connection to redis, db of it is not used, see MarketDB, AuditDB and MarketDataDB.
+	// In cluster mode and sentinel reading from nearest or random all stores are in db 0
+	// and must have different key prefixes.
+	Redis redCache.Config `yaml:"redis"`
+	// MarketDB - redis db with markets.
+	MarketDB int `yaml:"market_db" env:"MARKET_REDIS_DB" env-default:"1" env-description:"Redis db with markets."`
+	// AuditDB - redis db with audit trail of markets.
+	AuditDB int `yaml:"audit_db" env:"AUDIT_REDIS_DB" env-default:"2" env-description:"Redis db with audit trail of markets."`
+	// MarketDataDB - redis db with trades and closed candles.
+	MarketDataDB int `yaml:"market_data_db" env:"MARKET_DATA_REDIS_DB" env-default:"3" env-description:"Redis db with trades and closed candles."`
+	// MarketKeyPrefix, AuditKeyPrefix, MarketDataKeyPrefix - prefixes of keys of stores,
+	// stores with different prefixes may share one redis db.
+	MarketKeyPrefix     string `yaml:"market_key_prefix" env:"MARKET_REDIS_KEY_PREFIX" env-description:"Prefix of keys with markets."`
+	AuditKeyPrefix      string `yaml:"audit_key_prefix" env:"AUDIT_REDIS_KEY_PREFIX" env-description:"Prefix of keys with audit trail of markets."`
+	MarketDataKeyPrefix string `yaml:"market_data_key_prefix" env:"MARKET_DATA_REDIS_KEY_PREFIX" env-description:"Prefix of keys with trades and closed candles."`
+	// Postgres - durable storage of markets, then MarketDB is cache of it.
+	// Without it markets are stored only in redis.
+	Postgres postgres.Config `yaml:"postgres"`
+	// MarketCacheTTL - how long market read from postgres is kept in redis.
+	MarketCacheTTL time.Duration  `yaml:"market_cache_ttl" env:"MARKET_CACHE_TTL" env-default:"1m" env-description:"How long market read from postgres is kept in redis."`
+	Breaker        breaker.Config `yaml:"circuit_breaker"`
+	// Auth - tokens of callers, calls without token are anonymous customers.
+	Auth auth.Config `yaml:"auth"`
+}
+
+// Validate - implement config.Validator interface.
+func (c *Config) Validate() error {
+	var errs []error
+
+	if c.ListenAddr == "" {
+		errs = append(errs, errors.New("listen address can't be empty"))
+	}
+
+	if c.StartupTimeout <= 0 || c.ShutdownTimeout <= 0 {
+		errs = append(errs, errors.New("startup and shutdown timeouts must be positive"))
+	}
+
+	if c.MarketDB < 0 || c.AuditDB < 0 || c.MarketDataDB < 0 {
+		errs = append(errs, errors.New("redis db can't be negative"))
+	}
+
+	if err := c.Redis.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if c.Redis.Clustered() && (c.MarketDB != 0 || c.AuditDB != 0 || c.MarketDataDB != 0) {
+		errs = append(errs, errors.New("redis cluster client has only db 0, separate stores by key prefixes"))
+	}
+
+	type store struct {
+		db     int
+		prefix string
+	}
+
+	var (
+		markets    = store{c.MarketDB, c.MarketKeyPrefix}
+		audit      = store{c.AuditDB, c.AuditKeyPrefix}
+		marketData = store{c.MarketDataDB, c.MarketDataKeyPrefix}
+	)
+
+	if markets == audit || markets == marketData || audit == marketData {
+		errs = append(errs, errors.New("markets, audit and market data must be in different redis dbs or have different key prefixes"))
+	}
+
+	if c.MarketCacheTTL <= 0 {
+		errs = append(errs, errors.New("market cache ttl must be positive"))
+	}
+
+	if c.Breaker.Threshold <= 0 || c.Breaker.Window <= 0 || c.Breaker.HaltFor <= 0 {
+		errs = append(errs, errors.New("circuit breaker threshold, window and halt must be positive"))
+	}
+
+	if err := c.Auth.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	redCache "github.com/KonnorFrik/BinaryTentacles/pkg/cache/redis"
+	"github.com/KonnorFrik/BinaryTentacles/pkg/postgres"
+	"github.com/KonnorFrik/BinaryTentacles/pkg/retry"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// dialPolicy - repeat of connection to dependency at start.
+var dialPolicy = retry.Policy{
+	Attempts: 5,
+	Delay:    time.Second,
+	MaxDelay: 10 * time.Second,
+	Timeout:  5 * time.Second,
+}
+
+// dependencies - connections used by usecase logic.
+type dependencies struct {
+	marketCache     *redCache.Cache
+	auditCache      *redCache.Cache
+	marketDataCache *redCache.Cache
+	// marketStore - postgres with markets, nil if not configured.
+	marketStore *pgxpool.Pool
+}
+
+// connectDependencies - connect to all dependencies from 'cfg'.
+// Fails if any dependency is unreachable after dialPolicy.
+func connectDependencies(
+	ctx context.Context,
+	cfg *Config,
+	logger *slog.Logger,
+) (
+	*dependencies,
+	error,
+) {
+	var (
+		deps   dependencies
+		caches = []struct {
+			db     int
+			prefix string
+			cache  **redCache.Cache
+		}{
+			{cfg.MarketDB, cfg.MarketKeyPrefix, &deps.marketCache},
+			{cfg.AuditDB, cfg.AuditKeyPrefix, &deps.auditCache},
+			{cfg.MarketDataDB, cfg.MarketDataKeyPrefix, &deps.marketDataCache},
+		}
+	)
+
+	for _, c := range caches {
+		cache, err := connectCache(ctx, cfg.Redis, c.db, c.prefix, logger)
+
+		if err != nil {
+			deps.Close(ctx)
+			return nil, err
+		}
+
+		*c.cache = cache
+	}
+
+	if cfg.Postgres.Enabled() {
+		var err error
+		deps.marketStore, err = postgres.Connect(ctx, cfg.Postgres, dialPolicy)
+
+		if err != nil {
+			deps.Close(ctx)
+			return nil, fmt.Errorf("connect to postgres: %w", err)
+		}
+	}
+
+	return &deps, nil
+}
+
+// Close - close all connections of 'd'.
+func (d *dependencies) Close(ctx context.Context) error {
+	var errs []error
+
+	if d.marketStore != nil {
+		d.marketStore.Close()
+	}
+
+	for _, cache := range []*redCache.Cache{d.marketCache, d.auditCache, d.marketDataCache} {
+		if cache != nil {
+			errs = append(errs, cache.Close(ctx))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// connectCache - connect to redis db 'db' with settings 'config', keys of cache are under 'prefix'.
+func connectCache(
+	ctx context.Context,
+	config redCache.Config,
+	db int,
+	prefix string,
+	logger *slog.Logger,
+) (
+	*redCache.Cache,
+	error,
+) {
+	config.DB = db
+	cache, err := redCache.Connect(ctx, config, dialPolicy, redCache.WithSlog(logger), redCache.WithKeyPrefix(prefix))
+
+	if err != nil {
+		return nil, fmt.Errorf("connect to redis %s db %d: %w", config.Addr, db, err)
+	}
+
+	return cache, nil
+}
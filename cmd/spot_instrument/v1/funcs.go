@@ -6,6 +6,9 @@ import (
 	"log/slog"
 
 	"github.com/KonnorFrik/BinaryTentacles/cmd/spot_instrument/v1/usecase"
+	"github.com/KonnorFrik/BinaryTentacles/cmd/spot_instrument/v1/usecase/candle"
+	"github.com/KonnorFrik/BinaryTentacles/cmd/spot_instrument/v1/usecase/ticker"
+	"github.com/KonnorFrik/BinaryTentacles/cmd/spot_instrument/v1/usecase/watch"
 	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/logging"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -59,6 +62,15 @@ func wrapError(err error) error {
 	case errors.Is(err, usecase.ErrInvalidInput):
 		code = codes.InvalidArgument
 		msg = err.Error()
+	case errors.Is(err, usecase.ErrAlreadyExists):
+		code = codes.AlreadyExists
+		msg = err.Error()
+	case errors.Is(err, watch.ErrLagged):
+		code = codes.ResourceExhausted
+		msg = err.Error() + ", resume from last received revision"
+	case errors.Is(err, watch.ErrClosed), errors.Is(err, ticker.ErrClosed), errors.Is(err, candle.ErrClosed):
+		code = codes.Unavailable
+		msg = err.Error()
 	case errors.Is(err, usecase.ErrForbidden):
 		code = codes.PermissionDenied
 		msg = err.Error()
@@ -5,16 +5,24 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net"
 	"os"
 	"os/signal"
 	"sync"
 	"syscall"
-	"time"
+	// Market schedules use IANA time zones, don't depend on system tzdata.
+	_ "time/tzdata"
 
 	"github.com/KonnorFrik/BinaryTentacles/cmd/spot_instrument/v1/usecase"
+	"github.com/KonnorFrik/BinaryTentacles/cmd/spot_instrument/v1/usecase/policy"
+	"github.com/KonnorFrik/BinaryTentacles/cmd/spot_instrument/v1/usecase/repository"
+	"github.com/KonnorFrik/BinaryTentacles/pkg/auth"
 	"github.com/KonnorFrik/BinaryTentacles/pkg/call_chain"
+	"github.com/KonnorFrik/BinaryTentacles/pkg/config"
+	"github.com/KonnorFrik/BinaryTentacles/pkg/eventbus"
 	loggingWrap "github.com/KonnorFrik/BinaryTentacles/pkg/logging"
 
 	pb "github.com/KonnorFrik/BinaryTentacles/internal/generated/spot_instrument/v1"
@@ -26,16 +34,33 @@ import (
 	"google.golang.org/grpc"
 )
 
-const (
-	laddr = ":9999"
-)
-
 func main() {
+	var cfg Config
+	loaded, err := config.Load(os.Args[0], os.Args[1:], &cfg)
+
+	if errors.Is(err, config.ErrHelp) {
+		return
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	if loaded.PrintConfig {
+		if err = config.Print(os.Stdout, &cfg); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		return
+	}
+
 	logger := loggingWrap.Default()
 	osSignalChan := make(chan os.Signal, 6)
 	signal.Notify(osSignalChan, os.Interrupt, syscall.SIGKILL, syscall.SIGTERM)
 
-	listener, err := net.Listen("tcp", laddr)
+	listener, err := net.Listen("tcp", cfg.ListenAddr)
 
 	if err != nil {
 		logger.LogAttrs(
@@ -47,7 +72,77 @@ func main() {
 		os.Exit(1)
 	}
 
-	userServer, err := New(WithSlog(logger.Logger))
+	startCtx, cancelStart := context.WithTimeout(context.Background(), cfg.StartupTimeout)
+	deps, err := connectDependencies(startCtx, &cfg, logger.Logger)
+
+	if err != nil {
+		cancelStart()
+		logger.LogAttrs(
+			nil,
+			slog.LevelError,
+			"[Server/connectDependencies]",
+			slog.String("error", err.Error()),
+		)
+		os.Exit(1)
+	}
+
+	marketUsecase, err := newUsecase(startCtx, &cfg, deps)
+	cancelStart()
+
+	if err != nil {
+		logger.LogAttrs(
+			nil,
+			slog.LevelError,
+			"[Server/newUsecase]",
+			slog.String("error", err.Error()),
+		)
+		os.Exit(1)
+	}
+
+	if cfg.MarketSeedFile != "" {
+		_, err = marketUsecase.SeedMarkets(context.Background(), cfg.MarketSeedFile)
+
+		if err != nil {
+			logger.LogAttrs(
+				nil,
+				slog.LevelError,
+				"[Server/SeedMarkets]",
+				slog.String("error", err.Error()),
+			)
+			os.Exit(1)
+		}
+
+	} else {
+		logger.LogAttrs(
+			nil,
+			slog.LevelWarn,
+			"[Server/SeedMarkets]",
+			slog.String("status", "skipped, no seed file"),
+		)
+	}
+
+	if err = marketUsecase.RestoreCandles(context.Background()); err != nil {
+		logger.LogAttrs(
+			nil,
+			slog.LevelError,
+			"[Server/RestoreCandles]",
+			slog.String("error", err.Error()),
+		)
+	}
+
+	authenticator, err := newAuthenticator(&cfg)
+
+	if err != nil {
+		logger.LogAttrs(
+			nil,
+			slog.LevelError,
+			"[Server/newAuthenticator]",
+			slog.String("error", err.Error()),
+		)
+		os.Exit(1)
+	}
+
+	userServer, err := New(marketUsecase, WithSlog(logger.Logger))
 
 	if err != nil {
 		logger.LogAttrs(
@@ -68,6 +163,7 @@ func main() {
 				logging.WithCodes(ErrorToCode),
 			),
 			interceptor.UnaryServerXRequestId,
+			authenticator.UnaryServerInterceptor,
 			// From doc - "use those as "last" interceptor, so panic does not skip other interceptors"
 			recovery.UnaryServerInterceptor(recovery.WithRecoveryHandler(RecoveryHandler)),
 		),
@@ -79,18 +175,23 @@ func main() {
 				logging.WithCodes(ErrorToCode),
 			),
 			interceptor.StreamServerXRequestId,
+			authenticator.StreamServerInterceptor,
 			recovery.StreamServerInterceptor(recovery.WithRecoveryHandler(RecoveryHandler)),
 		),
 	)
 	pb.RegisterSpotInstrumentServiceServer(grpcServer, userServer)
+	pb.RegisterSpotInstrumentAdminServiceServer(grpcServer, userServer)
 	logger.LogAttrs(
 		nil,
 		slog.LevelInfo,
 		"[Server/Listen at]",
-		slog.String("local address", laddr),
+		slog.String("local address", cfg.ListenAddr),
 	)
 
 	gracefullShutdownChain := callChain.New(
+		marketUsecase.ShutdownMarketWatch,
+		marketUsecase.ShutdownTickerWatch,
+		marketUsecase.ShutdownCandleWatch,
 		func(ctx context.Context) error {
 			select {
 			case <-ctx.Done():
@@ -101,7 +202,7 @@ func main() {
 			grpcServer.GracefulStop()
 			return nil
 		},
-		usecase.ShutdownMarketCache,
+		deps.Close,
 	)
 
 	var chainGroup sync.WaitGroup
@@ -116,7 +217,7 @@ func main() {
 			"[GracefullShutdownChain]",
 			slog.String("status", "start"),
 		)
-		ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
 		defer cancel()
 		ind, err := gracefullShutdownChain.Call(ctx)
 
@@ -153,3 +254,67 @@ func main() {
 
 	chainGroup.Wait()
 }
+
+// Full names of services for rules of authentication.
+const (
+	spotService  = "/order_service.SpotInstrumentService/"
+	adminService = "/order_service.SpotInstrumentAdminService/"
+)
+
+// newAuthenticator - create authentication of callers with tokens from 'cfg'
+// and roles required by methods.
+func newAuthenticator(cfg *Config) (*auth.Authenticator, error) {
+	internalService := policy.RoleName(pb.UserRole_USER_ROLE_INTERNAL_SERVICE)
+	return auth.New(
+		cfg.Auth,
+		// Admin changes are audited with name of caller.
+		auth.Require(adminService, policy.RoleName(pb.UserRole_USER_ROLE_ADMIN)),
+		// Price and trade feeds halt markets by circuit breaker and build tickers and candles,
+		// only trusted services may report.
+		auth.Require(spotService+"ReportPrice", internalService),
+		auth.Require(spotService+"ReportTrade", internalService),
+	)
+}
+
+// newUsecase - create a logic of service with settings 'cfg' and dependencies 'deps'.
+func newUsecase(
+	ctx context.Context,
+	cfg *Config,
+	deps *dependencies,
+) (
+	*usecase.Usecase,
+	error,
+) {
+	var markets repository.MarketRepository
+
+	if deps.marketStore != nil {
+		markets = repository.NewCached(repository.NewPostgres(deps.marketStore), deps.marketCache, cfg.MarketCacheTTL)
+
+	} else {
+		var err error
+		markets, err = repository.NewRedis(ctx, deps.marketCache)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	bus, err := eventbus.NewRedis(
+		deps.auditCache.Client(),
+		eventbus.WithSlog(loggingWrap.Default().Logger),
+		eventbus.WithTenant(deps.auditCache.Tenant()),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return usecase.New(
+		ctx,
+		markets,
+		deps.auditCache,
+		usecase.WithEventBus(bus),
+		usecase.WithMarketDataCache(deps.marketDataCache),
+		usecase.WithPriceBreaker(cfg.Breaker),
+	)
+}
@@ -6,19 +6,29 @@ import (
 	"log/slog"
 
 	"github.com/KonnorFrik/BinaryTentacles/cmd/spot_instrument/v1/usecase"
+	"github.com/KonnorFrik/BinaryTentacles/cmd/spot_instrument/v1/usecase/candle"
 	"github.com/KonnorFrik/BinaryTentacles/cmd/spot_instrument/v1/usecase/market"
+	"github.com/KonnorFrik/BinaryTentacles/cmd/spot_instrument/v1/usecase/ticker"
+	"github.com/KonnorFrik/BinaryTentacles/cmd/spot_instrument/v1/usecase/watch"
 	pb "github.com/KonnorFrik/BinaryTentacles/internal/generated/spot_instrument/v1"
+	"google.golang.org/grpc"
 )
 
 type Option func(*server) error
 
 type server struct {
 	pb.UnimplementedSpotInstrumentServiceServer
-	logger *slog.Logger
+	pb.UnimplementedSpotInstrumentAdminServiceServer
+	logger  *slog.Logger
+	usecase *usecase.Usecase
 }
 
-func New(opts ...Option) (*server, error) {
-	var srv server
+func New(uc *usecase.Usecase, opts ...Option) (*server, error) {
+	if uc == nil {
+		return nil, errors.New("'New: usecase can't be nil")
+	}
+
+	var srv = server{usecase: uc}
 
 	for _, opt := range opts {
 		if e := opt(&srv); e != nil {
@@ -29,7 +39,7 @@ func New(opts ...Option) (*server, error) {
 	return &srv, nil
 }
 
-// ViewMarkets - return all available markets.
+// ViewMarkets - return page of markets selected by filters.
 func (s *server) ViewMarkets(
 	ctx context.Context,
 	req *pb.ViewMarketsRequest,
@@ -38,7 +48,7 @@ func (s *server) ViewMarkets(
 	error,
 ) {
 	const method = "ViewMarkets"
-	markets, err := usecase.ViewMarkets(ctx, req)
+	markets, nextPageToken, err := s.usecase.ViewMarkets(ctx, req)
 
 	if err != nil {
 		return nil, s.wrapError(err, method)
@@ -46,11 +56,33 @@ func (s *server) ViewMarkets(
 
 	var resp pb.ViewMarketsResponse
 	resp.Market = make([]*pb.Market, len(markets))
-	market.ToProtobufMany(markets, resp.Market)
+	resp.NextPageToken = nextPageToken
+	market.ToProtobufMany(markets, resp.Market, s.usecase.Now())
 	return &resp, nil
 }
 
-// IsAvailable - check is one market available.
+// GetMarketBySymbol - return one market by it symbol.
+func (s *server) GetMarketBySymbol(
+	ctx context.Context,
+	req *pb.GetMarketBySymbolRequest,
+) (
+	*pb.GetMarketBySymbolResponse,
+	error,
+) {
+	const method = "GetMarketBySymbol"
+	mark, err := s.usecase.GetMarketBySymbol(ctx, req)
+
+	if err != nil {
+		return nil, s.wrapError(err, method)
+	}
+
+	var resp pb.GetMarketBySymbolResponse
+	resp.Market = new(pb.Market)
+	market.ToProtobuf(mark, resp.Market, s.usecase.Now())
+	return &resp, nil
+}
+
+// IsAvailable - return trading state of one market.
 func (s *server) IsAvailable(
 	ctx context.Context,
 	req *pb.IsAvailableRequest,
@@ -59,17 +91,304 @@ func (s *server) IsAvailable(
 	error,
 ) {
 	const method = "IsAvailable"
-	available, err := usecase.IsAvailable(ctx, req)
+	state, reason, err := s.usecase.IsAvailable(ctx, req)
 
 	if err != nil {
 		return nil, s.wrapError(err, method)
 	}
 
 	var resp pb.IsAvailableResponse
-	resp.IsAvailable = available
+	resp.IsAvailable = state == market.StateActive
+	resp.State = market.StateToProtobuf(state)
+	resp.Reason = reason
+	return &resp, nil
+}
+
+// ReportPrice - feed a market price in circuit breaker.
+func (s *server) ReportPrice(
+	ctx context.Context,
+	req *pb.ReportPriceRequest,
+) (
+	*pb.ReportPriceResponse,
+	error,
+) {
+	const method = "ReportPrice"
+	state, reason, err := s.usecase.ReportPrice(ctx, req)
+
+	if err != nil {
+		return nil, s.wrapError(err, method)
+	}
+
+	var resp pb.ReportPriceResponse
+	resp.State = market.StateToProtobuf(state)
+	resp.Reason = reason
+	return &resp, nil
+}
+
+// GetTradingSchedule - return trading calendar of one market.
+func (s *server) GetTradingSchedule(
+	ctx context.Context,
+	req *pb.GetTradingScheduleRequest,
+) (
+	*pb.GetTradingScheduleResponse,
+	error,
+) {
+	const method = "GetTradingSchedule"
+	view, err := s.usecase.GetTradingSchedule(ctx, req)
+
+	if err != nil {
+		return nil, s.wrapError(err, method)
+	}
+
+	var resp pb.GetTradingScheduleResponse
+	market.ScheduleViewToProtobuf(&view, &resp)
+	return &resp, nil
+}
+
+// WatchMarkets - stream market changes.
+// Send snapshot of all markets first, if can't resume from requested revision.
+func (s *server) WatchMarkets(
+	req *pb.WatchMarketsRequest,
+	stream grpc.ServerStreamingServer[pb.WatchMarketsResponse],
+) error {
+	const method = "WatchMarkets"
+	watcher, err := s.usecase.WatchMarkets(stream.Context(), req)
+
+	if err != nil {
+		return s.wrapError(err, method)
+	}
+
+	defer watcher.Close()
+
+	if watcher.Snapshot != nil {
+		var resp pb.WatchMarketsResponse
+		watch.SnapshotToProtobuf(watcher.Snapshot, watcher.Revision, &resp, s.usecase.Now())
+
+		if e := stream.Send(&resp); e != nil {
+			return e
+		}
+	}
+
+	for i := range watcher.Missed {
+		var resp pb.WatchMarketsResponse
+		watch.ToProtobuf(&watcher.Missed[i], &resp, s.usecase.Now())
+
+		if e := stream.Send(&resp); e != nil {
+			return e
+		}
+	}
+
+	events := watcher.Events()
+
+	for {
+		var (
+			event  watch.Event
+			isOpen bool
+		)
+
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case event, isOpen = <-events:
+		}
+
+		if !isOpen {
+			return s.wrapError(watcher.Err(), method)
+		}
+
+		event, visible := watcher.Filter(event)
+
+		if !visible {
+			continue
+		}
+
+		var resp pb.WatchMarketsResponse
+		watch.ToProtobuf(&event, &resp, s.usecase.Now())
+
+		if e := stream.Send(&resp); e != nil {
+			return e
+		}
+	}
+}
+
+// ReportTrade - feed executed trade in ticker and circuit breaker.
+func (s *server) ReportTrade(
+	ctx context.Context,
+	req *pb.ReportTradeRequest,
+) (
+	*pb.ReportTradeResponse,
+	error,
+) {
+	const method = "ReportTrade"
+	state, reason, err := s.usecase.ReportTrade(ctx, req)
+
+	if err != nil {
+		return nil, s.wrapError(err, method)
+	}
+
+	var resp pb.ReportTradeResponse
+	resp.State = market.StateToProtobuf(state)
+	resp.Reason = reason
+	return &resp, nil
+}
+
+// GetTicker - return ticker of one market.
+func (s *server) GetTicker(
+	ctx context.Context,
+	req *pb.GetTickerRequest,
+) (
+	*pb.GetTickerResponse,
+	error,
+) {
+	const method = "GetTicker"
+	tick, err := s.usecase.GetTicker(ctx, req)
+
+	if err != nil {
+		return nil, s.wrapError(err, method)
+	}
+
+	var resp pb.GetTickerResponse
+	resp.Ticker = new(pb.Ticker)
+	ticker.ToProtobuf(&tick, resp.Ticker)
+	return &resp, nil
+}
+
+// GetTickers - return tickers of all visible markets.
+func (s *server) GetTickers(
+	ctx context.Context,
+	req *pb.GetTickersRequest,
+) (
+	*pb.GetTickersResponse,
+	error,
+) {
+	const method = "GetTickers"
+	tickers, err := s.usecase.GetTickers(ctx, req)
+
+	if err != nil {
+		return nil, s.wrapError(err, method)
+	}
+
+	var resp pb.GetTickersResponse
+	resp.Tickers = make([]*pb.Ticker, len(tickers))
+	ticker.ToProtobufMany(tickers, resp.Tickers)
+	return &resp, nil
+}
+
+// WatchTicker - stream ticker of one market on every change.
+func (s *server) WatchTicker(
+	req *pb.WatchTickerRequest,
+	stream grpc.ServerStreamingServer[pb.WatchTickerResponse],
+) error {
+	const method = "WatchTicker"
+	watcher, err := s.usecase.WatchTicker(stream.Context(), req)
+
+	if err != nil {
+		return s.wrapError(err, method)
+	}
+
+	defer watcher.Stop()
+	var resp = pb.WatchTickerResponse{Ticker: new(pb.Ticker)}
+	ticker.ToProtobuf(&watcher.Current, resp.Ticker)
+
+	if e := stream.Send(&resp); e != nil {
+		return e
+	}
+
+	for {
+		var (
+			tick   ticker.Ticker
+			isOpen bool
+		)
+
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case tick, isOpen = <-watcher.Updates:
+		}
+
+		if !isOpen {
+			return s.wrapError(ticker.ErrClosed, method)
+		}
+
+		watcher.Symbol(&tick)
+		resp = pb.WatchTickerResponse{Ticker: new(pb.Ticker)}
+		ticker.ToProtobuf(&tick, resp.Ticker)
+
+		if e := stream.Send(&resp); e != nil {
+			return e
+		}
+	}
+}
+
+// GetCandles - return candles of market.
+func (s *server) GetCandles(
+	ctx context.Context,
+	req *pb.GetCandlesRequest,
+) (
+	*pb.GetCandlesResponse,
+	error,
+) {
+	const method = "GetCandles"
+	candles, err := s.usecase.GetCandles(ctx, req)
+
+	if err != nil {
+		return nil, s.wrapError(err, method)
+	}
+
+	var resp pb.GetCandlesResponse
+	resp.Candles = make([]*pb.Candle, len(candles))
+	candle.ToProtobufMany(candles, resp.Candles)
 	return &resp, nil
 }
 
+// StreamCandles - get bar in progress of market on every trade.
+func (s *server) StreamCandles(
+	req *pb.StreamCandlesRequest,
+	stream grpc.ServerStreamingServer[pb.StreamCandlesResponse],
+) error {
+	const method = "StreamCandles"
+	watcher, err := s.usecase.StreamCandles(stream.Context(), req)
+
+	if err != nil {
+		return s.wrapError(err, method)
+	}
+
+	defer watcher.Stop()
+
+	if watcher.Current != nil {
+		var resp = pb.StreamCandlesResponse{Candle: new(pb.Candle)}
+		candle.ToProtobuf(watcher.Current, resp.Candle)
+
+		if e := stream.Send(&resp); e != nil {
+			return e
+		}
+	}
+
+	for {
+		var (
+			bar    candle.Candle
+			isOpen bool
+		)
+
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case bar, isOpen = <-watcher.Updates:
+		}
+
+		if !isOpen {
+			return s.wrapError(candle.ErrClosed, method)
+		}
+
+		var resp = pb.StreamCandlesResponse{Candle: new(pb.Candle)}
+		candle.ToProtobuf(&bar, resp.Candle)
+
+		if e := stream.Send(&resp); e != nil {
+			return e
+		}
+	}
+}
+
 // wrapError - log error if it not nil and call wrapError.
 func (s *server) wrapError(err error, method string) error {
 	if err == nil {
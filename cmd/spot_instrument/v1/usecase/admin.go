@@ -0,0 +1,379 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/KonnorFrik/BinaryTentacles/cmd/spot_instrument/v1/usecase/market"
+	pb "github.com/KonnorFrik/BinaryTentacles/internal/generated/spot_instrument/v1"
+	"github.com/KonnorFrik/BinaryTentacles/pkg/auth"
+	"github.com/google/uuid"
+)
+
+// actorCircuitBreaker - actor of changes made by circuit breaker.
+const actorCircuitBreaker = "circuit_breaker"
+
+// CreateMarket - create a new market logic.
+func (u *Usecase) CreateMarket(
+	ctx context.Context,
+	req *pb.CreateMarketRequest,
+) (
+	*market.Market,
+	error,
+) {
+	actor, err := actorOf(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	base := market.NormalizeSymbol(req.GetBaseAsset())
+	quote := market.NormalizeSymbol(req.GetQuoteAsset())
+
+	if err := validateAssets(base, quote); err != nil {
+		return nil, err
+	}
+
+	schedule := market.ScheduleFromProtobuf(req.GetSchedule())
+
+	if err := validateSchedule(schedule); err != nil {
+		return nil, err
+	}
+
+	symbol := market.NewSymbol(base, quote)
+
+	var mark = market.Market{
+		Id:                uuid.NewString(),
+		Enabled:           req.GetEnabled(),
+		Symbol:            symbol,
+		BaseAsset:         base,
+		QuoteAsset:        quote,
+		PricePrecision:    req.GetPricePrecision(),
+		QuantityPrecision: req.GetQuantityPrecision(),
+		Schedule:          schedule,
+		LaunchAt:          market.TimeFromProtobuf(req.GetLaunchAt()),
+	}
+
+	if err := u.saveMarketSymbol(ctx, &mark, market.Snapshot{}); err != nil {
+		return nil, err
+	}
+
+	u.recordAudit(ctx, &mark, actor, market.ActionCreate, market.Snapshot{})
+	u.publishChange(ctx, &mark, market.Snapshot{}, actor, market.ActionCreate)
+	return &mark, nil
+}
+
+// UpdateMarket - change only given fields of market logic.
+func (u *Usecase) UpdateMarket(
+	ctx context.Context,
+	req *pb.UpdateMarketRequest,
+) (
+	*market.Market,
+	error,
+) {
+	return u.changeMarket(ctx, req.GetMarketId(), market.ActionUpdate, func(mark *market.Market) error {
+		if req.BaseAsset != nil {
+			mark.BaseAsset = market.NormalizeSymbol(req.GetBaseAsset())
+		}
+
+		if req.QuoteAsset != nil {
+			mark.QuoteAsset = market.NormalizeSymbol(req.GetQuoteAsset())
+		}
+
+		if err := validateAssets(mark.BaseAsset, mark.QuoteAsset); err != nil {
+			return err
+		}
+
+		if req.PricePrecision != nil {
+			mark.PricePrecision = req.GetPricePrecision()
+		}
+
+		if req.QuantityPrecision != nil {
+			mark.QuantityPrecision = req.GetQuantityPrecision()
+		}
+
+		switch {
+		case req.GetClearSchedule():
+			mark.Schedule = nil
+		case req.GetSchedule() != nil:
+			schedule := market.ScheduleFromProtobuf(req.GetSchedule())
+
+			if err := validateSchedule(schedule); err != nil {
+				return err
+			}
+
+			mark.Schedule = schedule
+		}
+
+		switch {
+		case req.GetClearLaunchAt():
+			mark.LaunchAt = time.Time{}
+		case req.GetLaunchAt() != nil:
+			mark.LaunchAt = market.TimeFromProtobuf(req.GetLaunchAt())
+		}
+
+		if req.State != nil {
+			state, ok := market.StateFromProtobuf(req.GetState())
+
+			if !ok {
+				return fmt.Errorf("%w: unknown market state", ErrInvalidInput)
+			}
+
+			mark.State = state
+			mark.StateReason = req.GetStateReason()
+			mark.HaltedUntil = time.Time{}
+		}
+
+		// Symbol is reserved on save, see saveMarketSymbol.
+		mark.Symbol = market.NewSymbol(mark.BaseAsset, mark.QuoteAsset)
+		return nil
+	})
+}
+
+// EnableMarket - enable market logic.
+func (u *Usecase) EnableMarket(
+	ctx context.Context,
+	req *pb.MarketActionRequest,
+) (
+	*market.Market,
+	error,
+) {
+	return u.changeMarket(ctx, req.GetMarketId(), market.ActionEnable, func(mark *market.Market) error {
+		mark.Enabled = true
+		return nil
+	})
+}
+
+// DisableMarket - disable market logic.
+func (u *Usecase) DisableMarket(
+	ctx context.Context,
+	req *pb.MarketActionRequest,
+) (
+	*market.Market,
+	error,
+) {
+	return u.changeMarket(ctx, req.GetMarketId(), market.ActionDisable, func(mark *market.Market) error {
+		mark.Enabled = false
+		return nil
+	})
+}
+
+// SoftDeleteMarket - mark market as deleted logic.
+// Market stay stored and can be restored.
+func (u *Usecase) SoftDeleteMarket(
+	ctx context.Context,
+	req *pb.MarketActionRequest,
+) (
+	*market.Market,
+	error,
+) {
+	return u.changeMarket(ctx, req.GetMarketId(), market.ActionSoftDelete, func(mark *market.Market) error {
+		if mark.DeletedAt.IsZero() {
+			mark.DeletedAt = u.clock.Now()
+		}
+
+		return nil
+	})
+}
+
+// RestoreMarket - restore soft deleted market logic.
+func (u *Usecase) RestoreMarket(
+	ctx context.Context,
+	req *pb.MarketActionRequest,
+) (
+	*market.Market,
+	error,
+) {
+	return u.changeMarket(ctx, req.GetMarketId(), market.ActionRestore, func(mark *market.Market) error {
+		mark.DeletedAt = time.Time{}
+		return nil
+	})
+}
+
+// GetMarketAudit - return audit trail of market logic.
+// Oldest record first.
+func (u *Usecase) GetMarketAudit(
+	ctx context.Context,
+	req *pb.GetMarketAuditRequest,
+) (
+	[]*market.AuditRecord,
+	error,
+) {
+	if err := uuid.Validate(req.GetMarketId()); err != nil {
+		return nil, fmt.Errorf("%w: invalid market id", ErrInvalidInput)
+	}
+
+	recordsJSON, err := u.auditCache.Range(ctx, req.GetMarketId(), 0, -1)
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInternal, err)
+	}
+
+	var records = make([]*market.AuditRecord, 0, len(recordsJSON))
+
+	for _, recordJSON := range recordsJSON {
+		var record market.AuditRecord
+
+		if err = json.Unmarshal([]byte(recordJSON), &record); err != nil {
+			logger.LogAttrs(
+				ctx,
+				slog.LevelError,
+				"[SpotInstrument/GetMarketAudit/Unmarshal]",
+				slog.String("error", err.Error()),
+			)
+			continue
+		}
+
+		records = append(records, &record)
+	}
+
+	return records, nil
+}
+
+// changeMarket - load market, apply 'change', save and record audit
+// with authenticated caller as actor. Changes of one market are serialized.
+// Market is not saved if nothing changed.
+func (u *Usecase) changeMarket(
+	ctx context.Context,
+	marketId string,
+	action market.Action,
+	change func(*market.Market) error,
+) (
+	*market.Market,
+	error,
+) {
+	actor, err := actorOf(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uuid.Validate(marketId); err != nil {
+		return nil, fmt.Errorf("%w: invalid market id", ErrInvalidInput)
+	}
+
+	unlock := u.marketLocks.Lock(marketId)
+	defer unlock()
+	mark, err := u.marketById(ctx, marketId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	before := mark.Snapshot()
+
+	if err = change(mark); err != nil {
+		return nil, err
+	}
+
+	if len(market.Diff(before, mark.Snapshot())) == 0 {
+		return mark, nil
+	}
+
+	if err = u.saveMarketSymbol(ctx, mark, before); err != nil {
+		return nil, err
+	}
+
+	u.recordAudit(ctx, mark, actor, action, before)
+	u.publishChange(ctx, mark, before, actor, action)
+	return mark, nil
+}
+
+// recordAudit - append audit record of change market 'mark' from 'before'.
+// Errors only logged, change already saved.
+func (u *Usecase) recordAudit(
+	ctx context.Context,
+	mark *market.Market,
+	actor string,
+	action market.Action,
+	before market.Snapshot,
+) {
+	record := market.AuditRecord{
+		MarketId: mark.Id,
+		Actor:    actor,
+		Action:   action,
+		At:       u.clock.Now(),
+		Changes:  market.Diff(before, mark.Snapshot()),
+	}
+	recordBytes, err := json.Marshal(record)
+
+	if err == nil {
+		err = u.auditCache.Push(ctx, record.MarketId, string(recordBytes))
+	}
+
+	if err != nil {
+		logger.LogAttrs(
+			ctx,
+			slog.LevelError,
+			"[SpotInstrument/recordAudit]",
+			slog.String("market", record.MarketId),
+			slog.String("action", string(record.Action)),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// checkSymbolFree - check is no other not deleted market with 'symbol'.
+// Market with id 'exceptId' is ignored.
+func (u *Usecase) checkSymbolFree(
+	ctx context.Context,
+	symbol string,
+	exceptId string,
+) error {
+	markets, err := u.allMarkets(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	for _, mark := range markets {
+		if mark.Symbol == symbol && mark.Id != exceptId && mark.DeletedAt.IsZero() {
+			return fmt.Errorf("%w: market %q", ErrAlreadyExists, symbol)
+		}
+	}
+
+	return nil
+}
+
+// actorOf - return name of authenticated caller of call with 'ctx', as actor of change.
+// Actor in request is ignored, so audit trail can't be forged.
+func actorOf(ctx context.Context) (string, error) {
+	principal := auth.FromContext(ctx)
+
+	if principal.Anonymous() {
+		return "", fmt.Errorf("%w: actor is not authenticated", ErrForbidden)
+	}
+
+	return principal.Name, nil
+}
+
+// validateAssets - check are normalized 'base' and 'quote' assets usable in market symbol.
+// Symbol joins assets by '-', so assets can't contain it.
+func validateAssets(base, quote string) error {
+	if base == "" || quote == "" {
+		return fmt.Errorf("%w: base and quote assets are required", ErrInvalidInput)
+	}
+
+	if strings.Contains(base, "-") || strings.Contains(quote, "-") {
+		return fmt.Errorf("%w: asset can't contain '-'", ErrInvalidInput)
+	}
+
+	return nil
+}
+
+// validateSchedule - check is schedule usable. Nil schedule is valid.
+func validateSchedule(schedule *market.Schedule) error {
+	if schedule == nil {
+		return nil
+	}
+
+	if err := schedule.Validate(); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidInput, err)
+	}
+
+	return nil
+}
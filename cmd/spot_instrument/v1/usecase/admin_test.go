@@ -0,0 +1,100 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/KonnorFrik/BinaryTentacles/cmd/spot_instrument/v1/usecase/market"
+	"github.com/KonnorFrik/BinaryTentacles/cmd/spot_instrument/v1/usecase/repository"
+	pb "github.com/KonnorFrik/BinaryTentacles/internal/generated/spot_instrument/v1"
+)
+
+// slowMarkets - MarketRepository with slow read, so concurrent changes overlap.
+type slowMarkets struct {
+	repository.MarketRepository
+}
+
+func (s slowMarkets) Get(
+	ctx context.Context,
+	id string,
+) (
+	*market.Market,
+	error,
+) {
+	mark, err := s.MarketRepository.Get(ctx, id)
+	time.Sleep(time.Millisecond)
+	return mark, err
+}
+
+func TestUpdateMarketConcurrent(t *testing.T) {
+	u, _ := newTestUsecase(t)
+	u.markets = slowMarkets{u.markets}
+	mark, err := u.CreateMarket(adminCtx, &pb.CreateMarketRequest{BaseAsset: "btc", QuoteAsset: "usdt"})
+
+	if err != nil {
+		t.Fatalf("Got = %v, Want = %v\n", err, nil)
+	}
+
+	const callers = 16
+	var wg sync.WaitGroup
+
+	// Half of callers change price precision, half quantity precision.
+	for i := range callers {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			req := &pb.UpdateMarketRequest{MarketId: mark.Id, PricePrecision: ptr(uint32(2))}
+
+			if i%2 == 0 {
+				req = &pb.UpdateMarketRequest{MarketId: mark.Id, QuantityPrecision: ptr(uint32(3))}
+			}
+
+			if _, err := u.UpdateMarket(adminCtx, req); err != nil {
+				t.Errorf("Got = %v, Want = %v\n", err, nil)
+			}
+		}()
+	}
+
+	wg.Wait()
+	stored, err := u.marketById(adminCtx, mark.Id)
+
+	if err != nil || stored.PricePrecision != 2 || stored.QuantityPrecision != 3 {
+		t.Fatalf("Got = %d, %d, %v, Want = 2, 3\n", stored.PricePrecision, stored.QuantityPrecision, err)
+	}
+
+	// Only first change of each field is recorded, others change nothing.
+	audit, err := u.GetMarketAudit(adminCtx, &pb.GetMarketAuditRequest{MarketId: mark.Id})
+
+	if err != nil || len(audit) != 3 {
+		t.Fatalf("Got = %d records, %v, Want = 3\n", len(audit), err)
+	}
+}
+
+func TestUpdateMarketAssets(t *testing.T) {
+	u, _ := newTestUsecase(t)
+	mark, err := u.CreateMarket(adminCtx, &pb.CreateMarketRequest{BaseAsset: "btc", QuoteAsset: "usdt"})
+
+	if err != nil {
+		t.Fatalf("Got = %v, Want = %v\n", err, nil)
+	}
+
+	for _, req := range []*pb.UpdateMarketRequest{
+		{MarketId: mark.Id, BaseAsset: ptr("")},
+		{MarketId: mark.Id, BaseAsset: ptr("btc-x")},
+		{MarketId: mark.Id, QuoteAsset: ptr("usd-t")},
+	} {
+		if _, err := u.UpdateMarket(adminCtx, req); !errors.Is(err, ErrInvalidInput) {
+			t.Fatalf("Got = %v, Want = %v\n", err, ErrInvalidInput)
+		}
+	}
+
+	stored, err := u.marketById(adminCtx, mark.Id)
+
+	if err != nil || stored.Symbol != "BTC-USDT" {
+		t.Fatalf("Got = %v, %v, Want = BTC-USDT\n", stored, err)
+	}
+}
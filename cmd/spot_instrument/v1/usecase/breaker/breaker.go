@@ -0,0 +1,115 @@
+/*
+Price circuit breaker for markets.
+Trip when price moves more than threshold percent within a time window.
+*/
+package breaker
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ilyakaznacheev/cleanenv"
+)
+
+// Config - settings of circuit breaker.
+type Config struct {
+	// Threshold - max allowed price move in percents within Window.
+	Threshold float64       `yaml:"threshold" env:"CIRCUIT_BREAKER_THRESHOLD" env-default:"10" env-description:"Max allowed price move in percents within window."`
+	Window    time.Duration `yaml:"window" env:"CIRCUIT_BREAKER_WINDOW" env-default:"5m" env-description:"Period of price move check."`
+	// HaltFor - how long market stay halted after trip.
+	HaltFor time.Duration `yaml:"halt_for" env:"CIRCUIT_BREAKER_HALT" env-default:"5m" env-description:"How long market stay halted after trip."`
+}
+
+// NewConfig - create a new config for breaker.
+// Read values from env, missing values set to defaults.
+func NewConfig() (Config, error) {
+	var config Config
+	err := cleanenv.ReadEnv(&config)
+	return config, err
+}
+
+type sample struct {
+	price int64
+	at    time.Time
+}
+
+// Breaker - track prices per market.
+type Breaker struct {
+	mut     sync.Mutex
+	config  Config
+	samples map[string][]sample
+}
+
+// New - create a new Breaker with 'config'.
+func New(config Config) *Breaker {
+	return &Breaker{
+		config:  config,
+		samples: make(map[string][]sample),
+	}
+}
+
+// Config - return config of breaker 'b'.
+func (b *Breaker) Config() Config {
+	return b.config
+}
+
+// Observe - record 'price' of market 'marketId' at time 'at'.
+// Returns true and price move in percents if breaker tripped.
+// After trip all samples of market are dropped.
+func (b *Breaker) Observe(
+	marketId string,
+	price int64,
+	at time.Time,
+) (
+	bool,
+	float64,
+) {
+	if price <= 0 {
+		return false, 0
+	}
+
+	b.mut.Lock()
+	defer b.mut.Unlock()
+
+	var (
+		from    = at.Add(-b.config.Window)
+		samples = b.samples[marketId]
+		kept    = samples[:0]
+		move    float64
+	)
+
+	for _, s := range samples {
+		if s.at.Before(from) {
+			continue
+		}
+
+		kept = append(kept, s)
+		move = max(move, percentMove(s.price, price))
+	}
+
+	if move > b.config.Threshold {
+		delete(b.samples, marketId)
+		return true, move
+	}
+
+	b.samples[marketId] = append(kept, sample{price: price, at: at})
+	return false, move
+}
+
+// Reset - drop all samples of market 'marketId'.
+func (b *Breaker) Reset(marketId string) {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+	delete(b.samples, marketId)
+}
+
+// percentMove - absolute move from 'from' to 'to' in percents of 'from'.
+func percentMove(from, to int64) float64 {
+	diff := float64(to - from)
+
+	if diff < 0 {
+		diff = -diff
+	}
+
+	return diff / float64(from) * 100
+}
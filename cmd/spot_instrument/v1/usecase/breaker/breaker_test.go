@@ -0,0 +1,96 @@
+package breaker
+
+import (
+	"testing"
+	"time"
+)
+
+const marketId = "market"
+
+var (
+	start  = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	config = Config{Threshold: 10, Window: 5 * time.Minute, HaltFor: time.Minute}
+)
+
+type observation struct {
+	price   int64
+	after   time.Duration
+	tripped bool
+}
+
+func TestObserve(t *testing.T) {
+	tests := []struct {
+		name         string
+		observations []observation
+	}{
+		{
+			name:         "Move at threshold",
+			observations: []observation{{100, 0, false}, {110, time.Minute, false}, {99, 2 * time.Minute, false}},
+		},
+		{
+			name:         "Move above threshold",
+			observations: []observation{{100, 0, false}, {111, time.Minute, true}},
+		},
+		{
+			name:         "Drop above threshold",
+			observations: []observation{{100, 0, false}, {105, time.Minute, false}, {89, 2 * time.Minute, true}},
+		},
+		{
+			name:         "Move out of window",
+			observations: []observation{{100, 0, false}, {120, 6 * time.Minute, false}},
+		},
+		{
+			name:         "Move within window after old sample expired",
+			observations: []observation{{100, 0, false}, {108, 4 * time.Minute, false}, {118, 8 * time.Minute, false}, {130, 9 * time.Minute, true}},
+		},
+		{
+			name:         "Not positive price ignored",
+			observations: []observation{{100, 0, false}, {0, time.Minute, false}, {-500, time.Minute, false}, {105, 2 * time.Minute, false}},
+		},
+		{
+			name:         "Samples dropped after trip",
+			observations: []observation{{100, 0, false}, {200, time.Minute, true}, {100, 2 * time.Minute, false}, {105, 3 * time.Minute, false}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := New(config)
+
+			for i, o := range tt.observations {
+				tripped, move := b.Observe(marketId, o.price, start.Add(o.after))
+
+				if tripped != o.tripped {
+					t.Fatalf("Observation #%d: Got = %t (move %.2f%%), Want = %t\n", i, tripped, move, o.tripped)
+				}
+			}
+		})
+	}
+}
+
+func TestObservePerMarket(t *testing.T) {
+	b := New(config)
+	b.Observe("a", 100, start)
+
+	if tripped, _ := b.Observe("b", 200, start.Add(time.Second)); tripped {
+		t.Fatalf("Got = %t, Want = %t\n", tripped, false)
+	}
+
+	if tripped, _ := b.Observe("a", 200, start.Add(time.Second)); !tripped {
+		t.Fatalf("Got = %t, Want = %t\n", tripped, true)
+	}
+}
+
+func TestReset(t *testing.T) {
+	b := New(config)
+	b.Observe(marketId, 100, start)
+	b.Reset(marketId)
+
+	if tripped, _ := b.Observe(marketId, 200, start.Add(time.Second)); tripped {
+		t.Fatalf("Got = %t, Want = %t\n", tripped, false)
+	}
+
+	if tripped, move := b.Observe(marketId, 230, start.Add(2*time.Second)); !tripped || move != 15 {
+		t.Fatalf("Got = %t, %.2f, Want = %t, %.2f\n", tripped, move, true, 15.0)
+	}
+}
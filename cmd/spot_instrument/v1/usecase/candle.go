@@ -0,0 +1,547 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/KonnorFrik/BinaryTentacles/cmd/spot_instrument/v1/usecase/candle"
+	"github.com/KonnorFrik/BinaryTentacles/cmd/spot_instrument/v1/usecase/market"
+	pb "github.com/KonnorFrik/BinaryTentacles/internal/generated/spot_instrument/v1"
+	"github.com/google/uuid"
+)
+
+const (
+	// tradeRetention - how long stored trades are kept for backfill of candles.
+	tradeRetention = 7 * 24 * time.Hour
+	// candleRestoreWindow - period of candles rebuilt at startup.
+	candleRestoreWindow = 24 * time.Hour
+	// candleFlushPeriod - how often bars ended without new trades are closed.
+	candleFlushPeriod = time.Second
+	// maxCandles - max bars returned by GetCandles.
+	maxCandles = 1000
+)
+
+// storedTrade - trade in trade log.
+// Id keep equal trades as different members of sorted set.
+type storedTrade struct {
+	Id string `json:"id"`
+	candle.Trade
+}
+
+// GetCandles - return candles of market logic.
+// Bar in progress is included if it started in requested range.
+func (u *Usecase) GetCandles(
+	ctx context.Context,
+	req *pb.GetCandlesRequest,
+) (
+	[]candle.Candle,
+	error,
+) {
+	mark, err := u.visibleMarketById(ctx, req.GetUserRole(), req.GetMarketId())
+
+	if err != nil {
+		return nil, err
+	}
+
+	interval := candle.IntervalFromProtobuf(req.GetInterval())
+
+	if !interval.Valid() {
+		return nil, fmt.Errorf("%w: candle interval is required", ErrInvalidInput)
+	}
+
+	var (
+		now = u.clock.Now()
+		to  = now
+	)
+
+	if req.GetTo() != nil {
+		to = market.TimeFromProtobuf(req.GetTo())
+	}
+
+	from := to.Add(-maxCandles * interval.Duration())
+
+	if req.GetFrom() != nil && market.TimeFromProtobuf(req.GetFrom()).After(from) {
+		from = market.TimeFromProtobuf(req.GetFrom())
+	}
+
+	if !from.Before(to) {
+		return nil, fmt.Errorf("%w: 'from' must be before 'to'", ErrInvalidInput)
+	}
+
+	candles, err := u.storedCandles(ctx, mark.Id, interval, from, to)
+
+	if err != nil {
+		return nil, err
+	}
+
+	current, exist := u.marketCandles.Current(mark.Id, interval)
+
+	if exist && !current.Start.Before(from) && current.Start.Before(to) {
+		// Bar may be ended, but not flushed yet.
+		current.Closed = !current.End().After(now)
+		candles = append(candles, current)
+	}
+
+	return candles, nil
+}
+
+// CandleWatch - started watch of one market candles.
+type CandleWatch struct {
+	// Current - bar in progress at start of watch. Nil if no trades in it.
+	Current *candle.Candle
+	// Updates - bar on every change. Closed by Stop or on shutdown.
+	Updates <-chan candle.Candle
+	// Stop - stop watch.
+	Stop func()
+}
+
+// StreamCandles - start watch of market candles logic.
+// Caller must Stop returned watch.
+func (u *Usecase) StreamCandles(
+	ctx context.Context,
+	req *pb.StreamCandlesRequest,
+) (
+	*CandleWatch,
+	error,
+) {
+	mark, err := u.visibleMarketById(ctx, req.GetUserRole(), req.GetMarketId())
+
+	if err != nil {
+		return nil, err
+	}
+
+	interval := candle.IntervalFromProtobuf(req.GetInterval())
+
+	if !interval.Valid() {
+		return nil, fmt.Errorf("%w: candle interval is required", ErrInvalidInput)
+	}
+
+	// Subscribe before read current, so no change lost between them.
+	updates, stop := u.marketCandles.Subscribe(mark.Id, interval)
+	var watch = CandleWatch{
+		Updates: updates,
+		Stop:    stop,
+	}
+
+	if current, exist := u.marketCandles.Current(mark.Id, interval); exist {
+		watch.Current = &current
+	}
+
+	return &watch, nil
+}
+
+// BackfillCandles - rebuild candles of market from stored trades logic.
+// Returns count of used trades and rebuilt bars.
+func (u *Usecase) BackfillCandles(
+	ctx context.Context,
+	req *pb.BackfillCandlesRequest,
+) (
+	uint64,
+	uint64,
+	error,
+) {
+	actor, err := actorOf(ctx)
+
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if err := uuid.Validate(req.GetMarketId()); err != nil {
+		return 0, 0, fmt.Errorf("%w: invalid market id", ErrInvalidInput)
+	}
+
+	mark, err := u.marketById(ctx, req.GetMarketId())
+
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var to = u.clock.Now()
+
+	if req.GetTo() != nil {
+		to = market.TimeFromProtobuf(req.GetTo())
+	}
+
+	from := to.Add(-tradeRetention)
+
+	if req.GetFrom() != nil {
+		from = market.TimeFromProtobuf(req.GetFrom())
+	}
+
+	if !from.Before(to) {
+		return 0, 0, fmt.Errorf("%w: 'from' must be before 'to'", ErrInvalidInput)
+	}
+
+	trades, candles, err := u.backfillMarket(ctx, mark.Id, from, to)
+
+	if err != nil {
+		return 0, 0, err
+	}
+
+	logger.LogAttrs(
+		ctx,
+		slog.LevelInfo,
+		"[SpotInstrument/BackfillCandles]",
+		slog.String("actor", actor),
+		slog.String("market", mark.Id),
+		slog.Time("from", from),
+		slog.Time("to", to),
+		slog.Uint64("trades", trades),
+		slog.Uint64("candles", candles),
+	)
+	return trades, candles, nil
+}
+
+// RestoreCandles - rebuild recent candles of all markets from stored trades,
+// so bars in progress keep trades made before restart.
+// Errors of one market only logged.
+func (u *Usecase) RestoreCandles(ctx context.Context) error {
+	markets, err := u.allMarkets(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	var (
+		to   = u.clock.Now()
+		from = to.Add(-candleRestoreWindow)
+	)
+
+	for _, mark := range markets {
+		if _, _, err := u.backfillMarket(ctx, mark.Id, from, to); err != nil {
+			logger.LogAttrs(
+				ctx,
+				slog.LevelError,
+				"[SpotInstrument/RestoreCandles]",
+				slog.String("market", mark.Id),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+
+	return nil
+}
+
+// ShutdownCandleWatch - stop close of candles and all watches of candles.
+// Must be called before graceful stop of server, watch streams never end by itself.
+func (u *Usecase) ShutdownCandleWatch(ctx context.Context) error {
+	u.stopCandleFlush()
+	u.marketCandles.Close()
+	return nil
+}
+
+// recordCandleTrade - store trade of market 'marketId' and count it in candles.
+// Closed bars of late trade are rebuilt from stored trades.
+// Errors only logged, trade already counted in ticker.
+func (u *Usecase) recordCandleTrade(
+	ctx context.Context,
+	marketId string,
+	trade candle.Trade,
+) {
+	unlock := u.tradeLocks.Lock(marketId)
+	defer unlock()
+	err := u.storeTrade(ctx, marketId, trade)
+
+	if err != nil {
+		logger.LogAttrs(
+			ctx,
+			slog.LevelError,
+			"[SpotInstrument/recordCandleTrade]",
+			slog.String("market", marketId),
+			slog.String("error", err.Error()),
+		)
+	}
+
+	closed, late := u.marketCandles.Record(marketId, trade, err == nil, u.clock.Now())
+	u.saveCandles(ctx, closed)
+
+	// Not stored late trade is lost for closed bars.
+	if err == nil {
+		u.rebuildClosedCandles(ctx, marketId, trade.At, late)
+	}
+}
+
+// rebuildClosedCandles - rebuild from stored trades and save closed bars
+// of 'intervals' which contain time 'at'. Errors only logged.
+// Must be called with locked trades of market.
+func (u *Usecase) rebuildClosedCandles(
+	ctx context.Context,
+	marketId string,
+	at time.Time,
+	intervals []candle.Interval,
+) {
+	var now = u.clock.Now()
+
+	for _, interval := range intervals {
+		start := interval.Start(at)
+		trades, err := u.storedTrades(ctx, marketId, start, start.Add(interval.Duration()))
+
+		if err != nil {
+			logger.LogAttrs(
+				ctx,
+				slog.LevelError,
+				"[SpotInstrument/rebuildClosedCandles]",
+				slog.String("market", marketId),
+				slog.String("interval", interval.String()),
+				slog.String("error", err.Error()),
+			)
+			continue
+		}
+
+		for _, bar := range candle.Build(marketId, interval, trades, now) {
+			if bar.Closed {
+				u.saveCandles(ctx, []candle.Candle{bar})
+			}
+		}
+	}
+}
+
+// backfillMarket - rebuild bars of all intervals overlapping [from, to) from stored trades.
+// Closed bars are saved, bar in progress is merged in aggregator, see candle.Aggregator.Restore.
+func (u *Usecase) backfillMarket(
+	ctx context.Context,
+	marketId string,
+	from time.Time,
+	to time.Time,
+) (
+	uint64,
+	uint64,
+	error,
+) {
+	if u.marketDataCache == nil {
+		return 0, 0, fmt.Errorf("%w: no market data cache", ErrInternal)
+	}
+
+	// Trades recorded while rebuild would be missed by restored bars.
+	unlock := u.tradeLocks.Lock(marketId)
+	defer unlock()
+
+	// Read trades of whole bars of longest interval, so every rebuilt bar is complete.
+	var (
+		now       = u.clock.Now()
+		readFrom  = candle.Day.Start(from)
+		readTo    = candle.Day.Start(to.Add(-time.Nanosecond)).Add(candle.Day.Duration())
+		rebuilt   uint64
+		closedAll []candle.Candle
+	)
+
+	trades, err := u.storedTrades(ctx, marketId, readFrom, readTo)
+
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, interval := range candle.Intervals {
+		for _, bar := range candle.Build(marketId, interval, trades, now) {
+			if !bar.End().After(from) || !bar.Start.Before(to) {
+				continue
+			}
+
+			if bar.Closed {
+				closedAll = append(closedAll, bar)
+
+			} else {
+				u.marketCandles.Restore(bar)
+			}
+
+			rebuilt++
+		}
+	}
+
+	u.saveCandles(ctx, closedAll)
+	return uint64(len(trades)), rebuilt, nil
+}
+
+// flushCandles - close bars ended without new trades and save them, until 'ctx' is done.
+func (u *Usecase) flushCandles(ctx context.Context) {
+	ticker := time.NewTicker(candleFlushPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		u.saveCandles(ctx, u.marketCandles.Flush(u.clock.Now()))
+	}
+}
+
+// storeTrade - append 'trade' to trade log of market 'marketId'.
+// Trades older than tradeRetention are removed.
+func (u *Usecase) storeTrade(
+	ctx context.Context,
+	marketId string,
+	trade candle.Trade,
+) error {
+	if u.marketDataCache == nil {
+		return fmt.Errorf("%w: no market data cache", ErrInternal)
+	}
+
+	tradeJSON, err := json.Marshal(storedTrade{
+		Id:    uuid.NewString(),
+		Trade: trade,
+	})
+
+	if err != nil {
+		return fmt.Errorf("%w: trade marshal: %w", ErrInternal, err)
+	}
+
+	key := tradeKey(marketId)
+	err = u.marketDataCache.SortedAdd(ctx, key, float64(trade.At.UnixMilli()), string(tradeJSON))
+
+	if err != nil {
+		return fmt.Errorf("%w: trade save: %w", ErrInternal, err)
+	}
+
+	expired := u.clock.Now().Add(-tradeRetention).UnixMilli()
+	err = u.marketDataCache.SortedRemove(ctx, key, 0, float64(expired))
+
+	if err != nil {
+		return fmt.Errorf("%w: trade expire: %w", ErrInternal, err)
+	}
+
+	return nil
+}
+
+// storedTrades - return stored trades of market 'marketId' executed in [from, to).
+func (u *Usecase) storedTrades(
+	ctx context.Context,
+	marketId string,
+	from time.Time,
+	to time.Time,
+) (
+	[]candle.Trade,
+	error,
+) {
+	tradesJSON, err := u.marketDataCache.SortedRange(
+		ctx,
+		tradeKey(marketId),
+		float64(from.UnixMilli()),
+		float64(to.UnixMilli()-1),
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInternal, err)
+	}
+
+	var trades = make([]candle.Trade, 0, len(tradesJSON))
+
+	for _, tradeJSON := range tradesJSON {
+		var trade storedTrade
+
+		if err = json.Unmarshal([]byte(tradeJSON), &trade); err != nil {
+			logger.LogAttrs(
+				ctx,
+				slog.LevelError,
+				"[SpotInstrument/storedTrades]",
+				slog.String("market", marketId),
+				slog.String("error", err.Error()),
+			)
+			continue
+		}
+
+		trades = append(trades, trade.Trade)
+	}
+
+	return trades, nil
+}
+
+// storedCandles - return closed bars of market 'marketId' started in [from, to).
+func (u *Usecase) storedCandles(
+	ctx context.Context,
+	marketId string,
+	interval candle.Interval,
+	from time.Time,
+	to time.Time,
+) (
+	[]candle.Candle,
+	error,
+) {
+	if u.marketDataCache == nil {
+		return nil, nil
+	}
+
+	// Score is start in seconds, bars start on whole minutes.
+	candlesJSON, err := u.marketDataCache.SortedRange(
+		ctx,
+		candleKey(marketId, interval),
+		float64(from.Unix()),
+		float64(to.Add(-time.Second).Unix()),
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInternal, err)
+	}
+
+	var candles = make([]candle.Candle, 0, len(candlesJSON))
+
+	for _, candleJSON := range candlesJSON {
+		var bar candle.Candle
+
+		if err = json.Unmarshal([]byte(candleJSON), &bar); err != nil {
+			logger.LogAttrs(
+				ctx,
+				slog.LevelError,
+				"[SpotInstrument/storedCandles]",
+				slog.String("market", marketId),
+				slog.String("error", err.Error()),
+			)
+			continue
+		}
+
+		candles = append(candles, bar)
+	}
+
+	return candles, nil
+}
+
+// saveCandles - save closed bars 'candles', one bar per start.
+// Errors only logged, bars can be rebuilt by backfill.
+func (u *Usecase) saveCandles(
+	ctx context.Context,
+	candles []candle.Candle,
+) {
+	if u.marketDataCache == nil {
+		return
+	}
+
+	for i := range candles {
+		bar := &candles[i]
+		candleJSON, err := json.Marshal(bar)
+
+		if err == nil {
+			err = u.marketDataCache.SortedReplace(
+				ctx,
+				candleKey(bar.MarketId, bar.Interval),
+				float64(bar.Start.Unix()),
+				string(candleJSON),
+			)
+		}
+
+		if err != nil {
+			logger.LogAttrs(
+				ctx,
+				slog.LevelError,
+				"[SpotInstrument/saveCandles]",
+				slog.String("market", bar.MarketId),
+				slog.String("interval", bar.Interval.String()),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+}
+
+// tradeKey - key of trade log of market 'marketId'.
+func tradeKey(marketId string) string {
+	return "trades:" + marketId
+}
+
+// candleKey - key of closed bars of market 'marketId' and 'interval'.
+func candleKey(marketId string, interval candle.Interval) string {
+	return "candles:" + marketId + ":" + interval.String()
+}
@@ -0,0 +1,498 @@
+/*
+OHLCV bars of market trades in fixed intervals.
+Aggregator keep only bars in progress, closed bars are returned to caller for store.
+Trade of already closed bar is not counted by Aggregator, it reported as late,
+so caller can rebuild closed bar with Build from stored trades.
+*/
+package candle
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrClosed - aggregator closed, no more updates will be sent.
+	ErrClosed = errors.New("candle watch is closed")
+)
+
+// Interval - length of one bar.
+type Interval int
+
+const (
+	Minute Interval = iota + 1
+	FiveMinutes
+	Hour
+	Day
+)
+
+// Intervals - all supported intervals, shortest first.
+var Intervals = []Interval{Minute, FiveMinutes, Hour, Day}
+
+// Duration - return length of interval 'i'.
+func (i Interval) Duration() time.Duration {
+	switch i {
+	case Minute:
+		return time.Minute
+	case FiveMinutes:
+		return 5 * time.Minute
+	case Hour:
+		return time.Hour
+	case Day:
+		return 24 * time.Hour
+	}
+
+	return 0
+}
+
+// String - return short name of interval 'i', e.g. "5m".
+func (i Interval) String() string {
+	switch i {
+	case Minute:
+		return "1m"
+	case FiveMinutes:
+		return "5m"
+	case Hour:
+		return "1h"
+	case Day:
+		return "1d"
+	}
+
+	return "unknown"
+}
+
+// Valid - report whether interval 'i' is supported.
+func (i Interval) Valid() bool {
+	return i.Duration() > 0
+}
+
+// Start - return begin of bar of interval 'i' which contains time 'at'.
+// Bars are aligned to UTC.
+func (i Interval) Start(at time.Time) time.Time {
+	return at.UTC().Truncate(i.Duration())
+}
+
+// Trade - one executed trade.
+type Trade struct {
+	Price    int64     `json:"price"`
+	Quantity uint64    `json:"quantity"`
+	At       time.Time `json:"at"`
+}
+
+// Candle - OHLCV bar of trades in [Start, Start + Interval).
+type Candle struct {
+	MarketId   string    `json:"market_id"`
+	Interval   Interval  `json:"interval"`
+	Start      time.Time `json:"start"`
+	Open       int64     `json:"open"`
+	High       int64     `json:"high"`
+	Low        int64     `json:"low"`
+	Close      int64     `json:"close"`
+	Volume     uint64    `json:"volume"`
+	TradeCount uint64    `json:"trade_count"`
+	// Closed - bar ended, it values will not change.
+	Closed bool `json:"closed"`
+	// firstAt, lastAt - time of trades which set Open and Close.
+	firstAt time.Time
+	lastAt  time.Time
+}
+
+// End - return end of bar 'c', not included in bar.
+func (c *Candle) End() time.Time {
+	return c.Start.Add(c.Interval.Duration())
+}
+
+// add - count 'trade' in bar 'c'.
+// Trades may come out of order, Open and Close follow time of trades.
+func (c *Candle) add(trade Trade) {
+	if c.TradeCount == 0 {
+		c.Open, c.High, c.Low, c.Close = trade.Price, trade.Price, trade.Price, trade.Price
+		c.firstAt, c.lastAt = trade.At, trade.At
+
+	} else {
+		c.High = max(c.High, trade.Price)
+		c.Low = min(c.Low, trade.Price)
+
+		if trade.At.Before(c.firstAt) {
+			c.Open, c.firstAt = trade.Price, trade.At
+		}
+
+		if !trade.At.Before(c.lastAt) {
+			c.Close, c.lastAt = trade.Price, trade.At
+		}
+	}
+
+	c.Volume += trade.Quantity
+	c.TradeCount++
+}
+
+// merge - count trades of bar 'other' with same start in bar 'c'.
+// Trades of bars must be different.
+func (c *Candle) merge(other Candle) {
+	switch {
+	case other.TradeCount == 0:
+		return
+	case c.TradeCount == 0:
+		c.Open, c.High, c.Low, c.Close = other.Open, other.High, other.Low, other.Close
+		c.firstAt, c.lastAt = other.firstAt, other.lastAt
+	default:
+		c.High = max(c.High, other.High)
+		c.Low = min(c.Low, other.Low)
+
+		if other.firstAt.Before(c.firstAt) {
+			c.Open, c.firstAt = other.Open, other.firstAt
+		}
+
+		if !other.lastAt.Before(c.lastAt) {
+			c.Close, c.lastAt = other.Close, other.lastAt
+		}
+	}
+
+	c.Volume += other.Volume
+	c.TradeCount += other.TradeCount
+}
+
+// Build - aggregate 'trades' of market 'marketId' in bars of 'interval'.
+// Trades may be in any order. Bars ended before 'now' are closed.
+// Returns bars with trades, oldest first.
+func Build(
+	marketId string,
+	interval Interval,
+	trades []Trade,
+	now time.Time,
+) []Candle {
+	trades = slices.Clone(trades)
+	slices.SortStableFunc(trades, func(a, b Trade) int {
+		return a.At.Compare(b.At)
+	})
+	var candles []Candle
+
+	for _, trade := range trades {
+		start := interval.Start(trade.At)
+
+		if len(candles) == 0 || !candles[len(candles)-1].Start.Equal(start) {
+			candles = append(candles, Candle{
+				MarketId: marketId,
+				Interval: interval,
+				Start:    start,
+			})
+		}
+
+		candles[len(candles)-1].add(trade)
+	}
+
+	for i := range candles {
+		candles[i].Closed = !candles[i].End().After(now)
+	}
+
+	return candles
+}
+
+// key - bar of one market and interval.
+type key struct {
+	marketId string
+	interval Interval
+}
+
+// maxPendingClosed - how many closed bars can wait for receive in one subscription.
+const maxPendingClosed = 64
+
+// Aggregator - bars in progress of all markets and intervals.
+type Aggregator struct {
+	mut  sync.Mutex
+	bars map[key]*Candle
+	// unsaved - trades of bars in progress not saved in trade log,
+	// they are merged in bar rebuilt from trade log by Restore.
+	unsaved     map[key]*Candle
+	subscribers map[key]map[*subscriber]struct{}
+	closed      bool
+}
+
+// subscriber - receiver of bars of one market and interval.
+type subscriber struct {
+	updates chan Candle
+	// wake - signal of new bars for send, buffer of one.
+	wake chan struct{}
+	// done - closed when subscription stopped.
+	done chan struct{}
+	// closed - closed bars not sent yet, oldest first.
+	closed []Candle
+	// latest - bar in progress not sent yet.
+	latest *Candle
+}
+
+// NewAggregator - create a new empty Aggregator.
+func NewAggregator() *Aggregator {
+	return &Aggregator{
+		bars:        make(map[key]*Candle),
+		unsaved:     make(map[key]*Candle),
+		subscribers: make(map[key]map[*subscriber]struct{}),
+	}
+}
+
+// Record - add 'trade' of market 'marketId' in bars of all intervals at time 'now'.
+// 'saved' reports whether trade is saved in trade log, see Restore.
+// Returns bars closed because trade started a new bar and intervals
+// in which bar of trade is already closed, trade is not counted in them.
+func (a *Aggregator) Record(
+	marketId string,
+	trade Trade,
+	saved bool,
+	now time.Time,
+) (
+	closed []Candle,
+	late []Interval,
+) {
+	a.mut.Lock()
+	defer a.mut.Unlock()
+
+	for _, interval := range Intervals {
+		k := key{marketId: marketId, interval: interval}
+		start := interval.Start(trade.At)
+		bar, exist := a.bars[k]
+
+		switch {
+		case exist && bar.Start.Equal(start):
+		case exist && bar.Start.After(start), !start.Add(interval.Duration()).After(now):
+			late = append(late, interval)
+			continue
+		default:
+			if exist {
+				bar.Closed = true
+				closed = append(closed, *bar)
+				a.notify(k, bar)
+				delete(a.unsaved, k)
+			}
+
+			bar = &Candle{
+				MarketId: marketId,
+				Interval: interval,
+				Start:    start,
+			}
+			a.bars[k] = bar
+		}
+
+		bar.add(trade)
+
+		if !saved {
+			if a.unsaved[k] == nil {
+				a.unsaved[k] = &Candle{MarketId: marketId, Interval: interval, Start: start}
+			}
+
+			a.unsaved[k].add(trade)
+		}
+
+		a.notify(k, bar)
+	}
+
+	return closed, late
+}
+
+// Flush - close bars ended before 'now'.
+// Returns closed bars.
+func (a *Aggregator) Flush(now time.Time) []Candle {
+	a.mut.Lock()
+	defer a.mut.Unlock()
+
+	var closed []Candle
+
+	for k, bar := range a.bars {
+		if bar.End().After(now) {
+			continue
+		}
+
+		bar.Closed = true
+		closed = append(closed, *bar)
+		a.notify(k, bar)
+		delete(a.bars, k)
+		delete(a.unsaved, k)
+	}
+
+	return closed
+}
+
+// Restore - set bar in progress from 'bar' rebuilt by Build from trade log,
+// e.g. after restart. Recorded trades not saved in trade log are merged in it,
+// so 'bar' must include all saved trades of it start.
+// Bar is not changed if aggregator has newer bar.
+func (a *Aggregator) Restore(bar Candle) {
+	a.mut.Lock()
+	defer a.mut.Unlock()
+
+	k := key{marketId: bar.MarketId, interval: bar.Interval}
+
+	if current, exist := a.bars[k]; exist && current.Start.After(bar.Start) {
+		return
+	}
+
+	if unsaved, exist := a.unsaved[k]; exist {
+		if unsaved.Start.Equal(bar.Start) {
+			bar.merge(*unsaved)
+
+		} else {
+			delete(a.unsaved, k)
+		}
+	}
+
+	bar.Closed = false
+	a.bars[k] = &bar
+	a.notify(k, &bar)
+}
+
+// Current - return bar in progress of market 'marketId' and 'interval'.
+// Returns false if no trades in current bar.
+func (a *Aggregator) Current(marketId string, interval Interval) (Candle, bool) {
+	a.mut.Lock()
+	defer a.mut.Unlock()
+
+	bar, exist := a.bars[key{marketId: marketId, interval: interval}]
+
+	if !exist {
+		return Candle{}, false
+	}
+
+	return *bar, true
+}
+
+// Subscribe - receive bar of market 'marketId' and 'interval' on every change.
+// Every closed bar is received, slow receiver get only latest bar in progress.
+// Subscription with maxPendingClosed not received closed bars is stopped.
+// Returned function stop subscription, channel is closed after stop.
+// Channel of closed Aggregator is closed right away.
+func (a *Aggregator) Subscribe(marketId string, interval Interval) (<-chan Candle, func()) {
+	a.mut.Lock()
+	defer a.mut.Unlock()
+
+	k := key{marketId: marketId, interval: interval}
+	sub := &subscriber{
+		updates: make(chan Candle),
+		wake:    make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+
+	if a.subscribers[k] == nil {
+		a.subscribers[k] = make(map[*subscriber]struct{})
+	}
+
+	a.subscribers[k][sub] = struct{}{}
+	stop := func() {
+		a.mut.Lock()
+		defer a.mut.Unlock()
+		a.unsubscribe(k, sub)
+	}
+
+	if a.closed {
+		a.unsubscribe(k, sub)
+	}
+
+	go a.deliver(sub)
+	return sub.updates, stop
+}
+
+// Close - stop all subscriptions.
+func (a *Aggregator) Close() {
+	a.mut.Lock()
+	defer a.mut.Unlock()
+	a.closed = true
+
+	for k, subscribers := range a.subscribers {
+		for sub := range subscribers {
+			a.unsubscribe(k, sub)
+		}
+	}
+}
+
+// unsubscribe - remove subscriber 'sub' of bar 'k' and stop it.
+// Must be called with locked aggregator.
+func (a *Aggregator) unsubscribe(k key, sub *subscriber) {
+	if _, exist := a.subscribers[k][sub]; !exist {
+		return
+	}
+
+	delete(a.subscribers[k], sub)
+	close(sub.done)
+
+	if len(a.subscribers[k]) == 0 {
+		delete(a.subscribers, k)
+	}
+}
+
+// notify - queue 'bar' for send to all subscribers of 'k'.
+// Closed bar replace not sent bar in progress with same start.
+// Must be called with locked aggregator.
+func (a *Aggregator) notify(k key, bar *Candle) {
+	for sub := range a.subscribers[k] {
+		if bar.Closed {
+			if len(sub.closed) == maxPendingClosed {
+				a.unsubscribe(k, sub)
+				continue
+			}
+
+			sub.closed = append(sub.closed, *bar)
+
+			if sub.latest != nil && sub.latest.Start.Equal(bar.Start) {
+				sub.latest = nil
+			}
+
+		} else {
+			latest := *bar
+			sub.latest = &latest
+		}
+
+		select {
+		case sub.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// deliver - send queued bars of 'sub' to it channel until subscription stopped,
+// then close channel.
+func (a *Aggregator) deliver(sub *subscriber) {
+	defer close(sub.updates)
+
+	for {
+		select {
+		case <-sub.done:
+			return
+		case <-sub.wake:
+		}
+
+		for {
+			bar, exist := a.next(sub)
+
+			if !exist {
+				break
+			}
+
+			select {
+			case sub.updates <- bar:
+			case <-sub.done:
+				return
+			}
+		}
+	}
+}
+
+// next - take oldest not sent bar of 'sub', closed bars first.
+// Returns false if all bars sent.
+func (a *Aggregator) next(sub *subscriber) (Candle, bool) {
+	a.mut.Lock()
+	defer a.mut.Unlock()
+
+	if len(sub.closed) > 0 {
+		bar := sub.closed[0]
+		sub.closed = sub.closed[1:]
+		return bar, true
+	}
+
+	if sub.latest != nil {
+		bar := *sub.latest
+		sub.latest = nil
+		return bar, true
+	}
+
+	return Candle{}, false
+}
@@ -0,0 +1,230 @@
+package candle
+
+import (
+	"testing"
+	"time"
+)
+
+const marketId = "5d6f8857-fafe-432c-8380-2b340ec03bb7"
+
+var start = time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+
+// ohlcv - exported values of bar 'c', without unexported times.
+func ohlcv(c Candle) Candle {
+	return Candle{
+		MarketId:   c.MarketId,
+		Interval:   c.Interval,
+		Start:      c.Start,
+		Open:       c.Open,
+		High:       c.High,
+		Low:        c.Low,
+		Close:      c.Close,
+		Volume:     c.Volume,
+		TradeCount: c.TradeCount,
+		Closed:     c.Closed,
+	}
+}
+
+func TestBuild(t *testing.T) {
+	trades := []Trade{
+		{Price: 120, Quantity: 2, At: start.Add(4 * time.Minute)},
+		{Price: 100, Quantity: 1, At: start.Add(10 * time.Second)},
+		{Price: 90, Quantity: 3, At: start.Add(time.Minute)},
+		{Price: 110, Quantity: 4, At: start.Add(6 * time.Minute)},
+	}
+	now := start.Add(7 * time.Minute)
+
+	cases := []struct {
+		name     string
+		interval Interval
+		want     []Candle
+	}{
+		{
+			name:     "Five minutes",
+			interval: FiveMinutes,
+			want: []Candle{
+				{MarketId: marketId, Interval: FiveMinutes, Start: start, Open: 100, High: 120, Low: 90, Close: 120, Volume: 6, TradeCount: 3, Closed: true},
+				{MarketId: marketId, Interval: FiveMinutes, Start: start.Add(5 * time.Minute), Open: 110, High: 110, Low: 110, Close: 110, Volume: 4, TradeCount: 1},
+			},
+		},
+		{
+			name:     "Hour",
+			interval: Hour,
+			want: []Candle{
+				{MarketId: marketId, Interval: Hour, Start: start, Open: 100, High: 120, Low: 90, Close: 110, Volume: 10, TradeCount: 4},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := Build(marketId, c.interval, trades, now)
+
+			if len(got) != len(c.want) {
+				t.Fatalf("Got = %d bars, Want = %d\n", len(got), len(c.want))
+			}
+
+			for i := range got {
+				if ohlcv(got[i]) != c.want[i] {
+					t.Fatalf("Got = %+v\nWant = %+v\n", ohlcv(got[i]), c.want[i])
+				}
+			}
+		})
+	}
+}
+
+// record - record 'trade' as saved right after it execution.
+func record(agg *Aggregator, trade Trade) ([]Candle, []Interval) {
+	return agg.Record(marketId, trade, true, trade.At.Add(time.Second))
+}
+
+func TestRecord(t *testing.T) {
+	var agg = NewAggregator()
+
+	if closed, _ := record(agg, Trade{Price: 100, Quantity: 1, At: start}); len(closed) != 0 {
+		t.Fatalf("Got = %+v, Want no closed bars\n", closed)
+	}
+
+	record(agg, Trade{Price: 90, Quantity: 1, At: start.Add(30 * time.Second)})
+	closed, _ := record(agg, Trade{Price: 95, Quantity: 2, At: start.Add(time.Minute)})
+	want := Candle{MarketId: marketId, Interval: Minute, Start: start, Open: 100, High: 100, Low: 90, Close: 90, Volume: 2, TradeCount: 2, Closed: true}
+
+	if len(closed) != 1 || ohlcv(closed[0]) != want {
+		t.Fatalf("Got = %+v\nWant = %+v\n", closed, want)
+	}
+
+	// Trade before bar in progress is late.
+	_, late := agg.Record(marketId, Trade{Price: 1, Quantity: 1, At: start}, true, start.Add(time.Minute+time.Second))
+
+	if len(late) != 1 || late[0] != Minute {
+		t.Fatalf("Got = %v, Want = [%s]\n", late, Minute)
+	}
+
+	got, ok := agg.Current(marketId, Minute)
+	want = Candle{MarketId: marketId, Interval: Minute, Start: start.Add(time.Minute), Open: 95, High: 95, Low: 95, Close: 95, Volume: 2, TradeCount: 1}
+
+	if !ok || ohlcv(got) != want {
+		t.Fatalf("Got = %+v\nWant = %+v\n", got, want)
+	}
+
+	got, _ = agg.Current(marketId, FiveMinutes)
+
+	if got.TradeCount != 4 || got.Low != 1 {
+		t.Fatalf("Got = %+v, Want 4 trades with low 1\n", got)
+	}
+}
+
+func TestFlush(t *testing.T) {
+	var agg = NewAggregator()
+	record(agg, Trade{Price: 100, Quantity: 1, At: start})
+	updates, stop := agg.Subscribe(marketId, Minute)
+	defer stop()
+
+	closed := agg.Flush(start.Add(time.Minute))
+
+	if len(closed) != 1 || closed[0].Interval != Minute || !closed[0].Closed {
+		t.Fatalf("Got = %+v, Want closed minute bar\n", closed)
+	}
+
+	if got := <-updates; !got.Closed {
+		t.Fatalf("Got = %+v, Want closed bar\n", got)
+	}
+
+	if _, ok := agg.Current(marketId, Minute); ok {
+		t.Fatalf("Got bar in progress after flush\n")
+	}
+
+	// Flushed bar is not opened again by late trade.
+	if _, late := agg.Record(marketId, Trade{Price: 1, Quantity: 1, At: start}, true, start.Add(2*time.Minute)); len(late) != 1 {
+		t.Fatalf("Got = %v, Want = [%s]\n", late, Minute)
+	}
+
+	if _, ok := agg.Current(marketId, Minute); ok {
+		t.Fatalf("Got bar in progress after late trade\n")
+	}
+}
+
+func TestSubscribeCoalesce(t *testing.T) {
+	var agg = NewAggregator()
+	updates, stop := agg.Subscribe(marketId, Minute)
+
+	for i := range 3 {
+		record(agg, Trade{Price: int64(100 + i), Quantity: 1, At: start})
+	}
+
+	// First bar may be taken for send before next trades.
+	got := <-updates
+
+	if got.TradeCount != 3 {
+		got = <-updates
+	}
+
+	if got.TradeCount != 3 || got.Close != 102 {
+		t.Fatalf("Got = %+v, Want latest bar\n", got)
+	}
+
+	stop()
+
+	if _, ok := <-updates; ok {
+		t.Fatalf("Channel is not closed after stop\n")
+	}
+
+	agg.Close()
+	updates, _ = agg.Subscribe(marketId, Minute)
+
+	if _, ok := <-updates; ok {
+		t.Fatalf("Channel is not closed after close\n")
+	}
+}
+
+func TestSubscribeClosedBars(t *testing.T) {
+	var agg = NewAggregator()
+	updates, stop := agg.Subscribe(marketId, Minute)
+	defer stop()
+
+	// Nothing is received while bars are closed.
+	for i := range 5 {
+		record(agg, Trade{Price: 100, Quantity: 1, At: start.Add(time.Duration(i) * time.Minute)})
+	}
+
+	for i := range 4 {
+		got := <-updates
+
+		for !got.Closed {
+			got = <-updates
+		}
+
+		if want := start.Add(time.Duration(i) * time.Minute); !got.Start.Equal(want) {
+			t.Fatalf("Got = %v, Want closed bar of %v\n", got.Start, want)
+		}
+	}
+
+	if got := <-updates; got.Closed || !got.Start.Equal(start.Add(4*time.Minute)) {
+		t.Fatalf("Got = %+v, Want bar in progress\n", got)
+	}
+}
+
+func TestRestore(t *testing.T) {
+	var (
+		agg    = NewAggregator()
+		trades = []Trade{
+			{Price: 100, Quantity: 1, At: start},
+			{Price: 110, Quantity: 2, At: start.Add(10 * time.Second)},
+		}
+		unsaved = Trade{Price: 90, Quantity: 4, At: start.Add(20 * time.Second)}
+	)
+
+	for _, trade := range trades {
+		record(agg, trade)
+	}
+
+	agg.Record(marketId, unsaved, false, unsaved.At)
+	// Rebuilt from trade log, without unsaved trade.
+	agg.Restore(Build(marketId, Minute, trades, unsaved.At)[0])
+	got, _ := agg.Current(marketId, Minute)
+	want := Candle{MarketId: marketId, Interval: Minute, Start: start, Open: 100, High: 110, Low: 90, Close: 90, Volume: 7, TradeCount: 3}
+
+	if ohlcv(got) != want {
+		t.Fatalf("Got = %+v\nWant = %+v\n", ohlcv(got), want)
+	}
+}
@@ -0,0 +1,67 @@
+package candle
+
+import (
+	pb "github.com/KonnorFrik/BinaryTentacles/internal/generated/spot_instrument/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// IntervalFromProtobuf - convert pb.CandleInterval in Interval.
+// Unspecified interval converted in invalid Interval.
+func IntervalFromProtobuf(in pb.CandleInterval) Interval {
+	switch in {
+	case pb.CandleInterval_CANDLE_INTERVAL_1M:
+		return Minute
+	case pb.CandleInterval_CANDLE_INTERVAL_5M:
+		return FiveMinutes
+	case pb.CandleInterval_CANDLE_INTERVAL_1H:
+		return Hour
+	case pb.CandleInterval_CANDLE_INTERVAL_1D:
+		return Day
+	}
+
+	return 0
+}
+
+// IntervalToProtobuf - convert Interval in pb.CandleInterval.
+func IntervalToProtobuf(in Interval) pb.CandleInterval {
+	switch in {
+	case Minute:
+		return pb.CandleInterval_CANDLE_INTERVAL_1M
+	case FiveMinutes:
+		return pb.CandleInterval_CANDLE_INTERVAL_5M
+	case Hour:
+		return pb.CandleInterval_CANDLE_INTERVAL_1H
+	case Day:
+		return pb.CandleInterval_CANDLE_INTERVAL_1D
+	}
+
+	return pb.CandleInterval_CANDLE_INTERVAL_UNSPECIFIED
+}
+
+// ToProtobuf - convert Candle object in pb.Candle object.
+func ToProtobuf(in *Candle, out *pb.Candle) {
+	if in == nil || out == nil {
+		return
+	}
+
+	out.MarketId = in.MarketId
+	out.Interval = IntervalToProtobuf(in.Interval)
+	out.Start = timestamppb.New(in.Start)
+	out.Open = in.Open
+	out.High = in.High
+	out.Low = in.Low
+	out.Close = in.Close
+	out.Volume = in.Volume
+	out.TradeCount = in.TradeCount
+	out.Closed = in.Closed
+}
+
+// ToProtobufMany - convert many Candle objects in pb.Candle objects.
+func ToProtobufMany(in []Candle, out []*pb.Candle) {
+	maxInd := min(len(in), len(out))
+
+	for i := range maxInd {
+		out[i] = new(pb.Candle)
+		ToProtobuf(&in[i], out[i])
+	}
+}
@@ -0,0 +1,20 @@
+package usecase
+
+import "time"
+
+// Clock - source of current time for usecase logic.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+// Now - return current local time.
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// Now - return current time by clock of usecase logic.
+func (u *Usecase) Now() time.Time {
+	return u.clock.Now()
+}
@@ -10,10 +10,14 @@ var (
 	ErrInvalidInput = errors.New("invalid input")
 	// ErrForbidden - access denien for any reason.
 	ErrForbidden = errors.New("fordibbed")
+	// ErrAlreadyExists - object with same unique fields already exist.
+	ErrAlreadyExists = errors.New("already exists")
 )
 
 // This errors unsafe for show to user. Error message must be substituted.
 var (
 	// ErrInternal - error from any other package for any reason.
 	ErrInternal = errors.New("internal")
+	// ErrInvalidOption - invalid option of Usecase for any reason.
+	ErrInvalidOption = errors.New("invalid option")
 )
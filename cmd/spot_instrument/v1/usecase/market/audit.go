@@ -0,0 +1,116 @@
+package market
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Action - kind of change of market.
+type Action string
+
+const (
+	ActionCreate     Action = "create"
+	ActionUpdate     Action = "update"
+	ActionEnable     Action = "enable"
+	ActionDisable    Action = "disable"
+	ActionSoftDelete Action = "soft_delete"
+	ActionRestore    Action = "restore"
+	ActionHalt       Action = "halt"
+)
+
+// Change - one changed field of market.
+type Change struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// AuditRecord - who, when and what changed in market.
+type AuditRecord struct {
+	MarketId string    `json:"market_id"`
+	Actor    string    `json:"actor"`
+	Action   Action    `json:"action"`
+	At       time.Time `json:"at"`
+	Changes  []Change  `json:"changes"`
+}
+
+// Snapshot - copy of market fields which can be changed.
+// Used for compare market before and after change.
+type Snapshot struct {
+	Symbol            string
+	BaseAsset         string
+	QuoteAsset        string
+	PricePrecision    uint32
+	QuantityPrecision uint32
+	Enabled           bool
+	DeletedAt         time.Time
+	State             State
+	StateReason       string
+	HaltedUntil       time.Time
+	Schedule          string
+	LaunchAt          time.Time
+}
+
+// Snapshot - take a snapshot of market 'm'.
+func (m *Market) Snapshot() Snapshot {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+
+	var schedule string
+
+	if m.Schedule != nil {
+		scheduleBytes, _ := json.Marshal(m.Schedule)
+		schedule = string(scheduleBytes)
+	}
+
+	return Snapshot{
+		Symbol:            m.Symbol,
+		BaseAsset:         m.BaseAsset,
+		QuoteAsset:        m.QuoteAsset,
+		PricePrecision:    m.PricePrecision,
+		QuantityPrecision: m.QuantityPrecision,
+		Enabled:           m.Enabled,
+		DeletedAt:         m.DeletedAt,
+		State:             m.State,
+		StateReason:       m.StateReason,
+		HaltedUntil:       m.HaltedUntil,
+		Schedule:          schedule,
+		LaunchAt:          m.LaunchAt,
+	}
+}
+
+// Diff - return changed fields from 'old' to 'new'.
+func Diff(old, new Snapshot) []Change {
+	var changes []Change
+
+	add := func(field, o, n string) {
+		if o != n {
+			changes = append(changes, Change{Field: field, Old: o, New: n})
+		}
+	}
+
+	add("symbol", old.Symbol, new.Symbol)
+	add("base_asset", old.BaseAsset, new.BaseAsset)
+	add("quote_asset", old.QuoteAsset, new.QuoteAsset)
+	add("price_precision", fmt.Sprint(old.PricePrecision), fmt.Sprint(new.PricePrecision))
+	add("quantity_precision", fmt.Sprint(old.QuantityPrecision), fmt.Sprint(new.QuantityPrecision))
+	add("enabled", strconv.FormatBool(old.Enabled), strconv.FormatBool(new.Enabled))
+	add("deleted_at", formatTime(old.DeletedAt), formatTime(new.DeletedAt))
+	add("state", old.State.String(), new.State.String())
+	add("state_reason", old.StateReason, new.StateReason)
+	add("halted_until", formatTime(old.HaltedUntil), formatTime(new.HaltedUntil))
+	add("schedule", old.Schedule, new.Schedule)
+	add("launch_at", formatTime(old.LaunchAt), formatTime(new.LaunchAt))
+	return changes
+}
+
+// formatTime - format 't' for audit, zero time is empty.
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.Format(time.RFC3339)
+}
@@ -1,30 +1,228 @@
 package market
 
 import (
+	"time"
+
 	pb "github.com/KonnorFrik/BinaryTentacles/internal/generated/spot_instrument/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 // ToProtobuf - convert Market object in pb.Market object.
-func ToProtobuf(in *Market, out *pb.Market) {
+// State of market evaluated at 'now'.
+func ToProtobuf(in *Market, out *pb.Market, now time.Time) {
 	if in == nil || out == nil {
 		return
 	}
 
+	state, reason := in.TradingState(now)
+	out.State = StateToProtobuf(state)
+	out.StateReason = reason
+
 	in.mut.Lock()
 	out.Id = in.Id
+	out.Symbol = in.Symbol
+	out.BaseAsset = in.BaseAsset
+	out.QuoteAsset = in.QuoteAsset
+	out.PricePrecision = in.PricePrecision
+	out.QuantityPrecision = in.QuantityPrecision
+	out.CreatedAt = timestamppb.New(in.CreatedAt)
+	out.UpdatedAt = timestamppb.New(in.UpdatedAt)
+
+	if !in.LaunchAt.IsZero() {
+		out.LaunchAt = timestamppb.New(in.LaunchAt)
+	}
+
 	in.mut.Unlock()
 }
 
 // ToProtobufMany - convert many Market objects in pb.Market objects.
-func ToProtobufMany(in []*Market, out []*pb.Market) {
+func ToProtobufMany(in []*Market, out []*pb.Market, now time.Time) {
 	if in == nil {
 		return
 	}
 
-	maxInd := max(len(in), len(out))
+	maxInd := min(len(in), len(out))
 
 	for i := range maxInd {
 		out[i] = new(pb.Market)
-		ToProtobuf(in[i], out[i])
+		ToProtobuf(in[i], out[i], now)
+	}
+}
+
+// QueryFromProtobuf - convert filters of pb.ViewMarketsRequest in Query object.
+func QueryFromProtobuf(in *pb.ViewMarketsRequest) Query {
+	var query = Query{
+		BaseAsset:    in.GetBaseAsset(),
+		QuoteAsset:   in.GetQuoteAsset(),
+		SymbolPrefix: in.GetSymbolPrefix(),
+	}
+
+	switch in.GetStatus() {
+	case pb.MarketStatusFilter_MARKET_STATUS_FILTER_DISABLED:
+		query.Status = StatusDisabled
+	case pb.MarketStatusFilter_MARKET_STATUS_FILTER_DELETED:
+		query.Status = StatusDeleted
+	case pb.MarketStatusFilter_MARKET_STATUS_FILTER_ALL:
+		query.Status = StatusAll
+	default:
+		query.Status = StatusEnabled
+	}
+
+	switch in.GetSort() {
+	case pb.MarketSortOrder_MARKET_SORT_ORDER_SYMBOL_DESC:
+		query.Sort = SortSymbolDesc
+	case pb.MarketSortOrder_MARKET_SORT_ORDER_CREATED_AT_ASC:
+		query.Sort = SortCreatedAtAsc
+	case pb.MarketSortOrder_MARKET_SORT_ORDER_CREATED_AT_DESC:
+		query.Sort = SortCreatedAtDesc
+	default:
+		query.Sort = SortSymbolAsc
+	}
+
+	return query
+}
+
+// TimeFromProtobuf - convert pb timestamp in time.Time. Nil is zero time.
+func TimeFromProtobuf(in *timestamppb.Timestamp) time.Time {
+	if in == nil {
+		return time.Time{}
+	}
+
+	return in.AsTime()
+}
+
+// StateToProtobuf - convert State in pb.MarketState.
+func StateToProtobuf(state State) pb.MarketState {
+	switch state {
+	case StateActive:
+		return pb.MarketState_MARKET_STATE_ACTIVE
+	case StateHalted:
+		return pb.MarketState_MARKET_STATE_HALTED
+	case StateAuctionOnly:
+		return pb.MarketState_MARKET_STATE_AUCTION_ONLY
+	case StateCancelOnly:
+		return pb.MarketState_MARKET_STATE_CANCEL_ONLY
+	case StateClosed:
+		return pb.MarketState_MARKET_STATE_CLOSED
+	default:
+		return pb.MarketState_MARKET_STATE_UNSPECIFIED
+	}
+}
+
+// ActionToEventProtobuf - convert Action in pb.MarketEventType.
+func ActionToEventProtobuf(action Action) pb.MarketEventType {
+	switch action {
+	case ActionCreate:
+		return pb.MarketEventType_MARKET_EVENT_TYPE_CREATED
+	case ActionUpdate:
+		return pb.MarketEventType_MARKET_EVENT_TYPE_UPDATED
+	case ActionHalt:
+		return pb.MarketEventType_MARKET_EVENT_TYPE_HALTED
+	case ActionEnable:
+		return pb.MarketEventType_MARKET_EVENT_TYPE_ENABLED
+	case ActionDisable:
+		return pb.MarketEventType_MARKET_EVENT_TYPE_DISABLED
+	case ActionSoftDelete:
+		return pb.MarketEventType_MARKET_EVENT_TYPE_DELETED
+	case ActionRestore:
+		return pb.MarketEventType_MARKET_EVENT_TYPE_RESTORED
+	default:
+		return pb.MarketEventType_MARKET_EVENT_TYPE_UNSPECIFIED
+	}
+}
+
+// ScheduleViewToProtobuf - convert ScheduleView object in pb.GetTradingScheduleResponse object.
+func ScheduleViewToProtobuf(in *ScheduleView, out *pb.GetTradingScheduleResponse) {
+	if in == nil || out == nil {
+		return
+	}
+
+	out.MarketId = in.MarketId
+	out.TimeZone = in.Schedule.TimeZone
+	out.Holidays = in.Schedule.Holidays
+	out.State = StateToProtobuf(in.State)
+	out.Reason = in.Reason
+	out.ServerTime = timestamppb.New(in.At)
+	out.Sessions = make([]*pb.TradingSession, len(in.Schedule.Sessions))
+
+	for i, session := range in.Schedule.Sessions {
+		out.Sessions[i] = &pb.TradingSession{
+			Weekday: int32(session.Weekday),
+			Open:    session.Open,
+			Close:   session.Close,
+		}
+	}
+
+	out.Upcoming = make([]*pb.SessionInterval, len(in.Upcoming))
+
+	for i, interval := range in.Upcoming {
+		out.Upcoming[i] = &pb.SessionInterval{
+			Open:  timestamppb.New(interval.Open),
+			Close: timestamppb.New(interval.Close),
+		}
+	}
+}
+
+// ScheduleFromProtobuf - convert pb.TradingSchedule object in Schedule object.
+// Returns nil for nil 'in'.
+func ScheduleFromProtobuf(in *pb.TradingSchedule) *Schedule {
+	if in == nil {
+		return nil
+	}
+
+	var out = Schedule{
+		TimeZone: in.GetTimeZone(),
+		Holidays: in.GetHolidays(),
+		Sessions: make([]Session, len(in.GetSessions())),
+	}
+
+	for i, session := range in.GetSessions() {
+		out.Sessions[i] = Session{
+			Weekday: time.Weekday(session.GetWeekday()),
+			Open:    session.GetOpen(),
+			Close:   session.GetClose(),
+		}
+	}
+
+	return &out
+}
+
+// StateFromProtobuf - convert pb.MarketState in State.
+// Returns false for unknown state.
+func StateFromProtobuf(state pb.MarketState) (State, bool) {
+	switch state {
+	case pb.MarketState_MARKET_STATE_ACTIVE:
+		return StateActive, true
+	case pb.MarketState_MARKET_STATE_HALTED:
+		return StateHalted, true
+	case pb.MarketState_MARKET_STATE_AUCTION_ONLY:
+		return StateAuctionOnly, true
+	case pb.MarketState_MARKET_STATE_CANCEL_ONLY:
+		return StateCancelOnly, true
+	case pb.MarketState_MARKET_STATE_CLOSED:
+		return StateClosed, true
+	default:
+		return StateActive, false
+	}
+}
+
+// AuditToProtobuf - convert AuditRecord object in pb.MarketAuditRecord object.
+func AuditToProtobuf(in *AuditRecord, out *pb.MarketAuditRecord) {
+	if in == nil || out == nil {
+		return
+	}
+
+	out.MarketId = in.MarketId
+	out.Actor = in.Actor
+	out.Action = string(in.Action)
+	out.At = timestamppb.New(in.At)
+	out.Changes = make([]*pb.MarketFieldChange, len(in.Changes))
+
+	for i, change := range in.Changes {
+		out.Changes[i] = &pb.MarketFieldChange{
+			Field:    change.Field,
+			OldValue: change.Old,
+			NewValue: change.New,
+		}
 	}
 }
@@ -2,6 +2,7 @@ package market
 
 import (
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 )
@@ -12,20 +13,147 @@ type Market struct {
 	Id        string    `json:"id"`
 	Enabled   bool      `json:"enabled"`
 	DeletedAt time.Time `json:"deleted_at"`
+
+	// Symbol - "<BaseAsset>-<QuoteAsset>", e.g. "BTC-USDT".
+	Symbol     string `json:"symbol"`
+	BaseAsset  string `json:"base_asset"`
+	QuoteAsset string `json:"quote_asset"`
+	// PricePrecision - count of decimal places in integer price.
+	// Price 12345 with precision 2 is 123.45.
+	PricePrecision uint32 `json:"price_precision"`
+	// QuantityPrecision - count of decimal places in integer quantity.
+	QuantityPrecision uint32 `json:"quantity_precision"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	State       State     `json:"state"`
+	StateReason string    `json:"state_reason"`
+	HaltedUntil time.Time `json:"halted_until"`
+
+	Schedule *Schedule `json:"schedule,omitempty"`
+	// LaunchAt - market is pre-launch before this time. Zero if launched.
+	LaunchAt time.Time `json:"launch_at"`
+}
+
+// NewSymbol - build a market symbol from assets.
+func NewSymbol(base, quote string) string {
+	return NormalizeSymbol(base + "-" + quote)
+}
+
+// NormalizeSymbol - bring symbol 's' to canonical form.
+func NormalizeSymbol(s string) string {
+	return strings.ToUpper(strings.TrimSpace(s))
 }
 
 // IsActive - check is market active.
-// Must be enabled and not deleted.
+// Must be enabled, not deleted and in StateActive.
 func (m *Market) IsActive() bool {
-	var emptyTime time.Time
+	state, _ := m.TradingState(time.Now())
+	return state == StateActive
+}
+
+// TradingState - return state of market 'm' at time 'now' with reason.
+// Disabled and deleted markets always closed.
+// Market outside of it schedule is closed.
+// Halt with expired 'HaltedUntil' is treated as active.
+func (m *Market) TradingState(now time.Time) (State, string) {
 	m.mut.Lock()
 	defer m.mut.Unlock()
-	return m.Enabled && m.DeletedAt == emptyTime
+
+	switch {
+	case !m.DeletedAt.IsZero():
+		return StateClosed, "market is deleted"
+	case !m.Enabled:
+		return StateClosed, "market is disabled"
+	}
+
+	if open, reason := m.Schedule.IsOpen(now); !open {
+		return StateClosed, reason
+	}
+
+	if m.State == StateHalted && !m.HaltedUntil.IsZero() && now.After(m.HaltedUntil) {
+		return StateActive, ""
+	}
+
+	return m.State, m.StateReason
+}
+
+// ScheduleView - trading calendar of market at some moment.
+type ScheduleView struct {
+	MarketId string
+	Schedule Schedule
+	State    State
+	Reason   string
+	// Upcoming - sessions from 'At', include session in progress.
+	Upcoming []Interval
+	At       time.Time
+}
+
+// ScheduleAt - return trading calendar of market 'm' at 'now'
+// with upcoming sessions for 'days' days.
+func (m *Market) ScheduleAt(now time.Time, days int) ScheduleView {
+	state, reason := m.TradingState(now)
+	view := ScheduleView{
+		MarketId: m.Id,
+		State:    state,
+		Reason:   reason,
+		At:       now,
+	}
+
+	m.mut.Lock()
+	defer m.mut.Unlock()
+
+	if m.Schedule != nil {
+		view.Schedule = *m.Schedule
+		view.Upcoming = m.Schedule.Upcoming(now, days)
+	}
+
+	return view
+}
+
+// Stub - return market with only id, symbol, assets and deletion time of market 'm'.
+// Stub is disabled, for tell about market without show it.
+func (m *Market) Stub() *Market {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	return &Market{
+		Id:         m.Id,
+		Symbol:     m.Symbol,
+		BaseAsset:  m.BaseAsset,
+		QuoteAsset: m.QuoteAsset,
+		DeletedAt:  m.DeletedAt,
+	}
+}
+
+// Halt - move market 'm' in StateHalted until 'until' with 'reason'.
+// Zero 'until' means halt without auto-resume.
+func (m *Market) Halt(until time.Time, reason string) {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	m.State = StateHalted
+	m.StateReason = reason
+	m.HaltedUntil = until
+}
+
+// Touch - mark market 'm' as updated at 'at'.
+func (m *Market) Touch(at time.Time) {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+
+	if m.CreatedAt.IsZero() {
+		m.CreatedAt = at
+	}
+
+	m.UpdatedAt = at
 }
 
 // String - implement Stringer interface.
 func (m *Market) String() string {
 	m.mut.Lock()
 	defer m.mut.Unlock()
-	return fmt.Sprintf("Market(ID:%s, Enabled:%t, DeletedAt:%v", m.Id, m.Enabled, m.DeletedAt)
+	return fmt.Sprintf(
+		"Market(ID:%s, Symbol:%s, Enabled:%t, DeletedAt:%v, State:%s)",
+		m.Id, m.Symbol, m.Enabled, m.DeletedAt, m.State,
+	)
 }
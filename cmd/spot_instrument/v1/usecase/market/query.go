@@ -0,0 +1,176 @@
+package market
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// StatusFilter - which markets select by lifecycle status.
+type StatusFilter int
+
+const (
+	// StatusEnabled - enabled and not deleted markets.
+	StatusEnabled StatusFilter = iota
+	// StatusDisabled - disabled and not deleted markets.
+	StatusDisabled
+	// StatusDeleted - soft deleted markets.
+	StatusDeleted
+	// StatusAll - any market.
+	StatusAll
+)
+
+// SortOrder - order of selected markets.
+type SortOrder int
+
+const (
+	SortSymbolAsc SortOrder = iota
+	SortSymbolDesc
+	SortCreatedAtAsc
+	SortCreatedAtDesc
+)
+
+// Query - filters and order for select markets.
+// Empty string filters match any market.
+type Query struct {
+	Status       StatusFilter
+	BaseAsset    string
+	QuoteAsset   string
+	SymbolPrefix string
+	Sort         SortOrder
+}
+
+// Cursor - sort keys of market, position of page in selected markets.
+type Cursor struct {
+	Symbol    string    `json:"symbol"`
+	CreatedAt time.Time `json:"created_at"`
+	Id        string    `json:"id"`
+}
+
+// Status - return lifecycle status of market 'm': StatusEnabled, StatusDisabled or StatusDeleted.
+func (m *Market) Status() StatusFilter {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+
+	switch {
+	case !m.DeletedAt.IsZero():
+		return StatusDeleted
+	case !m.Enabled:
+		return StatusDisabled
+	}
+
+	return StatusEnabled
+}
+
+// Cursor - return sort keys of market 'm'.
+func (m *Market) Cursor() Cursor {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	return Cursor{Symbol: m.Symbol, CreatedAt: m.CreatedAt, Id: m.Id}
+}
+
+// Match - check is market 'm' selected by query 'q'.
+func (q *Query) Match(m *Market) bool {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+
+	switch deleted := !m.DeletedAt.IsZero(); q.Status {
+	case StatusEnabled:
+		if deleted || !m.Enabled {
+			return false
+		}
+	case StatusDisabled:
+		if deleted || m.Enabled {
+			return false
+		}
+	case StatusDeleted:
+		if !deleted {
+			return false
+		}
+	}
+
+	if q.BaseAsset != "" && m.BaseAsset != NormalizeSymbol(q.BaseAsset) {
+		return false
+	}
+
+	if q.QuoteAsset != "" && m.QuoteAsset != NormalizeSymbol(q.QuoteAsset) {
+		return false
+	}
+
+	return strings.HasPrefix(m.Symbol, NormalizeSymbol(q.SymbolPrefix))
+}
+
+// Select - return markets matched by query 'q' in order of query.
+// Markets with same sort key ordered by id, so order is stable between calls.
+func (q *Query) Select(markets []*Market) []*Market {
+	var selected = make([]*Market, 0, len(markets))
+
+	for _, m := range markets {
+		if q.Match(m) {
+			selected = append(selected, m)
+		}
+	}
+
+	slices.SortFunc(selected, func(a, b *Market) int {
+		return q.Compare(a.Cursor(), b.Cursor())
+	})
+
+	return selected
+}
+
+// Page - return up to 'size' markets of 'selected' after 'after', nil is first page.
+// 'selected' must be in order of query 'q', see Select.
+// Returns cursor of last market in page, nil on last page.
+// Page is found by sort keys, so markets added or removed before it don't shift it.
+func (q *Query) Page(
+	selected []*Market,
+	after *Cursor,
+	size int,
+) (
+	[]*Market,
+	*Cursor,
+) {
+	var start int
+
+	if after != nil {
+		start, _ = slices.BinarySearchFunc(selected, *after, func(m *Market, c Cursor) int {
+			if q.Compare(m.Cursor(), c) <= 0 {
+				return -1
+			}
+
+			return 1
+		})
+	}
+
+	end := start + size
+
+	if end >= len(selected) {
+		return selected[start:], nil
+	}
+
+	last := selected[end-1].Cursor()
+	return selected[start:end], &last
+}
+
+// Compare - compare positions of markets with sort keys 'a' and 'b' in order of query 'q'.
+// Markets with same sort key ordered by id.
+func (q *Query) Compare(a, b Cursor) int {
+	var cmp int
+
+	switch q.Sort {
+	case SortSymbolDesc:
+		cmp = strings.Compare(b.Symbol, a.Symbol)
+	case SortCreatedAtAsc:
+		cmp = a.CreatedAt.Compare(b.CreatedAt)
+	case SortCreatedAtDesc:
+		cmp = b.CreatedAt.Compare(a.CreatedAt)
+	default:
+		cmp = strings.Compare(a.Symbol, b.Symbol)
+	}
+
+	if cmp == 0 {
+		cmp = strings.Compare(a.Id, b.Id)
+	}
+
+	return cmp
+}
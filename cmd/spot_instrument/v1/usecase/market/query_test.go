@@ -0,0 +1,96 @@
+package market
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQuerySelect(t *testing.T) {
+	var (
+		day     = time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+		btc     = &Market{Id: "1", Symbol: "BTC-USDT", BaseAsset: "BTC", QuoteAsset: "USDT", Enabled: true, CreatedAt: day}
+		eth     = &Market{Id: "2", Symbol: "ETH-USDT", BaseAsset: "ETH", QuoteAsset: "USDT", Enabled: true, CreatedAt: day.Add(time.Hour)}
+		ethBtc  = &Market{Id: "3", Symbol: "ETH-BTC", BaseAsset: "ETH", QuoteAsset: "BTC", CreatedAt: day.Add(2 * time.Hour)}
+		sol     = &Market{Id: "4", Symbol: "SOL-USDT", BaseAsset: "SOL", QuoteAsset: "USDT", Enabled: true, DeletedAt: day, CreatedAt: day}
+		markets = []*Market{sol, ethBtc, eth, btc}
+		idsOf   = func(ms []*Market) []string {
+			var ids []string
+
+			for _, m := range ms {
+				ids = append(ids, m.Id)
+			}
+
+			return ids
+		}
+	)
+
+	cases := []struct {
+		name  string
+		query Query
+		want  []string
+	}{
+		{name: "Default", query: Query{}, want: []string{"1", "2"}},
+		{name: "Disabled", query: Query{Status: StatusDisabled}, want: []string{"3"}},
+		{name: "Deleted", query: Query{Status: StatusDeleted}, want: []string{"4"}},
+		{name: "All by symbol desc", query: Query{Status: StatusAll, Sort: SortSymbolDesc}, want: []string{"4", "2", "3", "1"}},
+		{name: "Created at desc", query: Query{Status: StatusAll, Sort: SortCreatedAtDesc}, want: []string{"3", "2", "1", "4"}},
+		{name: "Base asset", query: Query{Status: StatusAll, BaseAsset: "eth"}, want: []string{"3", "2"}},
+		{name: "Quote asset", query: Query{QuoteAsset: " usdt "}, want: []string{"1", "2"}},
+		{name: "Symbol prefix", query: Query{Status: StatusAll, SymbolPrefix: "eth-u"}, want: []string{"2"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := idsOf(c.query.Select(markets))
+
+			if len(got) != len(c.want) {
+				t.Fatalf("Got = %v, Want = %v\n", got, c.want)
+			}
+
+			for i := range got {
+				if got[i] != c.want[i] {
+					t.Fatalf("Got = %v, Want = %v\n", got, c.want)
+				}
+			}
+		})
+	}
+}
+
+func TestQueryPage(t *testing.T) {
+	var (
+		query   = Query{Status: StatusAll}
+		markets []*Market
+	)
+
+	for _, symbol := range []string{"A-X", "C-X", "E-X", "G-X"} {
+		markets = append(markets, &Market{Id: symbol, Symbol: symbol})
+	}
+
+	first, after := query.Page(query.Select(markets), nil, 2)
+
+	if len(first) != 2 || first[1].Symbol != "C-X" || after == nil || after.Symbol != "C-X" {
+		t.Fatalf("Got = %d markets, cursor %v\n", len(first), after)
+	}
+
+	// Markets added and removed before cursor don't shift next page.
+	markets = append(markets[1:], &Market{Id: "B-X", Symbol: "B-X"}, &Market{Id: "D-X", Symbol: "D-X"})
+	second, after := query.Page(query.Select(markets), after, 2)
+
+	if len(second) != 2 || second[0].Symbol != "D-X" || second[1].Symbol != "E-X" || after == nil {
+		t.Fatalf("Got = %v, cursor %v\n", second, after)
+	}
+
+	last, after := query.Page(query.Select(markets), after, 2)
+
+	if len(last) != 1 || last[0].Symbol != "G-X" || after != nil {
+		t.Fatalf("Got = %v, cursor %v, Want = last page\n", last, after)
+	}
+
+	// Removed market of cursor.
+	descending := Query{Status: StatusAll, Sort: SortSymbolDesc}
+	page, _ := descending.Page(descending.Select(markets), &Cursor{Symbol: "F-X", Id: "F-X"}, 10)
+
+	if len(page) != 4 || page[0].Symbol != "E-X" {
+		t.Fatalf("Got = %v, Want = from E-X\n", page)
+	}
+}
@@ -0,0 +1,210 @@
+package market
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+const (
+	// SessionTimeLayout - layout of Session.Open and Session.Close.
+	SessionTimeLayout = "15:04"
+	// HolidayLayout - layout of Schedule.Holidays.
+	HolidayLayout = time.DateOnly
+)
+
+var (
+	// ErrInvalidSchedule - schedule can't be used for any reason.
+	ErrInvalidSchedule = errors.New("invalid schedule")
+)
+
+// Session - trading hours of one weekday in schedule time zone.
+// Session can't cross midnight, split it in two sessions instead.
+type Session struct {
+	Weekday time.Weekday `json:"weekday"`
+	// Open - time of day in SessionTimeLayout, inclusive.
+	Open string `json:"open"`
+	// Close - time of day in SessionTimeLayout, exclusive.
+	Close string `json:"close"`
+}
+
+// Schedule - trading calendar of market.
+// Schedule without sessions means market trade around the clock.
+type Schedule struct {
+	// TimeZone - IANA time zone name. Empty means UTC.
+	TimeZone string    `json:"time_zone"`
+	Sessions []Session `json:"sessions"`
+	// Holidays - dates in HolidayLayout when market is closed all day.
+	Holidays []string `json:"holidays"`
+}
+
+// Interval - concrete trading session [Open, Close).
+type Interval struct {
+	Open  time.Time
+	Close time.Time
+}
+
+// Validate - check is schedule 's' usable.
+func (s *Schedule) Validate() error {
+	if _, err := s.location(); err != nil {
+		return err
+	}
+
+	for i, session := range s.Sessions {
+		if session.Weekday < time.Sunday || session.Weekday > time.Saturday {
+			return fmt.Errorf("%w: session %d: bad weekday %d", ErrInvalidSchedule, i, session.Weekday)
+		}
+
+		open, close, err := session.bounds()
+
+		if err != nil {
+			return fmt.Errorf("%w: session %d: %w", ErrInvalidSchedule, i, err)
+		}
+
+		if close <= open {
+			return fmt.Errorf("%w: session %d: close must be after open", ErrInvalidSchedule, i)
+		}
+	}
+
+	for _, day := range s.Holidays {
+		if _, err := time.Parse(HolidayLayout, day); err != nil {
+			return fmt.Errorf("%w: holiday %q: %w", ErrInvalidSchedule, day, err)
+		}
+	}
+
+	return nil
+}
+
+// IsOpen - check is market open at 'now' by schedule 's'.
+// Returns reason if closed.
+func (s *Schedule) IsOpen(now time.Time) (bool, string) {
+	if s == nil || len(s.Sessions) == 0 {
+		return true, ""
+	}
+
+	loc, err := s.location()
+
+	if err != nil {
+		return false, err.Error()
+	}
+
+	now = now.In(loc)
+
+	if slices.Contains(s.Holidays, now.Format(HolidayLayout)) {
+		return false, "market holiday"
+	}
+
+	for _, interval := range s.day(now) {
+		if !now.Before(interval.Open) && now.Before(interval.Close) {
+			return true, ""
+		}
+	}
+
+	return false, "outside trading hours"
+}
+
+// Upcoming - return concrete sessions within 'days' days from 'now'.
+// Session in progress at 'now' is included.
+// Result sorted by open time.
+func (s *Schedule) Upcoming(now time.Time, days int) []Interval {
+	if s == nil || len(s.Sessions) == 0 {
+		return nil
+	}
+
+	loc, err := s.location()
+
+	if err != nil {
+		return nil
+	}
+
+	var result []Interval
+	now = now.In(loc)
+
+	for i := range days {
+		day := now.AddDate(0, 0, i)
+
+		if slices.Contains(s.Holidays, day.Format(HolidayLayout)) {
+			continue
+		}
+
+		for _, interval := range s.day(day) {
+			if interval.Close.After(now) {
+				result = append(result, interval)
+			}
+		}
+	}
+
+	slices.SortFunc(result, func(a, b Interval) int {
+		return a.Open.Compare(b.Open)
+	})
+	return result
+}
+
+// day - return concrete sessions at the date of 'at'.
+func (s *Schedule) day(at time.Time) []Interval {
+	var result []Interval
+
+	for _, session := range s.Sessions {
+		if session.Weekday != at.Weekday() {
+			continue
+		}
+
+		open, close, err := session.bounds()
+
+		if err != nil {
+			continue
+		}
+
+		result = append(result, Interval{
+			Open:  wallClock(at, open),
+			Close: wallClock(at, close),
+		})
+	}
+
+	return result
+}
+
+// wallClock - time at the date of 'at' with 'offset' from midnight by wall clock.
+// Keep a session hours stable across daylight saving changes.
+func wallClock(at time.Time, offset time.Duration) time.Time {
+	return time.Date(
+		at.Year(), at.Month(), at.Day(),
+		0, int(offset/time.Minute), 0, 0,
+		at.Location(),
+	)
+}
+
+// location - load time zone of schedule 's'.
+func (s *Schedule) location() (*time.Location, error) {
+	loc, err := time.LoadLocation(s.TimeZone)
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: time zone %q: %w", ErrInvalidSchedule, s.TimeZone, err)
+	}
+
+	return loc, nil
+}
+
+// bounds - parse open and close of session 's' as offsets from midnight.
+func (s Session) bounds() (time.Duration, time.Duration, error) {
+	open, err := time.Parse(SessionTimeLayout, s.Open)
+
+	if err != nil {
+		return 0, 0, fmt.Errorf("open: %w", err)
+	}
+
+	close, err := time.Parse(SessionTimeLayout, s.Close)
+
+	if err != nil {
+		return 0, 0, fmt.Errorf("close: %w", err)
+	}
+
+	// "24:00" isn't parsable, treat "00:00" close as end of day.
+	if s.Close == "00:00" {
+		close = close.Add(24 * time.Hour)
+	}
+
+	zero, _ := time.Parse(SessionTimeLayout, "00:00")
+	return open.Sub(zero), close.Sub(zero), nil
+}
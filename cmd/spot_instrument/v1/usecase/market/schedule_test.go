@@ -0,0 +1,126 @@
+package market
+
+import (
+	"testing"
+	"time"
+)
+
+// weekdays - schedule of Mon-Fri 09:30-16:00 in New York.
+var weekdays = Schedule{
+	TimeZone: "America/New_York",
+	Sessions: []Session{
+		{Weekday: time.Monday, Open: "09:30", Close: "16:00"},
+		{Weekday: time.Tuesday, Open: "09:30", Close: "16:00"},
+		{Weekday: time.Wednesday, Open: "09:30", Close: "16:00"},
+		{Weekday: time.Thursday, Open: "09:30", Close: "16:00"},
+		{Weekday: time.Friday, Open: "09:30", Close: "16:00"},
+	},
+	Holidays: []string{"2025-07-04"},
+}
+
+func TestScheduleIsOpen(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	cases := []struct {
+		name     string
+		schedule *Schedule
+		now      time.Time
+		want     bool
+	}{
+		{
+			name:     "No schedule",
+			schedule: nil,
+			now:      time.Date(2025, 7, 5, 3, 0, 0, 0, time.UTC),
+			want:     true,
+		},
+		{
+			name:     "Inside session",
+			schedule: &weekdays,
+			now:      time.Date(2025, 7, 7, 10, 0, 0, 0, newYork),
+			want:     true,
+		},
+		{
+			name:     "Open is inclusive",
+			schedule: &weekdays,
+			now:      time.Date(2025, 7, 7, 9, 30, 0, 0, newYork),
+			want:     true,
+		},
+		{
+			name:     "Close is exclusive",
+			schedule: &weekdays,
+			now:      time.Date(2025, 7, 7, 16, 0, 0, 0, newYork),
+			want:     false,
+		},
+		{
+			name:     "Other time zone of caller",
+			schedule: &weekdays,
+			now:      time.Date(2025, 7, 7, 14, 0, 0, 0, time.UTC),
+			want:     true,
+		},
+		{
+			name:     "Weekend",
+			schedule: &weekdays,
+			now:      time.Date(2025, 7, 6, 10, 0, 0, 0, newYork),
+			want:     false,
+		},
+		{
+			name:     "Holiday",
+			schedule: &weekdays,
+			now:      time.Date(2025, 7, 4, 10, 0, 0, 0, newYork),
+			want:     false,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			got, reason := tt.schedule.IsOpen(tt.now)
+
+			if got != tt.want {
+				t.Fatalf("Got = %t (%s), Want = %t\n", got, reason, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduleUpcoming(t *testing.T) {
+	// Thursday before the holiday, after close.
+	now := time.Date(2025, 7, 3, 20, 0, 0, 0, time.UTC)
+	got := weekdays.Upcoming(now, 7)
+
+	if len(got) != 3 {
+		t.Fatalf("Got = %d sessions, Want = 3\n", len(got))
+	}
+
+	if got[0].Open.Weekday() != time.Monday {
+		t.Fatalf("Got = %s, Want = %s\n", got[0].Open.Weekday(), time.Monday)
+	}
+}
+
+func TestScheduleValidate(t *testing.T) {
+	bad := Schedule{
+		Sessions: []Session{
+			{Weekday: time.Monday, Open: "16:00", Close: "09:30"},
+		},
+	}
+
+	if err := bad.Validate(); err == nil {
+		t.Fatalf("Got nil error")
+	}
+
+	if err := weekdays.Validate(); err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+}
+
+func TestMarketTradingStateBySchedule(t *testing.T) {
+	mark := Market{Enabled: true, Schedule: &weekdays}
+	state, _ := mark.TradingState(time.Date(2025, 7, 6, 15, 0, 0, 0, time.UTC))
+
+	if state != StateClosed {
+		t.Fatalf("Got = %s, Want = %s\n", state, StateClosed)
+	}
+}
@@ -0,0 +1,64 @@
+package market
+
+import "time"
+
+// Stage - lifecycle stage of market, used for decide who can see and trade it.
+type Stage int
+
+const (
+	// StageListed - enabled and launched market.
+	StageListed Stage = iota
+	// StagePreLaunch - enabled market with launch time in future.
+	StagePreLaunch
+	// StageDisabled - disabled market.
+	StageDisabled
+	// StageDeleted - soft deleted market.
+	StageDeleted
+)
+
+// String - implement Stringer interface.
+func (s Stage) String() string {
+	switch s {
+	case StageListed:
+		return "listed"
+	case StagePreLaunch:
+		return "pre-launch"
+	case StageDisabled:
+		return "disabled"
+	case StageDeleted:
+		return "deleted"
+	default:
+		return "unknown"
+	}
+}
+
+// StageAt - return lifecycle stage of market 'm' at time 'now'.
+func (m *Market) StageAt(now time.Time) Stage {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	return stageOf(m.DeletedAt, m.Enabled, m.LaunchAt, now)
+}
+
+// StageAt - return lifecycle stage of market in snapshot 's' at time 'now'.
+func (s Snapshot) StageAt(now time.Time) Stage {
+	return stageOf(s.DeletedAt, s.Enabled, s.LaunchAt, now)
+}
+
+// stageOf - return lifecycle stage of market with given fields at time 'now'.
+func stageOf(
+	deletedAt time.Time,
+	enabled bool,
+	launchAt time.Time,
+	now time.Time,
+) Stage {
+	switch {
+	case !deletedAt.IsZero():
+		return StageDeleted
+	case !enabled:
+		return StageDisabled
+	case now.Before(launchAt):
+		return StagePreLaunch
+	default:
+		return StageListed
+	}
+}
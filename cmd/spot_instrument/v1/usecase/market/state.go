@@ -0,0 +1,57 @@
+package market
+
+// State - trading state of market.
+type State int
+
+const (
+	// StateActive - market accept any orders.
+	// Zero value, so markets stored without state stay active.
+	StateActive State = iota
+	// StateHalted - trading is stopped, only cancels allowed.
+	StateHalted
+	// StateAuctionOnly - market accept orders only for auction.
+	// OrderService has no auction matching and trades in it as in StateActive.
+	StateAuctionOnly
+	// StateCancelOnly - market accept only cancels.
+	StateCancelOnly
+	// StateClosed - market is closed for any activity.
+	StateClosed
+)
+
+// String - implement Stringer interface.
+func (s State) String() string {
+	switch s {
+	case StateActive:
+		return "active"
+	case StateHalted:
+		return "halted"
+	case StateAuctionOnly:
+		return "auction only"
+	case StateCancelOnly:
+		return "cancel only"
+	case StateClosed:
+		return "closed"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseState - parse state from it config name:
+// "active", "halted", "auction_only", "cancel_only", "closed".
+// Empty name is StateActive.
+func ParseState(name string) (State, bool) {
+	switch name {
+	case "", "active":
+		return StateActive, true
+	case "halted":
+		return StateHalted, true
+	case "auction_only":
+		return StateAuctionOnly, true
+	case "cancel_only":
+		return StateCancelOnly, true
+	case "closed":
+		return StateClosed, true
+	default:
+		return StateActive, false
+	}
+}
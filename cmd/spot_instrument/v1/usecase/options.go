@@ -0,0 +1,59 @@
+package usecase
+
+import (
+	"fmt"
+
+	"github.com/KonnorFrik/BinaryTentacles/cmd/spot_instrument/v1/usecase/breaker"
+	redCache "github.com/KonnorFrik/BinaryTentacles/pkg/cache/redis"
+	"github.com/KonnorFrik/BinaryTentacles/pkg/eventbus"
+)
+
+// Option - option for customize Usecase at creation.
+type Option func(*Usecase) error
+
+// WithMarketDataCache - store trades and closed candles in redis 'c'.
+// Without it candles are lost on restart and can't be backfilled.
+func WithMarketDataCache(c *redCache.Cache) Option {
+	return func(u *Usecase) error {
+		if c == nil {
+			return fmt.Errorf("%w: market data cache cannot be nil", ErrInvalidOption)
+		}
+
+		u.marketDataCache = c
+		return nil
+	}
+}
+
+// WithEventBus - publish changes of markets to 'b', topic MarketChangesTopic.
+// Without it changes are sent only to watchers of markets in this instance.
+func WithEventBus(b eventbus.Bus) Option {
+	return func(u *Usecase) error {
+		if b == nil {
+			return fmt.Errorf("%w: event bus cannot be nil", ErrInvalidOption)
+		}
+
+		u.bus = b
+		return nil
+	}
+}
+
+// WithPriceBreaker - halt markets with circuit breaker with 'config'.
+// Without it prices never halt a market.
+func WithPriceBreaker(config breaker.Config) Option {
+	return func(u *Usecase) error {
+		u.priceBreaker = breaker.New(config)
+		return nil
+	}
+}
+
+// WithClock - use 'c' as source of current time instead of system clock.
+func WithClock(c Clock) Option {
+	return func(u *Usecase) error {
+		if c == nil {
+			return fmt.Errorf("%w: clock cannot be nil", ErrInvalidOption)
+		}
+
+		u.clock = c
+		return nil
+	}
+}
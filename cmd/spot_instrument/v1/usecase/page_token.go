@@ -0,0 +1,51 @@
+package usecase
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/KonnorFrik/BinaryTentacles/cmd/spot_instrument/v1/usecase/market"
+)
+
+// pageTokenPrefix - prefix of encoded page token, for detect foreign tokens.
+const pageTokenPrefix = "after:"
+
+// pageToken - position of page in markets sorted by 'Sort'.
+type pageToken struct {
+	Sort  market.SortOrder `json:"sort"`
+	After market.Cursor    `json:"after"`
+}
+
+// encodePageToken - make opaque page token for page after market with sort keys 'after'.
+func encodePageToken(sort market.SortOrder, after market.Cursor) string {
+	tokenBytes, _ := json.Marshal(pageToken{Sort: sort, After: after})
+	return base64.RawURLEncoding.EncodeToString(append([]byte(pageTokenPrefix), tokenBytes...))
+}
+
+// decodePageToken - return position of page from 'token' of markets sorted by 'sort'.
+// Empty token is first page, returned as nil.
+func decodePageToken(token string, sort market.SortOrder) (*market.Cursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+
+	if err != nil || !strings.HasPrefix(string(raw), pageTokenPrefix) {
+		return nil, fmt.Errorf("%w: invalid page token", ErrInvalidInput)
+	}
+
+	var decoded pageToken
+
+	if err = json.Unmarshal(raw[len(pageTokenPrefix):], &decoded); err != nil {
+		return nil, fmt.Errorf("%w: invalid page token", ErrInvalidInput)
+	}
+
+	if decoded.Sort != sort {
+		return nil, fmt.Errorf("%w: page token of other sort order", ErrInvalidInput)
+	}
+
+	return &decoded.After, nil
+}
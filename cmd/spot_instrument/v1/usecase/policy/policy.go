@@ -0,0 +1,102 @@
+/*
+Role based access to markets.
+What markets role can see and trade decided by lifecycle stage of market.
+*/
+package policy
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/KonnorFrik/BinaryTentacles/cmd/spot_instrument/v1/usecase/market"
+	pb "github.com/KonnorFrik/BinaryTentacles/internal/generated/spot_instrument/v1"
+)
+
+// Stages - set of market stages.
+type Stages map[market.Stage]bool
+
+// Rule - access of one role.
+type Rule struct {
+	// See - stages of markets visible for role.
+	See Stages
+	// Trade - stages of markets where role can trade. Must be subset of See.
+	Trade Stages
+}
+
+var (
+	none        = Stages{}
+	listed      = Stages{market.StageListed: true}
+	preLaunched = Stages{market.StageListed: true, market.StagePreLaunch: true}
+	notDeleted  = Stages{market.StageListed: true, market.StagePreLaunch: true, market.StageDisabled: true}
+	all         = Stages{market.StageListed: true, market.StagePreLaunch: true, market.StageDisabled: true, market.StageDeleted: true}
+)
+
+// Table - access of every known role.
+// Role not in table can't see any market.
+var Table = map[pb.UserRole]Rule{
+	pb.UserRole_USER_ROLE_CUSTOMER:         {See: listed, Trade: listed},
+	pb.UserRole_USER_ROLE_READ_ONLY:        {See: listed, Trade: none},
+	pb.UserRole_USER_ROLE_MARKET_MAKER:     {See: preLaunched, Trade: preLaunched},
+	pb.UserRole_USER_ROLE_INTERNAL_SERVICE: {See: notDeleted, Trade: listed},
+	pb.UserRole_USER_ROLE_ADMIN:            {See: all, Trade: preLaunched},
+}
+
+// RoleName - return name of 'role' in tokens of callers, like 'internal_service'.
+func RoleName(role pb.UserRole) string {
+	return strings.ToLower(strings.TrimPrefix(role.String(), "USER_ROLE_"))
+}
+
+// RoleByName - return role with 'name' in tokens of callers, see RoleName.
+// Returns false if no role in policy table has such name.
+func RoleByName(name string) (pb.UserRole, bool) {
+	for role := range Table {
+		if RoleName(role) == name {
+			return role, true
+		}
+	}
+
+	return pb.UserRole_USER_ROLE_UNSPECIFIED, false
+}
+
+// Known - check is 'role' in policy table.
+func Known(role pb.UserRole) bool {
+	_, exist := Table[role]
+	return exist
+}
+
+// CanSee - check is market in 'stage' visible for 'role'.
+func CanSee(role pb.UserRole, stage market.Stage) bool {
+	return Table[role].See[stage]
+}
+
+// CanTrade - check is 'role' allowed to trade on market in 'stage'.
+func CanTrade(role pb.UserRole, stage market.Stage) bool {
+	return Table[role].Trade[stage]
+}
+
+// TradingState - return state of market 'mark' at time 'now' for 'role'.
+// Market invisible for role reported as not existing.
+// Market where role can't trade is closed.
+func TradingState(
+	role pb.UserRole,
+	mark *market.Market,
+	now time.Time,
+) (
+	market.State,
+	string,
+) {
+	stage := mark.StageAt(now)
+
+	if !CanSee(role, stage) {
+		return market.StateClosed, "market does not exist"
+	}
+
+	state, reason := mark.TradingState(now)
+
+	if state != market.StateClosed && !CanTrade(role, stage) {
+		return market.StateClosed, fmt.Sprintf("trading on %s market is not allowed", stage)
+	}
+
+	return state, reason
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/KonnorFrik/BinaryTentacles/cmd/spot_instrument/v1/usecase/market"
+	redCache "github.com/KonnorFrik/BinaryTentacles/pkg/cache/redis"
+)
+
+const (
+	// cachedMarketKey - prefix of key with cached market, full key is prefix + market id.
+	// Not a uuid, so never collide with market stored by Redis in same db.
+	cachedMarketKey = "market:"
+	// cachedListKey - key with cached list of all markets.
+	cachedListKey = "markets:all"
+)
+
+// Cache - storage of values with expiry, like redis.
+// Get returns redis.ErrNil if 'key' not exist.
+type Cache interface {
+	Get(ctx context.Context, key string) (string, error)
+	Set(ctx context.Context, key string, value string, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) (int64, error)
+}
+
+// Cached - MarketRepository 'store' with read-through cache of markets and list of them.
+// Save goes to 'store' first, then cached market and list are dropped,
+// so cache never keeps market newer than in store.
+type Cached struct {
+	store MarketRepository
+	cache Cache
+	ttl   time.Duration
+}
+
+// NewCached - create a new Cached with markets stored in 'store'
+// and kept in 'cache' for 'ttl' after read.
+func NewCached(
+	store MarketRepository,
+	cache Cache,
+	ttl time.Duration,
+) *Cached {
+	return &Cached{store: store, cache: cache, ttl: ttl}
+}
+
+// Save - implement MarketRepository interface.
+func (c *Cached) Save(
+	ctx context.Context,
+	mark *market.Market,
+) error {
+	err := c.store.Save(ctx, mark)
+	// Dropped on error too, market may be saved before error.
+	c.cache.Delete(ctx, cachedMarketKey+mark.Id, cachedListKey)
+	return err
+}
+
+// Get - implement MarketRepository interface.
+func (c *Cached) Get(
+	ctx context.Context,
+	id string,
+) (
+	*market.Market,
+	error,
+) {
+	marketJSON, err := c.cache.Get(ctx, cachedMarketKey+id)
+
+	if err == nil {
+		if mark, err := decodeMarket([]byte(marketJSON)); err == nil {
+			return mark, nil
+		}
+	}
+
+	mark, err := c.store.Get(ctx, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	c.put(ctx, cachedMarketKey+id, mark)
+	return mark, nil
+}
+
+// List - implement MarketRepository interface.
+func (c *Cached) List(
+	ctx context.Context,
+) (
+	[]*market.Market,
+	error,
+) {
+	listJSON, err := c.cache.Get(ctx, cachedListKey)
+
+	if err == nil {
+		var markets []*market.Market
+
+		if err := json.Unmarshal([]byte(listJSON), &markets); err == nil {
+			return markets, nil
+		}
+	}
+
+	markets, err := c.store.List(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	c.put(ctx, cachedListKey, markets)
+	return markets, nil
+}
+
+// Find - implement MarketRepository interface.
+// Not cached, store find markets by it indexes.
+func (c *Cached) Find(
+	ctx context.Context,
+	query *market.Query,
+) (
+	[]*market.Market,
+	error,
+) {
+	return c.store.Find(ctx, query)
+}
+
+// put - keep 'value' in cache at 'key'. Errors are ignored, value is read from store next time.
+func (c *Cached) put(
+	ctx context.Context,
+	key string,
+	value any,
+) {
+	valueJSON, err := json.Marshal(value)
+
+	if err == nil {
+		c.cache.Set(ctx, key, string(valueJSON), c.ttl)
+	}
+}
+
+// compile time check, redis cache is usable as Cache.
+var _ Cache = (*redCache.Cache)(nil)
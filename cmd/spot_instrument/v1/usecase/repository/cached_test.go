@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/KonnorFrik/BinaryTentacles/cmd/spot_instrument/v1/usecase/market"
+	redCache "github.com/KonnorFrik/BinaryTentacles/pkg/cache/redis"
+)
+
+// mapCache - Cache in map without expiry.
+type mapCache struct {
+	mut    sync.Mutex
+	values map[string]string
+}
+
+func newMapCache() *mapCache {
+	return &mapCache{values: make(map[string]string)}
+}
+
+func (m *mapCache) Get(ctx context.Context, key string) (string, error) {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	value, exist := m.values[key]
+
+	if !exist {
+		return "", redCache.ErrNil
+	}
+
+	return value, nil
+}
+
+func (m *mapCache) Set(ctx context.Context, key string, value string, ttl time.Duration) error {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	m.values[key] = value
+	return nil
+}
+
+func (m *mapCache) Delete(ctx context.Context, keys ...string) (int64, error) {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	var removed int64
+
+	for _, key := range keys {
+		if _, exist := m.values[key]; exist {
+			delete(m.values, key)
+			removed++
+		}
+	}
+
+	return removed, nil
+}
+
+func TestCachedSaveDropsCache(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		store = NewMemory()
+		repo  = NewCached(store, newMapCache(), time.Minute)
+		mark  = &market.Market{Id: "0197a1c2-0000-7000-8000-000000000001", Symbol: "BTC-USDT"}
+	)
+
+	if err := repo.Save(ctx, mark); err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	// Fill cache of market and list.
+	if _, err := repo.Get(ctx, mark.Id); err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	if markets, err := repo.List(ctx); err != nil || len(markets) != 1 {
+		t.Fatalf("Got = %v, %v\n", markets, err)
+	}
+
+	mark.Enabled = true
+
+	if err := repo.Save(ctx, mark); err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	got, err := repo.Get(ctx, mark.Id)
+
+	if err != nil || !got.Enabled {
+		t.Fatalf("Got = %v, %v, Want enabled market\n", got, err)
+	}
+
+	second := &market.Market{Id: "0197a1c2-0000-7000-8000-000000000002", Symbol: "ETH-USDT"}
+
+	if err = repo.Save(ctx, second); err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	if markets, err := repo.List(ctx); err != nil || len(markets) != 2 {
+		t.Fatalf("Got = %v, %v, Want 2 markets\n", markets, err)
+	}
+
+	if _, err = repo.Get(ctx, "0197a1c2-0000-7000-8000-000000000003"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Got = %v, Want = %v\n", err, ErrNotFound)
+	}
+}
+
+func TestCachedReadThrough(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		store = NewMemory()
+		repo  = NewCached(store, newMapCache(), time.Minute)
+		mark  = &market.Market{Id: "0197a1c2-0000-7000-8000-000000000001", Symbol: "BTC-USDT"}
+	)
+
+	store.Save(ctx, mark)
+
+	if _, err := repo.Get(ctx, mark.Id); err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	// Changed in store by other instance, cached market is served until ttl.
+	mark.Enabled = true
+	store.Save(ctx, mark)
+	got, err := repo.Get(ctx, mark.Id)
+
+	if err != nil || got.Enabled {
+		t.Fatalf("Got = %v, %v, Want cached market\n", got, err)
+	}
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/KonnorFrik/BinaryTentacles/cmd/spot_instrument/v1/usecase/market"
+)
+
+// Memory - MarketRepository in memory of process, for run and tests without redis.
+// Markets are kept as JSON, so stored and returned markets never share state.
+type Memory struct {
+	mut     sync.Mutex
+	markets map[string][]byte
+}
+
+// NewMemory - create a new empty Memory.
+func NewMemory() *Memory {
+	return &Memory{markets: make(map[string][]byte)}
+}
+
+// Save - implement MarketRepository interface.
+func (m *Memory) Save(
+	ctx context.Context,
+	mark *market.Market,
+) error {
+	markBytes, err := json.Marshal(mark)
+
+	if err != nil {
+		return fmt.Errorf("market marshal: %w", err)
+	}
+
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	m.markets[mark.Id] = markBytes
+	return nil
+}
+
+// Get - implement MarketRepository interface.
+func (m *Memory) Get(
+	ctx context.Context,
+	id string,
+) (
+	*market.Market,
+	error,
+) {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	markBytes, exist := m.markets[id]
+
+	if !exist {
+		return nil, ErrNotFound
+	}
+
+	return decodeMarket(markBytes)
+}
+
+// List - implement MarketRepository interface.
+func (m *Memory) List(
+	ctx context.Context,
+) (
+	[]*market.Market,
+	error,
+) {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	var markets = make([]*market.Market, 0, len(m.markets))
+
+	for _, markBytes := range m.markets {
+		mark, err := decodeMarket(markBytes)
+
+		if err != nil {
+			return nil, err
+		}
+
+		markets = append(markets, mark)
+	}
+
+	return markets, nil
+}
+
+// Find - implement MarketRepository interface.
+func (m *Memory) Find(
+	ctx context.Context,
+	query *market.Query,
+) (
+	[]*market.Market,
+	error,
+) {
+	markets, err := m.List(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return match(markets, query), nil
+}
@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/KonnorFrik/BinaryTentacles/cmd/spot_instrument/v1/usecase/market"
+	"github.com/KonnorFrik/BinaryTentacles/pkg/postgres"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Postgres - MarketRepository stored in postgres, table 'markets'.
+type Postgres struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgres - create a new Postgres with markets stored in database of 'pool'.
+func NewPostgres(pool *pgxpool.Pool) *Postgres {
+	return &Postgres{pool: pool}
+}
+
+// Save - implement MarketRepository interface.
+func (p *Postgres) Save(
+	ctx context.Context,
+	mark *market.Market,
+) error {
+	markBytes, err := json.Marshal(mark)
+
+	if err != nil {
+		return fmt.Errorf("market marshal: %w", err)
+	}
+
+	_, err = p.pool.Exec(
+		ctx,
+		`INSERT INTO markets (id, symbol, data, status, base_asset, quote_asset) VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (id) DO UPDATE SET symbol = $2, data = $3, status = $4, base_asset = $5, quote_asset = $6, updated_at = now()`,
+		mark.Id, mark.Symbol, markBytes, statusNames[mark.Status()], mark.BaseAsset, mark.QuoteAsset,
+	)
+	return err
+}
+
+// Get - implement MarketRepository interface.
+func (p *Postgres) Get(
+	ctx context.Context,
+	id string,
+) (
+	*market.Market,
+	error,
+) {
+	var marketJSON []byte
+	err := p.pool.QueryRow(ctx, "SELECT data FROM markets WHERE id = $1", id).Scan(&marketJSON)
+
+	if postgres.IsNotFound(err) {
+		return nil, ErrNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeMarket(marketJSON)
+}
+
+// List - implement MarketRepository interface.
+func (p *Postgres) List(
+	ctx context.Context,
+) (
+	[]*market.Market,
+	error,
+) {
+	rows, err := p.pool.Query(ctx, "SELECT data FROM markets")
+
+	if err != nil {
+		return nil, err
+	}
+
+	return collectMarkets(rows)
+}
+
+// Find - implement MarketRepository interface.
+// Filters are applied by indexed columns.
+func (p *Postgres) Find(
+	ctx context.Context,
+	query *market.Query,
+) (
+	[]*market.Market,
+	error,
+) {
+	var (
+		conditions = []string{"TRUE"}
+		args       []any
+	)
+
+	where := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if query.Status != market.StatusAll {
+		where("status = $%d", statusNames[query.Status])
+	}
+
+	if query.BaseAsset != "" {
+		where("base_asset = $%d", market.NormalizeSymbol(query.BaseAsset))
+	}
+
+	if query.QuoteAsset != "" {
+		where("quote_asset = $%d", market.NormalizeSymbol(query.QuoteAsset))
+	}
+
+	if prefix := market.NormalizeSymbol(query.SymbolPrefix); prefix != "" {
+		where("symbol LIKE $%d || '%%'", strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(prefix))
+	}
+
+	rows, err := p.pool.Query(ctx, "SELECT data FROM markets WHERE "+strings.Join(conditions, " AND "), args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return collectMarkets(rows)
+}
+
+// collectMarkets - read markets from 'rows' with one column of market JSON.
+func collectMarkets(rows pgx.Rows) ([]*market.Market, error) {
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (*market.Market, error) {
+		var marketJSON []byte
+
+		if err := row.Scan(&marketJSON); err != nil {
+			return nil, err
+		}
+
+		return decodeMarket(marketJSON)
+	})
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/KonnorFrik/BinaryTentacles/cmd/spot_instrument/v1/usecase/market"
+	"github.com/KonnorFrik/BinaryTentacles/internal/migrations"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// testPostgres - return pool of migrated test database from env POSTGRES_TEST_DSN,
+// test is skipped if env is not set.
+func testPostgres(t *testing.T) *pgxpool.Pool {
+	dsn := os.Getenv("POSTGRES_TEST_DSN")
+
+	if dsn == "" {
+		t.Skip("POSTGRES_TEST_DSN is not set")
+	}
+
+	pool, err := pgxpool.New(context.Background(), dsn)
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	t.Cleanup(pool.Close)
+
+	if _, err = migrations.Up(context.Background(), pool); err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	return pool
+}
+
+func TestPostgresMarkets(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		repo = NewPostgres(testPostgres(t))
+		mark = &market.Market{Id: uuid.NewString(), Symbol: "BTC-USDT", BaseAsset: "BTC", QuoteAsset: "USDT"}
+	)
+
+	if _, err := repo.Get(ctx, mark.Id); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Got = %v, Want = %v\n", err, ErrNotFound)
+	}
+
+	for _, enabled := range []bool{false, true} {
+		mark.Enabled = enabled
+
+		if err := repo.Save(ctx, mark); err != nil {
+			t.Fatalf("Got = %q\n", err)
+		}
+	}
+
+	got, err := repo.Get(ctx, mark.Id)
+
+	if err != nil || !got.Enabled || got.Symbol != mark.Symbol {
+		t.Fatalf("Got = %v, %v\n", got, err)
+	}
+
+	markets, err := repo.List(ctx)
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	var found bool
+
+	for _, m := range markets {
+		found = found || m.Id == mark.Id
+	}
+
+	if !found {
+		t.Fatalf("Market %s not listed\n", mark.Id)
+	}
+
+	markets, err = repo.Find(ctx, &market.Query{BaseAsset: "btc", SymbolPrefix: "BTC-U"})
+
+	if err != nil || !slices.ContainsFunc(markets, func(m *market.Market) bool { return m.Id == mark.Id }) {
+		t.Fatalf("Got = %v, %v, Want = market %s\n", markets, err, mark.Id)
+	}
+
+	markets, err = repo.Find(ctx, &market.Query{Status: market.StatusDisabled, BaseAsset: "btc"})
+
+	if err != nil || slices.ContainsFunc(markets, func(m *market.Market) bool { return m.Id == mark.Id }) {
+		t.Fatalf("Got = %v, %v, Want = without market %s\n", markets, err, mark.Id)
+	}
+}
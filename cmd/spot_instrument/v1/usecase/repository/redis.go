@@ -0,0 +1,333 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/KonnorFrik/BinaryTentacles/cmd/spot_instrument/v1/usecase/market"
+	redCache "github.com/KonnorFrik/BinaryTentacles/pkg/cache/redis"
+	"github.com/google/uuid"
+)
+
+const (
+	// marketIndexKey - key of set with ids of all markets in market cache.
+	// Not a uuid, so never collide with market.
+	marketIndexKey = "markets:index"
+	// filterIndexPrefix - prefix of keys of sets with ids of markets by filtered attribute,
+	// like 'markets:by:status:enabled', 'markets:by:base:BTC' or 'markets:by:quote:USDT'.
+	filterIndexPrefix = "markets:by:"
+	// filterIndexReadyKey - key exist if filter indexes are built.
+	filterIndexReadyKey = filterIndexPrefix + "ready"
+)
+
+// marketTTL - markets are stored without expiry.
+const marketTTL = 0
+
+// Redis - MarketRepository stored in redis as JSON at key equal to market id.
+// Ids of all markets are kept in set at marketIndexKey,
+// ids of markets by status and assets in sets at filterIndexPrefix keys.
+type Redis struct {
+	cache   *redCache.Cache
+	markets *redCache.TypedStore[*market.Market]
+}
+
+// NewRedis - create a new Redis with markets stored in 'cache'.
+// Builds market indexes if they not exist.
+func NewRedis(
+	ctx context.Context,
+	cache *redCache.Cache,
+) (
+	*Redis,
+	error,
+) {
+	r := &Redis{
+		cache:   cache,
+		markets: redCache.NewTypedStore(cache, redCache.JSONCodec[*market.Market]{}),
+	}
+
+	if err := r.ensureIndex(ctx); err != nil {
+		return nil, fmt.Errorf("build market index: %w", err)
+	}
+
+	if err := r.ensureFilterIndex(ctx); err != nil {
+		return nil, fmt.Errorf("build market filter index: %w", err)
+	}
+
+	return r, nil
+}
+
+// Save - implement MarketRepository interface.
+// Market is added to filter indexes before save and removed from old ones after,
+// so interrupted save leaves only extra ids in indexes, Find skips them.
+func (r *Redis) Save(
+	ctx context.Context,
+	mark *market.Market,
+) error {
+	previous, err := r.markets.Get(ctx, mark.Id)
+
+	if err != nil && !errors.Is(err, redCache.ErrNil) {
+		return err
+	}
+
+	keys := filterKeys(mark)
+
+	for _, key := range keys {
+		if err = r.cache.SetAdd(ctx, key, mark.Id); err != nil {
+			return err
+		}
+	}
+
+	if err = r.markets.Set(ctx, mark.Id, mark, marketTTL); err != nil {
+		return err
+	}
+
+	if err = r.cache.SetAdd(ctx, marketIndexKey, mark.Id); err != nil {
+		return err
+	}
+
+	if previous == nil {
+		return nil
+	}
+
+	for _, key := range filterKeys(previous) {
+		if !slices.Contains(keys, key) {
+			if err = r.cache.SetRemove(ctx, key, mark.Id); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Get - implement MarketRepository interface.
+func (r *Redis) Get(
+	ctx context.Context,
+	id string,
+) (
+	*market.Market,
+	error,
+) {
+	mark, err := r.markets.Get(ctx, id)
+
+	if errors.Is(err, redCache.ErrNil) {
+		return nil, ErrNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return mark, nil
+}
+
+// List - implement MarketRepository interface.
+// Corrupted markets are logged and skipped.
+func (r *Redis) List(
+	ctx context.Context,
+) (
+	[]*market.Market,
+	error,
+) {
+	ids, err := r.cache.SetMembers(ctx, marketIndexKey)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return r.load(ctx, ids)
+}
+
+// Find - implement MarketRepository interface.
+// Markets are read by ids from intersection of filter indexes,
+// symbol prefix is matched on read markets.
+func (r *Redis) Find(
+	ctx context.Context,
+	query *market.Query,
+) (
+	[]*market.Market,
+	error,
+) {
+	var keys []string
+
+	if query.Status != market.StatusAll {
+		keys = append(keys, filterIndexPrefix+"status:"+statusNames[query.Status])
+	}
+
+	if query.BaseAsset != "" {
+		keys = append(keys, filterIndexPrefix+"base:"+market.NormalizeSymbol(query.BaseAsset))
+	}
+
+	if query.QuoteAsset != "" {
+		keys = append(keys, filterIndexPrefix+"quote:"+market.NormalizeSymbol(query.QuoteAsset))
+	}
+
+	if len(keys) == 0 {
+		keys = append(keys, marketIndexKey)
+	}
+
+	var ids []string
+
+	// Intersected here, not by SINTER, so sets may be in different slots of cluster.
+	for i, key := range keys {
+		members, err := r.cache.SetMembers(ctx, key)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if i > 0 {
+			var found = make(map[string]struct{}, len(ids))
+
+			for _, id := range ids {
+				found[id] = struct{}{}
+			}
+
+			members = slices.DeleteFunc(members, func(id string) bool {
+				_, exist := found[id]
+				return !exist
+			})
+		}
+
+		ids = members
+	}
+
+	markets, err := r.load(ctx, ids)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return match(markets, query), nil
+}
+
+// load - read markets with 'ids'.
+// Corrupted markets are logged and skipped.
+func (r *Redis) load(
+	ctx context.Context,
+	ids []string,
+) (
+	[]*market.Market,
+	error,
+) {
+	if len(ids) == 0 {
+		return []*market.Market{}, nil
+	}
+
+	entries, err := r.markets.MGet(ctx, ids...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var markets = make([]*market.Market, 0, len(entries))
+
+	for _, entry := range entries {
+		if entry.Err != nil {
+			logger.LogAttrs(
+				ctx,
+				slog.LevelError,
+				"[MarketRepository/Redis/load/MGet]",
+				slog.String("market", entry.Key),
+				slog.String("error", entry.Err.Error()),
+			)
+			continue
+		}
+
+		markets = append(markets, entry.Value)
+	}
+
+	return markets, nil
+}
+
+// filterKeys - return keys of filter index sets with market 'mark'.
+func filterKeys(mark *market.Market) []string {
+	return []string{
+		filterIndexPrefix + "status:" + statusNames[mark.Status()],
+		filterIndexPrefix + "base:" + mark.BaseAsset,
+		filterIndexPrefix + "quote:" + mark.QuoteAsset,
+	}
+}
+
+// ensureFilterIndex - build filter indexes from stored markets if they not built.
+// Needed for markets saved before filter indexes were introduced.
+func (r *Redis) ensureFilterIndex(
+	ctx context.Context,
+) error {
+	exist, err := r.cache.Exists(ctx, filterIndexReadyKey)
+
+	if err != nil || exist {
+		return err
+	}
+
+	markets, err := r.List(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	for _, mark := range markets {
+		for _, key := range filterKeys(mark) {
+			if err = r.cache.SetAdd(ctx, key, mark.Id); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err = r.cache.Set(ctx, filterIndexReadyKey, "1", marketTTL); err != nil {
+		return err
+	}
+
+	logger.LogAttrs(
+		ctx,
+		slog.LevelInfo,
+		"[MarketRepository/Redis/ensureFilterIndex]",
+		slog.Int("Indexed markets", len(markets)),
+	)
+	return nil
+}
+
+// ensureIndex - build market index from stored markets if index not exist.
+// Needed for markets saved before index was introduced.
+func (r *Redis) ensureIndex(
+	ctx context.Context,
+) error {
+	exist, err := r.cache.Exists(ctx, marketIndexKey)
+
+	if err != nil || exist {
+		return err
+	}
+
+	keys, err := r.cache.Keys(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	var ids = make([]string, 0, len(keys))
+
+	for _, key := range keys {
+		if uuid.Validate(key) == nil {
+			ids = append(ids, key)
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	if err = r.cache.SetAdd(ctx, marketIndexKey, ids...); err != nil {
+		return err
+	}
+
+	logger.LogAttrs(
+		ctx,
+		slog.LevelInfo,
+		"[MarketRepository/Redis/ensureIndex]",
+		slog.Int("Indexed markets", len(ids)),
+	)
+	return nil
+}
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/KonnorFrik/BinaryTentacles/cmd/spot_instrument/v1/usecase/market"
+	redCache "github.com/KonnorFrik/BinaryTentacles/pkg/cache/redis"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+)
+
+// newTestCache - return redis cache in miniredis.
+func newTestCache(t *testing.T) *redCache.Cache {
+	server := miniredis.RunT(t)
+	cache, err := redCache.New(context.Background(), redCache.Config{Addr: server.Addr()})
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	t.Cleanup(func() { cache.Close(context.Background()) })
+	return cache
+}
+
+// symbolsOf - return sorted symbols of 'markets'.
+func symbolsOf(markets []*market.Market) []string {
+	var symbols = make([]string, 0, len(markets))
+
+	for _, mark := range markets {
+		symbols = append(symbols, mark.Symbol)
+	}
+
+	slices.Sort(symbols)
+	return symbols
+}
+
+func newMarket(base, quote string, enabled bool) *market.Market {
+	return &market.Market{
+		Id:         uuid.NewString(),
+		Symbol:     market.NewSymbol(base, quote),
+		BaseAsset:  base,
+		QuoteAsset: quote,
+		Enabled:    enabled,
+	}
+}
+
+func TestRedisFind(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		cache   = newTestCache(t)
+		btc     = newMarket("BTC", "USDT", true)
+		eth     = newMarket("ETH", "USDT", false)
+		ethBtc  = newMarket("ETH", "BTC", true)
+		markets = []*market.Market{btc, eth, ethBtc}
+	)
+
+	repo, err := NewRedis(ctx, cache)
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	for _, mark := range markets {
+		if err = repo.Save(ctx, mark); err != nil {
+			t.Fatalf("Got = %q\n", err)
+		}
+	}
+
+	// Moved between indexes.
+	eth.Enabled = true
+	btc.DeletedAt = time.Now()
+	ethBtc.QuoteAsset, ethBtc.Symbol = "USDC", "ETH-USDC"
+
+	for _, mark := range markets {
+		if err = repo.Save(ctx, mark); err != nil {
+			t.Fatalf("Got = %q\n", err)
+		}
+	}
+
+	// Stale id in index is skipped.
+	if err = cache.SetAdd(ctx, filterIndexPrefix+"status:enabled", btc.Id); err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	cases := []struct {
+		query market.Query
+		want  []string
+	}{
+		{query: market.Query{}, want: []string{"ETH-USDC", "ETH-USDT"}},
+		{query: market.Query{Status: market.StatusDeleted}, want: []string{"BTC-USDT"}},
+		{query: market.Query{Status: market.StatusDisabled}, want: []string{}},
+		{query: market.Query{Status: market.StatusAll}, want: []string{"BTC-USDT", "ETH-USDC", "ETH-USDT"}},
+		{query: market.Query{Status: market.StatusAll, QuoteAsset: "usdt"}, want: []string{"BTC-USDT", "ETH-USDT"}},
+		{query: market.Query{BaseAsset: "eth", QuoteAsset: "usdc"}, want: []string{"ETH-USDC"}},
+		{query: market.Query{Status: market.StatusAll, QuoteAsset: "btc"}, want: []string{}},
+		{query: market.Query{Status: market.StatusAll, SymbolPrefix: "eth-usdt"}, want: []string{"ETH-USDT"}},
+	}
+
+	for _, c := range cases {
+		found, err := repo.Find(ctx, &c.query)
+
+		if got := symbolsOf(found); err != nil || !slices.Equal(got, c.want) {
+			t.Errorf("%+v: Got = %v, %v, Want = %v\n", c.query, got, err, c.want)
+		}
+	}
+}
+
+func TestRedisBuildsFilterIndex(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		cache = newTestCache(t)
+		store = redCache.NewTypedStore(cache, redCache.JSONCodec[*market.Market]{})
+		btc   = newMarket("BTC", "USDT", true)
+	)
+
+	// Market saved before filter indexes.
+	if err := store.Set(ctx, btc.Id, btc, marketTTL); err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	repo, err := NewRedis(ctx, cache)
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	found, err := repo.Find(ctx, &market.Query{BaseAsset: "BTC"})
+
+	if err != nil || len(found) != 1 || found[0].Id != btc.Id {
+		t.Fatalf("Got = %v, %v, Want = %s\n", found, err, btc.Id)
+	}
+}
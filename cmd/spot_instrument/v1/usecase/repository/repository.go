@@ -0,0 +1,68 @@
+/*
+Storage of markets.
+Repository save copies of markets, changes of returned market are not saved until Save.
+*/
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/KonnorFrik/BinaryTentacles/cmd/spot_instrument/v1/usecase/market"
+	"github.com/KonnorFrik/BinaryTentacles/pkg/logging"
+)
+
+var (
+	// ErrNotFound - market is not exist.
+	ErrNotFound = errors.New("market not found")
+)
+
+var (
+	logger = logging.Default()
+)
+
+// statusNames - names of market statuses in indexes of stores.
+var statusNames = map[market.StatusFilter]string{
+	market.StatusEnabled:  "enabled",
+	market.StatusDisabled: "disabled",
+	market.StatusDeleted:  "deleted",
+}
+
+// MarketRepository - storage of markets.
+type MarketRepository interface {
+	// Save - create or replace market 'mark'.
+	Save(ctx context.Context, mark *market.Market) error
+	// Get - return market with 'id'.
+	Get(ctx context.Context, id string) (*market.Market, error)
+	// List - return all markets in any order.
+	List(ctx context.Context) ([]*market.Market, error)
+	// Find - return markets matched by filters of 'query' in any order.
+	// Sort of query is not applied.
+	Find(ctx context.Context, query *market.Query) ([]*market.Market, error)
+}
+
+// match - return markets of 'markets' matched by filters of 'query'.
+func match(markets []*market.Market, query *market.Query) []*market.Market {
+	var matched = make([]*market.Market, 0, len(markets))
+
+	for _, mark := range markets {
+		if query.Match(mark) {
+			matched = append(matched, mark)
+		}
+	}
+
+	return matched
+}
+
+// decodeMarket - read market from JSON.
+func decodeMarket(marketJSON []byte) (*market.Market, error) {
+	var mark market.Market
+
+	if err := json.Unmarshal(marketJSON, &mark); err != nil {
+		return nil, fmt.Errorf("market unmarshal: %w", err)
+	}
+
+	return &mark, nil
+}
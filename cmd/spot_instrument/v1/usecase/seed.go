@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/KonnorFrik/BinaryTentacles/cmd/spot_instrument/v1/usecase/market"
+	"github.com/KonnorFrik/BinaryTentacles/cmd/spot_instrument/v1/usecase/seed"
+	"github.com/google/uuid"
+)
+
+// actorSeed - actor of changes made by market seed.
+const actorSeed = "seed"
+
+// SeedResult - counts of markets touched by seed.
+type SeedResult struct {
+	Created   int
+	Updated   int
+	Unchanged int
+}
+
+// SeedMarkets - upsert markets defined in seed file at 'path'.
+// Existing market found by id, or by symbol if id not given.
+// Idempotent: unchanged markets are not written.
+// Whole file is validated before any write.
+func (u *Usecase) SeedMarkets(
+	ctx context.Context,
+	path string,
+) (
+	SeedResult,
+	error,
+) {
+	var result SeedResult
+	file, err := seed.Load(path)
+
+	if err != nil {
+		return result, err
+	}
+
+	existing, err := u.allMarkets(ctx)
+
+	if err != nil {
+		return result, err
+	}
+
+	var (
+		byId     = make(map[string]*market.Market, len(existing))
+		bySymbol = make(map[string]*market.Market, len(existing))
+	)
+
+	for _, mark := range existing {
+		byId[mark.Id] = mark
+
+		if mark.DeletedAt.IsZero() {
+			bySymbol[mark.Symbol] = mark
+		}
+	}
+
+	type upsert struct {
+		def   seed.Market
+		mark  *market.Market
+		found bool
+	}
+
+	var plan = make([]upsert, 0, len(file.Markets))
+
+	// Resolve all markets before any write, so conflicting file don't apply partially.
+	for i, def := range file.Markets {
+		mark, found := byId[def.Id]
+
+		if def.Id == "" {
+			mark, found = bySymbol[def.Symbol()]
+		}
+
+		if other, exist := bySymbol[def.Symbol()]; exist && !def.Deleted && (!found || other.Id != mark.Id) {
+			return result, fmt.Errorf(
+				"%w: %s: markets[%d]: symbol %q already used by market %s",
+				ErrAlreadyExists, path, i, def.Symbol(), other.Id,
+			)
+		}
+
+		plan = append(plan, upsert{def: def, mark: mark, found: found})
+	}
+
+	for _, item := range plan {
+		var (
+			mark   = item.mark
+			before = market.Snapshot{}
+			action = market.ActionCreate
+		)
+
+		if item.found {
+			before = mark.Snapshot()
+			action = market.ActionUpdate
+
+		} else {
+			mark = &market.Market{Id: item.def.Id}
+
+			if mark.Id == "" {
+				mark.Id = uuid.NewString()
+			}
+		}
+
+		item.def.Apply(mark, u.clock.Now())
+
+		if item.found && len(market.Diff(before, mark.Snapshot())) == 0 {
+			result.Unchanged++
+			continue
+		}
+
+		if err = u.saveMarketSymbol(ctx, mark, before); err != nil {
+			return result, err
+		}
+
+		u.recordAudit(ctx, mark, actorSeed, action, before)
+		u.publishChange(ctx, mark, before, actorSeed, action)
+
+		if item.found {
+			result.Updated++
+
+		} else {
+			result.Created++
+		}
+	}
+
+	logger.LogAttrs(
+		ctx,
+		slog.LevelInfo,
+		"[SpotInstrument/SeedMarkets]",
+		slog.String("file", path),
+		slog.Int("created", result.Created),
+		slog.Int("updated", result.Updated),
+		slog.Int("unchanged", result.Unchanged),
+	)
+	return result, nil
+}
@@ -0,0 +1,222 @@
+/*
+Declarative market definitions loaded from YAML or JSON file.
+*/
+package seed
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/KonnorFrik/BinaryTentacles/cmd/spot_instrument/v1/usecase/market"
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// ErrInvalidSeed - seed file can't be used for any reason.
+	ErrInvalidSeed = errors.New("invalid market seed")
+)
+
+// File - root of seed file.
+type File struct {
+	Markets []Market `yaml:"markets" json:"markets"`
+}
+
+// Market - definition of one market.
+// Market identified by 'Id' if given, by symbol otherwise.
+type Market struct {
+	Id                string `yaml:"id" json:"id"`
+	BaseAsset         string `yaml:"base_asset" json:"base_asset"`
+	QuoteAsset        string `yaml:"quote_asset" json:"quote_asset"`
+	PricePrecision    uint32 `yaml:"price_precision" json:"price_precision"`
+	QuantityPrecision uint32 `yaml:"quantity_precision" json:"quantity_precision"`
+	Enabled           bool   `yaml:"enabled" json:"enabled"`
+	Deleted           bool   `yaml:"deleted" json:"deleted"`
+	// State - one of "active", "halted", "auction_only", "cancel_only", "closed".
+	// Empty means "active".
+	State       string    `yaml:"state" json:"state"`
+	StateReason string    `yaml:"state_reason" json:"state_reason"`
+	Schedule    *Schedule `yaml:"schedule" json:"schedule"`
+	// LaunchAt - RFC 3339 time, market is pre-launch before it. Empty if launched.
+	LaunchAt time.Time `yaml:"launch_at" json:"launch_at"`
+}
+
+// Schedule - trading calendar of market.
+type Schedule struct {
+	TimeZone string    `yaml:"time_zone" json:"time_zone"`
+	Sessions []Session `yaml:"sessions" json:"sessions"`
+	Holidays []string  `yaml:"holidays" json:"holidays"`
+}
+
+// Session - trading hours of one weekday.
+type Session struct {
+	// Weekday - 0 is Sunday ... 6 is Saturday.
+	Weekday int    `yaml:"weekday" json:"weekday"`
+	Open    string `yaml:"open" json:"open"`
+	Close   string `yaml:"close" json:"close"`
+}
+
+// Load - read and validate seed file at 'path'.
+// Format chosen by extension: ".json" for JSON, ".yaml" or ".yml" for YAML.
+// Unknown fields are rejected.
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSeed, err)
+	}
+
+	var file File
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&file)
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		err = decoder.Decode(&file)
+	default:
+		return nil, fmt.Errorf("%w: %s: unsupported extension %q", ErrInvalidSeed, path, ext)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrInvalidSeed, path, err)
+	}
+
+	if err = file.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return &file, nil
+}
+
+// Validate - check all markets of file 'f'.
+// Returns all found problems at once.
+func (f *File) Validate() error {
+	var (
+		errs    []error
+		ids     = make(map[string]int)
+		symbols = make(map[string]int)
+	)
+
+	for i, m := range f.Markets {
+		for _, err := range m.validate() {
+			errs = append(errs, fmt.Errorf("%w: markets[%d]: %w", ErrInvalidSeed, i, err))
+		}
+
+		if m.Id != "" {
+			if prev, exist := ids[m.Id]; exist {
+				errs = append(errs, fmt.Errorf("%w: markets[%d]: id %q already used in markets[%d]", ErrInvalidSeed, i, m.Id, prev))
+			}
+
+			ids[m.Id] = i
+		}
+
+		if symbol := m.Symbol(); !m.Deleted {
+			if prev, exist := symbols[symbol]; exist {
+				errs = append(errs, fmt.Errorf("%w: markets[%d]: symbol %q already used in markets[%d]", ErrInvalidSeed, i, symbol, prev))
+			}
+
+			symbols[symbol] = i
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Symbol - return symbol of market 'm'.
+func (m *Market) Symbol() string {
+	return market.NewSymbol(m.BaseAsset, m.QuoteAsset)
+}
+
+// Apply - write definition 'm' in market 'mark' at time 'now'.
+// Id and timestamps of 'mark' are not changed.
+func (m *Market) Apply(mark *market.Market, now time.Time) {
+	state, _ := market.ParseState(m.State)
+	mark.Symbol = m.Symbol()
+	mark.BaseAsset = market.NormalizeSymbol(m.BaseAsset)
+	mark.QuoteAsset = market.NormalizeSymbol(m.QuoteAsset)
+	mark.PricePrecision = m.PricePrecision
+	mark.QuantityPrecision = m.QuantityPrecision
+	mark.Enabled = m.Enabled
+	mark.State = state
+	mark.StateReason = m.StateReason
+	mark.HaltedUntil = time.Time{}
+	mark.Schedule = m.Schedule.toMarket()
+	mark.LaunchAt = m.LaunchAt
+
+	switch {
+	case !m.Deleted:
+		mark.DeletedAt = time.Time{}
+	case mark.DeletedAt.IsZero():
+		mark.DeletedAt = now
+	}
+}
+
+// validate - return all problems of market definition 'm'.
+func (m *Market) validate() []error {
+	var errs []error
+
+	if m.Id != "" {
+		if err := uuid.Validate(m.Id); err != nil {
+			errs = append(errs, fmt.Errorf("id %q: %w", m.Id, err))
+		}
+	}
+
+	assets := []struct{ field, value string }{
+		{"base_asset", m.BaseAsset},
+		{"quote_asset", m.QuoteAsset},
+	}
+
+	for _, asset := range assets {
+		switch {
+		case strings.TrimSpace(asset.value) == "":
+			errs = append(errs, fmt.Errorf("%s is required", asset.field))
+		case strings.Contains(asset.value, "-"):
+			errs = append(errs, fmt.Errorf("%s %q can't contain '-'", asset.field, asset.value))
+		}
+	}
+
+	if _, ok := market.ParseState(m.State); !ok {
+		errs = append(errs, fmt.Errorf("state %q: unknown state", m.State))
+	}
+
+	if schedule := m.Schedule.toMarket(); schedule != nil {
+		if err := schedule.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("schedule: %w", err))
+		}
+	}
+
+	return errs
+}
+
+// toMarket - convert schedule 's' in market.Schedule. Nil for nil 's'.
+func (s *Schedule) toMarket() *market.Schedule {
+	if s == nil {
+		return nil
+	}
+
+	var out = market.Schedule{
+		TimeZone: s.TimeZone,
+		Holidays: s.Holidays,
+		Sessions: make([]market.Session, len(s.Sessions)),
+	}
+
+	for i, session := range s.Sessions {
+		out.Sessions[i] = market.Session{
+			Weekday: time.Weekday(session.Weekday),
+			Open:    session.Open,
+			Close:   session.Close,
+		}
+	}
+
+	return &out
+}
@@ -0,0 +1,143 @@
+package seed
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeSeed - write 'content' in file 'name' of temporary dir, return path of it.
+func writeSeed(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Got = %v, Want = %v\n", err, nil)
+	}
+
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	path := writeSeed(t, "markets.yaml", `
+markets:
+  - id: 3f0c9a4e-1d2b-4c5e-8f7a-9b0c1d2e3f4a
+    base_asset: btc
+    quote_asset: usdt
+    price_precision: 2
+    enabled: true
+  - base_asset: eth
+    quote_asset: usdt
+    state: halted
+    schedule:
+      time_zone: UTC
+      sessions:
+        - {weekday: 1, open: "09:00", close: "17:00"}
+`)
+	file, err := Load(path)
+
+	if err != nil {
+		t.Fatalf("Got = %v, Want = %v\n", err, nil)
+	}
+
+	if len(file.Markets) != 2 || file.Markets[1].Symbol() != "ETH-USDT" {
+		t.Fatalf("Got = %+v, Want = BTC-USDT and ETH-USDT\n", file.Markets)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	const btc = "    base_asset: btc\n    quote_asset: usdt\n"
+
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		// problems - parts of error, one per expected problem.
+		problems []string
+	}{
+		{
+			name:     "Unsupported extension",
+			file:     "markets.toml",
+			content:  "",
+			problems: []string{`unsupported extension ".toml"`},
+		},
+		{
+			name:     "Unknown field",
+			file:     "markets.yaml",
+			content:  "markets:\n  - base: btc\n",
+			problems: []string{"base"},
+		},
+		{
+			name:     "Unknown json field",
+			file:     "markets.json",
+			content:  `{"markets": [{"base_asset": "btc", "quote_asset": "usdt", "tick": 1}]}`,
+			problems: []string{"tick"},
+		},
+		{
+			name:     "Missing assets",
+			file:     "markets.yaml",
+			content:  "markets:\n  - enabled: true\n",
+			problems: []string{"markets[0]: base_asset is required", "markets[0]: quote_asset is required"},
+		},
+		{
+			name:     "Dash in asset",
+			file:     "markets.yaml",
+			content:  "markets:\n  - base_asset: btc-x\n    quote_asset: usdt\n",
+			problems: []string{`base_asset "btc-x"`},
+		},
+		{
+			name:     "Invalid id",
+			file:     "markets.yaml",
+			content:  "markets:\n  - id: market-1\n" + btc,
+			problems: []string{`id "market-1"`},
+		},
+		{
+			name:     "Unknown state",
+			file:     "markets.yaml",
+			content:  "markets:\n  - state: paused\n" + btc,
+			problems: []string{`state "paused"`},
+		},
+		{
+			name:     "Invalid schedule",
+			file:     "markets.yaml",
+			content:  "markets:\n  - schedule: {time_zone: Mars/Olympus}\n" + btc,
+			problems: []string{"markets[0]: schedule"},
+		},
+		{
+			name: "Duplicated id and symbol",
+			file: "markets.yaml",
+			content: "markets:\n  - id: 3f0c9a4e-1d2b-4c5e-8f7a-9b0c1d2e3f4a\n" + btc +
+				"  - id: 3f0c9a4e-1d2b-4c5e-8f7a-9b0c1d2e3f4a\n" + btc,
+			problems: []string{"markets[1]: id", `markets[1]: symbol "BTC-USDT" already used in markets[0]`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writeSeed(t, tt.file, tt.content))
+
+			if !errors.Is(err, ErrInvalidSeed) {
+				t.Fatalf("Got = %v, Want = %v\n", err, ErrInvalidSeed)
+			}
+
+			for _, problem := range tt.problems {
+				if !strings.Contains(err.Error(), problem) {
+					t.Errorf("Got = %q, Want = contains %q\n", err, problem)
+				}
+			}
+		})
+	}
+}
+
+func TestDeletedSymbolReused(t *testing.T) {
+	file := File{Markets: []Market{
+		{BaseAsset: "btc", QuoteAsset: "usdt", Deleted: true},
+		{BaseAsset: "btc", QuoteAsset: "usdt"},
+	}}
+
+	if err := file.Validate(); err != nil {
+		t.Fatalf("Got = %v, Want = %v\n", err, nil)
+	}
+}
@@ -0,0 +1,113 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/KonnorFrik/BinaryTentacles/cmd/spot_instrument/v1/usecase/seed"
+	pb "github.com/KonnorFrik/BinaryTentacles/internal/generated/spot_instrument/v1"
+)
+
+const seedMarkets = `
+markets:
+  - id: 3f0c9a4e-1d2b-4c5e-8f7a-9b0c1d2e3f4a
+    base_asset: btc
+    quote_asset: usdt
+    price_precision: 2
+    enabled: true
+  - base_asset: eth
+    quote_asset: usdt
+    enabled: true
+`
+
+// writeSeed - write 'content' in seed file of temporary dir, return path of it.
+func writeSeed(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "markets.yaml")
+
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Got = %v, Want = %v\n", err, nil)
+	}
+
+	return path
+}
+
+func TestSeedMarketsRerun(t *testing.T) {
+	u, _ := newTestUsecase(t)
+	path := writeSeed(t, seedMarkets)
+	result, err := u.SeedMarkets(context.Background(), path)
+
+	if err != nil || result != (SeedResult{Created: 2}) {
+		t.Fatalf("Got = %+v, %v, Want = %+v\n", result, err, SeedResult{Created: 2})
+	}
+
+	result, err = u.SeedMarkets(context.Background(), path)
+
+	if err != nil || result != (SeedResult{Unchanged: 2}) {
+		t.Fatalf("Got = %+v, %v, Want = %+v\n", result, err, SeedResult{Unchanged: 2})
+	}
+
+	markets, err := u.allMarkets(context.Background())
+
+	if err != nil || len(markets) != 2 {
+		t.Fatalf("Got = %d markets, %v, Want = 2\n", len(markets), err)
+	}
+
+	// Rerun writes no audit.
+	records, err := u.GetMarketAudit(adminCtx, &pb.GetMarketAuditRequest{MarketId: "3f0c9a4e-1d2b-4c5e-8f7a-9b0c1d2e3f4a"})
+
+	if err != nil || len(records) != 1 || records[0].Actor != actorSeed {
+		t.Fatalf("Got = %v, %v, Want = one record by %q\n", records, err, actorSeed)
+	}
+
+	path = writeSeed(t, seedMarkets+"    price_precision: 4\n")
+	result, err = u.SeedMarkets(context.Background(), path)
+
+	if err != nil || result != (SeedResult{Updated: 1, Unchanged: 1}) {
+		t.Fatalf("Got = %+v, %v, Want = %+v\n", result, err, SeedResult{Updated: 1, Unchanged: 1})
+	}
+}
+
+func TestSeedMarketsInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    error
+	}{
+		{
+			name:    "Invalid entry after valid",
+			content: seedMarkets + "  - base_asset: sol\n",
+			want:    seed.ErrInvalidSeed,
+		},
+		{
+			name:    "Symbol of existing market under other id",
+			content: "markets:\n  - id: 7d9e2f1a-6b3c-4d5e-9f8a-0b1c2d3e4f5a\n    base_asset: btc\n    quote_asset: usdt\n",
+			want:    ErrAlreadyExists,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, _ := newTestUsecase(t)
+			_, err := u.CreateMarket(adminCtx, &pb.CreateMarketRequest{BaseAsset: "btc", QuoteAsset: "usdt"})
+
+			if err != nil {
+				t.Fatalf("Got = %v, Want = %v\n", err, nil)
+			}
+
+			result, err := u.SeedMarkets(context.Background(), writeSeed(t, tt.content))
+
+			if !errors.Is(err, tt.want) || result != (SeedResult{}) {
+				t.Fatalf("Got = %+v, %v, Want = %v\n", result, err, tt.want)
+			}
+
+			// Whole file is rejected before any write.
+			if markets, _ := u.allMarkets(context.Background()); len(markets) != 1 {
+				t.Fatalf("Got = %d markets, Want = 1\n", len(markets))
+			}
+		})
+	}
+}
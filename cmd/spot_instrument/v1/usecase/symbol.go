@@ -0,0 +1,195 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/KonnorFrik/BinaryTentacles/cmd/spot_instrument/v1/usecase/market"
+	redCache "github.com/KonnorFrik/BinaryTentacles/pkg/cache/redis"
+)
+
+const (
+	// symbolKeyPrefix - prefix of keys with id of market holding a symbol, full key is prefix + symbol.
+	symbolKeyPrefix = "symbol:"
+	// pendingSymbolTTL - how long symbol is reserved for market not saved yet,
+	// so reservation of crashed change is freed.
+	pendingSymbolTTL = time.Minute
+	// maxReserveAttempts - how many times reservation is tried if it changed concurrently.
+	maxReserveAttempts = 5
+)
+
+// saveMarketSymbol - save market 'mark' changed from 'before' and move reservation
+// of it symbol, so two not deleted markets never share a symbol.
+// Zero 'before' means new market.
+func (u *Usecase) saveMarketSymbol(
+	ctx context.Context,
+	mark *market.Market,
+	before market.Snapshot,
+) error {
+	var (
+		held  = before.Symbol != "" && before.DeletedAt.IsZero()
+		live  = mark.DeletedAt.IsZero()
+		claim = live && (!held || before.Symbol != mark.Symbol)
+	)
+
+	if claim {
+		if err := u.reserveSymbol(ctx, mark.Symbol, mark.Id); err != nil {
+			return err
+		}
+	}
+
+	if err := u.saveMarket(ctx, mark); err != nil {
+		if claim {
+			u.releaseSymbol(ctx, mark.Symbol, mark.Id)
+		}
+
+		return err
+	}
+
+	if claim {
+		u.confirmSymbol(ctx, mark.Symbol, mark.Id)
+	}
+
+	if held && (!live || before.Symbol != mark.Symbol) {
+		u.releaseSymbol(ctx, before.Symbol, mark.Id)
+	}
+
+	return nil
+}
+
+// reserveSymbol - atomically reserve 'symbol' for market 'marketId' for pendingSymbolTTL.
+// Reservation of deleted market or market with other symbol is taken over.
+// Returns ErrAlreadyExists if symbol is held by other not deleted or not saved yet market.
+func (u *Usecase) reserveSymbol(
+	ctx context.Context,
+	symbol string,
+	marketId string,
+) error {
+	var key = symbolKeyPrefix + symbol
+
+	for attempt := 1; ; attempt++ {
+		reserved, err := u.auditCache.SetNew(ctx, key, marketId, pendingSymbolTTL)
+
+		if err != nil {
+			return fmt.Errorf("%w: reserve symbol: %w", ErrInternal, err)
+		}
+
+		if reserved {
+			break
+		}
+
+		err = u.auditCache.SetIf(ctx, key, marketId, pendingSymbolTTL, func(owner string) error {
+			if owner == marketId {
+				return nil
+			}
+
+			return u.checkSymbolOwner(ctx, symbol, owner)
+		})
+
+		switch {
+		case err == nil:
+		case errors.Is(err, ErrAlreadyExists), errors.Is(err, ErrInternal):
+			return err
+		case (errors.Is(err, redCache.ErrNil) || errors.Is(err, redCache.ErrChanged)) && attempt < maxReserveAttempts:
+			continue
+		default:
+			return fmt.Errorf("%w: reserve symbol: %w", ErrInternal, err)
+		}
+
+		break
+	}
+
+	// Markets saved before reservations are not reserved.
+	if err := u.checkSymbolFree(ctx, symbol, marketId); err != nil {
+		u.releaseSymbol(ctx, symbol, marketId)
+		return err
+	}
+
+	return nil
+}
+
+// checkSymbolOwner - check is reservation of 'symbol' by market 'ownerId' can be taken over.
+func (u *Usecase) checkSymbolOwner(
+	ctx context.Context,
+	symbol string,
+	ownerId string,
+) error {
+	owner, err := u.marketById(ctx, ownerId)
+
+	switch {
+	case errors.Is(err, ErrNoMarkets):
+		// Owner is not saved yet, reservation expires if it never will.
+		return fmt.Errorf("%w: market %q is being created", ErrAlreadyExists, symbol)
+	case err != nil:
+		return err
+	case owner.DeletedAt.IsZero() && owner.Symbol == symbol:
+		return fmt.Errorf("%w: market %q", ErrAlreadyExists, symbol)
+	}
+
+	return nil
+}
+
+// confirmSymbol - make reservation of 'symbol' by saved market 'marketId' permanent.
+// Errors only logged, market already saved.
+func (u *Usecase) confirmSymbol(
+	ctx context.Context,
+	symbol string,
+	marketId string,
+) {
+	err := u.auditCache.SetIf(ctx, symbolKeyPrefix+symbol, marketId, 0, func(owner string) error {
+		if owner != marketId {
+			return fmt.Errorf("symbol is reserved by market %s", owner)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		u.logSymbolError(ctx, "[SpotInstrument/confirmSymbol]", symbol, marketId, err)
+	}
+}
+
+// releaseSymbol - drop reservation of 'symbol' if it held by market 'marketId'.
+// Errors only logged, left reservation is taken over by next reserve.
+func (u *Usecase) releaseSymbol(
+	ctx context.Context,
+	symbol string,
+	marketId string,
+) {
+	var key = symbolKeyPrefix + symbol
+	err := u.auditCache.Transaction(ctx, func(tx *redCache.Tx) error {
+		owner, err := tx.Get(key)
+
+		if err != nil || owner != marketId {
+			return err
+		}
+
+		tx.Delete(key)
+		return nil
+	}, key)
+
+	if err != nil && !errors.Is(err, redCache.ErrNil) {
+		u.logSymbolError(ctx, "[SpotInstrument/releaseSymbol]", symbol, marketId, err)
+	}
+}
+
+// logSymbolError - log error of reservation of 'symbol' for market 'marketId'.
+func (u *Usecase) logSymbolError(
+	ctx context.Context,
+	msg string,
+	symbol string,
+	marketId string,
+	err error,
+) {
+	logger.LogAttrs(
+		ctx,
+		slog.LevelError,
+		msg,
+		slog.String("symbol", symbol),
+		slog.String("market", marketId),
+		slog.String("error", err.Error()),
+	)
+}
@@ -0,0 +1,168 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/KonnorFrik/BinaryTentacles/cmd/spot_instrument/v1/usecase/market"
+	"github.com/KonnorFrik/BinaryTentacles/cmd/spot_instrument/v1/usecase/repository"
+	pb "github.com/KonnorFrik/BinaryTentacles/internal/generated/spot_instrument/v1"
+	"github.com/KonnorFrik/BinaryTentacles/pkg/auth"
+	redCache "github.com/KonnorFrik/BinaryTentacles/pkg/cache/redis"
+	"github.com/alicebob/miniredis/v2"
+)
+
+var adminCtx = auth.NewContext(context.Background(), auth.Principal{Name: "alice", Role: "admin"})
+
+// newTestUsecase - create Usecase with markets in memory and audit in miniredis.
+func newTestUsecase(t *testing.T) (*Usecase, *miniredis.Miniredis) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	return newTestUsecaseOn(t, server), server
+}
+
+// newTestUsecaseOn - create Usecase with 'opts', markets in memory and audit in redis 'server'.
+func newTestUsecaseOn(t *testing.T, server *miniredis.Miniredis, opts ...Option) *Usecase {
+	t.Helper()
+	cache, err := redCache.New(context.Background(), redCache.Config{Addr: server.Addr()})
+
+	if err != nil {
+		t.Fatalf("Got = %v, Want = %v\n", err, nil)
+	}
+
+	u, err := New(context.Background(), repository.NewMemory(), cache, opts...)
+
+	if err != nil {
+		t.Fatalf("Got = %v, Want = %v\n", err, nil)
+	}
+
+	t.Cleanup(func() {
+		u.ShutdownMarketWatch(context.Background())
+		u.ShutdownCandleWatch(context.Background())
+		cache.Close(context.Background())
+	})
+	return u
+}
+
+func TestCreateMarketSymbolRace(t *testing.T) {
+	u, _ := newTestUsecase(t)
+
+	const callers = 16
+	var (
+		wg      sync.WaitGroup
+		results = make(chan error, callers)
+	)
+
+	for range callers {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			_, err := u.CreateMarket(adminCtx, &pb.CreateMarketRequest{BaseAsset: "btc", QuoteAsset: "usdt"})
+			results <- err
+		}()
+	}
+
+	wg.Wait()
+	close(results)
+
+	var created int
+
+	for err := range results {
+		switch {
+		case err == nil:
+			created++
+		case !errors.Is(err, ErrAlreadyExists):
+			t.Fatalf("Got = %v, Want = %v\n", err, ErrAlreadyExists)
+		}
+	}
+
+	if created != 1 {
+		t.Fatalf("Got = %d created, Want = 1\n", created)
+	}
+}
+
+func TestSymbolReservation(t *testing.T) {
+	u, server := newTestUsecase(t)
+	first, err := u.CreateMarket(adminCtx, &pb.CreateMarketRequest{BaseAsset: "eth", QuoteAsset: "usdt"})
+
+	if err != nil {
+		t.Fatalf("Got = %v, Want = %v\n", err, nil)
+	}
+
+	if owner, _ := server.Get(symbolKeyPrefix + first.Symbol); owner != first.Id || server.TTL(symbolKeyPrefix+first.Symbol) != 0 {
+		t.Fatalf("Got = %q, Want = %q without ttl\n", owner, first.Id)
+	}
+
+	_, err = u.SoftDeleteMarket(adminCtx, &pb.MarketActionRequest{MarketId: first.Id})
+
+	if err != nil {
+		t.Fatalf("Got = %v, Want = %v\n", err, nil)
+	}
+
+	second, err := u.CreateMarket(adminCtx, &pb.CreateMarketRequest{BaseAsset: "eth", QuoteAsset: "usdt"})
+
+	if err != nil {
+		t.Fatalf("Got = %v, Want = %v\n", err, nil)
+	}
+
+	if _, err = u.RestoreMarket(adminCtx, &pb.MarketActionRequest{MarketId: first.Id}); !errors.Is(err, ErrAlreadyExists) {
+		t.Fatalf("Got = %v, Want = %v\n", err, ErrAlreadyExists)
+	}
+
+	// Rename frees old symbol.
+	_, err = u.UpdateMarket(adminCtx, &pb.UpdateMarketRequest{MarketId: second.Id, QuoteAsset: ptr("usdc")})
+
+	if err != nil {
+		t.Fatalf("Got = %v, Want = %v\n", err, nil)
+	}
+
+	restored, err := u.RestoreMarket(adminCtx, &pb.MarketActionRequest{MarketId: first.Id})
+
+	if err != nil || !restored.DeletedAt.IsZero() {
+		t.Fatalf("Got = %v, Want = %v\n", err, nil)
+	}
+}
+
+func TestSymbolOfUnsavedMarket(t *testing.T) {
+	u, server := newTestUsecase(t)
+	symbol := market.NewSymbol("SOL", "USDT")
+
+	// Reservation of market being created by other call.
+	if err := server.Set(symbolKeyPrefix+symbol, "6a1f8ab2-4b0e-4a53-9d5e-3d1d8f0c2a11"); err != nil {
+		t.Fatalf("Got = %v, Want = %v\n", err, nil)
+	}
+
+	_, err := u.CreateMarket(adminCtx, &pb.CreateMarketRequest{BaseAsset: "sol", QuoteAsset: "usdt"})
+
+	if !errors.Is(err, ErrAlreadyExists) {
+		t.Fatalf("Got = %v, Want = %v\n", err, ErrAlreadyExists)
+	}
+}
+
+func TestAdminActor(t *testing.T) {
+	u, _ := newTestUsecase(t)
+	_, err := u.CreateMarket(context.Background(), &pb.CreateMarketRequest{Actor: "alice", BaseAsset: "xrp", QuoteAsset: "usdt"})
+
+	if !errors.Is(err, ErrForbidden) {
+		t.Fatalf("Got = %v, Want = %v\n", err, ErrForbidden)
+	}
+
+	mark, err := u.CreateMarket(adminCtx, &pb.CreateMarketRequest{Actor: "mallory", BaseAsset: "xrp", QuoteAsset: "usdt"})
+
+	if err != nil {
+		t.Fatalf("Got = %v, Want = %v\n", err, nil)
+	}
+
+	records, err := u.GetMarketAudit(adminCtx, &pb.GetMarketAuditRequest{MarketId: mark.Id})
+
+	if err != nil || len(records) != 1 || records[0].Actor != "alice" {
+		t.Fatalf("Got = %v, %v, Want = one record by %q\n", records, err, "alice")
+	}
+}
+
+func ptr[T any](value T) *T {
+	return &value
+}
@@ -0,0 +1,184 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/KonnorFrik/BinaryTentacles/cmd/spot_instrument/v1/usecase/candle"
+	"github.com/KonnorFrik/BinaryTentacles/cmd/spot_instrument/v1/usecase/market"
+	"github.com/KonnorFrik/BinaryTentacles/cmd/spot_instrument/v1/usecase/ticker"
+	pb "github.com/KonnorFrik/BinaryTentacles/internal/generated/spot_instrument/v1"
+	"github.com/google/uuid"
+)
+
+// maxExecutedAtSkew - how far trade may be executed ahead of clock of service,
+// for clock drift between services.
+const maxExecutedAtSkew = 2 * time.Second
+
+// ReportTrade - feed executed trade in ticker, candles and circuit breaker logic.
+// Halt the market if breaker tripped.
+// Trades executed in future are rejected, they would open candles ahead of time.
+// Returns trading state of market after report.
+func (u *Usecase) ReportTrade(
+	ctx context.Context,
+	req *pb.ReportTradeRequest,
+) (
+	market.State,
+	string,
+	error,
+) {
+	if err := uuid.Validate(req.GetMarketId()); err != nil {
+		return market.StateClosed, "", fmt.Errorf("%w: invalid market id", ErrInvalidInput)
+	}
+
+	if req.GetPrice() <= 0 || req.GetQuantity() == 0 {
+		return market.StateClosed, "", fmt.Errorf("%w: price and quantity must be positive", ErrInvalidInput)
+	}
+
+	if req.GetBestBid() < 0 || req.GetBestAsk() < 0 {
+		return market.StateClosed, "", fmt.Errorf("%w: best bid and ask can't be negative", ErrInvalidInput)
+	}
+
+	mark, err := u.marketById(ctx, req.GetMarketId())
+
+	if err != nil {
+		return market.StateClosed, "", err
+	}
+
+	var (
+		now        = u.clock.Now()
+		executedAt = now
+	)
+
+	if req.GetExecutedAt() != nil {
+		executedAt = market.TimeFromProtobuf(req.GetExecutedAt())
+	}
+
+	if executedAt.After(now.Add(maxExecutedAtSkew)) {
+		return market.StateClosed, "", fmt.Errorf("%w: trade executed in future", ErrInvalidInput)
+	}
+
+	u.marketTickers.Record(mark.Id, ticker.Trade{
+		Price:    req.GetPrice(),
+		Quantity: req.GetQuantity(),
+		At:       executedAt,
+	})
+
+	u.recordCandleTrade(ctx, mark.Id, candle.Trade{
+		Price:    req.GetPrice(),
+		Quantity: req.GetQuantity(),
+		At:       executedAt,
+	})
+
+	if req.GetBestBid() > 0 || req.GetBestAsk() > 0 {
+		u.marketTickers.SetQuote(mark.Id, req.GetBestBid(), req.GetBestAsk(), executedAt)
+	}
+
+	return u.observePrice(ctx, mark, req.GetPrice(), now)
+}
+
+// GetTicker - return ticker of one market logic.
+func (u *Usecase) GetTicker(
+	ctx context.Context,
+	req *pb.GetTickerRequest,
+) (
+	ticker.Ticker,
+	error,
+) {
+	mark, err := u.visibleMarketById(ctx, req.GetUserRole(), req.GetMarketId())
+
+	if err != nil {
+		return ticker.Ticker{}, err
+	}
+
+	tick, _ := u.marketTickers.Get(mark.Id, u.clock.Now())
+	tick.Symbol = mark.Symbol
+	return tick, nil
+}
+
+// GetTickers - return tickers of all visible markets logic.
+// Ordered by symbol.
+func (u *Usecase) GetTickers(
+	ctx context.Context,
+	req *pb.GetTickersRequest,
+) (
+	[]ticker.Ticker,
+	error,
+) {
+	if err := checkRole(req.GetUserRole()); err != nil {
+		return nil, err
+	}
+
+	markets, err := u.allMarkets(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var now = u.clock.Now()
+	markets = visibleMarkets(req.GetUserRole(), markets, now)
+	slices.SortFunc(markets, func(a, b *market.Market) int {
+		return strings.Compare(a.Symbol, b.Symbol)
+	})
+	var tickers = make([]ticker.Ticker, len(markets))
+
+	for i, mark := range markets {
+		tickers[i], _ = u.marketTickers.Get(mark.Id, now)
+		tickers[i].Symbol = mark.Symbol
+	}
+
+	return tickers, nil
+}
+
+// ShutdownTickerWatch - stop all watches of tickers.
+// Must be called before graceful stop of server, watch streams never end by itself.
+func (u *Usecase) ShutdownTickerWatch(ctx context.Context) error {
+	u.marketTickers.Close()
+	return nil
+}
+
+// TickerWatch - started watch of one ticker.
+type TickerWatch struct {
+	// Current - ticker at start of watch.
+	Current ticker.Ticker
+	// Updates - ticker on every change. Closed by Stop or on shutdown.
+	Updates <-chan ticker.Ticker
+	// Stop - stop watch.
+	Stop   func()
+	symbol string
+}
+
+// Symbol - fill symbol of market in 'tick'.
+func (w *TickerWatch) Symbol(tick *ticker.Ticker) {
+	tick.Symbol = w.symbol
+}
+
+// WatchTicker - start watch of one ticker logic.
+// Caller must Stop returned watch.
+func (u *Usecase) WatchTicker(
+	ctx context.Context,
+	req *pb.WatchTickerRequest,
+) (
+	*TickerWatch,
+	error,
+) {
+	mark, err := u.visibleMarketById(ctx, req.GetUserRole(), req.GetMarketId())
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Subscribe before read current, so no change lost between them.
+	updates, stop := u.marketTickers.Subscribe(mark.Id)
+	current, _ := u.marketTickers.Get(mark.Id, u.clock.Now())
+	current.Symbol = mark.Symbol
+	return &TickerWatch{
+		Current: current,
+		Updates: updates,
+		Stop:    stop,
+		symbol:  mark.Symbol,
+	}, nil
+}
@@ -0,0 +1,45 @@
+package ticker
+
+import (
+	pb "github.com/KonnorFrik/BinaryTentacles/internal/generated/spot_instrument/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ToProtobuf - convert Ticker object in pb.Ticker object.
+func ToProtobuf(in *Ticker, out *pb.Ticker) {
+	if in == nil || out == nil {
+		return
+	}
+
+	out.MarketId = in.MarketId
+	out.Symbol = in.Symbol
+	out.LastPrice = in.LastPrice
+	out.LastQuantity = in.LastQuantity
+	out.BestBid = in.BestBid
+	out.BestAsk = in.BestAsk
+	out.Open_24H = in.Open
+	out.High_24H = in.High
+	out.Low_24H = in.Low
+	out.Volume_24H = in.Volume
+	out.TradeCount_24H = in.TradeCount
+	out.Change_24H = in.Change
+	out.ChangePercent_24H = in.ChangePercent
+
+	if !in.LastTradeAt.IsZero() {
+		out.LastTradeAt = timestamppb.New(in.LastTradeAt)
+	}
+
+	if !in.UpdatedAt.IsZero() {
+		out.UpdatedAt = timestamppb.New(in.UpdatedAt)
+	}
+}
+
+// ToProtobufMany - convert many Ticker objects in pb.Ticker objects.
+func ToProtobufMany(in []Ticker, out []*pb.Ticker) {
+	maxInd := min(len(in), len(out))
+
+	for i := range maxInd {
+		out[i] = new(pb.Ticker)
+		ToProtobuf(&in[i], out[i])
+	}
+}
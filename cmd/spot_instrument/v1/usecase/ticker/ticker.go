@@ -0,0 +1,302 @@
+/*
+Last price, top of book and rolling 24h statistics of markets.
+Trades are aggregated in minute buckets, so memory per market is fixed.
+*/
+package ticker
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrClosed - tickers closed, no more updates will be sent.
+	ErrClosed = errors.New("ticker watch is closed")
+)
+
+const (
+	// Window - period of rolling statistics.
+	Window      = 24 * time.Hour
+	bucketSize  = time.Minute
+	bucketCount = int(Window / bucketSize)
+)
+
+// Trade - one executed trade.
+type Trade struct {
+	Price    int64
+	Quantity uint64
+	At       time.Time
+}
+
+// Ticker - state of one market.
+// Open, High, Low, Volume and TradeCount are for last Window.
+type Ticker struct {
+	MarketId string
+	// Symbol - not known by Tickers, filled by caller.
+	Symbol       string
+	LastPrice    int64
+	LastQuantity uint64
+	LastTradeAt  time.Time
+	// BestBid, BestAsk - top of book, zero if unknown.
+	BestBid    int64
+	BestAsk    int64
+	Open       int64
+	High       int64
+	Low        int64
+	Volume     uint64
+	TradeCount uint64
+	// Change - LastPrice - Open.
+	Change        int64
+	ChangePercent float64
+	UpdatedAt     time.Time
+}
+
+// bucket - trades of one minute.
+type bucket struct {
+	// start - begin of minute, zero for empty bucket.
+	start  time.Time
+	open   int64
+	high   int64
+	low    int64
+	volume uint64
+	count  uint64
+}
+
+// state - trades and subscribers of one market.
+type state struct {
+	buckets     [bucketCount]bucket
+	last        Trade
+	bestBid     int64
+	bestAsk     int64
+	updatedAt   time.Time
+	subscribers map[chan Ticker]struct{}
+}
+
+// Tickers - tickers of all markets.
+type Tickers struct {
+	mut     sync.Mutex
+	markets map[string]*state
+	closed  bool
+}
+
+// New - create a new empty Tickers.
+func New() *Tickers {
+	return &Tickers{
+		markets: make(map[string]*state),
+	}
+}
+
+// Record - add 'trade' of market 'marketId'.
+// Trades out of Window are not counted in statistics.
+// Returns ticker at time of trade.
+func (t *Tickers) Record(marketId string, trade Trade) Ticker {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+
+	st := t.market(marketId)
+	start := trade.At.Truncate(bucketSize)
+	b := &st.buckets[start.Unix()/int64(bucketSize/time.Second)%int64(bucketCount)]
+
+	switch {
+	case b.start.Equal(start):
+		b.high = max(b.high, trade.Price)
+		b.low = min(b.low, trade.Price)
+		b.volume += trade.Quantity
+		b.count++
+	case b.start.Before(start):
+		*b = bucket{
+			start:  start,
+			open:   trade.Price,
+			high:   trade.Price,
+			low:    trade.Price,
+			volume: trade.Quantity,
+			count:  1,
+		}
+	default:
+		// Bucket already reused by newer minute, trade is out of window.
+		return st.ticker(marketId, trade.At)
+	}
+
+	if !trade.At.Before(st.last.At) {
+		st.last = trade
+	}
+
+	st.updatedAt = trade.At
+	return t.notify(marketId, st, trade.At)
+}
+
+// SetQuote - set top of book of market 'marketId' at time 'at'.
+// Zero 'bid' or 'ask' keep previous value.
+// Returns ticker at time 'at'.
+func (t *Tickers) SetQuote(
+	marketId string,
+	bid int64,
+	ask int64,
+	at time.Time,
+) Ticker {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+
+	st := t.market(marketId)
+
+	if bid > 0 {
+		st.bestBid = bid
+	}
+
+	if ask > 0 {
+		st.bestAsk = ask
+	}
+
+	st.updatedAt = at
+	return t.notify(marketId, st, at)
+}
+
+// Get - return ticker of market 'marketId' at time 'now'.
+// Returns false if no trades and quotes of market.
+func (t *Tickers) Get(marketId string, now time.Time) (Ticker, bool) {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+
+	st, exist := t.markets[marketId]
+
+	if !exist {
+		return Ticker{MarketId: marketId}, false
+	}
+
+	return st.ticker(marketId, now), true
+}
+
+// Subscribe - receive ticker of market 'marketId' on every change.
+// Slow receiver get only latest ticker.
+// Returned function stop subscription and close channel.
+// Channel of closed Tickers is closed right away.
+func (t *Tickers) Subscribe(marketId string) (<-chan Ticker, func()) {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+
+	st := t.market(marketId)
+	ch := make(chan Ticker, 1)
+	st.subscribers[ch] = struct{}{}
+	stop := func() {
+		t.mut.Lock()
+		defer t.mut.Unlock()
+		unsubscribe(st, ch)
+	}
+
+	if t.closed {
+		unsubscribe(st, ch)
+	}
+
+	return ch, stop
+}
+
+// Close - stop all subscriptions.
+func (t *Tickers) Close() {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+	t.closed = true
+
+	for _, st := range t.markets {
+		for ch := range st.subscribers {
+			unsubscribe(st, ch)
+		}
+	}
+}
+
+// unsubscribe - remove subscriber 'ch' of market and close it.
+// Must be called with locked tickers.
+func unsubscribe(st *state, ch chan Ticker) {
+	if _, exist := st.subscribers[ch]; !exist {
+		return
+	}
+
+	delete(st.subscribers, ch)
+	close(ch)
+}
+
+// market - return state of market 'marketId', create it if not exist.
+// Must be called with locked tickers.
+func (t *Tickers) market(marketId string) *state {
+	st, exist := t.markets[marketId]
+
+	if !exist {
+		st = &state{subscribers: make(map[chan Ticker]struct{})}
+		t.markets[marketId] = st
+	}
+
+	return st
+}
+
+// notify - send ticker of market at time 'now' to all subscribers.
+// Must be called with locked tickers.
+func (t *Tickers) notify(marketId string, st *state, now time.Time) Ticker {
+	tick := st.ticker(marketId, now)
+
+	for ch := range st.subscribers {
+		select {
+		case ch <- tick:
+		default:
+			// Replace not received ticker with latest.
+			select {
+			case <-ch:
+			default:
+			}
+
+			ch <- tick
+		}
+	}
+
+	return tick
+}
+
+// ticker - build ticker of market at time 'now'.
+func (st *state) ticker(marketId string, now time.Time) Ticker {
+	var (
+		tick = Ticker{
+			MarketId:     marketId,
+			LastPrice:    st.last.Price,
+			LastQuantity: st.last.Quantity,
+			LastTradeAt:  st.last.At,
+			BestBid:      st.bestBid,
+			BestAsk:      st.bestAsk,
+			UpdatedAt:    st.updatedAt,
+		}
+		windowStart = now.Add(-Window)
+		openAt      time.Time
+	)
+
+	for i := range st.buckets {
+		b := &st.buckets[i]
+
+		if b.start.IsZero() || !b.start.After(windowStart) || b.start.After(now) {
+			continue
+		}
+
+		if tick.TradeCount == 0 {
+			tick.High, tick.Low = b.high, b.low
+
+		} else {
+			tick.High = max(tick.High, b.high)
+			tick.Low = min(tick.Low, b.low)
+		}
+
+		if openAt.IsZero() || b.start.Before(openAt) {
+			openAt = b.start
+			tick.Open = b.open
+		}
+
+		tick.Volume += b.volume
+		tick.TradeCount += b.count
+	}
+
+	if tick.TradeCount > 0 {
+		tick.Change = tick.LastPrice - tick.Open
+
+		if tick.Open != 0 {
+			tick.ChangePercent = float64(tick.Change) / float64(tick.Open) * 100
+		}
+	}
+
+	return tick
+}
@@ -0,0 +1,90 @@
+package ticker
+
+import (
+	"testing"
+	"time"
+)
+
+const marketId = "5d6f8857-fafe-432c-8380-2b340ec03bb7"
+
+func TestRecord(t *testing.T) {
+	var (
+		start   = time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+		tickers = New()
+		trades  = []Trade{
+			{Price: 100, Quantity: 1, At: start},
+			{Price: 120, Quantity: 2, At: start.Add(30 * time.Second)},
+			{Price: 90, Quantity: 3, At: start.Add(time.Hour)},
+			{Price: 110, Quantity: 4, At: start.Add(20 * time.Hour)},
+		}
+	)
+
+	for _, trade := range trades {
+		tickers.Record(marketId, trade)
+	}
+
+	cases := []struct {
+		name string
+		now  time.Time
+		want Ticker
+	}{
+		{
+			name: "All in window",
+			now:  start.Add(21 * time.Hour),
+			want: Ticker{LastPrice: 110, Open: 100, High: 120, Low: 90, Volume: 10, TradeCount: 4, Change: 10, ChangePercent: 10},
+		},
+		{
+			name: "First minute out of window",
+			now:  start.Add(Window + time.Minute),
+			want: Ticker{LastPrice: 110, Open: 90, High: 110, Low: 90, Volume: 7, TradeCount: 2, Change: 20, ChangePercent: 200.0 / 9},
+		},
+		{
+			name: "All out of window",
+			now:  start.Add(20*time.Hour + Window),
+			want: Ticker{LastPrice: 110},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, ok := tickers.Get(marketId, c.now)
+
+			if !ok {
+				t.Fatalf("Got no ticker\n")
+			}
+
+			got.MarketId, got.LastQuantity, got.LastTradeAt, got.UpdatedAt = "", 0, time.Time{}, time.Time{}
+
+			if got != c.want {
+				t.Fatalf("Got = %+v\nWant = %+v\n", got, c.want)
+			}
+		})
+	}
+}
+
+func TestSubscribeLatest(t *testing.T) {
+	var (
+		at      = time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+		tickers = New()
+	)
+
+	updates, stop := tickers.Subscribe(marketId)
+	defer stop()
+
+	for price := int64(1); price <= 5; price++ {
+		tickers.Record(marketId, Trade{Price: price, Quantity: 1, At: at})
+	}
+
+	tickers.SetQuote(marketId, 4, 0, at)
+	got := <-updates
+
+	if got.LastPrice != 5 || got.BestBid != 4 || got.BestAsk != 0 {
+		t.Fatalf("Got = %+v\n", got)
+	}
+
+	tickers.Close()
+
+	if _, isOpen := <-updates; isOpen {
+		t.Fatalf("Got open channel after Close\n")
+	}
+}
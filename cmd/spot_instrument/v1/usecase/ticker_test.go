@@ -0,0 +1,43 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	pb "github.com/KonnorFrik/BinaryTentacles/internal/generated/spot_instrument/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestReportTradeExecutedAt(t *testing.T) {
+	u, _ := newTestUsecase(t)
+	mark, err := u.CreateMarket(adminCtx, &pb.CreateMarketRequest{BaseAsset: "btc", QuoteAsset: "usdt", Enabled: true})
+
+	if err != nil {
+		t.Fatalf("Got = %v, Want = %v\n", err, nil)
+	}
+
+	cases := []struct {
+		name  string
+		after time.Duration
+		want  error
+	}{
+		{name: "Past", after: -time.Hour, want: nil},
+		{name: "Within skew", after: maxExecutedAtSkew / 2, want: nil},
+		{name: "Future", after: time.Minute, want: ErrInvalidInput},
+	}
+
+	for _, c := range cases {
+		_, _, err := u.ReportTrade(context.Background(), &pb.ReportTradeRequest{
+			MarketId:   mark.Id,
+			Price:      100,
+			Quantity:   1,
+			ExecutedAt: timestamppb.New(time.Now().Add(c.after)),
+		})
+
+		if !errors.Is(err, c.want) {
+			t.Errorf("%s: Got = %v, Want = %v\n", c.name, err, c.want)
+		}
+	}
+}
@@ -2,123 +2,501 @@ package usecase
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"time"
 
+	"github.com/KonnorFrik/BinaryTentacles/cmd/spot_instrument/v1/usecase/breaker"
+	"github.com/KonnorFrik/BinaryTentacles/cmd/spot_instrument/v1/usecase/candle"
 	"github.com/KonnorFrik/BinaryTentacles/cmd/spot_instrument/v1/usecase/market"
+	"github.com/KonnorFrik/BinaryTentacles/cmd/spot_instrument/v1/usecase/policy"
+	"github.com/KonnorFrik/BinaryTentacles/cmd/spot_instrument/v1/usecase/repository"
+	"github.com/KonnorFrik/BinaryTentacles/cmd/spot_instrument/v1/usecase/ticker"
+	"github.com/KonnorFrik/BinaryTentacles/cmd/spot_instrument/v1/usecase/watch"
 	pb "github.com/KonnorFrik/BinaryTentacles/internal/generated/spot_instrument/v1"
+	"github.com/KonnorFrik/BinaryTentacles/pkg/auth"
 	"github.com/google/uuid"
 
-	"github.com/KonnorFrik/BinaryTentacles/pkg/cache/redis"
+	redCache "github.com/KonnorFrik/BinaryTentacles/pkg/cache/redis"
+	"github.com/KonnorFrik/BinaryTentacles/pkg/eventbus"
+	"github.com/KonnorFrik/BinaryTentacles/pkg/keylock"
 	"github.com/KonnorFrik/BinaryTentacles/pkg/logging"
 )
 
+// scheduleDaysAhead - how many days of upcoming sessions return in schedule.
+const scheduleDaysAhead = 7
+
+const (
+	// defaultPageSize - page size of ViewMarkets if not given.
+	defaultPageSize = 100
+	// maxPageSize - max page size of ViewMarkets.
+	maxPageSize = 1000
+)
+
 var (
 	logger = logging.Default()
 )
 
-// ViewMarkets - return available markets logic.
-func ViewMarkets(
+// Usecase - logic of SpotInstrumentService with it dependencies.
+type Usecase struct {
+	// markets - storage of markets.
+	markets repository.MarketRepository
+	// auditCache - redis with audit trail of markets, revision of market changes
+	// and reservations of market symbols.
+	auditCache *redCache.Cache
+	// marketDataCache - trades and closed candles.
+	// Nil if not given, then candles are kept only in memory.
+	marketDataCache *redCache.Cache
+	clock           Clock
+	// priceBreaker - circuit breaker of market prices, nil if disabled.
+	priceBreaker  *breaker.Breaker
+	marketTickers *ticker.Tickers
+	marketCandles *candle.Aggregator
+	// tradeLocks - serialize save of trades and rebuild of candles of one market,
+	// so rebuilt bar has every recorded trade.
+	tradeLocks keylock.Locks
+	// marketLocks - serialize read, change and save of one market,
+	// so concurrent changes are not lost and audit has real state before change.
+	marketLocks     keylock.Locks
+	stopCandleFlush context.CancelFunc
+	marketWatchers  *watch.Hub
+	// bus - changes of markets for other services and instances, nil if not given.
+	bus eventbus.Bus
+	// marketChanges - changes of markets from all instances for watchers in this one,
+	// nil without bus.
+	marketChanges eventbus.Subscription
+}
+
+// New - create a new Usecase with markets stored in 'markets'
+// and audit trail of markets stored in 'auditCache'.
+// Starts background work, stop it with ShutdownCandleWatch.
+func New(
+	ctx context.Context,
+	markets repository.MarketRepository,
+	auditCache *redCache.Cache,
+	opts ...Option,
+) (
+	*Usecase,
+	error,
+) {
+	if markets == nil || auditCache == nil {
+		return nil, fmt.Errorf("%w: market repository and audit cache are required", ErrInvalidOption)
+	}
+
+	u := &Usecase{
+		markets:       markets,
+		auditCache:    auditCache,
+		clock:         systemClock{},
+		marketTickers: ticker.New(),
+		marketCandles: candle.NewAggregator(),
+	}
+
+	for _, opt := range opts {
+		if err := opt(u); err != nil {
+			return nil, err
+		}
+	}
+
+	// Subscribe before read revision, so no change after it lost.
+	if err := u.subscribeMarketChanges(ctx); err != nil {
+		return nil, err
+	}
+
+	var err error
+	u.marketWatchers, err = watch.New(
+		ctx,
+		redisSequence{cache: auditCache},
+		redisHistory{cache: auditCache, size: watchHistorySize},
+	)
+
+	if err != nil {
+		if u.marketChanges != nil {
+			u.marketChanges.Close()
+		}
+
+		return nil, fmt.Errorf("read revision of markets: %w", err)
+	}
+
+	if u.marketChanges != nil {
+		go u.relayMarketChanges(u.marketChanges)
+	}
+
+	var flushCtx context.Context
+	flushCtx, u.stopCandleFlush = context.WithCancel(context.Background())
+	go u.flushCandles(flushCtx)
+	return u, nil
+}
+
+// ViewMarkets - return page of markets selected by filters logic.
+// Returns token of next page, empty on last page.
+func (u *Usecase) ViewMarkets(
 	ctx context.Context,
 	req *pb.ViewMarketsRequest,
 ) (
 	[]*market.Market,
+	string,
 	error,
 ) {
-	if req.GetUserRole() != pb.UserRole_USER_ROLE_CUSTOMER {
-		return nil, fmt.Errorf("%w: you are not allow to see markets", ErrForbidden)
+	if err := checkRole(req.GetUserRole()); err != nil {
+		return nil, "", err
+	}
+
+	pageSize := int(req.GetPageSize())
+
+	switch {
+	case pageSize == 0:
+		pageSize = defaultPageSize
+	case pageSize > maxPageSize:
+		pageSize = maxPageSize
 	}
 
-	all, err := marketCache.Values(ctx)
+	query := market.QueryFromProtobuf(req)
+	after, err := decodePageToken(req.GetPageToken(), query.Sort)
 
 	if err != nil {
-		return nil, fmt.Errorf("%w: %w", ErrInternal, err)
+		return nil, "", err
 	}
 
-	if len(all) == 0 {
-		logger.LogAttrs(
-			ctx,
-			slog.LevelError,
-			"[SpotInstrument/ViewMarkets/GetJSONMarkets",
-			slog.String("error", "Got 0 markets from redis"),
-		)
-		return nil, ErrNoMarkets
+	markets, err := u.markets.Find(ctx, &query)
+
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %w", ErrInternal, err)
 	}
 
-	var marketsJson = make([]string, 0, len(all))
+	markets = visibleMarkets(req.GetUserRole(), markets, u.clock.Now())
+	page, last := query.Page(query.Select(markets), after, pageSize)
 
-	for _, v := range all {
-		mark, ok := v.(string)
+	if last == nil {
+		return page, "", nil
+	}
 
-		if ok {
-			marketsJson = append(marketsJson, mark)
+	return page, encodePageToken(query.Sort, *last), nil
+}
 
-		} else {
-			logger.LogAttrs(
-				ctx,
-				slog.LevelError,
-				"[SpotInstrument/ViewMarkets/ConvertToStr",
-				slog.String("error", fmt.Sprintf("<%T, %[1]+v> Not string", v)),
-			)
-		}
+// GetMarketBySymbol - return one market by it symbol logic.
+func (u *Usecase) GetMarketBySymbol(
+	ctx context.Context,
+	req *pb.GetMarketBySymbolRequest,
+) (
+	*market.Market,
+	error,
+) {
+	symbol := market.NormalizeSymbol(req.GetSymbol())
+
+	if symbol == "" {
+		return nil, fmt.Errorf("%w: empty symbol", ErrInvalidInput)
+	}
+
+	if err := checkRole(req.GetUserRole()); err != nil {
+		return nil, err
+	}
+
+	markets, err := u.allMarkets(ctx)
+
+	if err != nil {
+		return nil, err
 	}
 
 	var (
-		markets = make([]*market.Market, len(marketsJson))
-		ind     int
+		now   = u.clock.Now()
+		found *market.Market
 	)
 
-	for _, v := range marketsJson {
-		err = json.Unmarshal([]byte(v), &markets[ind])
-
-		if err == nil {
-			ind++
+	// Deleted market may share symbol with a new one, prefer not deleted.
+	for _, mark := range visibleMarkets(req.GetUserRole(), markets, now) {
+		if mark.Symbol != symbol {
+			continue
+		}
 
-		} else {
-			logger.LogAttrs(
-				ctx,
-				slog.LevelError,
-				"[SpotInstrument/ViewMarkets/Unmarshal]",
-				slog.String("error", err.Error()),
-			)
+		if mark.StageAt(now) != market.StageDeleted {
+			return mark, nil
 		}
+
+		found = mark
 	}
 
-	return markets, nil
+	if found == nil {
+		return nil, fmt.Errorf("%w: market %q not found", ErrNoMarkets, symbol)
+	}
+
+	return found, nil
 }
 
-func IsAvailable(
+// IsAvailable - return trading state of one market with reason logic.
+func (u *Usecase) IsAvailable(
 	ctx context.Context,
 	req *pb.IsAvailableRequest,
 ) (
-	bool,
+	market.State,
+	string,
+	error,
+) {
+	if err := uuid.Validate(req.GetMarketId()); err != nil {
+		return market.StateClosed, "", fmt.Errorf("%w: invalid market id", ErrInvalidInput)
+	}
+
+	if err := checkRole(req.GetUserRole()); err != nil {
+		return market.StateClosed, "", err
+	}
+
+	mark, err := u.marketById(ctx, req.GetMarketId())
+
+	if err != nil {
+		if errors.Is(err, ErrNoMarkets) {
+			return market.StateClosed, "market does not exist", nil
+		}
+
+		return market.StateClosed, "", err
+	}
+
+	state, reason := policy.TradingState(req.GetUserRole(), mark, u.clock.Now())
+	return state, reason, nil
+}
+
+// ReportPrice - feed a price of market in circuit breaker logic.
+// Halt the market if breaker tripped.
+// Returns trading state of market after report.
+// Deprecated: use ReportTrade, calls are logged with name of caller.
+func (u *Usecase) ReportPrice(
+	ctx context.Context,
+	req *pb.ReportPriceRequest,
+) (
+	market.State,
+	string,
 	error,
 ) {
+	logger.LogAttrs(
+		ctx,
+		slog.LevelWarn,
+		"[SpotInstrument/ReportPrice]",
+		slog.String("caller", auth.FromContext(ctx).Name),
+		slog.String("reason", "deprecated, use ReportTrade"),
+	)
+
 	if err := uuid.Validate(req.GetMarketId()); err != nil {
-		return false, fmt.Errorf("%w: invalid market id", ErrInvalidInput)
+		return market.StateClosed, "", fmt.Errorf("%w: invalid market id", ErrInvalidInput)
+	}
+
+	if req.GetPrice() <= 0 {
+		return market.StateClosed, "", fmt.Errorf("%w: price must be positive", ErrInvalidInput)
+	}
+
+	mark, err := u.marketById(ctx, req.GetMarketId())
+
+	if err != nil {
+		return market.StateClosed, "", err
+	}
+
+	return u.observePrice(ctx, mark, req.GetPrice(), u.clock.Now())
+}
+
+// observePrice - feed 'price' of market 'mark' at time 'now' in circuit breaker.
+// Halt the market if breaker tripped.
+// Returns trading state of market after observe.
+func (u *Usecase) observePrice(
+	ctx context.Context,
+	mark *market.Market,
+	price int64,
+	now time.Time,
+) (
+	market.State,
+	string,
+	error,
+) {
+	state, reason := mark.TradingState(now)
+
+	if state != market.StateActive || u.priceBreaker == nil {
+		return state, reason, nil
+	}
+
+	tripped, move := u.priceBreaker.Observe(mark.Id, price, now)
+
+	if !tripped {
+		return state, reason, nil
+	}
+
+	unlock := u.marketLocks.Lock(mark.Id)
+	defer unlock()
+	// Market may be changed after it read, halt the saved one.
+	mark, err := u.marketById(ctx, mark.Id)
+
+	if err != nil {
+		return market.StateClosed, "", err
+	}
+
+	if state, reason = mark.TradingState(now); state != market.StateActive {
+		return state, reason, nil
+	}
+
+	config := u.priceBreaker.Config()
+	reason = fmt.Sprintf(
+		"circuit breaker: price moved %.2f%% within %s",
+		move,
+		config.Window,
+	)
+	before := mark.Snapshot()
+	mark.Halt(now.Add(config.HaltFor), reason)
+
+	if err := u.saveMarket(ctx, mark); err != nil {
+		return market.StateClosed, "", err
+	}
+
+	u.recordAudit(ctx, mark, actorCircuitBreaker, market.ActionHalt, before)
+	u.publishChange(ctx, mark, before, actorCircuitBreaker, market.ActionHalt)
+
+	logger.LogAttrs(
+		ctx,
+		slog.LevelWarn,
+		"[SpotInstrument/observePrice]",
+		slog.String("Halted market", mark.Id),
+		slog.String("reason", reason),
+	)
+	return market.StateHalted, reason, nil
+}
+
+// GetTradingSchedule - return trading calendar of one market logic.
+func (u *Usecase) GetTradingSchedule(
+	ctx context.Context,
+	req *pb.GetTradingScheduleRequest,
+) (
+	market.ScheduleView,
+	error,
+) {
+	mark, err := u.visibleMarketById(ctx, req.GetUserRole(), req.GetMarketId())
+
+	if err != nil {
+		return market.ScheduleView{}, err
+	}
+
+	return mark.ScheduleAt(u.clock.Now(), scheduleDaysAhead), nil
+}
+
+// checkRole - check is 'role' allowed to see any market.
+func checkRole(role pb.UserRole) error {
+	if !policy.Known(role) {
+		return fmt.Errorf("%w: you are not allow to see markets", ErrForbidden)
+	}
+
+	return nil
+}
+
+// roleOf - return role of caller with 'requested' role.
+// Anonymous caller is a customer, authenticated one has role of it token.
+// Internal services may act for users of any role.
+func roleOf(ctx context.Context, requested pb.UserRole) pb.UserRole {
+	principal := auth.FromContext(ctx)
+
+	if principal.Anonymous() {
+		return pb.UserRole_USER_ROLE_CUSTOMER
+	}
+
+	role, known := policy.RoleByName(principal.Role)
+
+	switch {
+	case !known:
+		return pb.UserRole_USER_ROLE_CUSTOMER
+	case role == pb.UserRole_USER_ROLE_INTERNAL_SERVICE && requested != pb.UserRole_USER_ROLE_UNSPECIFIED:
+		return requested
 	}
 
-	if req.GetUserRole() != pb.UserRole_USER_ROLE_CUSTOMER {
-		return false, fmt.Errorf("%w: you are not allow to see markets", ErrForbidden)
+	return role
+}
+
+// visibleMarketById - get market by it id, if it visible for 'role'.
+func (u *Usecase) visibleMarketById(
+	ctx context.Context,
+	role pb.UserRole,
+	id string,
+) (
+	*market.Market,
+	error,
+) {
+	if err := uuid.Validate(id); err != nil {
+		return nil, fmt.Errorf("%w: invalid market id", ErrInvalidInput)
 	}
 
-	marketJSON, err := marketCache.Get(ctx, req.GetMarketId())
+	if err := checkRole(role); err != nil {
+		return nil, err
+	}
+
+	mark, err := u.marketById(ctx, id)
 
 	if err != nil {
-		if err == redis.ErrNil {
-			return false, nil
+		return nil, err
+	}
+
+	if !policy.CanSee(role, mark.StageAt(u.clock.Now())) {
+		return nil, fmt.Errorf("%w: market %q not found", ErrNoMarkets, id)
+	}
+
+	return mark, nil
+}
+
+// visibleMarkets - return markets visible for 'role' at time 'now'.
+func visibleMarkets(
+	role pb.UserRole,
+	markets []*market.Market,
+	now time.Time,
+) []*market.Market {
+	var visible = make([]*market.Market, 0, len(markets))
+
+	for _, mark := range markets {
+		if policy.CanSee(role, mark.StageAt(now)) {
+			visible = append(visible, mark)
 		}
+	}
+
+	return visible
+}
+
+// allMarkets - read all markets from repository.
+func (u *Usecase) allMarkets(
+	ctx context.Context,
+) (
+	[]*market.Market,
+	error,
+) {
+	markets, err := u.markets.List(ctx)
 
-		return false, fmt.Errorf("%w: %w", ErrInternal, err)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInternal, err)
 	}
 
-	var mark market.Market
-	err = json.Unmarshal([]byte(marketJSON), &mark)
+	return markets, nil
+}
+
+// marketById - get market from repository by it id.
+func (u *Usecase) marketById(
+	ctx context.Context,
+	id string,
+) (
+	*market.Market,
+	error,
+) {
+	mark, err := u.markets.Get(ctx, id)
 
 	if err != nil {
-		return false, fmt.Errorf("%w: %w", ErrInternal, err)
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("%w: market %q not found", ErrNoMarkets, id)
+		}
+
+		return nil, fmt.Errorf("%w: %w", ErrInternal, err)
+	}
+
+	return mark, nil
+}
+
+// saveMarket - write market in repository.
+func (u *Usecase) saveMarket(
+	ctx context.Context,
+	mark *market.Market,
+) error {
+	mark.Touch(u.clock.Now())
+
+	if err := u.markets.Save(ctx, mark); err != nil {
+		return fmt.Errorf("%w: %w", ErrInternal, err)
 	}
 
-	return mark.IsActive(), nil
+	return nil
 }
@@ -0,0 +1,346 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/KonnorFrik/BinaryTentacles/cmd/spot_instrument/v1/usecase/market"
+	"github.com/KonnorFrik/BinaryTentacles/cmd/spot_instrument/v1/usecase/policy"
+	"github.com/KonnorFrik/BinaryTentacles/cmd/spot_instrument/v1/usecase/watch"
+	pb "github.com/KonnorFrik/BinaryTentacles/internal/generated/spot_instrument/v1"
+
+	redCache "github.com/KonnorFrik/BinaryTentacles/pkg/cache/redis"
+	"github.com/KonnorFrik/BinaryTentacles/pkg/eventbus"
+	"github.com/google/uuid"
+)
+
+const (
+	// revisionKey - key of market revision counter in audit cache.
+	// Not a uuid, so never collide with audit trail of market.
+	revisionKey = "markets:revision"
+	// watchHistoryKey - key of sorted set with last market events by revision in audit cache.
+	// Shared by all instances, so watcher can resume on any of them.
+	watchHistoryKey = "markets:history"
+	// watchHistorySize - how many last market events kept for resume.
+	watchHistorySize = 1024
+	// MarketChangesTopic - topic of event bus with changes of markets, payload is MarketChange as JSON.
+	MarketChangesTopic = "market_changes"
+)
+
+// MarketChange - change of market published to event bus.
+type MarketChange struct {
+	Revision uint64         `json:"revision"`
+	Action   market.Action  `json:"action"`
+	Market   *market.Market `json:"market"`
+	Actor    string         `json:"actor"`
+	At       time.Time      `json:"at"`
+	// BeforeStage - stage of market before change.
+	BeforeStage market.Stage `json:"before_stage"`
+}
+
+// MarketWatch - started watch of markets.
+type MarketWatch struct {
+	*watch.Subscription
+	// Revision - revision of Snapshot.
+	Revision uint64
+	// Snapshot - all markets. Nil if watch resumed from requested revision.
+	Snapshot []*market.Market
+	// Missed - events after requested revision, sent before new events.
+	Missed []watch.Event
+	role   pb.UserRole
+}
+
+// Filter - return event 'ev' as it must be sent to watcher 'w', false if it must not be sent.
+// Event is sent if market is visible for watcher after or before change,
+// so watcher can add and drop the market.
+// Market hidden by change is sent as stub, see market.Stub.
+func (w *MarketWatch) Filter(ev watch.Event) (watch.Event, bool) {
+	switch {
+	case policy.CanSee(w.role, ev.Market.StageAt(ev.At)):
+		return ev, true
+	case ev.Action != market.ActionCreate && policy.CanSee(w.role, ev.Before):
+		ev.Market = ev.Market.Stub()
+		return ev, true
+	}
+
+	return ev, false
+}
+
+// WatchMarkets - start watch of market changes logic.
+// Role of watcher is taken from caller, see roleOf.
+// Caller must Close returned watch.
+func (u *Usecase) WatchMarkets(
+	ctx context.Context,
+	req *pb.WatchMarketsRequest,
+) (
+	*MarketWatch,
+	error,
+) {
+	role := roleOf(ctx, req.GetUserRole())
+
+	if err := checkRole(role); err != nil {
+		return nil, err
+	}
+
+	// Subscribe before read snapshot, so no change lost between them.
+	// Changes made while snapshot read may come twice: in snapshot and as event.
+	sub, missed, revision, resumed, err := u.marketWatchers.Subscribe(ctx, req.GetFromRevision())
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: read market history: %w", ErrInternal, err)
+	}
+
+	var result = MarketWatch{
+		Subscription: sub,
+		Revision:     revision,
+		role:         role,
+	}
+
+	for _, ev := range missed {
+		if ev, visible := result.Filter(ev); visible {
+			result.Missed = append(result.Missed, ev)
+		}
+	}
+
+	if resumed {
+		return &result, nil
+	}
+
+	markets, err := u.allMarkets(ctx)
+
+	if err != nil {
+		sub.Close()
+		return nil, err
+	}
+
+	result.Snapshot = visibleMarkets(result.role, markets, u.clock.Now())
+	return &result, nil
+}
+
+// ShutdownMarketWatch - stop all watches of markets.
+// Must be called before graceful stop of server, watch streams never end by itself.
+func (u *Usecase) ShutdownMarketWatch(ctx context.Context) error {
+	var err error
+
+	if u.marketChanges != nil {
+		err = u.marketChanges.Close()
+	}
+
+	u.marketWatchers.Close()
+	return err
+}
+
+// subscribeMarketChanges - subscribe to changes of markets of event bus in own group of this instance,
+// so watchers of every instance get changes made by all instances.
+// Without bus changes are sent only to watchers of this instance.
+func (u *Usecase) subscribeMarketChanges(ctx context.Context) error {
+	if u.bus == nil {
+		return nil
+	}
+
+	sub, err := u.bus.Subscribe(ctx, MarketChangesTopic, "spot_instrument:"+uuid.NewString(), eventbus.WithEphemeral())
+
+	if err != nil {
+		return fmt.Errorf("subscribe to market changes: %w", err)
+	}
+
+	u.marketChanges = sub
+	return nil
+}
+
+// relayMarketChanges - send changes received by 'sub' to watchers of this instance until subscription closed.
+func (u *Usecase) relayMarketChanges(sub eventbus.Subscription) {
+	for msg := range sub.Messages() {
+		var change MarketChange
+
+		if err := json.Unmarshal(msg.Payload, &change); err != nil {
+			logger.LogAttrs(
+				context.Background(),
+				slog.LevelError,
+				"[SpotInstrument/relayMarketChanges]",
+				slog.String("message", msg.Id),
+				slog.String("error", err.Error()),
+			)
+
+		} else {
+			u.marketWatchers.Deliver(context.Background(), change.event())
+		}
+
+		sub.Ack(context.Background(), msg)
+	}
+}
+
+// publishChange - notify watchers and event bus about change of market 'mark' from 'before'.
+// Errors only logged, change already saved.
+func (u *Usecase) publishChange(
+	ctx context.Context,
+	mark *market.Market,
+	before market.Snapshot,
+	actor string,
+	action market.Action,
+) {
+	now := u.clock.Now()
+	ev := watch.Event{
+		Action: action,
+		Market: mark,
+		Before: before.StageAt(now),
+		Actor:  actor,
+		At:     now,
+	}
+
+	if u.bus == nil {
+		_, err := u.marketWatchers.Publish(ctx, ev)
+		u.logChangeError(ctx, mark, action, err)
+		return
+	}
+
+	// Watchers of all instances, this one too, get change from bus.
+	ev, err := u.marketWatchers.Record(ctx, ev)
+
+	if ev.Revision != 0 {
+		changeBytes, e := json.Marshal(newMarketChange(ev))
+
+		if e == nil {
+			_, e = u.bus.Publish(ctx, MarketChangesTopic, changeBytes)
+		}
+
+		// Other instances find change in history on next one.
+		if e != nil {
+			u.marketWatchers.Deliver(ctx, ev)
+			err = errors.Join(err, e)
+		}
+	}
+
+	u.logChangeError(ctx, mark, action, err)
+}
+
+// logChangeError - log error 'err' of publish change 'action' of market 'mark', if any.
+func (u *Usecase) logChangeError(
+	ctx context.Context,
+	mark *market.Market,
+	action market.Action,
+	err error,
+) {
+	if err == nil {
+		return
+	}
+
+	logger.LogAttrs(
+		ctx,
+		slog.LevelError,
+		"[SpotInstrument/publishChange]",
+		slog.String("market", mark.Id),
+		slog.String("action", string(action)),
+		slog.String("error", err.Error()),
+	)
+}
+
+// newMarketChange - convert watch event 'ev' in MarketChange.
+func newMarketChange(ev watch.Event) MarketChange {
+	return MarketChange{
+		Revision:    ev.Revision,
+		Action:      ev.Action,
+		Market:      ev.Market,
+		Actor:       ev.Actor,
+		At:          ev.At,
+		BeforeStage: ev.Before,
+	}
+}
+
+// event - convert change 'c' in watch event.
+func (c MarketChange) event() watch.Event {
+	return watch.Event{
+		Revision: c.Revision,
+		Action:   c.Action,
+		Market:   c.Market,
+		Before:   c.BeforeStage,
+		Actor:    c.Actor,
+		At:       c.At,
+	}
+}
+
+// redisSequence - revisions stored in redis, so they keep growing after restart.
+type redisSequence struct {
+	cache *redCache.Cache
+}
+
+// Current - implement watch.Sequence interface.
+func (r redisSequence) Current(ctx context.Context) (uint64, error) {
+	value, err := r.cache.Get(ctx, revisionKey)
+
+	if err == redCache.ErrNil {
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.ParseUint(value, 10, 64)
+}
+
+// Next - implement watch.Sequence interface.
+func (r redisSequence) Next(ctx context.Context) (uint64, error) {
+	revision, err := r.cache.Incr(ctx, revisionKey)
+	return uint64(revision), err
+}
+
+// redisHistory - last 'size' market events stored in redis as MarketChange JSON scored by revision.
+type redisHistory struct {
+	cache *redCache.Cache
+	size  uint64
+}
+
+// Add - implement watch.History interface.
+func (r redisHistory) Add(ctx context.Context, ev watch.Event) error {
+	changeBytes, err := json.Marshal(newMarketChange(ev))
+
+	if err != nil {
+		return err
+	}
+
+	if err = r.cache.SortedAdd(ctx, watchHistoryKey, float64(ev.Revision), string(changeBytes)); err != nil {
+		return err
+	}
+
+	if ev.Revision <= r.size {
+		return nil
+	}
+
+	return r.cache.SortedRemove(ctx, watchHistoryKey, 0, float64(ev.Revision-r.size))
+}
+
+// Range - implement watch.History interface.
+// Undecodable events are skipped, watcher resumed over them gets a snapshot.
+func (r redisHistory) Range(ctx context.Context, from uint64, to uint64) ([]watch.Event, error) {
+	members, err := r.cache.SortedRange(ctx, watchHistoryKey, float64(from+1), float64(to))
+
+	if err != nil {
+		return nil, err
+	}
+
+	var events = make([]watch.Event, 0, len(members))
+
+	for _, member := range members {
+		var change MarketChange
+
+		if err = json.Unmarshal([]byte(member), &change); err != nil {
+			logger.LogAttrs(
+				ctx,
+				slog.LevelError,
+				"[SpotInstrument/redisHistory/Range]",
+				slog.String("error", err.Error()),
+			)
+			continue
+		}
+
+		events = append(events, change.event())
+	}
+
+	return events, nil
+}
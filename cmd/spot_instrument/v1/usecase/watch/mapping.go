@@ -0,0 +1,47 @@
+package watch
+
+import (
+	"time"
+
+	"github.com/KonnorFrik/BinaryTentacles/cmd/spot_instrument/v1/usecase/market"
+	pb "github.com/KonnorFrik/BinaryTentacles/internal/generated/spot_instrument/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ToProtobuf - convert Event object in pb.WatchMarketsResponse object.
+// State of market evaluated at 'now'.
+func ToProtobuf(in *Event, out *pb.WatchMarketsResponse, now time.Time) {
+	if in == nil || out == nil {
+		return
+	}
+
+	var event = pb.MarketEvent{
+		Type:   market.ActionToEventProtobuf(in.Action),
+		Market: new(pb.Market),
+		Actor:  in.Actor,
+		At:     timestamppb.New(in.At),
+	}
+	market.ToProtobuf(in.Market, event.Market, now)
+	out.Revision = in.Revision
+	out.Payload = &pb.WatchMarketsResponse_Event{Event: &event}
+}
+
+// SnapshotToProtobuf - convert markets at 'revision' in pb.WatchMarketsResponse object.
+// State of markets evaluated at 'now'.
+func SnapshotToProtobuf(
+	in []*market.Market,
+	revision uint64,
+	out *pb.WatchMarketsResponse,
+	now time.Time,
+) {
+	if out == nil {
+		return
+	}
+
+	var snapshot = pb.MarketSnapshot{
+		Markets: make([]*pb.Market, len(in)),
+	}
+	market.ToProtobufMany(in, snapshot.Markets, now)
+	out.Revision = revision
+	out.Payload = &pb.WatchMarketsResponse_Snapshot{Snapshot: &snapshot}
+}
@@ -0,0 +1,393 @@
+/*
+Fan-out of market changes to watchers.
+Every change get a monotonically increasing revision from Sequence.
+Recent changes are kept in History, so watcher can resume from last seen revision.
+Sequence and History may be shared by hubs of many instances, so watcher can resume on any of them.
+Then every change is recorded by one hub with Record and delivered to hubs of all instances
+with Deliver, e.g. through event bus. Hub of single instance records and sends it with Publish.
+*/
+package watch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/KonnorFrik/BinaryTentacles/cmd/spot_instrument/v1/usecase/market"
+)
+
+var (
+	// ErrLagged - watcher read events too slow and was dropped.
+	// Watcher can resume from last received revision.
+	ErrLagged = errors.New("watcher is too slow")
+	// ErrClosed - hub is closed, no more events will be published.
+	ErrClosed = errors.New("market watch is closed")
+)
+
+// subscriberBuffer - how many events can wait for read in one subscription.
+const subscriberBuffer = 64
+
+// Sequence - source of revisions.
+type Sequence interface {
+	// Current - return last issued revision. Zero if no one issued.
+	Current(ctx context.Context) (uint64, error)
+	// Next - issue and return next revision.
+	Next(ctx context.Context) (uint64, error)
+}
+
+// History - storage of recent events.
+type History interface {
+	// Add - keep event 'ev'. Older events may be dropped.
+	Add(ctx context.Context, ev Event) error
+	// Range - return kept events with revision in ('from', 'to'], oldest first.
+	Range(ctx context.Context, from uint64, to uint64) ([]Event, error)
+}
+
+// Event - one change of market.
+type Event struct {
+	Revision uint64
+	Action   market.Action
+	// Market - market after change. Must not be changed after publish.
+	Market *market.Market
+	// Before - stage of market before change at time of change. Meaningless for created market.
+	Before market.Stage
+	Actor  string
+	At     time.Time
+}
+
+// Hub - deliver published events to all subscriptions.
+type Hub struct {
+	// publishMut - serialize publishes, so events delivered in order of revisions.
+	// Subscriptions don't wait for sequence and history behind it.
+	publishMut  sync.Mutex
+	mut         sync.Mutex
+	sequence    Sequence
+	history     History
+	revision    uint64
+	subscribers map[*Subscription]struct{}
+	closed      bool
+}
+
+// New - create a new Hub.
+// Published events are kept in 'history' for resume.
+func New(
+	ctx context.Context,
+	sequence Sequence,
+	history History,
+) (
+	*Hub,
+	error,
+) {
+	revision, err := sequence.Current(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Hub{
+		sequence:    sequence,
+		history:     history,
+		revision:    revision,
+		subscribers: make(map[*Subscription]struct{}),
+	}, nil
+}
+
+// Publish - assign next revision to event 'ev' and send it to all subscriptions.
+// Subscription which can't accept event is dropped with ErrLagged.
+// Event is sent even if it not kept in history, watcher resumed over it gets a snapshot.
+// Returns event with revision.
+func (h *Hub) Publish(
+	ctx context.Context,
+	ev Event,
+) (
+	Event,
+	error,
+) {
+	h.publishMut.Lock()
+	defer h.publishMut.Unlock()
+
+	ev, err := h.record(ctx, ev)
+
+	if ev.Revision == 0 {
+		return ev, err
+	}
+
+	h.mut.Lock()
+	defer h.mut.Unlock()
+	h.send(ev)
+	return ev, err
+}
+
+// Record - assign next revision to event 'ev' and keep it in history, without send.
+// Recorded event must be sent by Deliver of hubs of all instances.
+// Returns event with revision, zero revision if it not assigned.
+func (h *Hub) Record(
+	ctx context.Context,
+	ev Event,
+) (
+	Event,
+	error,
+) {
+	h.publishMut.Lock()
+	defer h.publishMut.Unlock()
+	return h.record(ctx, ev)
+}
+
+// Deliver - send event 'ev' recorded by hub of any instance to all subscriptions.
+// Events come in order of revisions: event at or before sent revision is ignored,
+// events missed before 'ev' are read from history. If some of them are not kept,
+// all subscriptions are dropped with ErrLagged, so watchers resume from history or snapshot.
+func (h *Hub) Deliver(
+	ctx context.Context,
+	ev Event,
+) {
+	h.publishMut.Lock()
+	defer h.publishMut.Unlock()
+
+	h.mut.Lock()
+	revision := h.revision
+	h.mut.Unlock()
+
+	if ev.Revision <= revision {
+		return
+	}
+
+	var events []Event
+
+	if ev.Revision > revision+1 {
+		missed, err := h.history.Range(ctx, revision, ev.Revision-1)
+
+		if err == nil && complete(missed, revision, ev.Revision-1) {
+			events = missed
+		}
+	}
+
+	events = append(events, ev)
+	h.mut.Lock()
+	defer h.mut.Unlock()
+
+	if events[0].Revision != revision+1 {
+		for sub := range h.subscribers {
+			h.drop(sub, ErrLagged)
+		}
+	}
+
+	for _, ev := range events {
+		h.send(ev)
+	}
+}
+
+// record - assign next revision to event 'ev' and keep it in history.
+// Must be called with locked publishMut.
+func (h *Hub) record(
+	ctx context.Context,
+	ev Event,
+) (
+	Event,
+	error,
+) {
+	revision, err := h.sequence.Next(ctx)
+
+	if err != nil {
+		return ev, err
+	}
+
+	ev.Revision = revision
+
+	// Keep before send, so subscription at this revision find event in history.
+	if err = h.history.Add(ctx, ev); err != nil {
+		return ev, fmt.Errorf("keep event %d: %w", revision, err)
+	}
+
+	return ev, nil
+}
+
+// send - send event 'ev' to all subscriptions and make it revision current.
+// Subscription which can't accept event is dropped with ErrLagged.
+// Must be called with locked hub.
+func (h *Hub) send(ev Event) {
+	h.revision = ev.Revision
+
+	for sub := range h.subscribers {
+		select {
+		case sub.events <- ev:
+		default:
+			h.drop(sub, ErrLagged)
+		}
+	}
+}
+
+// Subscribe - create a new subscription for events after revision 'from'.
+// If all events after 'from' are kept, they returned as 'missed' and 'resumed' is true.
+// Otherwise 'resumed' is false and caller must send a snapshot of markets
+// at returned 'revision': events published after it come in subscription.
+func (h *Hub) Subscribe(
+	ctx context.Context,
+	from uint64,
+) (
+	sub *Subscription,
+	missed []Event,
+	revision uint64,
+	resumed bool,
+	err error,
+) {
+	h.mut.Lock()
+	sub = &Subscription{
+		hub:    h,
+		events: make(chan Event, subscriberBuffer),
+	}
+	h.subscribers[sub] = struct{}{}
+	revision = h.revision
+
+	if h.closed {
+		h.drop(sub, ErrClosed)
+	}
+
+	h.mut.Unlock()
+
+	switch {
+	case from == 0 || from > revision:
+		return sub, nil, revision, false, nil
+	case from == revision:
+		return sub, nil, revision, true, nil
+	}
+
+	missed, err = h.history.Range(ctx, from, revision)
+
+	if err != nil {
+		sub.Close()
+		return nil, nil, 0, false, err
+	}
+
+	// Some events after 'from' are dropped from history or never kept.
+	if !complete(missed, from, revision) {
+		return sub, nil, revision, false, nil
+	}
+
+	return sub, missed, revision, true, nil
+}
+
+// complete - report is 'events' are all events with revision in ('from', 'to'], oldest first.
+func complete(events []Event, from uint64, to uint64) bool {
+	for i, ev := range events {
+		if ev.Revision != from+uint64(i)+1 {
+			return false
+		}
+	}
+
+	return len(events) == int(to-from)
+}
+
+// Close - drop all subscriptions with ErrClosed.
+// New subscriptions are dropped right away.
+func (h *Hub) Close() {
+	h.mut.Lock()
+	defer h.mut.Unlock()
+	h.closed = true
+
+	for sub := range h.subscribers {
+		h.drop(sub, ErrClosed)
+	}
+}
+
+// drop - remove subscription 'sub' with error 'err'.
+// Must be called with locked hub.
+func (h *Hub) drop(sub *Subscription, err error) {
+	if _, exist := h.subscribers[sub]; !exist {
+		return
+	}
+
+	delete(h.subscribers, sub)
+	sub.err = err
+	close(sub.events)
+}
+
+// Subscription - stream of events from Hub.
+type Subscription struct {
+	hub    *Hub
+	events chan Event
+	err    error
+}
+
+// Events - return channel of events.
+// Channel closed after Close or when subscription dropped, see Err.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Err - return reason of drop subscription.
+// Nil if subscription active or closed by Close.
+func (s *Subscription) Err() error {
+	s.hub.mut.Lock()
+	defer s.hub.mut.Unlock()
+	return s.err
+}
+
+// Close - stop receive events.
+func (s *Subscription) Close() {
+	s.hub.mut.Lock()
+	defer s.hub.mut.Unlock()
+	s.hub.drop(s, nil)
+}
+
+// MemoryHistory - in-process History, keep last 'Size' events.
+type MemoryHistory struct {
+	Size   int
+	mut    sync.Mutex
+	events []Event
+}
+
+// Add - implement History interface.
+func (m *MemoryHistory) Add(ctx context.Context, ev Event) error {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+
+	if m.Size <= 0 {
+		return nil
+	}
+
+	if len(m.events) == m.Size {
+		m.events = append(m.events[:0], m.events[1:]...)
+	}
+
+	m.events = append(m.events, ev)
+	return nil
+}
+
+// Range - implement History interface.
+func (m *MemoryHistory) Range(ctx context.Context, from uint64, to uint64) ([]Event, error) {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	var events []Event
+
+	for _, ev := range m.events {
+		if ev.Revision > from && ev.Revision <= to {
+			events = append(events, ev)
+		}
+	}
+
+	return events, nil
+}
+
+// MemorySequence - in-process Sequence, start from zero.
+type MemorySequence struct {
+	mut      sync.Mutex
+	revision uint64
+}
+
+// Current - implement Sequence interface.
+func (m *MemorySequence) Current(ctx context.Context) (uint64, error) {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	return m.revision, nil
+}
+
+// Next - implement Sequence interface.
+func (m *MemorySequence) Next(ctx context.Context) (uint64, error) {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	m.revision++
+	return m.revision, nil
+}
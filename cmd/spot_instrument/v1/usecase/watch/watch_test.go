@@ -0,0 +1,188 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/KonnorFrik/BinaryTentacles/cmd/spot_instrument/v1/usecase/market"
+)
+
+// newHub - create a hub with 'published' events and history of 'size'.
+func newHub(t *testing.T, size int, published int) *Hub {
+	hub, err := New(context.Background(), &MemorySequence{}, &MemoryHistory{Size: size})
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	for range published {
+		_, err = hub.Publish(context.Background(), Event{
+			Action: market.ActionUpdate,
+			Market: &market.Market{},
+		})
+
+		if err != nil {
+			t.Fatalf("Got = %q\n", err)
+		}
+	}
+
+	return hub
+}
+
+func TestSubscribe(t *testing.T) {
+	cases := []struct {
+		name        string
+		size        int
+		published   int
+		from        uint64
+		wantResumed bool
+		wantMissed  int
+	}{
+		{name: "From zero", size: 10, published: 5, from: 0, wantResumed: false},
+		{name: "From last", size: 10, published: 5, from: 5, wantResumed: true, wantMissed: 0},
+		{name: "From kept", size: 10, published: 5, from: 2, wantResumed: true, wantMissed: 3},
+		{name: "From oldest kept", size: 3, published: 5, from: 2, wantResumed: true, wantMissed: 3},
+		{name: "From evicted", size: 3, published: 5, from: 1, wantResumed: false},
+		{name: "From future", size: 10, published: 5, from: 6, wantResumed: false},
+		{name: "Without history", size: 0, published: 5, from: 4, wantResumed: false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			hub := newHub(t, c.size, c.published)
+			sub, missed, revision, resumed, err := hub.Subscribe(context.Background(), c.from)
+
+			if err != nil {
+				t.Fatalf("Got = %q\n", err)
+			}
+
+			defer sub.Close()
+
+			if revision != uint64(c.published) {
+				t.Fatalf("Got = %d, Want = %d\n", revision, c.published)
+			}
+
+			if resumed != c.wantResumed {
+				t.Fatalf("Got = %t, Want = %t\n", resumed, c.wantResumed)
+			}
+
+			if len(missed) != c.wantMissed {
+				t.Fatalf("Got = %d missed, Want = %d\n", len(missed), c.wantMissed)
+			}
+
+			for i, ev := range missed {
+				if want := c.from + uint64(i) + 1; ev.Revision != want {
+					t.Fatalf("Got = %d, Want = %d\n", ev.Revision, want)
+				}
+			}
+		})
+	}
+}
+
+func TestPublishDropLagged(t *testing.T) {
+	hub := newHub(t, 0, 0)
+	sub, _, _, _, _ := hub.Subscribe(context.Background(), 0)
+
+	for range subscriberBuffer + 1 {
+		_, err := hub.Publish(context.Background(), Event{Market: &market.Market{}})
+
+		if err != nil {
+			t.Fatalf("Got = %q\n", err)
+		}
+	}
+
+	var received int
+
+	for range sub.Events() {
+		received++
+	}
+
+	if received != subscriberBuffer {
+		t.Fatalf("Got = %d, Want = %d\n", received, subscriberBuffer)
+	}
+
+	if err := sub.Err(); !errors.Is(err, ErrLagged) {
+		t.Fatalf("Got = %q, Want = %q\n", err, ErrLagged)
+	}
+}
+
+// gapHistory - History which lost event with revision 'lost'.
+type gapHistory struct {
+	MemoryHistory
+	lost uint64
+}
+
+func (g *gapHistory) Add(ctx context.Context, ev Event) error {
+	if ev.Revision == g.lost {
+		return errors.New("lost")
+	}
+
+	return g.MemoryHistory.Add(ctx, ev)
+}
+
+func TestSubscribeOverLostEvent(t *testing.T) {
+	hub, err := New(context.Background(), &MemorySequence{}, &gapHistory{MemoryHistory: MemoryHistory{Size: 10}, lost: 3})
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	for i := range 5 {
+		ev, err := hub.Publish(context.Background(), Event{Market: &market.Market{}})
+
+		if (err != nil) != (i == 2) || ev.Revision != uint64(i+1) {
+			t.Fatalf("Got = %d, %v\n", ev.Revision, err)
+		}
+	}
+
+	for from, want := range map[uint64]bool{1: false, 2: false, 3: true} {
+		sub, _, _, resumed, err := hub.Subscribe(context.Background(), from)
+
+		if err != nil || resumed != want {
+			t.Fatalf("From %d: Got = %t, %v, Want = %t\n", from, resumed, err, want)
+		}
+
+		sub.Close()
+	}
+}
+
+func TestDeliver(t *testing.T) {
+	var (
+		ctx      = context.Background()
+		sequence = &MemorySequence{}
+		history  = &gapHistory{MemoryHistory: MemoryHistory{Size: 10}, lost: 4}
+	)
+
+	// 'writer' and 'reader' are hubs of two instances with shared sequence and history.
+	writer, _ := New(ctx, sequence, history)
+	reader, _ := New(ctx, sequence, history)
+	sub, _, _, _, _ := reader.Subscribe(ctx, 0)
+	var recorded []Event
+
+	for range 5 {
+		ev, _ := writer.Record(ctx, Event{Market: &market.Market{}})
+		recorded = append(recorded, ev)
+	}
+
+	// Event 1 is lost in transport, it is read from history before event 2.
+	reader.Deliver(ctx, recorded[1])
+	reader.Deliver(ctx, recorded[0])
+
+	for _, want := range []uint64{1, 2} {
+		if ev := <-sub.Events(); ev.Revision != want {
+			t.Fatalf("Got = %d, Want = %d\n", ev.Revision, want)
+		}
+	}
+
+	// Events 3 and 4 are lost in transport, 4 is not in history.
+	reader.Deliver(ctx, recorded[4])
+
+	if _, isOpen := <-sub.Events(); isOpen || !errors.Is(sub.Err(), ErrLagged) {
+		t.Fatalf("Got = %v, Want = %v\n", sub.Err(), ErrLagged)
+	}
+
+	if _, _, revision, _, _ := reader.Subscribe(ctx, 0); revision != 5 {
+		t.Fatalf("Got = %d, Want = 5\n", revision)
+	}
+}
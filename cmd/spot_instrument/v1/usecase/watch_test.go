@@ -0,0 +1,215 @@
+package usecase
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/KonnorFrik/BinaryTentacles/cmd/spot_instrument/v1/usecase/market"
+	"github.com/KonnorFrik/BinaryTentacles/cmd/spot_instrument/v1/usecase/policy"
+	"github.com/KonnorFrik/BinaryTentacles/cmd/spot_instrument/v1/usecase/watch"
+	pb "github.com/KonnorFrik/BinaryTentacles/internal/generated/spot_instrument/v1"
+	"github.com/KonnorFrik/BinaryTentacles/pkg/auth"
+	"github.com/KonnorFrik/BinaryTentacles/pkg/eventbus"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestWatchMarketsResumeOnOtherInstance(t *testing.T) {
+	first, server := newTestUsecase(t)
+
+	for _, base := range []string{"btc", "eth", "sol"} {
+		if _, err := first.CreateMarket(adminCtx, &pb.CreateMarketRequest{BaseAsset: base, QuoteAsset: "usdt"}); err != nil {
+			t.Fatalf("Got = %v, Want = %v\n", err, nil)
+		}
+	}
+
+	second := newTestUsecaseOn(t, server)
+	w, err := second.WatchMarkets(adminCtx, &pb.WatchMarketsRequest{
+		UserRole:     pb.UserRole_USER_ROLE_ADMIN,
+		FromRevision: 1,
+	})
+
+	if err != nil {
+		t.Fatalf("Got = %v, Want = %v\n", err, nil)
+	}
+
+	defer w.Close()
+
+	if w.Snapshot != nil || len(w.Missed) != 2 {
+		t.Fatalf("Got = %d missed, snapshot %v, Want = 2 missed\n", len(w.Missed), w.Snapshot)
+	}
+
+	for i, ev := range w.Missed {
+		if ev.Revision != uint64(i+2) || ev.Action != market.ActionCreate || ev.Market == nil {
+			t.Fatalf("Got = %+v, Want = create at revision %d\n", ev, i+2)
+		}
+	}
+}
+
+func TestWatchMarketsChangeOnOtherInstance(t *testing.T) {
+	server := miniredis.RunT(t)
+	withBus := func() Option {
+		client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+		t.Cleanup(func() { client.Close() })
+		bus, err := eventbus.NewRedis(client)
+
+		if err != nil {
+			t.Fatalf("Got = %v, Want = %v\n", err, nil)
+		}
+
+		return WithEventBus(bus)
+	}
+	first := newTestUsecaseOn(t, server, withBus())
+	second := newTestUsecaseOn(t, server, withBus())
+	w, err := second.WatchMarkets(adminCtx, &pb.WatchMarketsRequest{UserRole: pb.UserRole_USER_ROLE_ADMIN})
+
+	if err != nil {
+		t.Fatalf("Got = %v, Want = %v\n", err, nil)
+	}
+
+	defer w.Close()
+	mark, err := first.CreateMarket(adminCtx, &pb.CreateMarketRequest{BaseAsset: "btc", QuoteAsset: "usdt"})
+
+	if err != nil {
+		t.Fatalf("Got = %v, Want = %v\n", err, nil)
+	}
+
+	select {
+	case ev := <-w.Events():
+		if ev.Revision != w.Revision+1 || ev.Action != market.ActionCreate || ev.Market.Id != mark.Id {
+			t.Fatalf("Got = %+v, Want = create of %s\n", ev, mark.Id)
+		}
+
+	case <-time.After(time.Second):
+		t.Fatalf("Got no event, Want = create of %s\n", mark.Id)
+	}
+}
+
+func TestMarketWatchFilter(t *testing.T) {
+	var (
+		now    = time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+		listed = &market.Market{Id: "listed", Symbol: "BTC-USDT", Enabled: true}
+		// preLaunch - listed after launch, hidden before.
+		preLaunch = &market.Market{Id: "pre-launch", Symbol: "ETH-USDT", Enabled: true, LaunchAt: now.Add(time.Hour)}
+		disabled  = &market.Market{Id: "disabled", Symbol: "SOL-USDT"}
+		deleted   = &market.Market{Id: "deleted", Symbol: "XRP-USDT", Enabled: true, DeletedAt: now}
+	)
+
+	// Event kinds, 'sent' and 'stub' are roles which get event and which get it as stub.
+	type want struct {
+		sent []pb.UserRole
+		stub []pb.UserRole
+	}
+
+	var (
+		customer    = pb.UserRole_USER_ROLE_CUSTOMER
+		readOnly    = pb.UserRole_USER_ROLE_READ_ONLY
+		maker       = pb.UserRole_USER_ROLE_MARKET_MAKER
+		internal    = pb.UserRole_USER_ROLE_INTERNAL_SERVICE
+		admin       = pb.UserRole_USER_ROLE_ADMIN
+		roles       = []pb.UserRole{customer, readOnly, maker, internal, admin}
+		everyone    = roles
+		nonDeleted  = []pb.UserRole{internal, admin}
+		preLaunched = []pb.UserRole{maker, internal, admin}
+	)
+
+	cases := []struct {
+		name   string
+		action market.Action
+		market *market.Market
+		before market.Stage
+		want   want
+	}{
+		{name: "Create listed", action: market.ActionCreate, market: listed, want: want{sent: everyone}},
+		{name: "Create pre-launch", action: market.ActionCreate, market: preLaunch, want: want{sent: preLaunched}},
+		{name: "Create disabled", action: market.ActionCreate, market: disabled, want: want{sent: nonDeleted}},
+		{name: "Update listed", action: market.ActionUpdate, market: listed, before: market.StageListed, want: want{sent: everyone}},
+		{name: "Update pre-launch", action: market.ActionUpdate, market: preLaunch, before: market.StagePreLaunch, want: want{sent: preLaunched}},
+		{name: "Update disabled", action: market.ActionUpdate, market: disabled, before: market.StageDisabled, want: want{sent: nonDeleted}},
+		{name: "Halt listed", action: market.ActionHalt, market: listed, before: market.StageListed, want: want{sent: everyone}},
+		{name: "Enable disabled", action: market.ActionEnable, market: listed, before: market.StageDisabled, want: want{sent: everyone}},
+		{
+			name:   "Disable listed",
+			action: market.ActionDisable, market: disabled, before: market.StageListed,
+			want: want{sent: everyone, stub: []pb.UserRole{customer, readOnly, maker}},
+		},
+		{
+			name:   "Disable pre-launch",
+			action: market.ActionDisable, market: disabled, before: market.StagePreLaunch,
+			want: want{sent: preLaunched, stub: []pb.UserRole{maker}},
+		},
+		{
+			name:   "Delete listed",
+			action: market.ActionSoftDelete, market: deleted, before: market.StageListed,
+			want: want{sent: everyone, stub: []pb.UserRole{customer, readOnly, maker, internal}},
+		},
+		{
+			name:   "Delete disabled",
+			action: market.ActionSoftDelete, market: deleted, before: market.StageDisabled,
+			want: want{sent: nonDeleted, stub: []pb.UserRole{internal}},
+		},
+		{name: "Update deleted", action: market.ActionUpdate, market: deleted, before: market.StageDeleted, want: want{sent: []pb.UserRole{admin}}},
+		{
+			name:   "Restore disabled",
+			action: market.ActionRestore, market: disabled, before: market.StageDeleted,
+			want: want{sent: nonDeleted},
+		},
+	}
+
+	for _, c := range cases {
+		for _, role := range roles {
+			t.Run(c.name+"/"+policy.RoleName(role), func(t *testing.T) {
+				w := MarketWatch{role: role}
+				ev, sent := w.Filter(watch.Event{Action: c.action, Market: c.market, Before: c.before, At: now})
+
+				if sent != slices.Contains(c.want.sent, role) {
+					t.Fatalf("Got = %t, Want = %t\n", sent, !sent)
+				}
+
+				if !sent {
+					return
+				}
+
+				stub := ev.Market != c.market
+
+				if stub != slices.Contains(c.want.stub, role) {
+					t.Fatalf("Got stub = %t, Want = %t\n", stub, !stub)
+				}
+
+				if stub && (ev.Market.Id != c.market.Id || ev.Market.Enabled) {
+					t.Fatalf("Got = %+v, Want = stub of %s\n", ev.Market, c.market.Id)
+				}
+			})
+		}
+	}
+}
+
+func TestRoleOf(t *testing.T) {
+	var (
+		ctx      = context.Background()
+		service  = auth.NewContext(ctx, auth.Principal{Name: "order_service", Role: "internal_service"})
+		reader   = auth.NewContext(ctx, auth.Principal{Name: "dashboard", Role: "read_only"})
+		stranger = auth.NewContext(ctx, auth.Principal{Name: "stranger", Role: "root"})
+	)
+
+	cases := []struct {
+		name      string
+		ctx       context.Context
+		requested pb.UserRole
+		want      pb.UserRole
+	}{
+		{"Anonymous claims admin", ctx, pb.UserRole_USER_ROLE_ADMIN, pb.UserRole_USER_ROLE_CUSTOMER},
+		{"Read only claims admin", reader, pb.UserRole_USER_ROLE_ADMIN, pb.UserRole_USER_ROLE_READ_ONLY},
+		{"Unknown role", stranger, pb.UserRole_USER_ROLE_ADMIN, pb.UserRole_USER_ROLE_CUSTOMER},
+		{"Service acts for maker", service, pb.UserRole_USER_ROLE_MARKET_MAKER, pb.UserRole_USER_ROLE_MARKET_MAKER},
+		{"Service by itself", service, pb.UserRole_USER_ROLE_UNSPECIFIED, pb.UserRole_USER_ROLE_INTERNAL_SERVICE},
+	}
+
+	for _, c := range cases {
+		if got := roleOf(c.ctx, c.requested); got != c.want {
+			t.Errorf("%s: Got = %s, Want = %s\n", c.name, got, c.want)
+		}
+	}
+}
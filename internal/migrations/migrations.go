@@ -0,0 +1,167 @@
+/*
+Schema of postgres used by services.
+Migrations are files 'sql/NNNN_name.sql' applied once in order of name.
+Applied migrations are recorded in table schema_migrations.
+*/
+package migrations
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// lockId - key of advisory lock held while migrate, so migrators don't run concurrently.
+const lockId = 7_160_044
+
+//go:embed sql/*.sql
+var files embed.FS
+
+// Migration - one change of schema.
+type Migration struct {
+	// Version - name of file without extension, like '0001_create_orders'.
+	Version string
+	SQL     string
+}
+
+// All - return all migrations in order of apply.
+func All() ([]Migration, error) {
+	names, err := fs.Glob(files, "sql/*.sql")
+
+	if err != nil {
+		return nil, err
+	}
+
+	slices.Sort(names)
+	var all = make([]Migration, 0, len(names))
+
+	for _, name := range names {
+		content, err := files.ReadFile(name)
+
+		if err != nil {
+			return nil, err
+		}
+
+		all = append(all, Migration{
+			Version: strings.TrimSuffix(strings.TrimPrefix(name, "sql/"), ".sql"),
+			SQL:     string(content),
+		})
+	}
+
+	return all, nil
+}
+
+// Pending - return migrations not applied to database of 'pool'.
+func Pending(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+) (
+	[]Migration,
+	error,
+) {
+	conn, err := pool.Acquire(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer conn.Release()
+	return pending(ctx, conn.Conn())
+}
+
+// Up - apply pending migrations to database of 'pool', each in own transaction.
+// Returns applied migrations, on error applied before it.
+func Up(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+) (
+	[]Migration,
+	error,
+) {
+	conn, err := pool.Acquire(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer conn.Release()
+
+	if _, err = conn.Exec(ctx, "SELECT pg_advisory_lock($1)", lockId); err != nil {
+		return nil, fmt.Errorf("lock: %w", err)
+	}
+
+	defer conn.Exec(context.WithoutCancel(ctx), "SELECT pg_advisory_unlock($1)", lockId)
+	todo, err := pending(ctx, conn.Conn())
+
+	if err != nil {
+		return nil, err
+	}
+
+	var applied = make([]Migration, 0, len(todo))
+
+	for _, migration := range todo {
+		err = pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+			if _, err := tx.Exec(ctx, migration.SQL); err != nil {
+				return err
+			}
+
+			_, err := tx.Exec(ctx, "INSERT INTO schema_migrations (version) VALUES ($1)", migration.Version)
+			return err
+		})
+
+		if err != nil {
+			return applied, fmt.Errorf("migration %s: %w", migration.Version, err)
+		}
+
+		applied = append(applied, migration)
+	}
+
+	return applied, nil
+}
+
+// pending - create table of applied migrations if not exist
+// and return migrations not recorded in it.
+func pending(
+	ctx context.Context,
+	conn *pgx.Conn,
+) (
+	[]Migration,
+	error,
+) {
+	_, err := conn.Exec(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    TEXT PRIMARY KEY,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`)
+
+	if err != nil {
+		return nil, fmt.Errorf("create schema_migrations: %w", err)
+	}
+
+	rows, err := conn.Query(ctx, "SELECT version FROM schema_migrations")
+
+	if err != nil {
+		return nil, err
+	}
+
+	applied, err := pgx.CollectRows(rows, pgx.RowTo[string])
+
+	if err != nil {
+		return nil, err
+	}
+
+	all, err := All()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return slices.DeleteFunc(all, func(m Migration) bool {
+		return slices.Contains(applied, m.Version)
+	}), nil
+}
@@ -0,0 +1,13 @@
+-- Orders of order_service. Whole order is kept in 'data', columns are for search.
+CREATE TABLE orders (
+    id         TEXT PRIMARY KEY,
+    user_id    TEXT        NOT NULL,
+    market_id  TEXT        NOT NULL,
+    status     INTEGER     NOT NULL,
+    version    BIGINT      NOT NULL,
+    data       JSONB       NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX orders_user_id_idx ON orders (user_id, id);
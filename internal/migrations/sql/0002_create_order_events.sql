@@ -0,0 +1,17 @@
+-- Order events of users. Sequence counts events of one user, first event is 1.
+CREATE TABLE user_event_sequences (
+    user_id TEXT PRIMARY KEY,
+    last    BIGINT NOT NULL
+);
+
+CREATE TABLE order_events (
+    user_id  TEXT        NOT NULL,
+    sequence BIGINT      NOT NULL,
+    order_id TEXT        NOT NULL,
+    type     INTEGER     NOT NULL,
+    data     JSONB       NOT NULL,
+    at       TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (user_id, sequence)
+);
+
+CREATE INDEX order_events_order_id_idx ON order_events (order_id);
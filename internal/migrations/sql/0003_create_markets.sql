@@ -0,0 +1,9 @@
+-- Markets of spot_instrument. Whole market is kept in 'data', columns are for search.
+CREATE TABLE markets (
+    id         TEXT PRIMARY KEY,
+    symbol     TEXT        NOT NULL,
+    data       JSONB       NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX markets_symbol_idx ON markets (symbol);
@@ -0,0 +1,6 @@
+-- Order events saved with changes of orders and not yet published.
+CREATE TABLE order_outbox (
+    id         BIGSERIAL PRIMARY KEY,
+    data       JSONB       NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,26 @@
+-- History of orders: revision counts events of one order, first event is 1.
+-- Events saved before are numbered in order of user sequence.
+ALTER TABLE order_events ADD COLUMN revision BIGINT;
+
+UPDATE order_events e SET revision = h.revision
+FROM (
+    SELECT user_id, sequence, row_number() OVER (PARTITION BY order_id ORDER BY sequence) AS revision
+    FROM order_events
+) h
+WHERE e.user_id = h.user_id AND e.sequence = h.sequence;
+
+ALTER TABLE order_events ALTER COLUMN revision SET NOT NULL;
+
+CREATE UNIQUE INDEX order_events_order_revision_idx ON order_events (order_id, revision);
+
+UPDATE orders o SET data = jsonb_set(o.data, '{revision}', to_jsonb(h.revision))
+FROM (SELECT order_id, max(revision) AS revision FROM order_events GROUP BY order_id) h
+WHERE o.id = h.order_id;
+
+-- Orders folded from history up to 'revision', so only later events are folded on read.
+CREATE TABLE order_snapshots (
+    order_id   TEXT PRIMARY KEY,
+    revision   BIGINT      NOT NULL,
+    data       JSONB       NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
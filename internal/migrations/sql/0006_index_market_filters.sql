@@ -0,0 +1,20 @@
+-- Columns of ViewMarkets filters, so markets are found without read of all of them.
+-- Status is one of 'enabled', 'disabled', 'deleted'.
+ALTER TABLE markets
+    ADD COLUMN status      TEXT NOT NULL DEFAULT '',
+    ADD COLUMN base_asset  TEXT NOT NULL DEFAULT '',
+    ADD COLUMN quote_asset TEXT NOT NULL DEFAULT '';
+
+UPDATE markets SET
+    status = CASE
+        WHEN coalesce(data->>'deleted_at', '0001-01-01T00:00:00Z') <> '0001-01-01T00:00:00Z' THEN 'deleted'
+        WHEN coalesce((data->>'enabled')::boolean, false) THEN 'enabled'
+        ELSE 'disabled'
+    END,
+    base_asset  = coalesce(data->>'base_asset', ''),
+    quote_asset = coalesce(data->>'quote_asset', '');
+
+CREATE INDEX markets_status_idx ON markets (status);
+CREATE INDEX markets_base_asset_idx ON markets (base_asset);
+CREATE INDEX markets_quote_asset_idx ON markets (quote_asset);
+CREATE INDEX markets_symbol_prefix_idx ON markets (symbol text_pattern_ops);
@@ -0,0 +1,3 @@
+-- Orders which may wait in order book, read on start of order_service to rebuild books.
+-- Statuses REJECT (5), CANCELLED (6) and FILLED (8) never rest.
+CREATE INDEX orders_resting_idx ON orders (id) WHERE status NOT IN (5, 6, 8);
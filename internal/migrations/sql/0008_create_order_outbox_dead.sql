@@ -0,0 +1,8 @@
+-- Outbox entries which can't be decoded, moved out of order_outbox so publish of others goes on.
+CREATE TABLE order_outbox_dead (
+    id         BIGINT      PRIMARY KEY,
+    data       JSONB       NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    error      TEXT        NOT NULL,
+    failed_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,282 @@
+/*
+Authentication of gRPC callers by static bearer tokens.
+Every token maps to principal with name and role, see Config.
+Calls without token are anonymous, methods may require roles, see Require.
+*/
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Header - metadata key with token as 'Bearer <token>'.
+const Header = "authorization"
+
+const bearer = "Bearer "
+
+var (
+	// ErrInvalidConfig - config of authentication is invalid for any reason.
+	ErrInvalidConfig = errors.New("invalid auth config")
+	// ErrUnauthenticated - token of call is unknown or malformed.
+	ErrUnauthenticated = errors.New("unauthenticated")
+	// ErrForbidden - role of caller is not allowed to call method.
+	ErrForbidden = errors.New("forbidden")
+)
+
+// Principal - authenticated caller. Zero Principal is anonymous caller.
+type Principal struct {
+	Name string
+	Role string
+}
+
+// Anonymous - check is principal 'p' an anonymous caller.
+func (p Principal) Anonymous() bool {
+	return p.Name == ""
+}
+
+// Config - settings of authentication.
+type Config struct {
+	// Tokens - accepted tokens as 'token=name:role'.
+	Tokens []string `yaml:"tokens" env:"AUTH_TOKENS" env-description:"Comma separated accepted tokens as token=name:role." secret:"true"`
+}
+
+// Validate - check every token of config 'c' is well formed and unique.
+func (c *Config) Validate() error {
+	_, err := parseTokens(c.Tokens)
+	return err
+}
+
+// Option - type for customize Authenticator object.
+type Option func(*Authenticator) error
+
+// Require - calls of methods with full name starting with 'prefix',
+// like '/package.Service/' or '/package.Service/Method', need caller with one of 'roles'.
+// Longest matched prefix is applied.
+func Require(prefix string, roles ...string) Option {
+	return func(a *Authenticator) error {
+		if !strings.HasPrefix(prefix, "/") || len(roles) == 0 {
+			return fmt.Errorf("%w: rule %q needs method prefix and roles", ErrInvalidConfig, prefix)
+		}
+
+		a.rules = append(a.rules, rule{prefix: prefix, roles: roles})
+		return nil
+	}
+}
+
+type rule struct {
+	prefix string
+	roles  []string
+}
+
+// Authenticator - authenticate callers by tokens and check roles of them.
+type Authenticator struct {
+	// principals - callers by sha256 of their tokens.
+	principals map[[sha256.Size]byte]Principal
+	rules      []rule
+}
+
+// New - create a new Authenticator with tokens of 'config'.
+func New(config Config, opts ...Option) (*Authenticator, error) {
+	principals, err := parseTokens(config.Tokens)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var a = Authenticator{principals: principals}
+
+	for _, opt := range opts {
+		if e := opt(&a); e != nil {
+			return nil, e
+		}
+	}
+
+	return &a, nil
+}
+
+// Authenticate - return caller of call with incoming metadata in 'ctx'.
+// Returns anonymous Principal if call has no token and ErrUnauthenticated if token is unknown.
+func (a *Authenticator) Authenticate(ctx context.Context) (Principal, error) {
+	mData, _ := metadata.FromIncomingContext(ctx)
+	values := mData.Get(Header)
+
+	if len(values) == 0 {
+		return Principal{}, nil
+	}
+
+	token, ok := strings.CutPrefix(values[0], bearer)
+
+	if !ok {
+		return Principal{}, fmt.Errorf("%w: expected bearer token", ErrUnauthenticated)
+	}
+
+	principal, exist := a.principals[sha256.Sum256([]byte(token))]
+
+	if !exist {
+		return Principal{}, fmt.Errorf("%w: unknown token", ErrUnauthenticated)
+	}
+
+	return principal, nil
+}
+
+// Authorize - check is 'principal' allowed to call 'method'.
+func (a *Authenticator) Authorize(principal Principal, method string) error {
+	var matched *rule
+
+	for i, r := range a.rules {
+		if strings.HasPrefix(method, r.prefix) && (matched == nil || len(r.prefix) > len(matched.prefix)) {
+			matched = &a.rules[i]
+		}
+	}
+
+	if matched == nil {
+		return nil
+	}
+
+	for _, role := range matched.roles {
+		if !principal.Anonymous() && principal.Role == role {
+			return nil
+		}
+	}
+
+	if principal.Anonymous() {
+		return fmt.Errorf("%w: %s needs token", ErrUnauthenticated, method)
+	}
+
+	return fmt.Errorf("%w: %s is not allowed for %s", ErrForbidden, method, principal.Name)
+}
+
+// UnaryServerInterceptor - authenticate and authorize unary calls,
+// put caller in context of handler, see FromContext.
+func (a *Authenticator) UnaryServerInterceptor(
+	ctx context.Context,
+	req any,
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (
+	any,
+	error,
+) {
+	ctx, err := a.check(ctx, info.FullMethod)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+// StreamServerInterceptor - authenticate and authorize stream calls,
+// put caller in context of handler, see FromContext.
+func (a *Authenticator) StreamServerInterceptor(
+	srv any,
+	ss grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	ctx, err := a.check(ss.Context(), info.FullMethod)
+
+	if err != nil {
+		return err
+	}
+
+	return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+}
+
+// check - authenticate and authorize call of 'method', return 'ctx' with caller.
+func (a *Authenticator) check(
+	ctx context.Context,
+	method string,
+) (
+	context.Context,
+	error,
+) {
+	principal, err := a.Authenticate(ctx)
+
+	if err == nil {
+		err = a.Authorize(principal, method)
+	}
+
+	switch {
+	case errors.Is(err, ErrUnauthenticated):
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	case errors.Is(err, ErrForbidden):
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	return NewContext(ctx, principal), nil
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+type contextKey struct{}
+
+// NewContext - return 'ctx' with caller 'principal'.
+func NewContext(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, contextKey{}, principal)
+}
+
+// FromContext - return caller of call from 'ctx', anonymous if not authenticated.
+func FromContext(ctx context.Context) Principal {
+	principal, _ := ctx.Value(contextKey{}).(Principal)
+	return principal
+}
+
+// Token - per call credentials with bearer token, for clients of authenticated services.
+// Use with grpc.WithPerRPCCredentials.
+type Token string
+
+// GetRequestMetadata - implement credentials.PerRPCCredentials interface.
+func (t Token) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	if t == "" {
+		return nil, nil
+	}
+
+	return map[string]string{Header: bearer + string(t)}, nil
+}
+
+// RequireTransportSecurity - implement credentials.PerRPCCredentials interface.
+// Services of project talk in private network without TLS.
+func (t Token) RequireTransportSecurity() bool {
+	return false
+}
+
+// parseTokens - parse 'tokens' as 'token=name:role' to principals by sha256 of tokens.
+func parseTokens(tokens []string) (map[[sha256.Size]byte]Principal, error) {
+	var principals = make(map[[sha256.Size]byte]Principal, len(tokens))
+
+	for i, entry := range tokens {
+		token, caller, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		name, role, hasRole := strings.Cut(caller, ":")
+
+		if !ok || !hasRole || token == "" || name == "" || role == "" {
+			return nil, fmt.Errorf("%w: token #%d is not as token=name:role", ErrInvalidConfig, i+1)
+		}
+
+		key := sha256.Sum256([]byte(token))
+
+		if _, exist := principals[key]; exist {
+			return nil, fmt.Errorf("%w: token #%d is duplicated", ErrInvalidConfig, i+1)
+		}
+
+		principals[key] = Principal{Name: name, Role: role}
+	}
+
+	return principals, nil
+}
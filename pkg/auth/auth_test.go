@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"google.golang.org/grpc/metadata"
+)
+
+func withToken(token string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs(Header, bearer+token))
+}
+
+func TestAuthenticate(t *testing.T) {
+	a, err := New(Config{Tokens: []string{"secret=order_service:internal_service"}})
+
+	if err != nil {
+		t.Fatalf("Got = %v, Want = %v\n", err, nil)
+	}
+
+	principal, err := a.Authenticate(withToken("secret"))
+
+	if err != nil || principal != (Principal{Name: "order_service", Role: "internal_service"}) {
+		t.Errorf("Got = %v, %v\n", principal, err)
+	}
+
+	if principal, err = a.Authenticate(context.Background()); err != nil || !principal.Anonymous() {
+		t.Errorf("Got = %v, %v, Want = anonymous\n", principal, err)
+	}
+
+	if _, err = a.Authenticate(withToken("other")); !errors.Is(err, ErrUnauthenticated) {
+		t.Errorf("Got = %v, Want = %v\n", err, ErrUnauthenticated)
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(Header, "secret"))
+
+	if _, err = a.Authenticate(ctx); !errors.Is(err, ErrUnauthenticated) {
+		t.Errorf("Got = %v, Want = %v\n", err, ErrUnauthenticated)
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	a, err := New(
+		Config{},
+		Require("/svc.Admin/", "admin"),
+		Require("/svc.Public/Report", "internal_service"),
+	)
+
+	if err != nil {
+		t.Fatalf("Got = %v, Want = %v\n", err, nil)
+	}
+
+	var (
+		admin   = Principal{Name: "alice", Role: "admin"}
+		service = Principal{Name: "order_service", Role: "internal_service"}
+	)
+
+	tests := []struct {
+		principal Principal
+		method    string
+		want      error
+	}{
+		{admin, "/svc.Admin/Create", nil},
+		{service, "/svc.Admin/Create", ErrForbidden},
+		{Principal{}, "/svc.Admin/Create", ErrUnauthenticated},
+		{service, "/svc.Public/Report", nil},
+		{admin, "/svc.Public/Report", ErrForbidden},
+		{Principal{}, "/svc.Public/View", nil},
+	}
+
+	for _, tt := range tests {
+		if err := a.Authorize(tt.principal, tt.method); !errors.Is(err, tt.want) {
+			t.Errorf("%s by %q: Got = %v, Want = %v\n", tt.method, tt.principal.Name, err, tt.want)
+		}
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	for _, tokens := range [][]string{{"secret"}, {"secret=name"}, {"=name:role"}, {"a=x:y", "a=z:w"}} {
+		config := Config{Tokens: tokens}
+
+		if err := config.Validate(); !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("Got = %v, Want = %v for %q\n", err, ErrInvalidConfig, tokens)
+		}
+	}
+}
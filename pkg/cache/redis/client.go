@@ -0,0 +1,222 @@
+package redis
+
+import (
+	"context"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// newClient - create go-redis client for mode of 'config'.
+// Empty mode and read from mean standalone and master.
+func newClient(config Config) (redis.UniversalClient, error) {
+	tlsConfig, err := config.tlsConfig()
+
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		addrs          = config.addrs()
+		routeByLatency = config.ReadFrom == ReadFromNearest
+		routeRandomly  = config.ReadFrom == ReadFromRandom
+	)
+
+	switch config.Mode {
+	case ModeSentinel:
+		opts := redis.FailoverOptions{
+			MasterName:       config.MasterName,
+			SentinelAddrs:    addrs,
+			SentinelPassword: config.SentinelPassword,
+			Username:         config.User,
+			Password:         config.Password,
+			DB:               config.DB,
+			MaxRetries:       config.MaxRetries,
+			DialTimeout:      config.DialTimeout,
+			ReadTimeout:      config.Timeout,
+			WriteTimeout:     config.Timeout,
+			TLSConfig:        tlsConfig,
+			RouteByLatency:   routeByLatency,
+			RouteRandomly:    routeRandomly,
+		}
+
+		// Only cluster client of sentinel nodes routes reads to replicas.
+		if routeByLatency || routeRandomly {
+			return redis.NewFailoverClusterClient(&opts), nil
+		}
+
+		return redis.NewFailoverClient(&opts), nil
+
+	case ModeCluster:
+		return redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:          addrs,
+			Username:       config.User,
+			Password:       config.Password,
+			MaxRetries:     config.MaxRetries,
+			DialTimeout:    config.DialTimeout,
+			ReadTimeout:    config.Timeout,
+			WriteTimeout:   config.Timeout,
+			TLSConfig:      tlsConfig,
+			ReadOnly:       config.ReadFrom == ReadFromReplica,
+			RouteByLatency: routeByLatency,
+			RouteRandomly:  routeRandomly,
+		}), nil
+	}
+
+	var addr string
+
+	if len(addrs) > 0 {
+		addr = addrs[0]
+	}
+
+	return redis.NewClient(&redis.Options{
+		Addr:         addr,
+		Password:     config.Password,
+		DB:           config.DB,
+		Username:     config.User,
+		MaxRetries:   config.MaxRetries,
+		DialTimeout:  config.DialTimeout,
+		ReadTimeout:  config.Timeout,
+		WriteTimeout: config.Timeout,
+		TLSConfig:    tlsConfig,
+	}), nil
+}
+
+// masters - return clients of nodes storing keys of cache 'c':
+// every master in cluster mode, else client of cache.
+func (c *Cache) masters(ctx context.Context) ([]redis.Cmdable, error) {
+	cluster, ok := c.conn.(*redis.ClusterClient)
+
+	if !ok {
+		return []redis.Cmdable{c.conn}, nil
+	}
+
+	var (
+		mut   sync.Mutex
+		nodes []redis.Cmdable
+	)
+
+	err := cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+		mut.Lock()
+		defer mut.Unlock()
+		nodes = append(nodes, node)
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return nodes, nil
+}
+
+// scan - call 'fn' with batches of stored keys matching 'match' on every master,
+// until 'fn' returns false.
+func (c *Cache) scan(
+	ctx context.Context,
+	match string,
+	fn func(keys []string) bool,
+) error {
+	nodes, err := c.masters(ctx)
+
+	if err != nil {
+		return c.wrapError(err)
+	}
+
+	for _, node := range nodes {
+		var cursor uint64
+
+		for {
+			keys, next, err := node.Scan(ctx, cursor, match, scanBatch).Result()
+
+			if err != nil {
+				return c.wrapError(err)
+			}
+
+			if !fn(keys) {
+				return nil
+			}
+
+			if cursor = next; cursor == 0 {
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+// mget - get values of stored 'keys', value of missing key is nil.
+// In cluster mode keys may be in different slots, so they are read by pipeline of GET.
+func (c *Cache) mget(
+	ctx context.Context,
+	keys []string,
+) (
+	[]any,
+	error,
+) {
+	if _, ok := c.conn.(*redis.ClusterClient); !ok {
+		return c.conn.MGet(ctx, keys...).Result()
+	}
+
+	var cmds = make([]*redis.StringCmd, len(keys))
+	_, err := c.conn.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			cmds[i] = pipe.Get(ctx, key)
+		}
+
+		return nil
+	})
+
+	if err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	var values = make([]any, len(keys))
+
+	for i, cmd := range cmds {
+		switch value, err := cmd.Result(); {
+		case err == nil:
+			values[i] = value
+		case err != redis.Nil:
+			return nil, err
+		}
+	}
+
+	return values, nil
+}
+
+// del - remove stored 'keys', return count of removed keys.
+// In cluster mode keys may be in different slots, so they are removed by pipeline of DEL.
+func (c *Cache) del(
+	ctx context.Context,
+	keys []string,
+) (
+	int64,
+	error,
+) {
+	if _, ok := c.conn.(*redis.ClusterClient); !ok || len(keys) < 2 {
+		return c.conn.Del(ctx, keys...).Result()
+	}
+
+	var cmds = make([]*redis.IntCmd, len(keys))
+	_, err := c.conn.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			cmds[i] = pipe.Del(ctx, key)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+
+	for _, cmd := range cmds {
+		count += cmd.Val()
+	}
+
+	return count, nil
+}
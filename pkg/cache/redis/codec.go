@@ -0,0 +1,83 @@
+package redis
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/proto"
+)
+
+// Codec - encoding of values of type T stored by TypedStore.
+type Codec[T any] interface {
+	Encode(value T) ([]byte, error)
+	Decode(data []byte) (T, error)
+}
+
+// JSONCodec - Codec of values as JSON.
+type JSONCodec[T any] struct{}
+
+// Encode - implement Codec interface.
+func (JSONCodec[T]) Encode(value T) ([]byte, error) {
+	return json.Marshal(value)
+}
+
+// Decode - implement Codec interface.
+func (JSONCodec[T]) Decode(data []byte) (T, error) {
+	var value T
+	err := json.Unmarshal(data, &value)
+	return value, err
+}
+
+// MsgpackCodec - Codec of values as MessagePack.
+// Names of fields are taken from 'json' tags, so same types are stored by JSONCodec and MsgpackCodec.
+type MsgpackCodec[T any] struct{}
+
+// Encode - implement Codec interface.
+func (MsgpackCodec[T]) Encode(value T) ([]byte, error) {
+	var (
+		buf bytes.Buffer
+		enc = msgpack.NewEncoder(&buf)
+	)
+
+	enc.SetCustomStructTag("json")
+
+	if err := enc.Encode(value); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Decode - implement Codec interface.
+func (MsgpackCodec[T]) Decode(data []byte) (T, error) {
+	var (
+		value T
+		dec   = msgpack.NewDecoder(bytes.NewReader(data))
+	)
+
+	dec.SetCustomStructTag("json")
+	err := dec.Decode(&value)
+	return value, err
+}
+
+// ProtoCodec - Codec of protobuf messages in binary wire format.
+// T is pointer to generated message, like *pb.Order.
+type ProtoCodec[T proto.Message] struct{}
+
+// Encode - implement Codec interface.
+func (ProtoCodec[T]) Encode(value T) ([]byte, error) {
+	return proto.Marshal(value)
+}
+
+// Decode - implement Codec interface.
+func (ProtoCodec[T]) Decode(data []byte) (T, error) {
+	var zero T
+	value := zero.ProtoReflect().New().Interface().(T)
+
+	if err := proto.Unmarshal(data, value); err != nil {
+		return zero, err
+	}
+
+	return value, nil
+}
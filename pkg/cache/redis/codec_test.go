@@ -0,0 +1,68 @@
+package redis
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type codecValue struct {
+	Name  string   `json:"name"`
+	Count int      `json:"count,omitempty"`
+	Tags  []string `json:"tags"`
+}
+
+func TestCodecRoundTrip(t *testing.T) {
+	var (
+		value  = codecValue{Name: "BTC-USDT", Count: 3, Tags: []string{"spot"}}
+		codecs = map[string]Codec[codecValue]{
+			"json":    JSONCodec[codecValue]{},
+			"msgpack": MsgpackCodec[codecValue]{},
+		}
+	)
+
+	for name, codec := range codecs {
+		t.Run(name, func(t *testing.T) {
+			data, err := codec.Encode(value)
+
+			if err != nil {
+				t.Fatalf("Got = %q\n", err)
+			}
+
+			got, err := codec.Decode(data)
+
+			if err != nil || got.Name != value.Name || got.Count != value.Count || len(got.Tags) != 1 {
+				t.Fatalf("Got = %+v, %v, Want = %+v\n", got, err, value)
+			}
+		})
+	}
+}
+
+func TestProtoCodec(t *testing.T) {
+	var (
+		codec = ProtoCodec[*timestamppb.Timestamp]{}
+		value = timestamppb.New(time.Unix(1700000000, 5))
+	)
+
+	data, err := codec.Encode(value)
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	got, err := codec.Decode(data)
+
+	if err != nil || !got.AsTime().Equal(value.AsTime()) {
+		t.Fatalf("Got = %v, %v, Want = %v\n", got, err, value)
+	}
+}
+
+func TestTypedStoreDecodeError(t *testing.T) {
+	store := NewTypedStore(nil, JSONCodec[codecValue]{})
+
+	if _, err := store.decode("key", "not json"); !errors.Is(err, ErrDecode) {
+		t.Fatalf("Got = %v, Want = %v\n", err, ErrDecode)
+	}
+}
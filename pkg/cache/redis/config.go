@@ -1,18 +1,80 @@
 package redis
 
 import (
-	"github.com/ilyakaznacheev/cleanenv"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
 	"time"
+
+	"github.com/ilyakaznacheev/cleanenv"
+)
+
+// Modes of redis deployment, see Config.Mode.
+const (
+	// ModeStandalone - single redis server at Addr.
+	ModeStandalone = "standalone"
+	// ModeSentinel - master of MasterName found by sentinels at Addrs.
+	ModeSentinel = "sentinel"
+	// ModeCluster - redis cluster, Addrs are seed nodes.
+	ModeCluster = "cluster"
+)
+
+// Routing of read-only commands, see Config.ReadFrom.
+const (
+	// ReadFromMaster - all commands are sent to master.
+	ReadFromMaster = "master"
+	// ReadFromReplica - read-only commands are sent to replicas of slot, only in cluster mode.
+	ReadFromReplica = "replica"
+	// ReadFromNearest - read-only commands are sent to node with lowest latency.
+	ReadFromNearest = "nearest"
+	// ReadFromRandom - read-only commands are sent to random node.
+	ReadFromRandom = "random"
+)
+
+var (
+	// ErrInvalidConfig - config of connection is invalid for any reason.
+	ErrInvalidConfig = errors.New("invalid redis config")
 )
 
+// Config - settings of connection to redis.
 type Config struct {
-	Addr        string        `env:"REDIS_ADDR" env-required`
-	Password    string        `env:"REDIS_PASSWORD" env-required`
-	User        string        `env:"REDIS_USER" env-required`
-	DB          int           `env:"REDIS_DB" env-required`
-	MaxRetries  int           `env:"REDIS_MAX_RETRIES" env-required`
-	DialTimeout time.Duration `env:"REDIS_DIAL_TIMEOUT" env-required`
-	Timeout     time.Duration `env:"REDIS_RW_TIMEOUT" env-required`
+	// Mode - deployment of redis, one of ModeStandalone, ModeSentinel, ModeCluster.
+	Mode string `yaml:"mode" env:"REDIS_MODE" env-default:"standalone" env-description:"Deployment of redis: standalone, sentinel or cluster."`
+	// Addr - address of redis in standalone mode.
+	Addr string `yaml:"addr" env:"REDIS_ADDR" env-description:"Address of redis as host:port, for standalone mode."`
+	// Addrs - addresses of sentinels in sentinel mode or seed nodes in cluster mode.
+	Addrs    []string `yaml:"addrs" env:"REDIS_ADDRS" env-description:"Comma separated addresses of sentinels or cluster seed nodes."`
+	Password string   `yaml:"password" env:"REDIS_PASSWORD" env-description:"Password of redis user." secret:"true"`
+	User     string   `yaml:"user" env:"REDIS_USER" env-description:"Name of redis user."`
+	// DB - number of redis db, cluster and sentinel reading from nearest or random have only db 0.
+	DB          int           `yaml:"db" env:"REDIS_DB" env-default:"0" env-description:"Number of redis db."`
+	MaxRetries  int           `yaml:"max_retries" env:"REDIS_MAX_RETRIES" env-default:"3" env-description:"Max retries of failed command."`
+	DialTimeout time.Duration `yaml:"dial_timeout" env:"REDIS_DIAL_TIMEOUT" env-default:"5s" env-description:"Time limit of connect."`
+	Timeout     time.Duration `yaml:"timeout" env:"REDIS_RW_TIMEOUT" env-default:"3s" env-description:"Time limit of read and write."`
+	// Tenant - first segment of keys, for share one redis between environments or tenants.
+	Tenant string `yaml:"tenant" env:"REDIS_TENANT" env-description:"Optional tenant segment of all keys, for share one redis between environments."`
+	// MasterName - name of master monitored by sentinels.
+	MasterName       string `yaml:"master_name" env:"REDIS_MASTER_NAME" env-description:"Name of master monitored by sentinels, for sentinel mode."`
+	SentinelPassword string `yaml:"sentinel_password" env:"REDIS_SENTINEL_PASSWORD" env-description:"Password of sentinels." secret:"true"`
+	// ReadFrom - routing of read-only commands, one of ReadFromMaster, ReadFromReplica,
+	// ReadFromNearest, ReadFromRandom. Replicas may return stale values.
+	ReadFrom string    `yaml:"read_from" env:"REDIS_READ_FROM" env-default:"master" env-description:"Routing of read-only commands: master, replica, nearest or random."`
+	TLS      TLSConfig `yaml:"tls"`
+}
+
+// TLSConfig - settings of TLS connection to redis.
+type TLSConfig struct {
+	Enabled bool `yaml:"enabled" env:"REDIS_TLS" env-default:"false" env-description:"Connect to redis with TLS."`
+	// CAFile - PEM file with certificates of trusted authorities, system pool if empty.
+	CAFile string `yaml:"ca_file" env:"REDIS_TLS_CA_FILE" env-description:"PEM file with trusted certificate authorities."`
+	// CertFile, KeyFile - PEM files with client certificate and key, for mutual TLS.
+	CertFile           string `yaml:"cert_file" env:"REDIS_TLS_CERT_FILE" env-description:"PEM file with client certificate."`
+	KeyFile            string `yaml:"key_file" env:"REDIS_TLS_KEY_FILE" env-description:"PEM file with key of client certificate."`
+	ServerName         string `yaml:"server_name" env:"REDIS_TLS_SERVER_NAME" env-description:"Name of server for verify certificate, host of address if empty."`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify" env:"REDIS_TLS_INSECURE_SKIP_VERIFY" env-default:"false" env-description:"Skip verify of server certificate, only for tests."`
 }
 
 // ConfigOption - option for customize the Config object at creation.
@@ -30,6 +92,8 @@ func NewConfig(opts ...ConfigOption) (Config, error) {
 				return config, e
 			}
 		}
+
+		err = config.Validate()
 	}
 
 	return config, err
@@ -42,3 +106,128 @@ func WithDB(num int) ConfigOption {
 		return nil
 	}
 }
+
+// Validate - check settings of config 'c' are consistent with mode of it.
+// Configs containing Config must call it, as config.Load validates only top config.
+func (c *Config) Validate() error {
+	var errs []error
+
+	switch c.Mode {
+	case ModeStandalone:
+		if len(c.addrs()) != 1 {
+			errs = append(errs, errors.New("standalone mode needs exactly one address"))
+		}
+
+		if c.ReadFrom != ReadFromMaster {
+			errs = append(errs, errors.New("standalone mode reads only from master"))
+		}
+
+	case ModeSentinel:
+		if len(c.addrs()) == 0 || c.MasterName == "" {
+			errs = append(errs, errors.New("sentinel mode needs addresses of sentinels and master name"))
+		}
+
+		if c.ReadFrom == ReadFromReplica {
+			errs = append(errs, errors.New("sentinel mode can't read only from replicas, use nearest or random"))
+		}
+
+		if c.Clustered() && c.DB != 0 {
+			errs = append(errs, errors.New("sentinel mode reading from nearest or random has only db 0"))
+		}
+
+	case ModeCluster:
+		if len(c.addrs()) == 0 {
+			errs = append(errs, errors.New("cluster mode needs addresses of seed nodes"))
+		}
+
+		if c.DB != 0 {
+			errs = append(errs, errors.New("cluster has only db 0"))
+		}
+
+	default:
+		errs = append(errs, fmt.Errorf("unknown mode %q", c.Mode))
+	}
+
+	if !slices.Contains([]string{ReadFromMaster, ReadFromReplica, ReadFromNearest, ReadFromRandom}, c.ReadFrom) {
+		errs = append(errs, fmt.Errorf("unknown read from %q", c.ReadFrom))
+	}
+
+	if c.DB < 0 || c.MaxRetries < 0 {
+		errs = append(errs, errors.New("db and max retries can't be negative"))
+	}
+
+	if (c.TLS.CertFile == "") != (c.TLS.KeyFile == "") {
+		errs = append(errs, errors.New("tls cert and key files must be given together"))
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+
+	return nil
+}
+
+// Clustered - report whether client of config 'c' is a cluster client: in cluster mode
+// and in sentinel mode reading from nearest or random node. Such client has only db 0
+// and can't watch or change in one transaction keys of different slots.
+func (c *Config) Clustered() bool {
+	switch c.Mode {
+	case ModeCluster:
+		return true
+	case ModeSentinel:
+		return c.ReadFrom == ReadFromNearest || c.ReadFrom == ReadFromRandom
+	}
+
+	return false
+}
+
+// addrs - return addresses of redis nodes of config 'c', Addrs or Addr if Addrs is empty.
+func (c *Config) addrs() []string {
+	switch {
+	case len(c.Addrs) > 0:
+		return c.Addrs
+	case c.Addr != "":
+		return []string{c.Addr}
+	}
+
+	return nil
+}
+
+// tlsConfig - build TLS settings of config 'c', nil if TLS is disabled.
+func (c *Config) tlsConfig() (*tls.Config, error) {
+	if !c.TLS.Enabled {
+		return nil, nil
+	}
+
+	var conf = tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         c.TLS.ServerName,
+		InsecureSkipVerify: c.TLS.InsecureSkipVerify,
+	}
+
+	if c.TLS.CAFile != "" {
+		pem, err := os.ReadFile(c.TLS.CAFile)
+
+		if err != nil {
+			return nil, fmt.Errorf("%w: tls ca: %w", ErrInvalidConfig, err)
+		}
+
+		conf.RootCAs = x509.NewCertPool()
+
+		if !conf.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("%w: tls ca: no certificates in %s", ErrInvalidConfig, c.TLS.CAFile)
+		}
+	}
+
+	if c.TLS.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.TLS.CertFile, c.TLS.KeyFile)
+
+		if err != nil {
+			return nil, fmt.Errorf("%w: tls cert: %w", ErrInvalidConfig, err)
+		}
+
+		conf.Certificates = []tls.Certificate{cert}
+	}
+
+	return &conf, nil
+}
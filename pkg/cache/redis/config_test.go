@@ -0,0 +1,91 @@
+package redis
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		valid  bool
+	}{
+		{"standalone", Config{Mode: ModeStandalone, Addr: "redis:6379", ReadFrom: ReadFromMaster}, true},
+		{"standalone without address", Config{Mode: ModeStandalone, ReadFrom: ReadFromMaster}, false},
+		{"standalone from replica", Config{Mode: ModeStandalone, Addr: "redis:6379", ReadFrom: ReadFromReplica}, false},
+		{"sentinel", Config{Mode: ModeSentinel, Addrs: []string{"s1:26379", "s2:26379"}, MasterName: "main", ReadFrom: ReadFromNearest}, true},
+		{"sentinel from nearest with db", Config{Mode: ModeSentinel, Addrs: []string{"s1:26379"}, MasterName: "main", DB: 1, ReadFrom: ReadFromNearest}, false},
+		{"sentinel from master with db", Config{Mode: ModeSentinel, Addrs: []string{"s1:26379"}, MasterName: "main", DB: 1, ReadFrom: ReadFromMaster}, true},
+		{"sentinel without master", Config{Mode: ModeSentinel, Addrs: []string{"s1:26379"}, ReadFrom: ReadFromMaster}, false},
+		{"sentinel from replica", Config{Mode: ModeSentinel, Addrs: []string{"s1:26379"}, MasterName: "main", ReadFrom: ReadFromReplica}, false},
+		{"cluster", Config{Mode: ModeCluster, Addrs: []string{"n1:7000", "n2:7000"}, ReadFrom: ReadFromReplica}, true},
+		{"cluster with db", Config{Mode: ModeCluster, Addrs: []string{"n1:7000"}, DB: 1, ReadFrom: ReadFromMaster}, false},
+		{"unknown mode", Config{Mode: "ring", Addr: "redis:6379", ReadFrom: ReadFromMaster}, false},
+		{"unknown read from", Config{Mode: ModeCluster, Addrs: []string{"n1:7000"}, ReadFrom: "any"}, false},
+		{"tls cert without key", Config{Mode: ModeStandalone, Addr: "redis:6379", ReadFrom: ReadFromMaster, TLS: TLSConfig{Enabled: true, CertFile: "cert.pem"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+
+			if tt.valid && err != nil {
+				t.Errorf("Got = %v, Want = %v\n", err, nil)
+			}
+
+			if !tt.valid && !errors.Is(err, ErrInvalidConfig) {
+				t.Errorf("Got = %v, Want = %v\n", err, ErrInvalidConfig)
+			}
+		})
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		check  func(redis.UniversalClient) bool
+	}{
+		{"standalone", Config{Addr: "redis:6379"}, func(c redis.UniversalClient) bool {
+			_, ok := c.(*redis.Client)
+			return ok
+		}},
+		{"sentinel", Config{Mode: ModeSentinel, Addrs: []string{"s1:26379"}, MasterName: "main", ReadFrom: ReadFromMaster}, func(c redis.UniversalClient) bool {
+			_, ok := c.(*redis.Client)
+			return ok
+		}},
+		{"sentinel from nearest", Config{Mode: ModeSentinel, Addrs: []string{"s1:26379"}, MasterName: "main", ReadFrom: ReadFromNearest}, func(c redis.UniversalClient) bool {
+			_, ok := c.(*redis.ClusterClient)
+			return ok
+		}},
+		{"cluster from replica", Config{Mode: ModeCluster, Addrs: []string{"n1:7000"}, ReadFrom: ReadFromReplica}, func(c redis.UniversalClient) bool {
+			cluster, ok := c.(*redis.ClusterClient)
+			return ok && cluster.Options().ReadOnly
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := newClient(tt.config)
+
+			if err != nil {
+				t.Fatalf("Got = %v, Want = %v\n", err, nil)
+			}
+
+			defer client.Close()
+
+			if !tt.check(client) {
+				t.Errorf("Got = %T, unexpected client for %s\n", client, tt.config.Mode)
+			}
+		})
+	}
+
+	_, err := newClient(Config{Addr: "redis:6379", TLS: TLSConfig{Enabled: true, CAFile: "missing.pem"}})
+
+	if !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("Got = %v, Want = %v\n", err, ErrInvalidConfig)
+	}
+}
@@ -13,6 +13,8 @@ var (
 	ErrTimeOut = errors.New("time out")
 	// ErrNil - same as redis.Nil
 	ErrNil = errors.New("value is nil")
+	// ErrChanged - value is changed by other client while transaction
+	ErrChanged = errors.New("value is changed")
 )
 
 // wrapError - Map errors from other packages.
@@ -0,0 +1,48 @@
+package redis
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNamespace(t *testing.T) {
+	tests := []struct {
+		tenant, prefix string
+		want           string
+	}{
+		{"", "", ""},
+		{"", "orders", "orders:"},
+		{"staging", "", "staging:"},
+		{"staging", "orders", "staging:orders:"},
+	}
+
+	for _, tt := range tests {
+		if got := namespace(tt.tenant, tt.prefix); got != tt.want {
+			t.Errorf("Got = %q, Want = %q\n", got, tt.want)
+		}
+	}
+
+	c := Cache{namespace: namespace("staging", "orders")}
+
+	if got := c.keys([]string{"a", "b"}); got[0] != "staging:orders:a" || got[1] != "staging:orders:b" {
+		t.Errorf("Got = %q\n", got)
+	}
+
+	if got := c.unkey(c.Key("order:1")); got != "order:1" {
+		t.Errorf("Got = %q, Want = %q\n", got, "order:1")
+	}
+}
+
+func TestNamespaceSegment(t *testing.T) {
+	for _, segment := range []string{"a:b", "a*", "a?", "[a]", "a\\b"} {
+		var c Cache
+
+		if err := WithKeyPrefix(segment)(&c); !errors.Is(err, ErrInvalidOption) {
+			t.Errorf("Got = %v, Want = %v for %q\n", err, ErrInvalidOption, segment)
+		}
+
+		if err := WithTenant(segment)(&c); !errors.Is(err, ErrInvalidOption) {
+			t.Errorf("Got = %v, Want = %v for %q\n", err, ErrInvalidOption, segment)
+		}
+	}
+}
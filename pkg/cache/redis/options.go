@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"strings"
 )
 
 // Option - type for customize cache object.
@@ -25,3 +26,39 @@ func WithSlog(l *slog.Logger) Option {
 		return nil
 	}
 }
+
+// WithKeyPrefix - store keys of cache under 'prefix', so several stores share one redis db.
+// Keys of cache are read and written without prefix.
+func WithKeyPrefix(prefix string) Option {
+	return func(c *Cache) error {
+		if err := checkSegment(prefix); err != nil {
+			return fmt.Errorf("%w: key prefix %w", ErrInvalidOption, err)
+		}
+
+		c.prefix = prefix
+		return nil
+	}
+}
+
+// WithTenant - store keys of cache under 'tenant' before key prefix,
+// so several environments or tenants share one redis. Empty 'tenant' means no tenant.
+func WithTenant(tenant string) Option {
+	return func(c *Cache) error {
+		if err := checkSegment(tenant); err != nil {
+			return fmt.Errorf("%w: tenant %w", ErrInvalidOption, err)
+		}
+
+		c.tenant = tenant
+		return nil
+	}
+}
+
+// checkSegment - check 'segment' of namespace can't collide with other namespaces
+// and can be used in redis SCAN MATCH pattern as is.
+func checkSegment(segment string) error {
+	if strings.ContainsAny(segment, ":*?[]\\") {
+		return fmt.Errorf("%q contains one of ':*?[]\\'", segment)
+	}
+
+	return nil
+}
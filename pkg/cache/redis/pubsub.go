@@ -0,0 +1,95 @@
+package redis
+
+import (
+	"context"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Message - one message received by Subscription.
+type Message struct {
+	Channel string
+	Payload string
+	// Subscribed - message is a mark of subscription restored after broken connection,
+	// not a published payload. Messages published while connection was broken are lost.
+	Subscribed bool
+}
+
+// Subscription - receive messages published to channels matched by patterns.
+type Subscription struct {
+	pubsub   *redis.PubSub
+	messages chan Message
+	// namespace - namespace of cache, removed from channels of messages.
+	namespace string
+}
+
+// Publish - send 'payload' to all subscribers of 'channel' in cache 'c'.
+// Channel is in namespace of cache, like keys.
+func (c *Cache) Publish(
+	ctx context.Context,
+	channel string,
+	payload string,
+) error {
+	return c.wrapError(c.conn.Publish(ctx, c.key(channel), payload).Err())
+}
+
+// PSubscribe - subscribe to channels matched by any of 'patterns' in cache 'c'.
+// Patterns are matched in namespace of cache.
+// Returns after subscription confirmed by redis.
+// Subscription is restored after broken connection until Close.
+func (c *Cache) PSubscribe(
+	ctx context.Context,
+	patterns ...string,
+) (
+	*Subscription,
+	error,
+) {
+	pubsub := c.conn.PSubscribe(ctx, c.keys(patterns)...)
+
+	// Wait confirmations, so messages published after return are received.
+	for range patterns {
+		if _, err := pubsub.Receive(ctx); err != nil {
+			pubsub.Close()
+			return nil, c.wrapError(err)
+		}
+	}
+
+	sub := &Subscription{
+		pubsub:    pubsub,
+		messages:  make(chan Message),
+		namespace: c.namespace,
+	}
+
+	go sub.run(pubsub.ChannelWithSubscriptions())
+	return sub, nil
+}
+
+// Messages - return channel of received messages.
+// Channel closed after Close.
+func (s *Subscription) Messages() <-chan Message {
+	return s.messages
+}
+
+// Close - stop receive messages.
+func (s *Subscription) Close() error {
+	return wrapError(s.pubsub.Close())
+}
+
+// run - convert messages from 'received' until it closed.
+func (s *Subscription) run(received <-chan interface{}) {
+	defer close(s.messages)
+
+	for msg := range received {
+		switch msg := msg.(type) {
+		case *redis.Message:
+			s.messages <- Message{Channel: strings.TrimPrefix(msg.Channel, s.namespace), Payload: msg.Payload}
+		case *redis.Subscription:
+			if msg.Kind != "subscribe" && msg.Kind != "psubscribe" {
+				continue
+			}
+
+			s.messages <- Message{Channel: strings.TrimPrefix(msg.Channel, s.namespace), Subscribed: true}
+		}
+	}
+}
@@ -5,49 +5,65 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/KonnorFrik/BinaryTentacles/pkg/retry"
 	"github.com/redis/go-redis/v9"
 )
 
 // Cache - redis cache wrap.
+// Keys and channels of cache are stored with namespace 'tenant:prefix:',
+// empty segments are omitted, see WithTenant and WithKeyPrefix.
+// Namespace is not visible for callers of Cache.
+//
+// In cluster mode keys of one Transaction or SetIf must be in one slot,
+// use hash tags like '{order}:1' for it.
 type Cache struct {
-	conn   *redis.Client
+	conn   redis.UniversalClient
 	logger *slog.Logger
+	tenant string
+	prefix string
+	// namespace - joined tenant and prefix, prepended to every key.
+	namespace string
 }
 
+// KeepTTL - pass as ttl in Set for keep existing ttl of key.
+const KeepTTL = redis.KeepTTL
+
 var (
 	// ErrConnection - error with the connection for any reason
 	ErrConnection = errors.New("connection error")
 )
 
 // New - create a new Cache object.
+// Client is created for mode of 'config', see Config.
+// Tenant of 'config' is used if WithTenant is not given.
 func New(ctx context.Context, config Config, opts ...Option) (*Cache, error) {
 	var cache Cache
 
+	opts = append([]Option{WithTenant(config.Tenant)}, opts...)
+
 	for _, opt := range opts {
 		if e := opt(&cache); e != nil {
 			return nil, cache.wrapError(e)
 		}
 	}
 
-	c := redis.NewClient(&redis.Options{
-		Addr:         config.Addr,
-		Password:     config.Password,
-		DB:           config.DB,
-		Username:     config.User,
-		MaxRetries:   config.MaxRetries,
-		DialTimeout:  config.DialTimeout,
-		ReadTimeout:  config.Timeout,
-		WriteTimeout: config.Timeout,
-	})
+	c, err := newClient(config)
+
+	if err != nil {
+		return nil, cache.wrapError(err)
+	}
 
 	if cache.logger != nil {
 		cache.logger.LogAttrs(
 			nil,
 			slog.LevelInfo,
 			"[redis/New]",
-			slog.String("Connected to", config.Addr),
+			slog.String("Mode", config.Mode),
+			slog.String("Connected to", strings.Join(config.addrs(), ",")),
 			slog.String("Connected as", config.User),
 			slog.Int("Connected DB", config.DB),
 		)
@@ -62,13 +78,39 @@ func New(ctx context.Context, config Config, opts ...Option) (*Cache, error) {
 				slog.String("Ping Error", err.Error()),
 			)
 		}
+		c.Close()
 		return nil, fmt.Errorf("%w: redis is unavailable", ErrConnection)
 	}
 
 	cache.conn = c
+	cache.namespace = namespace(cache.tenant, cache.prefix)
 	return &cache, nil
 }
 
+// Connect - create a new Cache object, repeat connection by 'policy' while redis is unavailable.
+func Connect(
+	ctx context.Context,
+	config Config,
+	policy retry.Policy,
+	opts ...Option,
+) (
+	*Cache,
+	error,
+) {
+	var cache *Cache
+	err := retry.Do(ctx, policy, func(ctx context.Context) error {
+		var err error
+		cache, err = New(ctx, config, opts...)
+		return err
+	}, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return cache, nil
+}
+
 // Set - write a pair key-value in cache 'c'.
 // ttl same as in redis.
 func (c *Cache) Set(
@@ -77,7 +119,85 @@ func (c *Cache) Set(
 	value string,
 	ttl time.Duration,
 ) error {
-	return c.wrapError(c.conn.Set(ctx, key, value, ttl).Err())
+	return c.wrapError(c.conn.Set(ctx, c.key(key), value, ttl).Err())
+}
+
+// SetNew - write a pair key-value in cache 'c' if 'key' is not exist.
+// Returns false if 'key' already exist.
+func (c *Cache) SetNew(
+	ctx context.Context,
+	key string,
+	value string,
+	ttl time.Duration,
+) (
+	bool,
+	error,
+) {
+	res, err := c.conn.SetNX(ctx, c.key(key), value, ttl).Result()
+
+	if err != nil {
+		return false, c.wrapError(err)
+	}
+
+	return res, nil
+}
+
+// SetIf - atomically replace value of existing 'key' in cache 'c' with 'value'
+// if 'check' accepts current value. Error of 'check' is returned as is.
+// Returns ErrNil if 'key' not exist and ErrChanged if value changed while checked.
+func (c *Cache) SetIf(
+	ctx context.Context,
+	key string,
+	value string,
+	ttl time.Duration,
+	check func(current string) error,
+) error {
+	var checkErr error
+	err := c.conn.Watch(ctx, func(tx *redis.Tx) error {
+		current, err := tx.Get(ctx, c.key(key)).Result()
+
+		if err != nil {
+			return err
+		}
+
+		if checkErr = check(current); checkErr != nil {
+			return checkErr
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, c.key(key), value, ttl)
+			return nil
+		})
+		return err
+	}, c.key(key))
+
+	switch {
+	case checkErr != nil:
+		return checkErr
+	case errors.Is(err, redis.TxFailedErr):
+		return ErrChanged
+	}
+
+	return c.wrapError(err)
+}
+
+// Delete - remove 'keys' from cache 'c'.
+// In cluster mode keys are removed one by one, not atomically.
+// Returns count of removed keys.
+func (c *Cache) Delete(
+	ctx context.Context,
+	keys ...string,
+) (
+	int64,
+	error,
+) {
+	res, err := c.del(ctx, c.keys(keys))
+
+	if err != nil {
+		return 0, c.wrapError(err)
+	}
+
+	return res, nil
 }
 
 // Get - get stored value from cache 'c'.
@@ -88,7 +208,7 @@ func (c *Cache) Get(
 	string,
 	error,
 ) {
-	r := c.conn.Get(ctx, key)
+	r := c.conn.Get(ctx, c.key(key))
 	res, err := r.Result()
 
 	if err != nil {
@@ -98,38 +218,280 @@ func (c *Cache) Get(
 	return res, nil
 }
 
-// Keys - return all keys stored in cache 'c'.
-func (c *Cache) Keys(
+// Incr - increment integer stored at 'key' in cache 'c' and return new value.
+// Missing key counts as 0.
+func (c *Cache) Incr(
+	ctx context.Context,
+	key string,
+) (
+	int64,
+	error,
+) {
+	res, err := c.conn.Incr(ctx, c.key(key)).Result()
+
+	if err != nil {
+		return 0, c.wrapError(err)
+	}
+
+	return res, nil
+}
+
+// Push - append 'values' to the end of list stored at 'key' in cache 'c'.
+// Create the list if not exist.
+func (c *Cache) Push(
+	ctx context.Context,
+	key string,
+	values ...string,
+) error {
+	var args = make([]any, len(values))
+
+	for i, v := range values {
+		args[i] = v
+	}
+
+	return c.wrapError(c.conn.RPush(ctx, c.key(key), args...).Err())
+}
+
+// Append - append 'value' to the end of list stored at 'key' in cache 'c'
+// and set 'ttl' of list. Returns length of list with 'value'.
+func (c *Cache) Append(
+	ctx context.Context,
+	key string,
+	value string,
+	ttl time.Duration,
+) (
+	int64,
+	error,
+) {
+	var length *redis.IntCmd
+	_, err := c.conn.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		length = pipe.RPush(ctx, c.key(key), value)
+		pipe.Expire(ctx, c.key(key), ttl)
+		return nil
+	})
+
+	if err != nil {
+		return 0, c.wrapError(err)
+	}
+
+	return length.Val(), nil
+}
+
+// ListRemove - remove first element equal to 'value' from list stored at 'key' in cache 'c'.
+// Returns false if list has no such element.
+func (c *Cache) ListRemove(
+	ctx context.Context,
+	key string,
+	value string,
+) (
+	bool,
+	error,
+) {
+	res, err := c.conn.LRem(ctx, c.key(key), 1, value).Result()
+
+	if err != nil {
+		return false, c.wrapError(err)
+	}
+
+	return res > 0, nil
+}
+
+// Range - return elements of list stored at 'key' in cache 'c'.
+// 'start' and 'stop' same as in redis LRANGE, inclusive, negative counts from the end.
+func (c *Cache) Range(
 	ctx context.Context,
+	key string,
+	start int64,
+	stop int64,
 ) (
 	[]string,
 	error,
 ) {
-	// TODO: add limit for get N keys
-	var (
-		keys   []string
-		cursor uint64
-	)
+	res, err := c.conn.LRange(ctx, c.key(key), start, stop).Result()
 
-	for {
-		ks, nextCursor, err := c.conn.Scan(ctx, cursor, "*", 100).Result()
+	if err != nil {
+		return nil, c.wrapError(err)
+	}
 
-		if err != nil {
-			return nil, err
-		}
+	return res, nil
+}
 
-		keys = append(keys, ks...)
-		cursor = nextCursor
+// GetMany - get stored values of 'keys' from cache 'c'.
+// Value of missing key is nil.
+func (c *Cache) GetMany(
+	ctx context.Context,
+	keys ...string,
+) (
+	[]any,
+	error,
+) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	res, err := c.mget(ctx, c.keys(keys))
+
+	if err != nil {
+		return nil, c.wrapError(err)
+	}
+
+	return res, nil
+}
+
+// Exists - check is 'key' stored in cache 'c'.
+func (c *Cache) Exists(
+	ctx context.Context,
+	key string,
+) (
+	bool,
+	error,
+) {
+	res, err := c.conn.Exists(ctx, c.key(key)).Result()
+
+	if err != nil {
+		return false, c.wrapError(err)
+	}
+
+	return res > 0, nil
+}
+
+// SetAdd - add 'members' to set stored at 'key' in cache 'c'.
+// Create the set if not exist.
+func (c *Cache) SetAdd(
+	ctx context.Context,
+	key string,
+	members ...string,
+) error {
+	var args = make([]any, len(members))
+
+	for i, v := range members {
+		args[i] = v
+	}
+
+	return c.wrapError(c.conn.SAdd(ctx, c.key(key), args...).Err())
+}
+
+// SetRemove - remove 'members' from set stored at 'key' in cache 'c'.
+func (c *Cache) SetRemove(
+	ctx context.Context,
+	key string,
+	members ...string,
+) error {
+	var args = make([]any, len(members))
+
+	for i, v := range members {
+		args[i] = v
+	}
+
+	return c.wrapError(c.conn.SRem(ctx, c.key(key), args...).Err())
+}
+
+// SetMembers - return all members of set stored at 'key' in cache 'c'.
+func (c *Cache) SetMembers(
+	ctx context.Context,
+	key string,
+) (
+	[]string,
+	error,
+) {
+	res, err := c.conn.SMembers(ctx, c.key(key)).Result()
+
+	if err != nil {
+		return nil, c.wrapError(err)
+	}
+
+	return res, nil
+}
 
-		if cursor == 0 {
-			break
+// SortedAdd - add 'member' with 'score' to sorted set stored at 'key' in cache 'c'.
+// Create the set if not exist. Score of existing member is updated.
+func (c *Cache) SortedAdd(
+	ctx context.Context,
+	key string,
+	score float64,
+	member string,
+) error {
+	return c.wrapError(c.conn.ZAdd(ctx, c.key(key), redis.Z{Score: score, Member: member}).Err())
+}
+
+// SortedReplace - atomically remove members with 'score' from sorted set stored at 'key'
+// in cache 'c' and add 'member' with it.
+// So one member per score is kept.
+func (c *Cache) SortedReplace(
+	ctx context.Context,
+	key string,
+	score float64,
+	member string,
+) error {
+	bound := formatScore(score)
+	_, err := c.conn.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRemRangeByScore(ctx, c.key(key), bound, bound)
+		pipe.ZAdd(ctx, c.key(key), redis.Z{Score: score, Member: member})
+		return nil
+	})
+	return c.wrapError(err)
+}
+
+// SortedRange - return members of sorted set stored at 'key' in cache 'c'
+// with score in [from, to], lowest score first.
+func (c *Cache) SortedRange(
+	ctx context.Context,
+	key string,
+	from float64,
+	to float64,
+) (
+	[]string,
+	error,
+) {
+	res, err := c.conn.ZRangeByScore(ctx, c.key(key), &redis.ZRangeBy{
+		Min: formatScore(from),
+		Max: formatScore(to),
+	}).Result()
+
+	if err != nil {
+		return nil, c.wrapError(err)
+	}
+
+	return res, nil
+}
+
+// SortedRemove - remove members of sorted set stored at 'key' in cache 'c'
+// with score in [from, to].
+func (c *Cache) SortedRemove(
+	ctx context.Context,
+	key string,
+	from float64,
+	to float64,
+) error {
+	return c.wrapError(c.conn.ZRemRangeByScore(ctx, c.key(key), formatScore(from), formatScore(to)).Err())
+}
+
+// Keys - return all keys stored in cache 'c', without namespace of cache.
+// Only keys in namespace of cache are returned, in cluster mode from every master.
+func (c *Cache) Keys(
+	ctx context.Context,
+) (
+	[]string,
+	error,
+) {
+	// TODO: add limit for get N keys
+	var keys []string
+	err := c.scan(ctx, c.namespace+"*", func(ks []string) bool {
+		for _, k := range ks {
+			keys = append(keys, c.unkey(k))
 		}
+
+		return true
+	})
+
+	if err != nil {
+		return nil, err
 	}
 
 	return keys, nil
 }
 
-// Values - return all values stored in cache 'c'.
+// Values - return all values stored in cache 'c', in namespace of cache.
 func (c *Cache) Values(
 	ctx context.Context,
 ) (
@@ -142,15 +504,38 @@ func (c *Cache) Values(
 		return nil, err
 	}
 
-	values, err := c.conn.MGet(ctx, keys...).Result()
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	values, err := c.mget(ctx, c.keys(keys))
 
 	if err != nil {
-		return nil, err
+		return nil, c.wrapError(err)
 	}
 
 	return values, nil
 }
 
+// Tenant - return tenant segment of namespace of cache 'c', empty if not given.
+func (c *Cache) Tenant() string {
+	return c.tenant
+}
+
+// Key - return 'key' with namespace of cache 'c', as it stored in redis.
+// For use with Client.
+func (c *Cache) Key(key string) string {
+	return c.namespace + key
+}
+
+// Client - return go-redis client of cache 'c',
+// for packages built on features of redis not wrapped by Cache, like streams.
+// Namespace of cache is not applied by client, see Cache.Key.
+// Client is *redis.Client, *redis.ClusterClient or failover client, by mode of config.
+func (c *Cache) Client() redis.UniversalClient {
+	return c.conn
+}
+
 // Shutdown - shutdown a redis cache.
 func (c *Cache) Close(
 	ctx context.Context,
@@ -186,6 +571,49 @@ func (c *Cache) Close(
 	return nil
 }
 
+// namespace - return namespace of keys with 'tenant' and 'prefix', empty segments are omitted.
+func namespace(tenant string, prefix string) string {
+	var ns string
+
+	for _, segment := range []string{tenant, prefix} {
+		if segment != "" {
+			ns += segment + ":"
+		}
+	}
+
+	return ns
+}
+
+// key - return 'key' with namespace of cache 'c'.
+func (c *Cache) key(key string) string {
+	return c.namespace + key
+}
+
+// keys - return 'keys' with namespace of cache 'c'.
+func (c *Cache) keys(keys []string) []string {
+	if c.namespace == "" {
+		return keys
+	}
+
+	var result = make([]string, len(keys))
+
+	for i, k := range keys {
+		result[i] = c.namespace + k
+	}
+
+	return result
+}
+
+// unkey - return stored 'key' without namespace of cache 'c'.
+func (c *Cache) unkey(key string) string {
+	return strings.TrimPrefix(key, c.namespace)
+}
+
+// formatScore - format 'score' as bound of redis score range.
+func formatScore(score float64) string {
+	return strconv.FormatFloat(score, 'f', -1, 64)
+}
+
 // wrapError - log error if it not nil and call wrapError function.
 func (c *Cache) wrapError(err error) error {
 	if err == nil {
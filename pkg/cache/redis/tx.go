@@ -0,0 +1,128 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Tx - reads of watched keys and writes applied together by Cache.Transaction.
+// Writes are queued and applied only after function of transaction returns nil.
+type Tx struct {
+	ctx    context.Context
+	cache  *Cache
+	conn   *redis.Tx
+	writes []func(pipe redis.Pipeliner)
+}
+
+// Get - return current value of 'key'.
+// Returns ErrNil if 'key' not exist.
+func (t *Tx) Get(key string) (string, error) {
+	res, err := t.conn.Get(t.ctx, t.cache.key(key)).Result()
+	return res, wrapError(err)
+}
+
+// Len - return length of list stored at 'key', 0 if not exist.
+func (t *Tx) Len(key string) (int64, error) {
+	res, err := t.conn.LLen(t.ctx, t.cache.key(key)).Result()
+	return res, wrapError(err)
+}
+
+// Set - queue write of pair key-value with 'ttl'.
+func (t *Tx) Set(
+	key string,
+	value string,
+	ttl time.Duration,
+) {
+	t.writes = append(t.writes, func(pipe redis.Pipeliner) {
+		pipe.Set(t.ctx, t.cache.key(key), value, ttl)
+	})
+}
+
+// Delete - queue remove of 'keys'.
+func (t *Tx) Delete(keys ...string) {
+	t.writes = append(t.writes, func(pipe redis.Pipeliner) {
+		pipe.Del(t.ctx, t.cache.keys(keys)...)
+	})
+}
+
+// Push - queue append of 'values' to the end of list stored at 'key'.
+// List expires after 'ttl' if it positive.
+func (t *Tx) Push(
+	key string,
+	ttl time.Duration,
+	values ...string,
+) {
+	var args = make([]any, len(values))
+
+	for i, v := range values {
+		args[i] = v
+	}
+
+	t.writes = append(t.writes, func(pipe redis.Pipeliner) {
+		pipe.RPush(t.ctx, t.cache.key(key), args...)
+
+		if ttl > 0 {
+			pipe.Expire(t.ctx, t.cache.key(key), ttl)
+		}
+	})
+}
+
+// SetAdd - queue add of 'members' to set stored at 'key'.
+func (t *Tx) SetAdd(
+	key string,
+	members ...string,
+) {
+	var args = make([]any, len(members))
+
+	for i, v := range members {
+		args[i] = v
+	}
+
+	t.writes = append(t.writes, func(pipe redis.Pipeliner) {
+		pipe.SAdd(t.ctx, t.cache.key(key), args...)
+	})
+}
+
+// Transaction - call 'fn' for read watched 'keys' and queue writes,
+// then apply all writes at once (MULTI/EXEC) if none of 'keys' changed after read.
+// Error of 'fn' is returned as is and nothing is written.
+// Returns ErrChanged if any of 'keys' changed.
+func (c *Cache) Transaction(
+	ctx context.Context,
+	fn func(tx *Tx) error,
+	keys ...string,
+) error {
+	var fnErr error
+	err := c.conn.Watch(ctx, func(conn *redis.Tx) error {
+		tx := Tx{ctx: ctx, cache: c, conn: conn}
+
+		if fnErr = fn(&tx); fnErr != nil {
+			return fnErr
+		}
+
+		if len(tx.writes) == 0 {
+			return nil
+		}
+
+		_, err := conn.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			for _, write := range tx.writes {
+				write(pipe)
+			}
+
+			return nil
+		})
+		return err
+	}, c.keys(keys)...)
+
+	switch {
+	case fnErr != nil:
+		return fnErr
+	case errors.Is(err, redis.TxFailedErr):
+		return ErrChanged
+	}
+
+	return c.wrapError(err)
+}
@@ -0,0 +1,220 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// scanBatch - count of keys read at once by Keys and TypedStore.Scan.
+const scanBatch = 100
+
+// ErrDecode - stored value can't be decoded by codec of TypedStore.
+var ErrDecode = errors.New("value decode error")
+
+// TypedStore - values of type T stored in Cache, encoded by codec.
+type TypedStore[T any] struct {
+	cache *Cache
+	codec Codec[T]
+}
+
+// Entry - value of key read by TypedStore.
+// Err is ErrNil if key is not exist and ErrDecode if value can't be decoded,
+// then Value is zero.
+type Entry[T any] struct {
+	Key   string
+	Value T
+	Err   error
+}
+
+// NewTypedStore - create a new TypedStore with values stored in 'cache' and encoded by 'codec'.
+func NewTypedStore[T any](
+	cache *Cache,
+	codec Codec[T],
+) *TypedStore[T] {
+	return &TypedStore[T]{cache: cache, codec: codec}
+}
+
+// Get - return value stored at 'key'.
+// Returns ErrNil if 'key' not exist and ErrDecode if value can't be decoded.
+func (s *TypedStore[T]) Get(
+	ctx context.Context,
+	key string,
+) (
+	T,
+	error,
+) {
+	data, err := s.cache.Get(ctx, key)
+
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	return s.decode(key, data)
+}
+
+// Set - store 'value' at 'key' for 'ttl', ttl same as in Cache.Set.
+func (s *TypedStore[T]) Set(
+	ctx context.Context,
+	key string,
+	value T,
+	ttl time.Duration,
+) error {
+	data, err := s.codec.Encode(value)
+
+	if err != nil {
+		return fmt.Errorf("key %s: value encode: %w", key, err)
+	}
+
+	return s.cache.Set(ctx, key, string(data), ttl)
+}
+
+// MGet - return values of 'keys' in order of 'keys'.
+// Missing and not decoded values are reported in Err of entry, error is returned only if read failed.
+func (s *TypedStore[T]) MGet(
+	ctx context.Context,
+	keys ...string,
+) (
+	[]Entry[T],
+	error,
+) {
+	values, err := s.cache.GetMany(ctx, keys...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var entries = make([]Entry[T], len(keys))
+
+	for i, key := range keys {
+		entries[i].Key = key
+
+		switch data := values[i].(type) {
+		case nil:
+			entries[i].Err = ErrNil
+		case string:
+			entries[i].Value, entries[i].Err = s.decode(key, data)
+		default:
+			entries[i].Err = fmt.Errorf("%w: key %s: unexpected value %T", ErrDecode, key, data)
+		}
+	}
+
+	return entries, nil
+}
+
+// Scan - iterate over values of keys matching 'pattern', like redis SCAN MATCH.
+// Pattern is matched in namespace of cache, in cluster mode on every master.
+// Keys removed while scan are skipped, key may be met more than once, see redis SCAN.
+// Not decoded values are reported in Err of entry.
+// If read failed, error is yielded once with empty entry and iteration ends.
+func (s *TypedStore[T]) Scan(
+	ctx context.Context,
+	pattern string,
+) iter.Seq2[Entry[T], error] {
+	return func(yield func(Entry[T], error) bool) {
+		err := s.cache.scan(ctx, s.cache.key(pattern), func(keys []string) bool {
+			for i, key := range keys {
+				keys[i] = s.cache.unkey(key)
+			}
+
+			entries, err := s.MGet(ctx, keys...)
+
+			if err != nil {
+				yield(Entry[T]{}, err)
+				return false
+			}
+
+			for _, entry := range entries {
+				if errors.Is(entry.Err, ErrNil) {
+					continue
+				}
+
+				if !yield(entry, nil) {
+					return false
+				}
+			}
+
+			return true
+		})
+
+		if err != nil {
+			yield(Entry[T]{}, err)
+		}
+	}
+}
+
+// CompareAndSwap - atomically store 'value' at 'key' for 'ttl' if current value is 'old'.
+// Values are equal if their encodings are equal.
+// Returns false if current value is other or it changed while compared.
+// Returns ErrNil if 'key' not exist.
+func (s *TypedStore[T]) CompareAndSwap(
+	ctx context.Context,
+	key string,
+	old T,
+	value T,
+	ttl time.Duration,
+) (
+	bool,
+	error,
+) {
+	oldData, err := s.codec.Encode(old)
+
+	if err != nil {
+		return false, fmt.Errorf("key %s: value encode: %w", key, err)
+	}
+
+	newData, err := s.codec.Encode(value)
+
+	if err != nil {
+		return false, fmt.Errorf("key %s: value encode: %w", key, err)
+	}
+
+	var swapped bool
+	err = s.cache.conn.Watch(ctx, func(tx *redis.Tx) error {
+		current, err := tx.Get(ctx, s.cache.key(key)).Result()
+
+		if err != nil || current != string(oldData) {
+			return err
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, s.cache.key(key), newData, ttl)
+			return nil
+		})
+		swapped = err == nil
+		return err
+	}, s.cache.key(key))
+
+	if errors.Is(err, redis.TxFailedErr) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, s.cache.wrapError(err)
+	}
+
+	return swapped, nil
+}
+
+// decode - return value decoded from 'data' stored at 'key'.
+func (s *TypedStore[T]) decode(
+	key string,
+	data string,
+) (
+	T,
+	error,
+) {
+	value, err := s.codec.Decode([]byte(data))
+
+	if err != nil {
+		var zero T
+		return zero, fmt.Errorf("%w: key %s: %w", ErrDecode, key, err)
+	}
+
+	return value, nil
+}
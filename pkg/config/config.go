@@ -0,0 +1,343 @@
+/*
+Settings of service from YAML file, env and command line flags.
+
+Every field with 'env' tag is a setting. Priority from lowest: 'env-default' tag,
+YAML file, env, flag. Flag name is env name in lower case with '-' instead of '_',
+e.g. REDIS_ADDR is set by --redis-addr.
+Field with tag `secret:"true"` is redacted by Print.
+*/
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ilyakaznacheev/cleanenv"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// fileEnv - env with path of YAML file, overridden by flag --config.
+	fileEnv = "CONFIG_FILE"
+	// redacted - printed value of not empty secret.
+	redacted = "<redacted>"
+)
+
+var (
+	// ErrInvalid - config can't be loaded or has invalid values.
+	ErrInvalid = errors.New("invalid config")
+	// ErrHelp - help is requested by flag -h, usage already printed.
+	ErrHelp = flag.ErrHelp
+)
+
+// Validator - config with checks of values beyond required and types.
+type Validator interface {
+	Validate() error
+}
+
+// Result - what was requested by command line besides settings.
+type Result struct {
+	// File - path of used YAML file, empty if none.
+	File string
+	// PrintConfig - print effective config and exit instead of start service.
+	PrintConfig bool
+}
+
+// setting - one field of config.
+type setting struct {
+	env    string
+	flag   string
+	usage  string
+	secret bool
+	value  reflect.Value
+}
+
+// Load - fill 'cfg', pointer to struct, from YAML file, env and command line 'args'
+// of program 'name'. 'args' are without program name, like os.Args[1:].
+// Checks required fields and calls Validate if 'cfg' implements Validator.
+func Load(
+	name string,
+	args []string,
+	cfg any,
+) (
+	Result,
+	error,
+) {
+	var result Result
+	settings, err := collect(cfg)
+
+	if err != nil {
+		return result, err
+	}
+
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.StringVar(&result.File, "config", os.Getenv(fileEnv), "YAML file with settings. Default from env "+fileEnv)
+	fs.BoolVar(&result.PrintConfig, "print-config", false, "Print effective config with redacted secrets and exit")
+	var given = make(map[string]string)
+
+	for _, s := range settings {
+		fs.Func(s.flag, s.usage+" Default from env "+s.env, func(value string) error {
+			given[s.flag] = value
+			return nil
+		})
+	}
+
+	if err = fs.Parse(args); err != nil {
+		return result, err
+	}
+
+	// Flags are set before read, so required setting may be given only by flag,
+	// and after read, so they override file and env.
+	if err = apply(settings, given); err != nil {
+		return result, err
+	}
+
+	if result.File != "" {
+		err = cleanenv.ReadConfig(result.File, cfg)
+
+	} else {
+		err = cleanenv.ReadEnv(cfg)
+	}
+
+	if err != nil {
+		return result, fmt.Errorf("%w: %w", ErrInvalid, err)
+	}
+
+	if err = apply(settings, given); err != nil {
+		return result, err
+	}
+
+	if v, ok := cfg.(Validator); ok {
+		if err = v.Validate(); err != nil {
+			return result, fmt.Errorf("%w: %w", ErrInvalid, err)
+		}
+	}
+
+	return result, nil
+}
+
+// Print - write 'cfg' to 'w' as YAML with secrets redacted.
+func Print(
+	w io.Writer,
+	cfg any,
+) error {
+	node, err := toNode(reflect.ValueOf(cfg))
+
+	if err != nil {
+		return err
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+
+	if err = encoder.Encode(node); err != nil {
+		return err
+	}
+
+	return encoder.Close()
+}
+
+// collect - return settings of struct pointed by 'cfg'.
+func collect(cfg any) ([]setting, error) {
+	value := reflect.ValueOf(cfg)
+
+	if value.Kind() != reflect.Pointer || value.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: config must be pointer to struct, got %T", ErrInvalid, cfg)
+	}
+
+	var settings []setting
+	collectStruct(value.Elem(), "", &settings)
+	return settings, nil
+}
+
+// collectStruct - append settings of struct 'value' with env names prefixed by 'prefix'.
+func collectStruct(
+	value reflect.Value,
+	prefix string,
+	settings *[]setting,
+) {
+	for i := range value.NumField() {
+		field := value.Type().Field(i)
+
+		if !field.IsExported() {
+			continue
+		}
+
+		env, hasEnv := field.Tag.Lookup("env")
+
+		if !hasEnv && isNested(field.Type) {
+			collectStruct(value.Field(i), prefix+field.Tag.Get("env-prefix"), settings)
+			continue
+		}
+
+		if !hasEnv {
+			continue
+		}
+
+		env = prefix + strings.Split(env, ",")[0]
+		*settings = append(*settings, setting{
+			env:    env,
+			flag:   strings.ReplaceAll(strings.ToLower(env), "_", "-"),
+			usage:  field.Tag.Get("env-description"),
+			secret: field.Tag.Get("secret") == "true",
+			value:  value.Field(i),
+		})
+	}
+}
+
+// apply - set settings from 'given' values of flags.
+func apply(
+	settings []setting,
+	given map[string]string,
+) error {
+	for _, s := range settings {
+		raw, ok := given[s.flag]
+
+		if !ok {
+			continue
+		}
+
+		if err := setValue(s.value, raw); err != nil {
+			return fmt.Errorf("%w: flag --%s: %w", ErrInvalid, s.flag, err)
+		}
+	}
+
+	return nil
+}
+
+// setValue - parse 'raw' into 'value' by it type.
+func setValue(
+	value reflect.Value,
+	raw string,
+) error {
+	if value.Type() == reflect.TypeFor[time.Duration]() {
+		d, err := time.ParseDuration(raw)
+
+		if err != nil {
+			return err
+		}
+
+		value.SetInt(int64(d))
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(raw)
+
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+
+		if err != nil {
+			return err
+		}
+
+		value.SetBool(b)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 0, value.Type().Bits())
+
+		if err != nil {
+			return err
+		}
+
+		value.SetInt(n)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 0, value.Type().Bits())
+
+		if err != nil {
+			return err
+		}
+
+		value.SetUint(n)
+
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, value.Type().Bits())
+
+		if err != nil {
+			return err
+		}
+
+		value.SetFloat(f)
+
+	case reflect.Slice:
+		if value.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported type %s", value.Type())
+		}
+
+		value.Set(reflect.ValueOf(strings.Split(raw, ",")))
+
+	default:
+		return fmt.Errorf("unsupported type %s", value.Type())
+	}
+
+	return nil
+}
+
+// toNode - convert struct 'value' to YAML mapping with secrets redacted.
+// Keys are taken from 'yaml' tags, fields without it are named as in Go.
+func toNode(value reflect.Value) (*yaml.Node, error) {
+	for value.Kind() == reflect.Pointer {
+		value = value.Elem()
+	}
+
+	var node = yaml.Node{Kind: yaml.MappingNode}
+
+	for i := range value.NumField() {
+		field := value.Type().Field(i)
+		key := strings.Split(field.Tag.Get("yaml"), ",")[0]
+
+		if !field.IsExported() || key == "-" {
+			continue
+		}
+
+		if key == "" {
+			key = field.Name
+		}
+
+		var (
+			item *yaml.Node
+			err  error
+		)
+
+		switch {
+		case field.Tag.Get("secret") == "true":
+			item = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str"}
+
+			if !value.Field(i).IsZero() {
+				item.Value = redacted
+			}
+
+		case isNested(field.Type):
+			item, err = toNode(value.Field(i))
+
+		case field.Type == reflect.TypeFor[time.Duration]():
+			item = &yaml.Node{Kind: yaml.ScalarNode, Value: value.Field(i).Interface().(time.Duration).String()}
+
+		default:
+			item = new(yaml.Node)
+			err = item.Encode(value.Field(i).Interface())
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, item)
+	}
+
+	return &node, nil
+}
+
+// isNested - check is 't' a struct with settings, not a single value.
+func isNested(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t != reflect.TypeFor[time.Time]()
+}
@@ -0,0 +1,118 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testInner struct {
+	Password string `yaml:"password" env:"PASSWORD" secret:"true"`
+	Empty    string `yaml:"empty" env:"EMPTY" secret:"true"`
+}
+
+type testConfig struct {
+	Addr    string        `yaml:"addr" env:"ADDR" env-required:"true"`
+	Port    int           `yaml:"port" env:"PORT" env-default:"80"`
+	Timeout time.Duration `yaml:"timeout" env:"TIMEOUT" env-default:"1s"`
+	Inner   testInner     `yaml:"inner" env-prefix:"INNER_"`
+}
+
+func (c *testConfig) Validate() error {
+	if c.Port <= 0 {
+		return errors.New("port must be positive")
+	}
+
+	return nil
+}
+
+func TestLoadPriority(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	err := os.WriteFile(file, []byte("addr: file\nport: 1\ntimeout: 5s\n"), 0o600)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("PORT", "2")
+	t.Setenv("INNER_PASSWORD", "env")
+	var cfg testConfig
+	result, err := Load("test", []string{"--config", file, "--timeout", "7s"}, &cfg)
+
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	if result.File != file || result.PrintConfig {
+		t.Errorf("got result %+v", result)
+	}
+
+	var want = testConfig{Addr: "file", Port: 2, Timeout: 7 * time.Second, Inner: testInner{Password: "env"}}
+
+	if cfg != want {
+		t.Errorf("got config %+v, want %+v", cfg, want)
+	}
+}
+
+func TestLoadRequiredByFlag(t *testing.T) {
+	var cfg testConfig
+
+	if _, err := Load("test", nil, &cfg); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("Load without required: got error %v, want %v", err, ErrInvalid)
+	}
+
+	cfg = testConfig{}
+	result, err := Load("test", []string{"--addr", "flag", "--print-config"}, &cfg)
+
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	if cfg.Addr != "flag" || cfg.Port != 80 || cfg.Timeout != time.Second || !result.PrintConfig {
+		t.Errorf("got config %+v and result %+v", cfg, result)
+	}
+}
+
+func TestLoadValidate(t *testing.T) {
+	var cfg testConfig
+	_, err := Load("test", []string{"--addr", "a", "--port", "-1"}, &cfg)
+
+	if !errors.Is(err, ErrInvalid) {
+		t.Errorf("got error %v, want %v", err, ErrInvalid)
+	}
+
+	_, err = Load("test", []string{"--addr", "a", "--port", "x"}, &cfg)
+
+	if !errors.Is(err, ErrInvalid) {
+		t.Errorf("got error %v, want %v", err, ErrInvalid)
+	}
+}
+
+func TestPrint(t *testing.T) {
+	var (
+		out bytes.Buffer
+		cfg = testConfig{Addr: "a", Port: 1, Timeout: time.Minute, Inner: testInner{Password: "secret"}}
+	)
+
+	if err := Print(&out, &cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	var want = strings.Join([]string{
+		"addr: a",
+		"port: 1",
+		"timeout: 1m0s",
+		"inner:",
+		"  password: <redacted>",
+		`  empty: ""`,
+		"",
+	}, "\n")
+
+	if out.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
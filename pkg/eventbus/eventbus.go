@@ -0,0 +1,157 @@
+/*
+Publish and subscribe of messages by topics with consumer groups.
+Every group gets all messages of topic published after group creation,
+each message is delivered to one subscription of group.
+Message not acknowledged in ack timeout is delivered again,
+after max deliveries it is moved to dead letter topic.
+Delivery is at-least-once, handlers must tolerate repeated messages.
+*/
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// defaultAckTimeout - time for ack of message before redelivery, if not given.
+	defaultAckTimeout = 30 * time.Second
+	// defaultMaxDeliveries - deliveries of message before dead letter, if not given.
+	defaultMaxDeliveries = 5
+	// deadLetterSuffix - suffix of dead letter topic, full name is topic + suffix.
+	deadLetterSuffix = ".dead"
+)
+
+var (
+	// ErrClosed - bus or subscription is closed.
+	ErrClosed = errors.New("event bus closed")
+	// ErrInvalidOption - invalid option of subscription for any reason.
+	ErrInvalidOption = errors.New("invalid option")
+)
+
+// Message - published message.
+type Message struct {
+	// Id - id of message in topic, assigned by bus.
+	Id      string
+	Topic   string
+	Payload []byte
+	// Deliveries - count of deliveries of message to group with this one, 1 on first delivery.
+	Deliveries int
+}
+
+// Bus - publish and subscribe of messages.
+type Bus interface {
+	// Publish - add message with 'payload' to 'topic', returns id of message.
+	Publish(ctx context.Context, topic string, payload []byte) (string, error)
+	// Subscribe - receive messages of 'topic' as member of consumer 'group'.
+	// Group is created if not exist.
+	Subscribe(ctx context.Context, topic string, group string, opts ...SubscribeOption) (Subscription, error)
+}
+
+// Subscription - messages delivered to one member of group.
+type Subscription interface {
+	// Messages - channel of delivered messages, closed after Close.
+	Messages() <-chan Message
+	// Ack - acknowledge processing of 'msg', so it is not delivered again.
+	Ack(ctx context.Context, msg Message) error
+	// Close - stop delivery. Not acknowledged messages are delivered to other members of group.
+	Close() error
+}
+
+// DeadLetterTopic - return topic of messages of 'topic' not acknowledged after max deliveries.
+// Dead message has payload and id of original message in topic as payload.
+func DeadLetterTopic(topic string) string {
+	return topic + deadLetterSuffix
+}
+
+// DeadLetter - message moved to dead letter topic.
+type DeadLetter struct {
+	// Id - id of original message.
+	Id         string `json:"id"`
+	Payload    []byte `json:"payload"`
+	Deliveries int    `json:"deliveries"`
+}
+
+// subscribeConfig - settings of subscription.
+type subscribeConfig struct {
+	consumer      string
+	ackTimeout    time.Duration
+	maxDeliveries int
+	ephemeral     bool
+}
+
+// SubscribeOption - option for customize subscription.
+type SubscribeOption func(*subscribeConfig) error
+
+// WithConsumer - set name of member in group, unique in group.
+// Random name is used if not given.
+func WithConsumer(name string) SubscribeOption {
+	return func(c *subscribeConfig) error {
+		if name == "" {
+			return fmt.Errorf("%w: consumer name cannot be empty", ErrInvalidOption)
+		}
+
+		c.consumer = name
+		return nil
+	}
+}
+
+// WithAckTimeout - deliver message again if not acknowledged in 'timeout'.
+func WithAckTimeout(timeout time.Duration) SubscribeOption {
+	return func(c *subscribeConfig) error {
+		if timeout <= 0 {
+			return fmt.Errorf("%w: ack timeout must be positive", ErrInvalidOption)
+		}
+
+		c.ackTimeout = timeout
+		return nil
+	}
+}
+
+// WithMaxDeliveries - move message to dead letter topic after 'count' deliveries without ack.
+func WithMaxDeliveries(count int) SubscribeOption {
+	return func(c *subscribeConfig) error {
+		if count <= 0 {
+			return fmt.Errorf("%w: max deliveries must be positive", ErrInvalidOption)
+		}
+
+		c.maxDeliveries = count
+		return nil
+	}
+}
+
+// WithEphemeral - remove group when subscription closed.
+// For group with one member, like broadcast to every instance of service.
+// Backend may deliver messages of ephemeral subscription without group, then
+// message is delivered once, without redelivery and dead letter, see Redis.
+func WithEphemeral() SubscribeOption {
+	return func(c *subscribeConfig) error {
+		c.ephemeral = true
+		return nil
+	}
+}
+
+// newSubscribeConfig - return settings from 'opts' with defaults.
+func newSubscribeConfig(
+	consumer string,
+	opts []SubscribeOption,
+) (
+	subscribeConfig,
+	error,
+) {
+	var config = subscribeConfig{
+		consumer:      consumer,
+		ackTimeout:    defaultAckTimeout,
+		maxDeliveries: defaultMaxDeliveries,
+	}
+
+	for _, opt := range opts {
+		if err := opt(&config); err != nil {
+			return config, err
+		}
+	}
+
+	return config, nil
+}
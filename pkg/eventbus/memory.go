@@ -0,0 +1,277 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// memoryBufferSize - size of channel of subscription.
+	// Message not fit in channel is delivered by next redelivery check.
+	memoryBufferSize = 64
+	// maxRedeliveryInterval - max time between checks of not acknowledged messages.
+	maxRedeliveryInterval = time.Second
+)
+
+// Memory - Bus in memory of process, for run and tests without redis.
+// Messages are not kept after all groups acknowledged them.
+type Memory struct {
+	mut    sync.Mutex
+	topics map[string]*memoryTopic
+}
+
+// memoryTopic - groups of one topic.
+type memoryTopic struct {
+	// lastId - id of last published message.
+	lastId uint64
+	groups map[string]*memoryGroup
+}
+
+// memoryGroup - not acknowledged messages of group and members of it.
+type memoryGroup struct {
+	// pending - not acknowledged messages, oldest first.
+	pending []*memoryPending
+	members []*memorySubscription
+	// next - index of member for next delivery, round robin.
+	next int
+}
+
+// memoryPending - not acknowledged message.
+type memoryPending struct {
+	msg Message
+	// member - subscription got last delivery, nil if not delivered.
+	member      *memorySubscription
+	deliveredAt time.Time
+}
+
+// memorySubscription - Subscription of Memory.
+type memorySubscription struct {
+	bus      *Memory
+	topic    string
+	group    string
+	config   subscribeConfig
+	messages chan Message
+	stop     chan struct{}
+	// closed - protected by mutex of bus.
+	closed bool
+}
+
+// NewMemory - create a new empty Memory.
+func NewMemory() *Memory {
+	return &Memory{topics: make(map[string]*memoryTopic)}
+}
+
+// Publish - implement Bus interface.
+func (m *Memory) Publish(
+	ctx context.Context,
+	topic string,
+	payload []byte,
+) (
+	string,
+	error,
+) {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	return m.publish(topic, payload, time.Now()), nil
+}
+
+// Subscribe - implement Bus interface.
+func (m *Memory) Subscribe(
+	ctx context.Context,
+	topic string,
+	group string,
+	opts ...SubscribeOption,
+) (
+	Subscription,
+	error,
+) {
+	config, err := newSubscribeConfig(uuid.NewString(), opts)
+
+	if err != nil {
+		return nil, err
+	}
+
+	sub := &memorySubscription{
+		bus:      m,
+		topic:    topic,
+		group:    group,
+		config:   config,
+		messages: make(chan Message, memoryBufferSize),
+		stop:     make(chan struct{}),
+	}
+
+	m.mut.Lock()
+	g := m.topic(topic).group(group)
+	g.members = append(g.members, sub)
+	m.dispatch(topic, g, time.Now())
+	m.mut.Unlock()
+
+	go sub.redeliver()
+	return sub, nil
+}
+
+// publish - add message to all groups of 'topic' and deliver it.
+// Must be called with locked 'm'.
+func (m *Memory) publish(
+	topic string,
+	payload []byte,
+	now time.Time,
+) string {
+	t := m.topic(topic)
+	t.lastId++
+	id := strconv.FormatUint(t.lastId, 10)
+
+	for _, g := range t.groups {
+		g.pending = append(g.pending, &memoryPending{
+			msg: Message{Id: id, Topic: topic, Payload: slices.Clone(payload)},
+		})
+		m.dispatch(topic, g, now)
+	}
+
+	return id
+}
+
+// topic - return topic with 'name', created if not exist.
+// Must be called with locked 'm'.
+func (m *Memory) topic(name string) *memoryTopic {
+	t, exist := m.topics[name]
+
+	if !exist {
+		t = &memoryTopic{groups: make(map[string]*memoryGroup)}
+		m.topics[name] = t
+	}
+
+	return t
+}
+
+// group - return group with 'name', created if not exist.
+func (t *memoryTopic) group(name string) *memoryGroup {
+	g, exist := t.groups[name]
+
+	if !exist {
+		g = &memoryGroup{}
+		t.groups[name] = g
+	}
+
+	return g
+}
+
+// dispatch - deliver not delivered messages of 'g' and messages not acknowledged in time,
+// move messages out of deliveries to dead letter topic.
+// Must be called with locked 'm'.
+func (m *Memory) dispatch(
+	topic string,
+	g *memoryGroup,
+	now time.Time,
+) {
+	for i := 0; i < len(g.pending) && len(g.members) > 0; {
+		p := g.pending[i]
+
+		if p.member != nil && !p.member.closed && now.Sub(p.deliveredAt) < p.member.config.ackTimeout {
+			i++
+			continue
+		}
+
+		member := g.members[g.next%len(g.members)]
+		g.next++
+
+		if p.msg.Deliveries >= member.config.maxDeliveries {
+			dead, _ := json.Marshal(DeadLetter{Id: p.msg.Id, Payload: p.msg.Payload, Deliveries: p.msg.Deliveries})
+			m.publish(DeadLetterTopic(topic), dead, now)
+			g.pending = slices.Delete(g.pending, i, i+1)
+			continue
+		}
+
+		msg := p.msg
+		msg.Deliveries++
+
+		select {
+		case member.messages <- msg:
+			p.msg = msg
+			p.member = member
+			p.deliveredAt = now
+		default:
+		}
+
+		i++
+	}
+}
+
+// Messages - implement Subscription interface.
+func (s *memorySubscription) Messages() <-chan Message {
+	return s.messages
+}
+
+// Ack - implement Subscription interface.
+// Ack of unknown or already acknowledged message is ignored.
+func (s *memorySubscription) Ack(
+	ctx context.Context,
+	msg Message,
+) error {
+	s.bus.mut.Lock()
+	defer s.bus.mut.Unlock()
+
+	if s.closed {
+		return ErrClosed
+	}
+
+	g := s.bus.topic(s.topic).group(s.group)
+	g.pending = slices.DeleteFunc(g.pending, func(p *memoryPending) bool {
+		return p.msg.Id == msg.Id
+	})
+	return nil
+}
+
+// Close - implement Subscription interface.
+func (s *memorySubscription) Close() error {
+	s.bus.mut.Lock()
+	defer s.bus.mut.Unlock()
+
+	if s.closed {
+		return nil
+	}
+
+	s.closed = true
+	close(s.stop)
+	close(s.messages)
+	t := s.bus.topic(s.topic)
+	g := t.group(s.group)
+	g.members = slices.DeleteFunc(g.members, func(member *memorySubscription) bool {
+		return member == s
+	})
+
+	if s.config.ephemeral {
+		delete(t.groups, s.group)
+		return nil
+	}
+
+	s.bus.dispatch(s.topic, g, time.Now())
+	return nil
+}
+
+// redeliver - check not acknowledged messages of group until subscription closed.
+func (s *memorySubscription) redeliver() {
+	ticker := time.NewTicker(max(min(s.config.ackTimeout/2, maxRedeliveryInterval), time.Millisecond))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case now := <-ticker.C:
+			s.bus.mut.Lock()
+
+			if g, exist := s.bus.topic(s.topic).groups[s.group]; exist {
+				s.bus.dispatch(s.topic, g, now)
+			}
+
+			s.bus.mut.Unlock()
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// receive - return next message of 'sub' or fail after 'timeout'.
+func receive(
+	t *testing.T,
+	sub Subscription,
+	timeout time.Duration,
+) Message {
+	t.Helper()
+
+	select {
+	case msg := <-sub.Messages():
+		return msg
+	case <-time.After(timeout):
+		t.Fatalf("No message in %s\n", timeout)
+	}
+
+	return Message{}
+}
+
+func TestMemoryGroups(t *testing.T) {
+	var (
+		ctx = context.Background()
+		bus = NewMemory()
+	)
+
+	first, _ := bus.Subscribe(ctx, "orders", "first")
+	second, _ := bus.Subscribe(ctx, "orders", "second")
+	defer first.Close()
+	defer second.Close()
+
+	if _, err := bus.Publish(ctx, "orders", []byte("created")); err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	for _, sub := range []Subscription{first, second} {
+		msg := receive(t, sub, time.Second)
+
+		if string(msg.Payload) != "created" || msg.Deliveries != 1 {
+			t.Fatalf("Got = %+v\n", msg)
+		}
+
+		sub.Ack(ctx, msg)
+	}
+
+	// Late group gets only new messages.
+	late, _ := bus.Subscribe(ctx, "orders", "late")
+	defer late.Close()
+
+	select {
+	case msg := <-late.Messages():
+		t.Fatalf("Got = %+v, Want no message\n", msg)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestMemoryMembersShareGroup(t *testing.T) {
+	var (
+		ctx = context.Background()
+		bus = NewMemory()
+	)
+
+	first, _ := bus.Subscribe(ctx, "orders", "group")
+	second, _ := bus.Subscribe(ctx, "orders", "group")
+	defer first.Close()
+	defer second.Close()
+
+	bus.Publish(ctx, "orders", []byte("1"))
+	bus.Publish(ctx, "orders", []byte("2"))
+
+	if msg := receive(t, first, time.Second); string(msg.Payload) != "1" {
+		t.Fatalf("Got = %q, Want = %q\n", msg.Payload, "1")
+	}
+
+	if msg := receive(t, second, time.Second); string(msg.Payload) != "2" {
+		t.Fatalf("Got = %q, Want = %q\n", msg.Payload, "2")
+	}
+}
+
+func TestMemoryRedeliveryAndDeadLetter(t *testing.T) {
+	var (
+		ctx = context.Background()
+		bus = NewMemory()
+	)
+
+	dead, _ := bus.Subscribe(ctx, DeadLetterTopic("orders"), "dead")
+	sub, _ := bus.Subscribe(ctx, "orders", "group", WithAckTimeout(20*time.Millisecond), WithMaxDeliveries(2))
+	defer dead.Close()
+	defer sub.Close()
+
+	id, _ := bus.Publish(ctx, "orders", []byte("poison"))
+
+	for want := 1; want <= 2; want++ {
+		msg := receive(t, sub, time.Second)
+
+		if msg.Id != id || msg.Deliveries != want {
+			t.Fatalf("Got = %+v, Want delivery %d of %s\n", msg, want, id)
+		}
+	}
+
+	var letter DeadLetter
+	msg := receive(t, dead, time.Second)
+
+	if err := json.Unmarshal(msg.Payload, &letter); err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	if letter.Id != id || string(letter.Payload) != "poison" || letter.Deliveries != 2 {
+		t.Fatalf("Got = %+v\n", letter)
+	}
+
+	select {
+	case msg, isOpen := <-sub.Messages():
+		if isOpen {
+			t.Fatalf("Got = %+v, Want no message after dead letter\n", msg)
+		}
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestMemoryCloseRedelivers(t *testing.T) {
+	var (
+		ctx = context.Background()
+		bus = NewMemory()
+	)
+
+	first, _ := bus.Subscribe(ctx, "orders", "group")
+	second, _ := bus.Subscribe(ctx, "orders", "group")
+	defer second.Close()
+
+	bus.Publish(ctx, "orders", []byte("1"))
+	receive(t, first, time.Second)
+
+	// Not acknowledged message of closed member goes to other member.
+	first.Close()
+	msg := receive(t, second, time.Second)
+
+	if string(msg.Payload) != "1" || msg.Deliveries != 2 {
+		t.Fatalf("Got = %+v\n", msg)
+	}
+}
@@ -0,0 +1,466 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// payloadField - field of stream entry with payload of message.
+	payloadField = "payload"
+	// defaultMaxLen - approximate count of messages kept in stream of topic, if not given.
+	defaultMaxLen = 100_000
+	// readCount - max messages read or claimed at once.
+	readCount = 64
+	// maxReadBlock - max wait of new messages in one read, also pause after failed read.
+	maxReadBlock = time.Second
+)
+
+// Redis - Bus on redis streams, stream of topic has same name as topic, after tenant if given.
+// Groups are groups of stream, new group starts from messages published after it creation.
+// Ephemeral subscription reads stream without group, so nothing is left in redis
+// by subscription never closed, e.g. of crashed instance.
+// Messages of it are not delivered again and Ack does nothing.
+type Redis struct {
+	client redis.UniversalClient
+	maxLen int64
+	logger *slog.Logger
+	// streamPrefix - prepended to topic for name of stream.
+	streamPrefix string
+}
+
+// Option - option for customize Redis.
+type Option func(*Redis) error
+
+// WithMaxLen - keep about 'count' last messages in stream of topic.
+// Older messages are removed even if not acknowledged.
+func WithMaxLen(count int64) Option {
+	return func(r *Redis) error {
+		if count <= 0 {
+			return fmt.Errorf("%w: max length must be positive", ErrInvalidOption)
+		}
+
+		r.maxLen = count
+		return nil
+	}
+}
+
+// WithSlog - log errors of subscriptions with 'l'.
+func WithSlog(l *slog.Logger) Option {
+	return func(r *Redis) error {
+		if l == nil {
+			return fmt.Errorf("%w: logger cannot be nil", ErrInvalidOption)
+		}
+
+		r.logger = l
+		return nil
+	}
+}
+
+// WithTenant - keep streams of topics under 'tenant', like keys of redis cache with same tenant,
+// so several environments or tenants share one redis. Empty 'tenant' means no tenant.
+func WithTenant(tenant string) Option {
+	return func(r *Redis) error {
+		if strings.Contains(tenant, ":") {
+			return fmt.Errorf("%w: tenant %q contains ':'", ErrInvalidOption, tenant)
+		}
+
+		if tenant != "" {
+			r.streamPrefix = tenant + ":"
+		}
+
+		return nil
+	}
+}
+
+// NewRedis - create a new Redis with streams in redis of 'client'.
+func NewRedis(
+	client redis.UniversalClient,
+	opts ...Option,
+) (
+	*Redis,
+	error,
+) {
+	r := &Redis{client: client, maxLen: defaultMaxLen}
+
+	for _, opt := range opts {
+		if err := opt(r); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+// Publish - implement Bus interface.
+func (r *Redis) Publish(
+	ctx context.Context,
+	topic string,
+	payload []byte,
+) (
+	string,
+	error,
+) {
+	return r.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: r.streamPrefix + topic,
+		MaxLen: r.maxLen,
+		Approx: true,
+		Values: map[string]any{payloadField: payload},
+	}).Result()
+}
+
+// Subscribe - implement Bus interface.
+// Not acknowledged messages stay with consumer after Close,
+// use WithConsumer with stable name, so they are delivered after restart.
+func (r *Redis) Subscribe(
+	ctx context.Context,
+	topic string,
+	group string,
+	opts ...SubscribeOption,
+) (
+	Subscription,
+	error,
+) {
+	config, err := newSubscribeConfig(uuid.NewString(), opts)
+
+	if err != nil {
+		return nil, err
+	}
+
+	stream := r.streamPrefix + topic
+	runCtx, cancel := context.WithCancel(context.Background())
+	sub := &redisSubscription{
+		bus:      r,
+		topic:    topic,
+		stream:   stream,
+		group:    group,
+		config:   config,
+		messages: make(chan Message),
+		cancel:   cancel,
+	}
+
+	if config.ephemeral {
+		lastId, err := r.lastId(ctx, stream)
+
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+
+		sub.wg.Add(1)
+		go sub.runEphemeral(runCtx, lastId)
+		return sub, nil
+	}
+
+	err = r.client.XGroupCreateMkStream(ctx, stream, group, "$").Err()
+
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		cancel()
+		return nil, err
+	}
+
+	sub.wg.Add(1)
+	go sub.run(runCtx)
+	return sub, nil
+}
+
+// lastId - return id of last message in 'stream', "0-0" if stream is empty or not exist.
+func (r *Redis) lastId(
+	ctx context.Context,
+	stream string,
+) (
+	string,
+	error,
+) {
+	entries, err := r.client.XRevRangeN(ctx, stream, "+", "-", 1).Result()
+
+	if err != nil {
+		return "", err
+	}
+
+	if len(entries) == 0 {
+		return "0-0", nil
+	}
+
+	return entries[0].ID, nil
+}
+
+// redisSubscription - Subscription of Redis.
+type redisSubscription struct {
+	bus   *Redis
+	topic string
+	// stream - name of stream of topic.
+	stream   string
+	group    string
+	config   subscribeConfig
+	messages chan Message
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+	once     sync.Once
+}
+
+// Messages - implement Subscription interface.
+func (s *redisSubscription) Messages() <-chan Message {
+	return s.messages
+}
+
+// Ack - implement Subscription interface.
+func (s *redisSubscription) Ack(
+	ctx context.Context,
+	msg Message,
+) error {
+	if s.config.ephemeral {
+		return nil
+	}
+
+	return s.bus.client.XAck(ctx, s.stream, s.group, msg.Id).Err()
+}
+
+// Close - implement Subscription interface.
+func (s *redisSubscription) Close() error {
+	s.once.Do(func() {
+		s.cancel()
+		s.wg.Wait()
+	})
+
+	return nil
+}
+
+// run - deliver new messages and messages not acknowledged in time until 'ctx' done.
+func (s *redisSubscription) run(ctx context.Context) {
+	defer s.wg.Done()
+	defer close(s.messages)
+
+	var (
+		// Block 0 is wait forever in redis.
+		block     = max(min(s.config.ackTimeout/2, maxReadBlock), time.Millisecond)
+		lastClaim time.Time
+	)
+
+	for ctx.Err() == nil {
+		if time.Since(lastClaim) >= s.config.ackTimeout/2 {
+			lastClaim = time.Now()
+
+			if err := s.claim(ctx); err != nil {
+				s.logError(ctx, "[eventbus/Redis/claim]", err)
+			}
+		}
+
+		streams, err := s.bus.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    s.group,
+			Consumer: s.config.consumer,
+			Streams:  []string{s.stream, ">"},
+			Count:    readCount,
+			Block:    block,
+		}).Result()
+
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+
+		if err != nil {
+			s.logError(ctx, "[eventbus/Redis/XReadGroup]", err)
+			s.pause(ctx)
+			continue
+		}
+
+		for _, stream := range streams {
+			for _, entry := range stream.Messages {
+				if !s.deliver(ctx, entry, 1) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// runEphemeral - deliver messages after message 'lastId' until 'ctx' done, without group.
+func (s *redisSubscription) runEphemeral(
+	ctx context.Context,
+	lastId string,
+) {
+	defer s.wg.Done()
+	defer close(s.messages)
+
+	for ctx.Err() == nil {
+		streams, err := s.bus.client.XRead(ctx, &redis.XReadArgs{
+			Streams: []string{s.stream, lastId},
+			Count:   readCount,
+			Block:   maxReadBlock,
+		}).Result()
+
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+
+		if err != nil {
+			s.logError(ctx, "[eventbus/Redis/XRead]", err)
+			s.pause(ctx)
+			continue
+		}
+
+		for _, stream := range streams {
+			for _, entry := range stream.Messages {
+				if !s.deliver(ctx, entry, 1) {
+					return
+				}
+
+				lastId = entry.ID
+			}
+		}
+	}
+}
+
+// claim - take messages of group not acknowledged in ack timeout and deliver them again,
+// messages out of deliveries are moved to dead letter topic.
+func (s *redisSubscription) claim(ctx context.Context) error {
+	pending, err := s.bus.client.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream: s.stream,
+		Group:  s.group,
+		Idle:   s.config.ackTimeout,
+		Start:  "-",
+		End:    "+",
+		Count:  readCount,
+	}).Result()
+
+	if err != nil {
+		return err
+	}
+
+	var (
+		ids        = make([]string, 0, len(pending))
+		deliveries = make(map[string]int, len(pending))
+	)
+
+	for _, p := range pending {
+		if p.RetryCount >= int64(s.config.maxDeliveries) {
+			if err := s.deadLetter(ctx, p.ID, int(p.RetryCount)); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		ids = append(ids, p.ID)
+		deliveries[p.ID] = int(p.RetryCount) + 1
+	}
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	entries, err := s.bus.client.XClaim(ctx, &redis.XClaimArgs{
+		Stream:   s.stream,
+		Group:    s.group,
+		Consumer: s.config.consumer,
+		MinIdle:  s.config.ackTimeout,
+		Messages: ids,
+	}).Result()
+
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		// Message removed from stream by max length, nothing to deliver.
+		if entry.Values == nil {
+			if err := s.bus.client.XAck(ctx, s.stream, s.group, entry.ID).Err(); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if !s.deliver(ctx, entry, deliveries[entry.ID]) {
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+// deadLetter - move message with 'id' to dead letter topic and acknowledge it.
+// Message removed from stream by max length is only acknowledged.
+func (s *redisSubscription) deadLetter(
+	ctx context.Context,
+	id string,
+	deliveries int,
+) error {
+	entries, err := s.bus.client.XRangeN(ctx, s.stream, id, id, 1).Result()
+
+	if err != nil {
+		return err
+	}
+
+	if len(entries) > 0 {
+		payload, _ := entries[0].Values[payloadField].(string)
+		dead, err := json.Marshal(DeadLetter{Id: id, Payload: []byte(payload), Deliveries: deliveries})
+
+		if err != nil {
+			return err
+		}
+
+		if _, err = s.bus.Publish(ctx, DeadLetterTopic(s.topic), dead); err != nil {
+			return err
+		}
+	}
+
+	return s.bus.client.XAck(ctx, s.stream, s.group, id).Err()
+}
+
+// deliver - send 'entry' to channel of subscription.
+// Returns false if 'ctx' done before send.
+func (s *redisSubscription) deliver(
+	ctx context.Context,
+	entry redis.XMessage,
+	deliveries int,
+) bool {
+	payload, _ := entry.Values[payloadField].(string)
+
+	select {
+	case s.messages <- Message{Id: entry.ID, Topic: s.topic, Payload: []byte(payload), Deliveries: deliveries}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// pause - wait before retry of failed read or until 'ctx' done.
+func (s *redisSubscription) pause(ctx context.Context) {
+	timer := time.NewTimer(maxReadBlock)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
+
+// logError - log 'err' if logger given and 'ctx' not done.
+func (s *redisSubscription) logError(
+	ctx context.Context,
+	msg string,
+	err error,
+) {
+	if s.bus.logger == nil || ctx.Err() != nil {
+		return
+	}
+
+	s.bus.logger.LogAttrs(
+		ctx,
+		slog.LevelError,
+		msg,
+		slog.String("topic", s.topic),
+		slog.String("group", s.group),
+		slog.String("error", err.Error()),
+	)
+}
@@ -0,0 +1,142 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// newTestRedis - create Redis bus on miniredis with 'opts'.
+func newTestRedis(t *testing.T, opts ...Option) (*Redis, redis.UniversalClient) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	bus, err := NewRedis(client, opts...)
+
+	if err != nil {
+		t.Fatalf("Got = %v, Want = %v\n", err, nil)
+	}
+
+	return bus, client
+}
+
+func TestRedisGroups(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		bus, _ = newTestRedis(t, WithTenant("test"))
+	)
+
+	first, err := bus.Subscribe(ctx, "orders", "first")
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	defer first.Close()
+	second, _ := bus.Subscribe(ctx, "orders", "second")
+	defer second.Close()
+
+	if _, err := bus.Publish(ctx, "orders", []byte("created")); err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	for _, sub := range []Subscription{first, second} {
+		msg := receive(t, sub, time.Second)
+
+		if string(msg.Payload) != "created" || msg.Topic != "orders" || msg.Deliveries != 1 {
+			t.Fatalf("Got = %+v\n", msg)
+		}
+
+		if err := sub.Ack(ctx, msg); err != nil {
+			t.Fatalf("Got = %q\n", err)
+		}
+	}
+
+	// Late group gets only new messages.
+	late, _ := bus.Subscribe(ctx, "orders", "late")
+	defer late.Close()
+
+	select {
+	case msg := <-late.Messages():
+		t.Fatalf("Got = %+v, Want no message\n", msg)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestRedisRedelivery(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		bus, _ = newTestRedis(t)
+	)
+
+	sub, err := bus.Subscribe(ctx, "orders", "group", WithAckTimeout(100*time.Millisecond), WithMaxDeliveries(2))
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	defer sub.Close()
+	dead, _ := bus.Subscribe(ctx, DeadLetterTopic("orders"), "dead")
+	defer dead.Close()
+	id, _ := bus.Publish(ctx, "orders", []byte("created"))
+
+	// Not acknowledged message is delivered again, then moved to dead letter topic.
+	for want := 1; want <= 2; want++ {
+		if msg := receive(t, sub, time.Second); msg.Id != id || msg.Deliveries != want {
+			t.Fatalf("Got = %+v, Want delivery %d of %s\n", msg, want, id)
+		}
+	}
+
+	var letter DeadLetter
+
+	if err := json.Unmarshal(receive(t, dead, 2*time.Second).Payload, &letter); err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	if letter.Id != id || string(letter.Payload) != "created" || letter.Deliveries != 2 {
+		t.Fatalf("Got = %+v\n", letter)
+	}
+}
+
+func TestRedisEphemeral(t *testing.T) {
+	var (
+		ctx         = context.Background()
+		bus, client = newTestRedis(t)
+	)
+
+	bus.Publish(ctx, "orders", []byte("before"))
+	sub, err := bus.Subscribe(ctx, "orders", "instance", WithEphemeral())
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	bus.Publish(ctx, "orders", []byte("1"))
+	bus.Publish(ctx, "orders", []byte("2"))
+
+	for _, want := range []string{"1", "2"} {
+		if msg := receive(t, sub, time.Second); string(msg.Payload) != want {
+			t.Fatalf("Got = %q, Want = %q\n", msg.Payload, want)
+		}
+	}
+
+	// Subscription never closed, like of crashed instance, leaves no group.
+	groups, err := client.XInfoGroups(ctx, "orders").Result()
+
+	if err != nil || len(groups) != 0 {
+		t.Fatalf("Got = %v, %v, Want no groups\n", groups, err)
+	}
+
+	if err := sub.Close(); err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	if _, isOpen := <-sub.Messages(); isOpen {
+		t.Fatalf("Messages are not closed after Close\n")
+	}
+}
@@ -53,6 +53,41 @@ func (d *Db) Get(
 	return obj, ok
 }
 
+// Update - replace stored object by id with 'obj'.
+// Returns false if object not exist.
+func (d *Db) Update(
+	ctx context.Context,
+	id uint64,
+	obj any,
+) bool {
+	d.itemsMut.Lock()
+	defer d.itemsMut.Unlock()
+
+	if _, ok := d.items[id]; !ok {
+		return false
+	}
+
+	d.items[id] = obj
+	return true
+}
+
+// Delete - remove stored object by id.
+// Returns false if object not exist.
+func (d *Db) Delete(
+	ctx context.Context,
+	id uint64,
+) bool {
+	d.itemsMut.Lock()
+	defer d.itemsMut.Unlock()
+
+	if _, ok := d.items[id]; !ok {
+		return false
+	}
+
+	delete(d.items, id)
+	return true
+}
+
 func (d *Db) All(
 	ctx context.Context,
 ) []any {
@@ -0,0 +1,52 @@
+/*
+Mutexes by key, e.g. by id of order or market.
+Mutex of key exists only while it locked or waited for.
+*/
+package keylock
+
+import "sync"
+
+// Locks - mutexes by key. Zero value is ready to use.
+type Locks struct {
+	mut   sync.Mutex
+	locks map[string]*entry
+}
+
+// entry - mutex of one key.
+type entry struct {
+	mut sync.Mutex
+	// users - count of holder and waiters of mutex.
+	users int
+}
+
+// Lock - lock mutex of 'key', wait while it locked by others.
+// Returned function unlocks it and must be called once.
+func (l *Locks) Lock(key string) func() {
+	l.mut.Lock()
+
+	if l.locks == nil {
+		l.locks = make(map[string]*entry)
+	}
+
+	e, exist := l.locks[key]
+
+	if !exist {
+		e = new(entry)
+		l.locks[key] = e
+	}
+
+	e.users++
+	l.mut.Unlock()
+	e.mut.Lock()
+
+	return func() {
+		e.mut.Unlock()
+		l.mut.Lock()
+		defer l.mut.Unlock()
+		e.users--
+
+		if e.users == 0 {
+			delete(l.locks, key)
+		}
+	}
+}
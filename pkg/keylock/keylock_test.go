@@ -0,0 +1,38 @@
+package keylock
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestLock(t *testing.T) {
+	var (
+		locks   Locks
+		wg      sync.WaitGroup
+		counter = map[string]*int{"a": new(int), "b": new(int)}
+	)
+
+	for i := range 100 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			key := []string{"a", "b"}[i%2]
+			unlock := locks.Lock(key)
+			// Not synchronized except by lock of key.
+			value := *counter[key]
+			*counter[key] = value + 1
+			unlock()
+		}()
+	}
+
+	wg.Wait()
+
+	if *counter["a"] != 50 || *counter["b"] != 50 {
+		t.Fatalf("Got = %d, %d, Want = 50 per key\n", *counter["a"], *counter["b"])
+	}
+
+	if len(locks.locks) != 0 {
+		t.Fatalf("Got = %d mutexes, Want = 0\n", len(locks.locks))
+	}
+}
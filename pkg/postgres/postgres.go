@@ -0,0 +1,82 @@
+/*
+Connection to PostgreSQL.
+*/
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/KonnorFrik/BinaryTentacles/pkg/retry"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// uniqueViolation - code of postgres error on insert of existing unique key.
+const uniqueViolation = "23505"
+
+var (
+	// ErrConnection - error with the connection for any reason.
+	ErrConnection = errors.New("connection error")
+)
+
+// Config - settings of connection to postgres.
+// Empty DSN means postgres is not used.
+type Config struct {
+	DSN      string `yaml:"dsn" env:"POSTGRES_DSN" env-description:"Connection string of postgres, empty for not use postgres." secret:"true"`
+	MaxConns int32  `yaml:"max_conns" env:"POSTGRES_MAX_CONNS" env-default:"10" env-description:"Max open connections to postgres."`
+}
+
+// Enabled - report whether postgres is configured.
+func (c *Config) Enabled() bool {
+	return c.DSN != ""
+}
+
+// Connect - create a pool of connections by 'config',
+// repeat connection by 'policy' while postgres is unavailable.
+func Connect(
+	ctx context.Context,
+	config Config,
+	policy retry.Policy,
+) (
+	*pgxpool.Pool,
+	error,
+) {
+	poolConfig, err := pgxpool.ParseConfig(config.DSN)
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: parse dsn: %w", ErrConnection, err)
+	}
+
+	if config.MaxConns > 0 {
+		poolConfig.MaxConns = config.MaxConns
+	}
+
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrConnection, err)
+	}
+
+	err = retry.Do(ctx, policy, pool.Ping, nil)
+
+	if err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("%w: postgres is unavailable: %w", ErrConnection, err)
+	}
+
+	return pool, nil
+}
+
+// IsUniqueViolation - report whether 'err' is insert of existing unique key.
+func IsUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation
+}
+
+// IsNotFound - report whether 'err' is no rows of query for one row.
+func IsNotFound(err error) bool {
+	return errors.Is(err, pgx.ErrNoRows)
+}
@@ -0,0 +1,71 @@
+/*
+Repeat of calls which may fail for a while, like connection to other services at start.
+*/
+package retry
+
+import (
+	"context"
+	"time"
+)
+
+// Policy - how many times and how often a call is repeated.
+type Policy struct {
+	// Attempts - max count of calls, values less than 1 mean one call.
+	Attempts int
+	// Delay - delay before second call, doubled for every next call up to MaxDelay.
+	Delay    time.Duration
+	MaxDelay time.Duration
+	// Timeout - time limit of one call, zero means no limit.
+	Timeout time.Duration
+}
+
+// Do - call 'fn' until it succeed, attempts of 'policy' are over or 'ctx' is done.
+// 'onFail' is called after every failed call if not nil.
+// Returns error of last call.
+func Do(
+	ctx context.Context,
+	policy Policy,
+	fn func(ctx context.Context) error,
+	onFail func(attempt int, err error),
+) error {
+	var delay = policy.Delay
+
+	for attempt := 1; ; attempt++ {
+		err := call(ctx, policy.Timeout, fn)
+
+		if err == nil {
+			return nil
+		}
+
+		if onFail != nil {
+			onFail(attempt, err)
+		}
+
+		if attempt >= policy.Attempts {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+
+		delay = min(delay*2, max(policy.MaxDelay, policy.Delay))
+	}
+}
+
+// call - call 'fn' with 'timeout'.
+func call(
+	ctx context.Context,
+	timeout time.Duration,
+	fn func(ctx context.Context) error,
+) error {
+	if timeout <= 0 {
+		return fn(ctx)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return fn(ctx)
+}
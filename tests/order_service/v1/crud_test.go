@@ -62,6 +62,7 @@ func TestCRUD(t *testing.T) {
 					UserId:    userID,
 					MarketId:  marketIdValid,
 					OrderType: client.OrderType_ORDER_TYPE_T1,
+					Side:      client.OrderSide_ORDER_SIDE_BUY,
 					Price:     123,
 					Quantity:  1,
 				}
@@ -105,6 +106,7 @@ func TestCRUD(t *testing.T) {
 					UserId:    userID,
 					MarketId:  "",
 					OrderType: client.OrderType_ORDER_TYPE_T1,
+					Side:      client.OrderSide_ORDER_SIDE_BUY,
 					Price:     123,
 					Quantity:  1,
 				}
@@ -133,6 +135,7 @@ func TestCRUD(t *testing.T) {
 					UserId:    userID,
 					MarketId:  "1234",
 					OrderType: client.OrderType_ORDER_TYPE_T1,
+					Side:      client.OrderSide_ORDER_SIDE_BUY,
 					Price:     123,
 					Quantity:  1,
 				}
@@ -161,6 +164,7 @@ func TestCRUD(t *testing.T) {
 					UserId:    userID,
 					MarketId:  uuid.NewString(),
 					OrderType: client.OrderType_ORDER_TYPE_T1,
+					Side:      client.OrderSide_ORDER_SIDE_BUY,
 					Price:     123,
 					Quantity:  1,
 				}
@@ -200,7 +204,10 @@ func TestStream(t *testing.T) {
 		t.Fatalf("Got = %q\n", err)
 	}
 
-	var wantStatus = client.OrderStatus_ORDER_STATUS_CREATED
+	var (
+		wantStatus = client.OrderStatus_ORDER_STATUS_CREATED
+		sequence   uint64
+	)
 
 	for {
 		resp, err := stream.Recv()
@@ -213,6 +220,12 @@ func TestStream(t *testing.T) {
 			t.Fatalf("Got = %q\n", err)
 		}
 
+		sequence++
+
+		if resp.GetSequence() != sequence {
+			t.Fatalf("Got = %d, Want = %d\n", resp.GetSequence(), sequence)
+		}
+
 		switch wantStatus {
 		case client.OrderStatus_ORDER_STATUS_CREATED:
 			if resp.GetStatus() != wantStatus {
@@ -237,14 +250,165 @@ func TestStream(t *testing.T) {
 				t.Fatalf("Got = %d, Want = %d || %d\n", resp.GetStatus(), client.OrderStatus_ORDER_STATUS_CONFIRM, client.OrderStatus_ORDER_STATUS_REJECT)
 
 			} else {
+				// Confirmed order waits in book, stream ends only with final status.
 				err := stream.CloseSend()
 
 				if err != nil {
 					t.Fatalf("Got = %q\n", err)
 				}
 
-				break
+				return
 			}
 		}
 	}
 }
+
+func TestStreamResume(t *testing.T) {
+	createReq := client.CreateRequest{
+		UserId:    userID,
+		MarketId:  marketIdValid,
+		OrderType: client.OrderType_ORDER_TYPE_T1,
+		Side:      client.OrderSide_ORDER_SIDE_BUY,
+		Price:     123,
+		Quantity:  1,
+	}
+	created, err := orderService.Create(baseCtx, &createReq)
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	ctx, cancel := context.WithCancel(baseCtx)
+	stream, err := orderService.OrderUpdates(ctx, &client.OrderUpdatesRequest{
+		OrderId: created.GetOrderId(),
+		UserId:  userID,
+	})
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	var last *client.OrderUpdatesResponse
+
+	for range 2 {
+		if last, err = stream.Recv(); err != nil {
+			t.Fatalf("Got = %q\n", err)
+		}
+	}
+
+	// Reconnect: only changes after last received must come.
+	cancel()
+	stream, err = orderService.OrderUpdates(baseCtx, &client.OrderUpdatesRequest{
+		OrderId:      created.GetOrderId(),
+		UserId:       userID,
+		FromSequence: last.GetSequence(),
+	})
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	resp, err := stream.Recv()
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	if resp.GetSequence() != last.GetSequence()+1 || resp.GetStatus() != client.OrderStatus_ORDER_STATUS_PROCESSED {
+		t.Fatalf("Got = %d/%s, Want = %d/%s\n", resp.GetSequence(), resp.GetStatus(), last.GetSequence()+1, client.OrderStatus_ORDER_STATUS_PROCESSED)
+	}
+}
+
+func TestCancel(t *testing.T) {
+	createReq := client.CreateRequest{
+		UserId:    userID,
+		MarketId:  marketIdValid,
+		OrderType: client.OrderType_ORDER_TYPE_T1,
+		Side:      client.OrderSide_ORDER_SIDE_BUY,
+		Price:     123,
+		Quantity:  1,
+	}
+	created, err := orderService.Create(baseCtx, &createReq)
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	_, err = orderService.Cancel(baseCtx, &client.CancelRequest{
+		OrderId: created.GetOrderId(),
+		UserId:  uuid.NewString(),
+	})
+
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("Cancel by other user: Got = %v, Want = %d\n", err, codes.NotFound)
+	}
+
+	req := client.CancelRequest{
+		OrderId: created.GetOrderId(),
+		UserId:  userID,
+	}
+	resp, err := orderService.Cancel(baseCtx, &req)
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	if resp.GetStatus() != client.OrderStatus_ORDER_STATUS_CANCELLED {
+		t.Fatalf("Got = %d, Want = %d\n", resp.GetStatus(), client.OrderStatus_ORDER_STATUS_CANCELLED)
+	}
+
+	_, err = orderService.Cancel(baseCtx, &req)
+	stat, ok := status.FromError(err)
+
+	if !ok {
+		t.Fatalf("Error on convert status from error: %q\n", err)
+	}
+
+	if stat.Code() != codes.FailedPrecondition {
+		t.Fatalf("Got = %d, Want = %d\n", stat.Code(), codes.FailedPrecondition)
+	}
+}
+
+func TestCancelProcessed(t *testing.T) {
+	created, err := orderService.Create(baseCtx, &client.CreateRequest{
+		UserId:    userID,
+		MarketId:  marketIdValid,
+		OrderType: client.OrderType_ORDER_TYPE_T1,
+		Side:      client.OrderSide_ORDER_SIDE_BUY,
+		Price:     123,
+		Quantity:  1,
+	})
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	ctx, cancel := context.WithTimeout(baseCtx, 10*time.Second)
+	defer cancel()
+	stream, err := orderService.OrderUpdates(ctx, &client.OrderUpdatesRequest{OrderId: created.GetOrderId(), UserId: userID})
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	var last client.OrderStatus
+
+	for last != client.OrderStatus_ORDER_STATUS_CONFIRM && last != client.OrderStatus_ORDER_STATUS_REJECT {
+		resp, err := stream.Recv()
+
+		if err != nil {
+			t.Fatalf("Got = %q\n", err)
+		}
+
+		last = resp.GetStatus()
+	}
+
+	_, err = orderService.Cancel(baseCtx, &client.CancelRequest{OrderId: created.GetOrderId(), UserId: userID})
+
+	switch {
+	case last == client.OrderStatus_ORDER_STATUS_CONFIRM && err != nil:
+		t.Fatalf("Cancel of confirmed order: Got = %q, Want = nil\n", err)
+	case last == client.OrderStatus_ORDER_STATUS_REJECT && status.Code(err) != codes.FailedPrecondition:
+		t.Fatalf("Cancel of rejected order: Got = %v, Want = %d\n", err, codes.FailedPrecondition)
+	}
+}
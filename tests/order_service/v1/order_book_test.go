@@ -0,0 +1,150 @@
+package order_service_v1_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	client "github.com/KonnorFrik/BinaryTentacles/internal/generated/order_service/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestOrderBook(t *testing.T) {
+	ctx, cancel := context.WithTimeout(baseCtx, time.Second*10)
+	defer cancel()
+	stream, err := orderService.StreamOrderBook(ctx, &client.StreamOrderBookRequest{MarketId: marketIdValid})
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	first, err := stream.Recv()
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	if first.GetSnapshot() == nil {
+		t.Fatalf("First message is not a snapshot: %v\n", first)
+	}
+
+	// Unique price, so resting orders of previous runs are not matched.
+	var (
+		price    = 1_000_000 + time.Now().UnixNano()%1_000_000
+		sequence = first.GetSequence()
+	)
+
+	nextAsk := func(t *testing.T) *client.OrderBookLevel {
+		resp, err := stream.Recv()
+
+		if err != nil {
+			t.Fatalf("Got = %q\n", err)
+		}
+
+		sequence++
+
+		if resp.GetSequence() != sequence {
+			t.Fatalf("Gap in sequence: Got = %d, Want = %d\n", resp.GetSequence(), sequence)
+		}
+
+		for _, lvl := range resp.GetUpdate().GetAsks() {
+			if lvl.GetPrice() == price {
+				return lvl
+			}
+		}
+
+		t.Fatalf("Got no ask at %d in %v\n", price, resp)
+		return nil
+	}
+
+	sell, err := orderService.Create(ctx, &client.CreateRequest{
+		UserId:    userID,
+		MarketId:  marketIdValid,
+		OrderType: client.OrderType_ORDER_TYPE_T1,
+		Side:      client.OrderSide_ORDER_SIDE_SELL,
+		Price:     price,
+		Quantity:  3,
+	})
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	if lvl := nextAsk(t); lvl.GetQuantity() != 3 || lvl.GetOrderCount() != 1 {
+		t.Fatalf("Got = %v, Want quantity 3 of 1 order\n", lvl)
+	}
+
+	book, err := orderService.GetOrderBook(ctx, &client.GetOrderBookRequest{MarketId: marketIdValid, Depth: 1000})
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	if book.GetSequence() != sequence {
+		t.Fatalf("Got = %d, Want = %d\n", book.GetSequence(), sequence)
+	}
+
+	buy, err := orderService.Create(ctx, &client.CreateRequest{
+		UserId:    userID,
+		MarketId:  marketIdValid,
+		OrderType: client.OrderType_ORDER_TYPE_T1,
+		Side:      client.OrderSide_ORDER_SIDE_BUY,
+		Price:     price,
+		Quantity:  1,
+	})
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	if lvl := nextAsk(t); lvl.GetQuantity() != 2 {
+		t.Fatalf("Got = %v, Want quantity 2\n", lvl)
+	}
+
+	if buy.GetOrderStatus() != client.OrderStatus_ORDER_STATUS_FILLED {
+		t.Fatalf("Got = %s, Want = %s\n", buy.GetOrderStatus(), client.OrderStatus_ORDER_STATUS_FILLED)
+	}
+
+	sellStatus, err := orderService.OrderStatus(ctx, &client.OrderStatusRequest{OrderId: sell.GetOrderId(), UserId: userID})
+
+	if err != nil || sellStatus.GetStatus() != client.OrderStatus_ORDER_STATUS_PARTIALLY_FILLED {
+		t.Fatalf("Got = %v, %v, Want = %s\n", sellStatus, err, client.OrderStatus_ORDER_STATUS_PARTIALLY_FILLED)
+	}
+
+	_, err = orderService.Cancel(ctx, &client.CancelRequest{OrderId: buy.GetOrderId(), UserId: userID})
+
+	if stat, _ := status.FromError(err); stat.Code() != codes.FailedPrecondition {
+		t.Fatalf("Cancel of filled order: Got = %v, Want = %d\n", err, codes.FailedPrecondition)
+	}
+
+	_, err = orderService.Cancel(ctx, &client.CancelRequest{OrderId: sell.GetOrderId(), UserId: userID})
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	if lvl := nextAsk(t); lvl.GetQuantity() != 0 {
+		t.Fatalf("Got = %v, Want removed level\n", lvl)
+	}
+}
+
+func TestOrderBookInvalid(t *testing.T) {
+	_, err := orderService.GetOrderBook(baseCtx, &client.GetOrderBookRequest{MarketId: "1234"})
+
+	if stat, _ := status.FromError(err); stat.Code() != codes.FailedPrecondition {
+		t.Fatalf("Got = %d, Want = %d\n", stat.Code(), codes.FailedPrecondition)
+	}
+
+	_, err = orderService.Create(baseCtx, &client.CreateRequest{
+		UserId:    userID,
+		MarketId:  marketIdValid,
+		OrderType: client.OrderType_ORDER_TYPE_T1,
+		Price:     123,
+		Quantity:  1,
+	})
+
+	if stat, _ := status.FromError(err); stat.Code() != codes.InvalidArgument {
+		t.Fatalf("Got = %d, Want = %d\n", stat.Code(), codes.InvalidArgument)
+	}
+}
@@ -0,0 +1,61 @@
+package order_service_v1_test
+
+import (
+	"testing"
+
+	client "github.com/KonnorFrik/BinaryTentacles/internal/generated/order_service/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGetOrderHistory(t *testing.T) {
+	created, err := orderService.Create(baseCtx, &client.CreateRequest{
+		UserId:    userID,
+		MarketId:  marketIdValid,
+		OrderType: client.OrderType_ORDER_TYPE_T1,
+		Side:      client.OrderSide_ORDER_SIDE_SELL,
+		Price:     1,
+		Quantity:  1,
+	})
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	_, err = orderService.Cancel(baseCtx, &client.CancelRequest{OrderId: created.GetOrderId(), UserId: userID})
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	resp, err := orderService.GetOrderHistory(baseCtx, &client.GetOrderHistoryRequest{
+		OrderId: created.GetOrderId(),
+		UserId:  userID,
+	})
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	events := resp.GetEvents()
+
+	if len(events) < 2 || events[0].GetType() != client.OrderEventType_ORDER_EVENT_TYPE_CREATED {
+		t.Fatalf("Got = %v, Want CREATED first\n", events)
+	}
+
+	for i, event := range events {
+		if event.GetRevision() != uint64(i+1) {
+			t.Fatalf("Got = %d, Want = %d\n", event.GetRevision(), i+1)
+		}
+	}
+
+	if last := events[len(events)-1]; last.GetType() != client.OrderEventType_ORDER_EVENT_TYPE_CANCELLED {
+		t.Fatalf("Got = %s, Want = %s\n", last.GetType(), client.OrderEventType_ORDER_EVENT_TYPE_CANCELLED)
+	}
+
+	_, err = orderService.GetOrderHistory(baseCtx, &client.GetOrderHistoryRequest{OrderId: created.GetOrderId(), UserId: "other"})
+
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("Got = %v, Want = %v\n", status.Code(err), codes.NotFound)
+	}
+}
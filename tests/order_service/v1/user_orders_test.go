@@ -0,0 +1,98 @@
+package order_service_v1_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	client "github.com/KonnorFrik/BinaryTentacles/internal/generated/order_service/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestStreamUserOrders(t *testing.T) {
+	ctx, cancel := context.WithTimeout(baseCtx, time.Second*10)
+	defer cancel()
+	// Unique user, so events of previous runs are not received.
+	user := fmt.Sprintf("%s-%d", userID, time.Now().UnixNano())
+	stream, err := orderService.StreamUserOrders(ctx, &client.StreamUserOrdersRequest{
+		UserId:   user,
+		MarketId: marketIdValid,
+	})
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	created, err := orderService.Create(baseCtx, &client.CreateRequest{
+		UserId:    user,
+		MarketId:  marketIdValid,
+		OrderType: client.OrderType_ORDER_TYPE_T1,
+		Side:      client.OrderSide_ORDER_SIDE_BUY,
+		Price:     1,
+		Quantity:  1,
+	})
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	want := []client.OrderEventType{
+		client.OrderEventType_ORDER_EVENT_TYPE_CREATED,
+		client.OrderEventType_ORDER_EVENT_TYPE_STATUS_CHANGED,
+	}
+
+	for i, wantType := range want {
+		resp, err := stream.Recv()
+
+		if err != nil {
+			t.Fatalf("Got = %q\n", err)
+		}
+
+		event := resp.GetEvent()
+
+		if event.GetSequence() != uint64(i+1) || event.GetType() != wantType || event.GetOrderId() != created.GetOrderId() {
+			t.Fatalf("Got = %v, Want sequence %d of type %s\n", event, i+1, wantType)
+		}
+	}
+
+	// Reconnect: only events after last received must come.
+	stream, err = orderService.StreamUserOrders(ctx, &client.StreamUserOrdersRequest{
+		UserId:       user,
+		FromSequence: 1,
+	})
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	resp, err := stream.Recv()
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	if resp.GetEvent().GetSequence() != 2 || resp.GetEvent().GetStatus() != client.OrderStatus_ORDER_STATUS_PROCESSING {
+		t.Fatalf("Got = %v, Want sequence 2 with status %s\n", resp.GetEvent(), client.OrderStatus_ORDER_STATUS_PROCESSING)
+	}
+}
+
+func TestStreamUserOrdersInvalid(t *testing.T) {
+	requests := []*client.StreamUserOrdersRequest{
+		{},
+		{UserId: userID, MarketId: "not-uuid"},
+	}
+
+	for _, req := range requests {
+		stream, err := orderService.StreamUserOrders(baseCtx, req)
+
+		if err == nil {
+			_, err = stream.Recv()
+		}
+
+		if stat, _ := status.FromError(err); stat.Code() != codes.InvalidArgument {
+			t.Fatalf("Got = %d, Want = %d\n", stat.Code(), codes.InvalidArgument)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package spot_instrument_v1_test
+
+import (
+	"testing"
+
+	client "github.com/KonnorFrik/BinaryTentacles/internal/generated/spot_instrument/v1"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// actor - name of caller with adminToken in container/.env.
+const actor = "admin"
+
+func TestAdminMarketLifecycle(t *testing.T) {
+	admin := client.NewSpotInstrumentAdminServiceClient(conn)
+	// Unique asset, so test can be run many times against same redis.
+	base := "T" + uuid.NewString()[:8]
+	created, err := admin.CreateMarket(adminCtx, &client.CreateMarketRequest{
+		// Ignored, actor is authenticated caller.
+		Actor:          "forged",
+		BaseAsset:      base,
+		QuoteAsset:     "USDT",
+		PricePrecision: 2,
+		Enabled:        true,
+	})
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	marketId := created.GetMarket().GetId()
+
+	_, err = admin.CreateMarket(adminCtx, &client.CreateMarketRequest{
+		BaseAsset:  base,
+		QuoteAsset: "USDT",
+	})
+
+	if stat, _ := status.FromError(err); stat.Code() != codes.AlreadyExists {
+		t.Fatalf("Got = %d, Want = %d\n", stat.Code(), codes.AlreadyExists)
+	}
+
+	action := client.MarketActionRequest{MarketId: marketId}
+	steps := []struct {
+		name string
+		call func() (*client.MarketResponse, error)
+		want client.MarketState
+	}{
+		{
+			name: "Disable",
+			call: func() (*client.MarketResponse, error) { return admin.DisableMarket(adminCtx, &action) },
+			want: client.MarketState_MARKET_STATE_CLOSED,
+		},
+		{
+			name: "Enable",
+			call: func() (*client.MarketResponse, error) { return admin.EnableMarket(adminCtx, &action) },
+			want: client.MarketState_MARKET_STATE_ACTIVE,
+		},
+		{
+			name: "Soft delete",
+			call: func() (*client.MarketResponse, error) { return admin.SoftDeleteMarket(adminCtx, &action) },
+			want: client.MarketState_MARKET_STATE_CLOSED,
+		},
+		{
+			name: "Restore",
+			call: func() (*client.MarketResponse, error) { return admin.RestoreMarket(adminCtx, &action) },
+			want: client.MarketState_MARKET_STATE_ACTIVE,
+		},
+	}
+
+	for _, step := range steps {
+		resp, err := step.call()
+
+		if err != nil {
+			t.Fatalf("%s: Got = %q\n", step.name, err)
+		}
+
+		if resp.GetMarket().GetState() != step.want {
+			t.Fatalf("%s: Got = %d, Want = %d\n", step.name, resp.GetMarket().GetState(), step.want)
+		}
+	}
+
+	audit, err := admin.GetMarketAudit(adminCtx, &client.GetMarketAuditRequest{MarketId: marketId})
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	// create + 4 steps
+	if len(audit.GetRecords()) != 5 {
+		t.Fatalf("Got = %d records, Want = 5\n", len(audit.GetRecords()))
+	}
+
+	for _, record := range audit.GetRecords() {
+		if record.GetActor() != actor {
+			t.Fatalf("Got = %q, Want = %q\n", record.GetActor(), actor)
+		}
+	}
+}
+
+func TestAdminNeedsToken(t *testing.T) {
+	admin := client.NewSpotInstrumentAdminServiceClient(conn)
+	_, err := admin.CreateMarket(baseCtx, &client.CreateMarketRequest{
+		Actor:      actor,
+		BaseAsset:  "T" + uuid.NewString()[:8],
+		QuoteAsset: "USDT",
+	})
+
+	if stat, _ := status.FromError(err); stat.Code() != codes.Unauthenticated {
+		t.Fatalf("Got = %d, Want = %d\n", stat.Code(), codes.Unauthenticated)
+	}
+}
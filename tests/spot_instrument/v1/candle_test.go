@@ -0,0 +1,111 @@
+package spot_instrument_v1_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	client "github.com/KonnorFrik/BinaryTentacles/internal/generated/spot_instrument/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCandles(t *testing.T) {
+	ctx, cancel := context.WithTimeout(baseCtx, 10*time.Second)
+	defer cancel()
+
+	stream, err := service.StreamCandles(ctx, &client.StreamCandlesRequest{
+		UserRole: client.UserRole_USER_ROLE_CUSTOMER,
+		MarketId: marketIdListed,
+		Interval: client.CandleInterval_CANDLE_INTERVAL_1M,
+	})
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	ticker, err := service.GetTicker(baseCtx, &client.GetTickerRequest{
+		UserRole: client.UserRole_USER_ROLE_CUSTOMER,
+		MarketId: marketIdListed,
+	})
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	// Same price as last trade, so circuit breaker is not tripped.
+	price := ticker.GetTicker().GetLastPrice()
+
+	if price == 0 {
+		price = 100
+	}
+
+	_, err = service.ReportTrade(serviceCtx, &client.ReportTradeRequest{
+		MarketId: marketIdListed,
+		Price:    price,
+		Quantity: 2,
+	})
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	var bar *client.Candle
+
+	// First message may be a bar in progress before trade.
+	for bar.GetTradeCount() == 0 || bar.GetClose() != price {
+		resp, err := stream.Recv()
+
+		if err != nil {
+			t.Fatalf("Got = %q\n", err)
+		}
+
+		bar = resp.GetCandle()
+	}
+
+	if bar.GetClosed() || bar.GetInterval() != client.CandleInterval_CANDLE_INTERVAL_1M {
+		t.Fatalf("Got = %v, Want minute bar in progress\n", bar)
+	}
+
+	candles, err := service.GetCandles(baseCtx, &client.GetCandlesRequest{
+		UserRole: client.UserRole_USER_ROLE_CUSTOMER,
+		MarketId: marketIdListed,
+		Interval: client.CandleInterval_CANDLE_INTERVAL_1M,
+	})
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	if len(candles.GetCandles()) == 0 {
+		t.Fatalf("Got no candles\n")
+	}
+
+	last := candles.GetCandles()[len(candles.GetCandles())-1]
+
+	if !last.GetStart().AsTime().Equal(bar.GetStart().AsTime()) || last.GetVolume() < 2 {
+		t.Fatalf("Got = %v\nWant bar started at %v\n", last, bar.GetStart().AsTime())
+	}
+
+	admin := client.NewSpotInstrumentAdminServiceClient(conn)
+	backfill, err := admin.BackfillCandles(adminCtx, &client.BackfillCandlesRequest{
+		MarketId: marketIdListed,
+	})
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	if backfill.GetTrades() == 0 || backfill.GetCandles() == 0 {
+		t.Fatalf("Got = %v, Want rebuilt candles\n", backfill)
+	}
+
+	_, err = service.GetCandles(baseCtx, &client.GetCandlesRequest{
+		UserRole: client.UserRole_USER_ROLE_CUSTOMER,
+		MarketId: marketIdListed,
+	})
+
+	if stat, _ := status.FromError(err); stat.Code() != codes.InvalidArgument {
+		t.Fatalf("Got = %d, Want = %d\n", stat.Code(), codes.InvalidArgument)
+	}
+}
@@ -0,0 +1,123 @@
+package spot_instrument_v1_test
+
+import (
+	"testing"
+
+	client "github.com/KonnorFrik/BinaryTentacles/internal/generated/spot_instrument/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// Markets from config/markets/integration.json
+	marketIdDisabled  = "117c4cfb-27d8-421b-a8aa-58560ed2658c"
+	marketIdPreLaunch = "4632bdd9-28c5-4bd4-b8a5-c590165f865e"
+	marketIdListed    = "5d6f8857-fafe-432c-8380-2b340ec03bb7"
+)
+
+func TestViewMarketsByRole(t *testing.T) {
+	cases := []struct {
+		name        string
+		role        client.UserRole
+		wantVisible map[string]bool
+	}{
+		{
+			name:        "Customer",
+			role:        client.UserRole_USER_ROLE_CUSTOMER,
+			wantVisible: map[string]bool{marketIdListed: true, marketIdPreLaunch: false, marketIdDisabled: false},
+		},
+		{
+			name:        "Read only",
+			role:        client.UserRole_USER_ROLE_READ_ONLY,
+			wantVisible: map[string]bool{marketIdListed: true, marketIdPreLaunch: false, marketIdDisabled: false},
+		},
+		{
+			name:        "Market maker",
+			role:        client.UserRole_USER_ROLE_MARKET_MAKER,
+			wantVisible: map[string]bool{marketIdListed: true, marketIdPreLaunch: true, marketIdDisabled: false},
+		},
+		{
+			name:        "Admin",
+			role:        client.UserRole_USER_ROLE_ADMIN,
+			wantVisible: map[string]bool{marketIdListed: true, marketIdPreLaunch: true, marketIdDisabled: true},
+		},
+	}
+
+	for _, c := range cases {
+		resp, err := service.ViewMarkets(baseCtx, &client.ViewMarketsRequest{
+			UserRole: c.role,
+			Status:   client.MarketStatusFilter_MARKET_STATUS_FILTER_ALL,
+		})
+
+		if err != nil {
+			t.Fatalf("%s: Got = %q\n", c.name, err)
+		}
+
+		var got = make(map[string]bool)
+
+		for _, m := range resp.GetMarket() {
+			got[m.GetId()] = true
+		}
+
+		for id, want := range c.wantVisible {
+			if got[id] != want {
+				t.Fatalf("%s: market %q: Got = %t, Want = %t\n", c.name, id, got[id], want)
+			}
+		}
+	}
+}
+
+func TestIsAvailableByRole(t *testing.T) {
+	cases := []struct {
+		name      string
+		role      client.UserRole
+		marketId  string
+		wantState client.MarketState
+	}{
+		{
+			name:      "Customer listed",
+			role:      client.UserRole_USER_ROLE_CUSTOMER,
+			marketId:  marketIdListed,
+			wantState: client.MarketState_MARKET_STATE_ACTIVE,
+		},
+		{
+			name:      "Read only listed",
+			role:      client.UserRole_USER_ROLE_READ_ONLY,
+			marketId:  marketIdListed,
+			wantState: client.MarketState_MARKET_STATE_CLOSED,
+		},
+		{
+			name:      "Customer pre-launch",
+			role:      client.UserRole_USER_ROLE_CUSTOMER,
+			marketId:  marketIdPreLaunch,
+			wantState: client.MarketState_MARKET_STATE_CLOSED,
+		},
+		{
+			name:      "Market maker pre-launch",
+			role:      client.UserRole_USER_ROLE_MARKET_MAKER,
+			marketId:  marketIdPreLaunch,
+			wantState: client.MarketState_MARKET_STATE_ACTIVE,
+		},
+	}
+
+	for _, c := range cases {
+		resp, err := service.IsAvailable(baseCtx, &client.IsAvailableRequest{
+			UserRole: c.role,
+			MarketId: c.marketId,
+		})
+
+		if err != nil {
+			t.Fatalf("%s: Got = %q\n", c.name, err)
+		}
+
+		if resp.GetState() != c.wantState {
+			t.Fatalf("%s: Got = %d, Want = %d\n", c.name, resp.GetState(), c.wantState)
+		}
+	}
+
+	_, err := service.IsAvailable(baseCtx, &client.IsAvailableRequest{MarketId: marketIdListed})
+
+	if stat, _ := status.FromError(err); stat.Code() != codes.PermissionDenied {
+		t.Fatalf("Got = %d, Want = %d\n", stat.Code(), codes.PermissionDenied)
+	}
+}
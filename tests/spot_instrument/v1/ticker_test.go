@@ -0,0 +1,118 @@
+package spot_instrument_v1_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	client "github.com/KonnorFrik/BinaryTentacles/internal/generated/spot_instrument/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestTicker(t *testing.T) {
+	ctx, cancel := context.WithTimeout(baseCtx, 10*time.Second)
+	defer cancel()
+
+	stream, err := service.WatchTicker(ctx, &client.WatchTickerRequest{
+		UserRole: client.UserRole_USER_ROLE_CUSTOMER,
+		MarketId: marketIdListed,
+	})
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	if _, err = stream.Recv(); err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	before, err := service.GetTicker(baseCtx, &client.GetTickerRequest{
+		UserRole: client.UserRole_USER_ROLE_CUSTOMER,
+		MarketId: marketIdListed,
+	})
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	// Same price as last trade, so circuit breaker is not tripped.
+	price := before.GetTicker().GetLastPrice()
+
+	if price == 0 {
+		price = 100
+	}
+
+	_, err = service.ReportTrade(serviceCtx, &client.ReportTradeRequest{
+		MarketId: marketIdListed,
+		Price:    price,
+		Quantity: 3,
+		BestBid:  price - 1,
+		BestAsk:  price + 1,
+	})
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	resp, err := stream.Recv()
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	tick := resp.GetTicker()
+
+	if tick.GetSymbol() != "BTC-USDT" {
+		t.Fatalf("Got = %q, Want = %q\n", tick.GetSymbol(), "BTC-USDT")
+	}
+
+	if tick.GetLastPrice() != price || tick.GetLastQuantity() != 3 {
+		t.Fatalf("Got = %d x %d, Want = %d x 3\n", tick.GetLastPrice(), tick.GetLastQuantity(), price)
+	}
+
+	if tick.GetVolume_24H() < before.GetTicker().GetVolume_24H()+3 {
+		t.Fatalf("Got = %d, Want >= %d\n", tick.GetVolume_24H(), before.GetTicker().GetVolume_24H()+3)
+	}
+
+	tickers, err := service.GetTickers(baseCtx, &client.GetTickersRequest{
+		UserRole: client.UserRole_USER_ROLE_CUSTOMER,
+	})
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	for _, tick := range tickers.GetTickers() {
+		if tick.GetMarketId() == marketIdPreLaunch {
+			t.Fatalf("Got ticker of pre-launch market\n")
+		}
+	}
+}
+
+func TestReportTradeRejected(t *testing.T) {
+	req := client.ReportTradeRequest{
+		MarketId: marketIdListed,
+		Price:    100,
+		Quantity: 1,
+	}
+	_, err := service.ReportTrade(baseCtx, &req)
+
+	if stat, _ := status.FromError(err); stat.Code() != codes.Unauthenticated {
+		t.Fatalf("Anonymous: Got = %d, Want = %d\n", stat.Code(), codes.Unauthenticated)
+	}
+
+	_, err = service.ReportTrade(adminCtx, &req)
+
+	if stat, _ := status.FromError(err); stat.Code() != codes.PermissionDenied {
+		t.Fatalf("Admin: Got = %d, Want = %d\n", stat.Code(), codes.PermissionDenied)
+	}
+
+	req.ExecutedAt = timestamppb.New(time.Now().Add(time.Hour))
+	_, err = service.ReportTrade(serviceCtx, &req)
+
+	if stat, _ := status.FromError(err); stat.Code() != codes.InvalidArgument {
+		t.Fatalf("Future: Got = %d, Want = %d\n", stat.Code(), codes.InvalidArgument)
+	}
+}
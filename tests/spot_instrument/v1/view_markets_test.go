@@ -6,7 +6,10 @@ import (
 
 	client "github.com/KonnorFrik/BinaryTentacles/internal/generated/spot_instrument/v1"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 const (
@@ -14,12 +17,22 @@ const (
 	marketIdInvalidMax uint64 = 4
 
 	orderServiceAddr = "0.0.0.0:9999"
+
+	// adminToken - token of admin from container/.env.
+	adminToken = "dev-admin-token"
+	// serviceToken - token of order_service from container/.env.
+	serviceToken = "dev-order-service-token"
 )
 
 var (
+	conn    *grpc.ClientConn
 	service client.SpotInstrumentServiceClient
 	baseCtx = context.Background()
-	orderId uint64
+	// adminCtx - context of calls authenticated as admin.
+	adminCtx = metadata.AppendToOutgoingContext(baseCtx, "authorization", "Bearer "+adminToken)
+	// serviceCtx - context of calls authenticated as internal service.
+	serviceCtx = metadata.AppendToOutgoingContext(baseCtx, "authorization", "Bearer "+serviceToken)
+	orderId    uint64
 )
 
 func init() {
@@ -27,7 +40,8 @@ func init() {
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
 	}
 
-	conn, err := grpc.NewClient(orderServiceAddr, opts...)
+	var err error
+	conn, err = grpc.NewClient(orderServiceAddr, opts...)
 
 	if err != nil {
 		panic(err)
@@ -50,3 +64,114 @@ func TestViewMarkets(t *testing.T) {
 		t.Fatalf("Got no markets")
 	}
 }
+
+func TestGetMarketBySymbol(t *testing.T) {
+	req := client.GetMarketBySymbolRequest{
+		UserRole: client.UserRole_USER_ROLE_CUSTOMER,
+		Symbol:   "btc-usdt",
+	}
+	resp, err := service.GetMarketBySymbol(baseCtx, &req)
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	if resp.GetMarket().GetSymbol() != "BTC-USDT" {
+		t.Fatalf("Got = %q, Want = %q\n", resp.GetMarket().GetSymbol(), "BTC-USDT")
+	}
+
+	if resp.GetMarket().GetBaseAsset() != "BTC" || resp.GetMarket().GetQuoteAsset() != "USDT" {
+		t.Fatalf("Got = %q/%q, Want = BTC/USDT\n", resp.GetMarket().GetBaseAsset(), resp.GetMarket().GetQuoteAsset())
+	}
+}
+
+func TestViewMarketsFilter(t *testing.T) {
+	cases := []struct {
+		name string
+		req  client.ViewMarketsRequest
+		want func(*client.Market) bool
+	}{
+		{
+			name: "Enabled by default",
+			req:  client.ViewMarketsRequest{},
+			want: func(m *client.Market) bool {
+				return m.GetStateReason() != "market is disabled" && m.GetStateReason() != "market is deleted"
+			},
+		},
+		{
+			name: "Quote asset",
+			req:  client.ViewMarketsRequest{QuoteAsset: "usdt"},
+			want: func(m *client.Market) bool { return m.GetQuoteAsset() == "USDT" },
+		},
+		{
+			name: "Symbol prefix",
+			req:  client.ViewMarketsRequest{Status: client.MarketStatusFilter_MARKET_STATUS_FILTER_ALL, SymbolPrefix: "btc-"},
+			want: func(m *client.Market) bool { return m.GetBaseAsset() == "BTC" },
+		},
+	}
+
+	for _, c := range cases {
+		c.req.UserRole = client.UserRole_USER_ROLE_CUSTOMER
+		resp, err := service.ViewMarkets(baseCtx, &c.req)
+
+		if err != nil {
+			t.Fatalf("%s: Got = %q\n", c.name, err)
+		}
+
+		for _, m := range resp.GetMarket() {
+			if !c.want(m) {
+				t.Fatalf("%s: Got unexpected market %q\n", c.name, m.GetSymbol())
+			}
+		}
+	}
+}
+
+func TestViewMarketsPages(t *testing.T) {
+	req := client.ViewMarketsRequest{
+		UserRole: client.UserRole_USER_ROLE_CUSTOMER,
+		Status:   client.MarketStatusFilter_MARKET_STATUS_FILTER_ALL,
+	}
+	all, err := service.ViewMarkets(baseCtx, &req)
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	req.PageSize = 1
+	var seen []string
+
+	for {
+		resp, err := service.ViewMarkets(baseCtx, &req)
+
+		if err != nil {
+			t.Fatalf("Got = %q\n", err)
+		}
+
+		for _, m := range resp.GetMarket() {
+			seen = append(seen, m.GetId())
+		}
+
+		if resp.GetNextPageToken() == "" {
+			break
+		}
+
+		req.PageToken = resp.GetNextPageToken()
+	}
+
+	if len(seen) != len(all.GetMarket()) {
+		t.Fatalf("Got = %d, Want = %d\n", len(seen), len(all.GetMarket()))
+	}
+
+	for i, m := range all.GetMarket() {
+		if seen[i] != m.GetId() {
+			t.Fatalf("Got = %q, Want = %q\n", seen[i], m.GetId())
+		}
+	}
+
+	req.PageToken = "not a token"
+	_, err = service.ViewMarkets(baseCtx, &req)
+
+	if stat, _ := status.FromError(err); stat.Code() != codes.InvalidArgument {
+		t.Fatalf("Got = %d, Want = %d\n", stat.Code(), codes.InvalidArgument)
+	}
+}
@@ -0,0 +1,101 @@
+package spot_instrument_v1_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	client "github.com/KonnorFrik/BinaryTentacles/internal/generated/spot_instrument/v1"
+	"github.com/google/uuid"
+)
+
+func TestWatchMarkets(t *testing.T) {
+	ctx, cancel := context.WithTimeout(baseCtx, 10*time.Second)
+	defer cancel()
+
+	stream, err := service.WatchMarkets(ctx, &client.WatchMarketsRequest{
+		UserRole: client.UserRole_USER_ROLE_CUSTOMER,
+	})
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	first, err := stream.Recv()
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	if first.GetSnapshot() == nil {
+		t.Fatalf("Got no snapshot")
+	}
+
+	admin := client.NewSpotInstrumentAdminServiceClient(conn)
+	created, err := admin.CreateMarket(adminCtx, &client.CreateMarketRequest{
+		BaseAsset:  "W" + uuid.NewString()[:8],
+		QuoteAsset: "USDT",
+		Enabled:    true,
+	})
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	// Other changes can come first, wait for event of created market.
+	var revision uint64
+
+	for revision == 0 {
+		resp, err := stream.Recv()
+
+		if err != nil {
+			t.Fatalf("Got = %q\n", err)
+		}
+
+		if resp.GetRevision() <= first.GetRevision() {
+			t.Fatalf("Got = %d, Want > %d\n", resp.GetRevision(), first.GetRevision())
+		}
+
+		event := resp.GetEvent()
+
+		if event.GetMarket().GetId() == created.GetMarket().GetId() {
+			if event.GetType() != client.MarketEventType_MARKET_EVENT_TYPE_CREATED {
+				t.Fatalf("Got = %d, Want = %d\n", event.GetType(), client.MarketEventType_MARKET_EVENT_TYPE_CREATED)
+			}
+
+			revision = resp.GetRevision()
+		}
+	}
+
+	_, err = admin.DisableMarket(adminCtx, &client.MarketActionRequest{
+		MarketId: created.GetMarket().GetId(),
+	})
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	// Resume after create: disable must come without snapshot.
+	resumed, err := service.WatchMarkets(ctx, &client.WatchMarketsRequest{
+		UserRole:     client.UserRole_USER_ROLE_CUSTOMER,
+		FromRevision: revision,
+	})
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	resp, err := resumed.Recv()
+
+	if err != nil {
+		t.Fatalf("Got = %q\n", err)
+	}
+
+	if resp.GetSnapshot() != nil {
+		t.Fatalf("Got snapshot, Want event")
+	}
+
+	if resp.GetRevision() <= revision {
+		t.Fatalf("Got = %d, Want > %d\n", resp.GetRevision(), revision)
+	}
+}